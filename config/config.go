@@ -31,6 +31,10 @@ type Config struct {
 	AlertScheduledTime                 string
 	AlertScheduledHour                 int
 	AlertScheduledMinute               int
+	FeedbackDelayMinutes               int64
+	MentorBookingReminderMinutes       int64
+	ReferalLinkConfirmDays             int
+	ReferalLinkConfirmGraceDays        int
 }
 
 type S3Config struct {
@@ -68,6 +72,26 @@ func LoadConfig() {
 		alertReminderThird = 60
 	}
 
+	feedbackDelay := viper.GetInt64("FEEDBACK_DELAY_MINUTES")
+	if feedbackDelay == 0 {
+		feedbackDelay = 60
+	}
+
+	mentorBookingReminder := viper.GetInt64("MENTOR_BOOKING_REMINDER_MINUTES")
+	if mentorBookingReminder == 0 {
+		mentorBookingReminder = 60
+	}
+
+	referalLinkConfirmDays := viper.GetInt("REFERAL_LINK_CONFIRM_DAYS")
+	if referalLinkConfirmDays == 0 {
+		referalLinkConfirmDays = 30
+	}
+
+	referalLinkConfirmGraceDays := viper.GetInt("REFERAL_LINK_CONFIRM_GRACE_DAYS")
+	if referalLinkConfirmGraceDays == 0 {
+		referalLinkConfirmGraceDays = 3
+	}
+
 	var alertScheduledTime string
 	var alertScheduledHour, alertScheduledMinute int
 	
@@ -110,6 +134,10 @@ func LoadConfig() {
 		AlertScheduledTime:                 alertScheduledTime,
 		AlertScheduledHour:                 alertScheduledHour,
 		AlertScheduledMinute:               alertScheduledMinute,
+		FeedbackDelayMinutes:               feedbackDelay,
+		MentorBookingReminderMinutes:       mentorBookingReminder,
+		ReferalLinkConfirmDays:             referalLinkConfirmDays,
+		ReferalLinkConfirmGraceDays:        referalLinkConfirmGraceDays,
 		S3: S3Config{
 			Endpoint:  viper.GetString("S3_ENDPOINT"),
 			Region:    viper.GetString("S3_REGION"),
@@ -0,0 +1,19 @@
+ALTER TABLE "events" ADD COLUMN IF NOT EXISTS "capacity" INTEGER NULL;
+
+CREATE TABLE IF NOT EXISTS "event_waitlist" (
+  "id" SERIAL PRIMARY KEY,
+  "event_id" INTEGER NOT NULL,
+  "member_id" INTEGER NOT NULL,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE(event_id, member_id)
+);
+
+CREATE INDEX IF NOT EXISTS "idx_event_waitlist_event_id" ON "event_waitlist"("event_id");
+
+ALTER TABLE "event_waitlist"
+ADD FOREIGN KEY("event_id") REFERENCES "events"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "event_waitlist"
+ADD FOREIGN KEY("member_id") REFERENCES "members"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
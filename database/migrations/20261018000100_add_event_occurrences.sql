@@ -0,0 +1,49 @@
+CREATE TABLE IF NOT EXISTS "event_occurrences" (
+  "id" SERIAL PRIMARY KEY,
+  "event_id" INTEGER NOT NULL,
+  "occurrence_date" TIMESTAMP NOT NULL,
+  "last_alert_sent_at" TIMESTAMP NULL,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE(event_id, occurrence_date)
+);
+
+ALTER TABLE "event_occurrences"
+ADD FOREIGN KEY("event_id") REFERENCES "events"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+CREATE TABLE IF NOT EXISTS "event_occurrence_members" (
+  "occurrence_id" INTEGER NOT NULL,
+  "member_id" INTEGER NOT NULL,
+  PRIMARY KEY (occurrence_id, member_id)
+);
+
+ALTER TABLE "event_occurrence_members"
+ADD FOREIGN KEY("occurrence_id") REFERENCES "event_occurrences"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "event_occurrence_members"
+ADD FOREIGN KEY("member_id") REFERENCES "members"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+-- Лист ожидания и подписки на алерты теперь могут относиться к конкретному повторению
+ALTER TABLE "event_waitlist" ADD COLUMN IF NOT EXISTS "occurrence_id" INTEGER NULL;
+
+ALTER TABLE "event_waitlist"
+ADD FOREIGN KEY("occurrence_id") REFERENCES "event_occurrences"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "event_waitlist" DROP CONSTRAINT IF EXISTS "event_waitlist_event_id_member_id_key";
+
+CREATE UNIQUE INDEX IF NOT EXISTS "event_waitlist_event_member_occurrence_unique"
+    ON "event_waitlist" ("event_id", "member_id", COALESCE("occurrence_id", 0));
+
+ALTER TABLE "event_alert_subscriptions" ADD COLUMN IF NOT EXISTS "occurrence_id" INTEGER NULL;
+
+ALTER TABLE "event_alert_subscriptions"
+ADD FOREIGN KEY("occurrence_id") REFERENCES "event_occurrences"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "event_alert_subscriptions" DROP CONSTRAINT IF EXISTS "event_alert_subscriptions_event_id_member_id_key";
+
+CREATE UNIQUE INDEX IF NOT EXISTS "event_alert_subscriptions_event_member_occurrence_unique"
+    ON "event_alert_subscriptions" ("event_id", "member_id", COALESCE("occurrence_id", 0));
@@ -0,0 +1,21 @@
+-- Правило повторения в формате RFC 5545. Если не задано, используются поля repeat_*
+ALTER TABLE "events" ADD COLUMN IF NOT EXISTS "rrule" TEXT NULL;
+
+-- Отмена (EXDATE) и перенос конкретного повторения
+ALTER TABLE "event_occurrences" ADD COLUMN IF NOT EXISTS "cancelled" BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE "event_occurrences" ADD COLUMN IF NOT EXISTS "override_date" TIMESTAMP NULL;
+
+-- Ведущие конкретного повторения, если они отличаются от ведущих события
+CREATE TABLE IF NOT EXISTS "event_occurrence_hosts" (
+  "occurrence_id" INTEGER NOT NULL,
+  "member_id" INTEGER NOT NULL,
+  PRIMARY KEY (occurrence_id, member_id)
+);
+
+ALTER TABLE "event_occurrence_hosts"
+ADD FOREIGN KEY("occurrence_id") REFERENCES "event_occurrences"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "event_occurrence_hosts"
+ADD FOREIGN KEY("member_id") REFERENCES "members"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
@@ -0,0 +1,10 @@
+-- Секретные токены персональных лент календаря участников
+CREATE TABLE IF NOT EXISTS "member_calendar_tokens" (
+  "member_id" INTEGER PRIMARY KEY,
+  "token" VARCHAR(64) NOT NULL UNIQUE,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE "member_calendar_tokens"
+ADD FOREIGN KEY("member_id") REFERENCES "members"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
@@ -0,0 +1,30 @@
+-- Отзывы участников о прошедших событиях
+CREATE TABLE IF NOT EXISTS "event_feedback" (
+  "id" SERIAL PRIMARY KEY,
+  "event_id" INTEGER NOT NULL,
+  "occurrence_id" INTEGER NULL,
+  "member_id" INTEGER NOT NULL,
+  "rating" SMALLINT NULL CHECK ("rating" BETWEEN 1 AND 5),
+  "comment" TEXT NULL,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE "event_feedback"
+ADD FOREIGN KEY("event_id") REFERENCES "events"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "event_feedback"
+ADD FOREIGN KEY("occurrence_id") REFERENCES "event_occurrences"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "event_feedback"
+ADD FOREIGN KEY("member_id") REFERENCES "members"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS "event_feedback_event_member_occurrence_unique"
+    ON "event_feedback" ("event_id", "member_id", COALESCE("occurrence_id", 0));
+
+-- Отметка о том, что запрос отзыва по событию или повторению уже разослан
+ALTER TABLE "events" ADD COLUMN IF NOT EXISTS "feedback_requested_at" TIMESTAMP NULL;
+ALTER TABLE "event_occurrences" ADD COLUMN IF NOT EXISTS "feedback_requested_at" TIMESTAMP NULL;
@@ -0,0 +1,28 @@
+-- Фактическое посещение офлайн и гибридных событий (отметка по коду участника)
+CREATE TABLE IF NOT EXISTS "event_attendances" (
+  "id" SERIAL PRIMARY KEY,
+  "event_id" INTEGER NOT NULL,
+  "occurrence_id" INTEGER NULL,
+  "member_id" INTEGER NOT NULL,
+  "checked_in_by" INTEGER NOT NULL,
+  "checked_in_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE "event_attendances"
+ADD FOREIGN KEY("event_id") REFERENCES "events"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "event_attendances"
+ADD FOREIGN KEY("occurrence_id") REFERENCES "event_occurrences"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "event_attendances"
+ADD FOREIGN KEY("member_id") REFERENCES "members"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "event_attendances"
+ADD FOREIGN KEY("checked_in_by") REFERENCES "members"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS "event_attendances_event_member_occurrence_unique"
+    ON "event_attendances" ("event_id", "member_id", COALESCE("occurrence_id", 0));
@@ -0,0 +1,56 @@
+-- Черновики событий: события, созданные ведущими на платформе, публикует администратор
+ALTER TABLE "events" ADD COLUMN IF NOT EXISTS "status" VARCHAR(32) NOT NULL DEFAULT 'PUBLISHED';
+ALTER TABLE "events" ADD COLUMN IF NOT EXISTS "created_by" INTEGER NULL;
+
+ALTER TABLE "events"
+ADD FOREIGN KEY("created_by") REFERENCES "members"("id")
+ON UPDATE NO ACTION ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS "events_status_idx" ON "events" ("status");
+
+-- Приглашения соведущих
+CREATE TABLE IF NOT EXISTS "event_host_invitations" (
+  "id" SERIAL PRIMARY KEY,
+  "event_id" INTEGER NOT NULL,
+  "member_id" INTEGER NOT NULL,
+  "invited_by" INTEGER NOT NULL,
+  "status" VARCHAR(32) NOT NULL DEFAULT 'PENDING',
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  "responded_at" TIMESTAMP NULL,
+  CONSTRAINT event_host_invitations_unique_event_member UNIQUE(event_id, member_id)
+);
+
+ALTER TABLE "event_host_invitations"
+ADD FOREIGN KEY("event_id") REFERENCES "events"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "event_host_invitations"
+ADD FOREIGN KEY("member_id") REFERENCES "members"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "event_host_invitations"
+ADD FOREIGN KEY("invited_by") REFERENCES "members"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+-- Публиковать черновики ведущих может только администратор: право редактировать события
+-- в админке есть и у EVENT_MAKER
+INSERT INTO permissions (name)
+SELECT 'can_publish_admin_events'
+WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE name = 'can_publish_admin_events');
+
+INSERT INTO role_permissions (role, permission_id)
+SELECT 'ADMIN', id
+FROM permissions
+WHERE name = 'can_publish_admin_events'
+ON CONFLICT DO NOTHING;
+
+-- Право создавать и редактировать свои события на платформе
+INSERT INTO permissions (name)
+SELECT 'can_edit_platform_events'
+WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE name = 'can_edit_platform_events');
+
+INSERT INTO role_permissions (role, permission_id)
+SELECT role, id
+FROM permissions, (VALUES ('EVENT_MAKER'), ('ADMIN')) AS roles(role)
+WHERE name = 'can_edit_platform_events'
+ON CONFLICT DO NOTHING;
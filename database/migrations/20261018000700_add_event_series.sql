@@ -0,0 +1,33 @@
+-- Серии событий: многочастные курсы и конференции
+CREATE TABLE IF NOT EXISTS "event_series" (
+  "id" SERIAL PRIMARY KEY,
+  "title" VARCHAR NOT NULL,
+  "description" VARCHAR NULL,
+  "kind" VARCHAR(32) NOT NULL DEFAULT 'COURSE',
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE "events" ADD COLUMN IF NOT EXISTS "series_id" INTEGER NULL;
+ALTER TABLE "events" ADD COLUMN IF NOT EXISTS "series_position" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "events" ADD COLUMN IF NOT EXISTS "track" VARCHAR(255) NULL;
+
+ALTER TABLE "events"
+ADD FOREIGN KEY("series_id") REFERENCES "event_series"("id")
+ON UPDATE NO ACTION ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS "events_series_idx" ON "events" ("series_id", "series_position");
+
+-- Участники, записанные на серию целиком: их записывают и на части, добавленные позже
+CREATE TABLE IF NOT EXISTS "event_series_members" (
+  "series_id" INTEGER NOT NULL,
+  "member_id" INTEGER NOT NULL,
+  PRIMARY KEY (series_id, member_id)
+);
+
+ALTER TABLE "event_series_members"
+ADD FOREIGN KEY("series_id") REFERENCES "event_series"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "event_series_members"
+ADD FOREIGN KEY("member_id") REFERENCES "members"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
@@ -0,0 +1,40 @@
+-- Каталог записей событий с главами и уровнями доступа
+CREATE TABLE IF NOT EXISTS "event_recordings" (
+  "id" SERIAL PRIMARY KEY,
+  "event_id" INTEGER NOT NULL,
+  "title" VARCHAR NOT NULL,
+  "description" VARCHAR NULL,
+  "video_link" VARCHAR NULL,
+  "file_path" VARCHAR NULL,
+  "file_name" VARCHAR NULL,
+  "content_type" VARCHAR NULL,
+  "duration" INTEGER NULL,
+  "access" VARCHAR(32) NOT NULL DEFAULT 'MEMBERS',
+  "recorded_at" TIMESTAMP NOT NULL,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE "event_recordings"
+ADD FOREIGN KEY("event_id") REFERENCES "events"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS "event_recordings_event_idx" ON "event_recordings" ("event_id");
+CREATE INDEX IF NOT EXISTS "event_recordings_recorded_at_idx" ON "event_recordings" ("recorded_at");
+
+CREATE TABLE IF NOT EXISTS "event_recording_chapters" (
+  "id" SERIAL PRIMARY KEY,
+  "recording_id" INTEGER NOT NULL,
+  "title" VARCHAR NOT NULL,
+  "start_seconds" INTEGER NOT NULL DEFAULT 0
+);
+
+ALTER TABLE "event_recording_chapters"
+ADD FOREIGN KEY("recording_id") REFERENCES "event_recordings"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+-- Ссылки на видео, которые уже есть у прошедших событий, попадают в каталог как записи для участников
+INSERT INTO "event_recordings" ("event_id", "title", "video_link", "access", "recorded_at")
+SELECT e.id, e.title, e.video_link, 'MEMBERS', e.date
+FROM "events" e
+WHERE e.video_link IS NOT NULL AND e.video_link <> ''
+  AND NOT EXISTS (SELECT 1 FROM "event_recordings" r WHERE r.event_id = e.id);
@@ -0,0 +1,53 @@
+-- Расписание менторов и записи участников на их услуги
+CREATE TABLE IF NOT EXISTS "mentor_slots" (
+  "id" SERIAL PRIMARY KEY,
+  "mentor_id" INTEGER NOT NULL,
+  "starts_at" TIMESTAMP NOT NULL,
+  "ends_at" TIMESTAMP NOT NULL,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  CHECK ("ends_at" > "starts_at")
+);
+
+ALTER TABLE "mentor_slots"
+ADD FOREIGN KEY("mentor_id") REFERENCES "mentors"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS "mentor_slots_mentor_idx" ON "mentor_slots" ("mentor_id", "starts_at");
+
+CREATE TABLE IF NOT EXISTS "mentor_bookings" (
+  "id" SERIAL PRIMARY KEY,
+  "slot_id" INTEGER NOT NULL,
+  "mentor_id" INTEGER NOT NULL,
+  "service_id" INTEGER NOT NULL,
+  "member_id" INTEGER NOT NULL,
+  "status" VARCHAR(32) NOT NULL DEFAULT 'PENDING',
+  "comment" VARCHAR NULL,
+  "cancel_reason" VARCHAR NULL,
+  "changed_by" INTEGER NULL,
+  "reminder_sent_at" TIMESTAMP NULL,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE "mentor_bookings"
+ADD FOREIGN KEY("slot_id") REFERENCES "mentor_slots"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "mentor_bookings"
+ADD FOREIGN KEY("mentor_id") REFERENCES "mentors"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+-- Услугу с предстоящими записями приложение удалить не даст, прошедшие и отмененные записи удаляются вместе с ней
+ALTER TABLE "mentor_bookings"
+ADD FOREIGN KEY("service_id") REFERENCES "services"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "mentor_bookings"
+ADD FOREIGN KEY("member_id") REFERENCES "members"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+-- На слот может быть только одна активная запись
+CREATE UNIQUE INDEX IF NOT EXISTS "mentor_bookings_active_slot_idx" ON "mentor_bookings" ("slot_id")
+WHERE "status" IN ('PENDING', 'CONFIRMED', 'RESCHEDULED');
+
+CREATE INDEX IF NOT EXISTS "mentor_bookings_member_idx" ON "mentor_bookings" ("member_id");
@@ -0,0 +1,23 @@
+-- Отзывы на услуги от участников платформы по итогам встреч с ментором
+ALTER TABLE "reviewOnService" ADD COLUMN IF NOT EXISTS "memberId" INTEGER NULL;
+ALTER TABLE "reviewOnService" ADD COLUMN IF NOT EXISTS "bookingId" INTEGER NULL;
+ALTER TABLE "reviewOnService" ADD COLUMN IF NOT EXISTS "rating" INTEGER NULL;
+ALTER TABLE "reviewOnService" ADD COLUMN IF NOT EXISTS "status" VARCHAR(255) NOT NULL DEFAULT 'DRAFT';
+ALTER TABLE "reviewOnService" ALTER COLUMN "text" TYPE TEXT;
+
+-- Ранее добавленные отзывы уже опубликованы
+UPDATE "reviewOnService" SET "status" = 'APPROVED';
+
+ALTER TABLE "reviewOnService"
+ADD FOREIGN KEY("memberId") REFERENCES "members"("id")
+ON UPDATE NO ACTION ON DELETE SET NULL;
+
+ALTER TABLE "reviewOnService"
+ADD FOREIGN KEY("bookingId") REFERENCES "mentor_bookings"("id")
+ON UPDATE NO ACTION ON DELETE SET NULL;
+
+ALTER TABLE "reviewOnService"
+ADD CONSTRAINT "reviewOnService_rating_check" CHECK ("rating" IS NULL OR "rating" BETWEEN 1 AND 5);
+
+-- По одной встрече можно оставить только один отзыв
+CREATE UNIQUE INDEX IF NOT EXISTS "reviewOnService_booking_idx" ON "reviewOnService" ("bookingId") WHERE "bookingId" IS NOT NULL;
@@ -0,0 +1,63 @@
+-- Заявки участников на менторство
+CREATE TABLE IF NOT EXISTS "mentor_applications" (
+  "id" SERIAL PRIMARY KEY,
+  "member_id" INTEGER NOT NULL,
+  "occupation" VARCHAR NOT NULL,
+  "experience" VARCHAR NOT NULL,
+  "about" VARCHAR NULL,
+  "status" VARCHAR(32) NOT NULL DEFAULT 'PENDING',
+  "review_comment" VARCHAR NULL,
+  "reviewed_at" TIMESTAMP NULL,
+  "mentor_id" INTEGER NULL,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE "mentor_applications"
+ADD FOREIGN KEY("member_id") REFERENCES "members"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "mentor_applications"
+ADD FOREIGN KEY("mentor_id") REFERENCES "mentors"("id")
+ON UPDATE NO ACTION ON DELETE SET NULL;
+
+-- У участника может быть только одна заявка на рассмотрении
+CREATE UNIQUE INDEX IF NOT EXISTS "mentor_applications_pending_idx" ON "mentor_applications" ("member_id")
+WHERE "status" = 'PENDING';
+
+CREATE INDEX IF NOT EXISTS "mentor_applications_status_idx" ON "mentor_applications" ("status", "created_at");
+
+CREATE TABLE IF NOT EXISTS "mentor_application_tags" (
+  "application_id" INTEGER NOT NULL,
+  "tag_id" INTEGER NOT NULL,
+  PRIMARY KEY ("application_id", "tag_id")
+);
+
+ALTER TABLE "mentor_application_tags"
+ADD FOREIGN KEY("application_id") REFERENCES "mentor_applications"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "mentor_application_tags"
+ADD FOREIGN KEY("tag_id") REFERENCES "profTags"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+CREATE TABLE IF NOT EXISTS "mentor_application_services" (
+  "id" SERIAL PRIMARY KEY,
+  "application_id" INTEGER NOT NULL,
+  "name" VARCHAR NOT NULL,
+  "price" INTEGER NOT NULL DEFAULT 0
+);
+
+ALTER TABLE "mentor_application_services"
+ADD FOREIGN KEY("application_id") REFERENCES "mentor_applications"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+CREATE TABLE IF NOT EXISTS "mentor_application_contacts" (
+  "id" SERIAL PRIMARY KEY,
+  "application_id" INTEGER NOT NULL,
+  "type" SMALLINT NOT NULL,
+  "link" VARCHAR NOT NULL
+);
+
+ALTER TABLE "mentor_application_contacts"
+ADD FOREIGN KEY("application_id") REFERENCES "mentor_applications"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
@@ -0,0 +1,49 @@
+-- Версии публичного профиля ментора и модерация правок
+CREATE TABLE IF NOT EXISTS "mentor_revisions" (
+  "id" SERIAL PRIMARY KEY,
+  "mentor_id" INTEGER NOT NULL,
+  "version" INTEGER NOT NULL,
+  "occupation" VARCHAR NULL,
+  "experience" VARCHAR NULL,
+  "status" VARCHAR(32) NOT NULL DEFAULT 'PENDING',
+  "review_comment" VARCHAR NULL,
+  "reviewed_at" TIMESTAMP NULL,
+  "restored_from_id" INTEGER NULL,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE "mentor_revisions"
+ADD FOREIGN KEY("mentor_id") REFERENCES "mentors"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "mentor_revisions"
+ADD FOREIGN KEY("restored_from_id") REFERENCES "mentor_revisions"("id")
+ON UPDATE NO ACTION ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS "mentor_revisions_version_idx" ON "mentor_revisions" ("mentor_id", "version");
+
+CREATE INDEX IF NOT EXISTS "mentor_revisions_status_idx" ON "mentor_revisions" ("status", "created_at");
+
+CREATE TABLE IF NOT EXISTS "mentor_revision_services" (
+  "id" SERIAL PRIMARY KEY,
+  "revision_id" INTEGER NOT NULL,
+  "service_id" INTEGER NOT NULL DEFAULT 0,
+  "name" VARCHAR NOT NULL,
+  "price" INTEGER NOT NULL DEFAULT 0
+);
+
+ALTER TABLE "mentor_revision_services"
+ADD FOREIGN KEY("revision_id") REFERENCES "mentor_revisions"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+CREATE TABLE IF NOT EXISTS "mentor_revision_contacts" (
+  "id" SERIAL PRIMARY KEY,
+  "revision_id" INTEGER NOT NULL,
+  "contact_id" INTEGER NOT NULL DEFAULT 0,
+  "type" SMALLINT NOT NULL,
+  "link" VARCHAR NOT NULL
+);
+
+ALTER TABLE "mentor_revision_contacts"
+ADD FOREIGN KEY("revision_id") REFERENCES "mentor_revisions"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
@@ -0,0 +1,8 @@
+-- Доступность ментора: прием менти, лист ожидания или отпуск
+ALTER TABLE "mentors" ADD COLUMN IF NOT EXISTS "availability" VARCHAR(32) NOT NULL DEFAULT 'ACCEPTING';
+ALTER TABLE "mentors" ADD COLUMN IF NOT EXISTS "vacation_until" TIMESTAMP NULL;
+ALTER TABLE "mentors" ADD COLUMN IF NOT EXISTS "max_mentees" INTEGER NULL;
+ALTER TABLE "mentors" ADD COLUMN IF NOT EXISTS "vacation_reminder_sent_at" TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS "mentors_vacation_idx" ON "mentors" ("vacation_until")
+WHERE "availability" = 'VACATION';
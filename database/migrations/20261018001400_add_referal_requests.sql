@@ -0,0 +1,31 @@
+-- Заявки участников на рекомендацию по реферальным ссылкам
+CREATE TABLE IF NOT EXISTS "referal_requests" (
+  "id" SERIAL PRIMARY KEY,
+  "link_id" INTEGER NOT NULL,
+  "member_id" INTEGER NOT NULL,
+  "resume_id" INTEGER NULL,
+  "message" VARCHAR NULL,
+  "status" VARCHAR(32) NOT NULL DEFAULT 'submitted',
+  "comment" VARCHAR NULL,
+  "changed_by" INTEGER NULL,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE "referal_requests"
+ADD FOREIGN KEY("link_id") REFERENCES "referal_links"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "referal_requests"
+ADD FOREIGN KEY("member_id") REFERENCES "members"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "referal_requests"
+ADD FOREIGN KEY("resume_id") REFERENCES "resumes"("id")
+ON UPDATE NO ACTION ON DELETE SET NULL;
+
+-- По одной ссылке у участника может быть только одна незавершенная заявка
+CREATE UNIQUE INDEX IF NOT EXISTS "referal_requests_active_idx" ON "referal_requests" ("link_id", "member_id")
+WHERE "status" NOT IN ('hired', 'rejected');
+
+CREATE INDEX IF NOT EXISTS "referal_requests_member_idx" ON "referal_requests" ("member_id");
@@ -0,0 +1,5 @@
+-- Подтверждение актуальности реферальных ссылок авторами
+ALTER TABLE "referal_links" ADD COLUMN IF NOT EXISTS "confirmed_at" TIMESTAMP NULL;
+ALTER TABLE "referal_links" ADD COLUMN IF NOT EXISTS "confirmation_requested_at" TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS "referal_links_status_idx" ON "referal_links" ("status");
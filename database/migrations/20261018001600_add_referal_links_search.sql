@@ -0,0 +1,6 @@
+-- Нечеткий поиск реферальных ссылок по компании и фильтры по грейду и тегам
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS "referal_links_company_trgm_idx" ON "referal_links" USING GIN ("company" gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS "referal_links_grade_idx" ON "referal_links" (LOWER("grade"));
+CREATE INDEX IF NOT EXISTS "referal_links_tags_prof_tag_id_idx" ON "referal_links_tags" ("prof_tag_id");
@@ -0,0 +1,69 @@
+-- Справочник компаний с алиасами вместо свободного текста в реферальных ссылках
+CREATE TABLE IF NOT EXISTS "companies" (
+  "id" SERIAL PRIMARY KEY,
+  "name" VARCHAR NOT NULL,
+  "normalized_name" VARCHAR NOT NULL,
+  "website" VARCHAR NULL,
+  "logo_path" VARCHAR NULL,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "companies_normalized_name_idx" ON "companies" ("normalized_name");
+
+CREATE TABLE IF NOT EXISTS "company_aliases" (
+  "id" SERIAL PRIMARY KEY,
+  "company_id" INTEGER NOT NULL,
+  "alias" VARCHAR NOT NULL,
+  "normalized_alias" VARCHAR NOT NULL
+);
+
+ALTER TABLE "company_aliases"
+ADD FOREIGN KEY("company_id") REFERENCES "companies"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS "company_aliases_normalized_alias_idx" ON "company_aliases" ("normalized_alias");
+
+-- Поиск ссылок по компании идет по справочнику: названию и алиасам
+CREATE INDEX IF NOT EXISTS "companies_normalized_name_trgm_idx" ON "companies" USING GIN ("normalized_name" gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS "company_aliases_normalized_alias_trgm_idx" ON "company_aliases" USING GIN ("normalized_alias" gin_trgm_ops);
+
+ALTER TABLE "referal_links" ADD COLUMN IF NOT EXISTS "company_id" INTEGER NULL;
+ALTER TABLE "referal_links"
+ADD FOREIGN KEY("company_id") REFERENCES "companies"("id")
+ON UPDATE NO ACTION ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS "referal_links_company_id_idx" ON "referal_links" ("company_id");
+
+ALTER TABLE "members" ADD COLUMN IF NOT EXISTS "company_id" INTEGER NULL;
+ALTER TABLE "members"
+ADD FOREIGN KEY("company_id") REFERENCES "companies"("id")
+ON UPDATE NO ACTION ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS "members_company_id_idx" ON "members" ("company_id");
+
+-- Существующие названия из ссылок попадают в справочник, разные регистры и пробелы сливаются в одну компанию
+INSERT INTO "companies" ("name", "normalized_name")
+SELECT MIN(TRIM("company")), LOWER(REGEXP_REPLACE(TRIM(REGEXP_REPLACE("company", '["''«»`]', ' ', 'g')), '\s+', ' ', 'g'))
+FROM "referal_links"
+WHERE TRIM(COALESCE("company", '')) <> ''
+GROUP BY 2
+ON CONFLICT DO NOTHING;
+
+UPDATE "referal_links" rl
+SET "company_id" = c.id, "company" = c.name
+FROM "companies" c
+WHERE rl.company_id IS NULL
+  AND c.normalized_name = LOWER(REGEXP_REPLACE(TRIM(REGEXP_REPLACE(rl.company, '["''«»`]', ' ', 'g')), '\s+', ' ', 'g'));
+
+INSERT INTO permissions (name)
+SELECT p.name
+FROM (VALUES ('can_view_admin_companies'), ('can_edit_admin_companies')) AS p(name)
+WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE permissions.name = p.name);
+
+INSERT INTO role_permissions (role, permission_id)
+SELECT 'ADMIN', p.id
+FROM permissions p
+WHERE p.name IN ('can_view_admin_companies', 'can_edit_admin_companies')
+  AND NOT EXISTS (
+        SELECT 1 FROM role_permissions rp
+        WHERE rp.role = 'ADMIN' AND rp.permission_id = p.id
+    );
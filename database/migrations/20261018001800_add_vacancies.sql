@@ -0,0 +1,78 @@
+-- Доска вакансий от участников с модерацией и рассылкой по тегам
+CREATE TABLE IF NOT EXISTS "vacancies" (
+  "id" SERIAL PRIMARY KEY,
+  "author_id" INTEGER NOT NULL,
+  "title" VARCHAR NOT NULL,
+  "company_id" INTEGER NULL,
+  "company" VARCHAR NOT NULL,
+  "grade" VARCHAR NULL,
+  "work_format" VARCHAR(32) NULL,
+  "salary_from" INTEGER NULL,
+  "salary_to" INTEGER NULL,
+  "description" TEXT NULL,
+  "expires_at" TIMESTAMP NOT NULL,
+  "status" VARCHAR(32) NOT NULL DEFAULT 'PENDING',
+  "review_comment" VARCHAR NULL,
+  "reviewed_at" TIMESTAMP NULL,
+  "announced_at" TIMESTAMP NULL,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE "vacancies"
+ADD FOREIGN KEY("author_id") REFERENCES "members"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "vacancies"
+ADD FOREIGN KEY("company_id") REFERENCES "companies"("id")
+ON UPDATE NO ACTION ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS "vacancies_status_expires_idx" ON "vacancies" ("status", "expires_at");
+CREATE INDEX IF NOT EXISTS "vacancies_author_idx" ON "vacancies" ("author_id");
+CREATE INDEX IF NOT EXISTS "vacancies_company_idx" ON "vacancies" ("company_id");
+
+CREATE TABLE IF NOT EXISTS "vacancy_tags" (
+  "vacancy_id" INTEGER NOT NULL,
+  "prof_tag_id" INTEGER NOT NULL,
+  PRIMARY KEY ("vacancy_id", "prof_tag_id")
+);
+
+ALTER TABLE "vacancy_tags"
+ADD FOREIGN KEY("vacancy_id") REFERENCES "vacancies"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "vacancy_tags"
+ADD FOREIGN KEY("prof_tag_id") REFERENCES "profTags"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS "vacancy_tags_prof_tag_idx" ON "vacancy_tags" ("prof_tag_id");
+
+CREATE TABLE IF NOT EXISTS "vacancy_subscriptions" (
+  "member_id" INTEGER NOT NULL,
+  "prof_tag_id" INTEGER NOT NULL,
+  PRIMARY KEY ("member_id", "prof_tag_id")
+);
+
+ALTER TABLE "vacancy_subscriptions"
+ADD FOREIGN KEY("member_id") REFERENCES "members"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "vacancy_subscriptions"
+ADD FOREIGN KEY("prof_tag_id") REFERENCES "profTags"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS "vacancy_subscriptions_prof_tag_idx" ON "vacancy_subscriptions" ("prof_tag_id");
+
+INSERT INTO permissions (name)
+SELECT p.name
+FROM (VALUES ('can_view_admin_vacancies'), ('can_edit_admin_vacancies')) AS p(name)
+WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE permissions.name = p.name);
+
+INSERT INTO role_permissions (role, permission_id)
+SELECT 'ADMIN', p.id
+FROM permissions p
+WHERE p.name IN ('can_view_admin_vacancies', 'can_edit_admin_vacancies')
+  AND NOT EXISTS (
+        SELECT 1 FROM role_permissions rp
+        WHERE rp.role = 'ADMIN' AND rp.permission_id = p.id
+    );
@@ -0,0 +1,21 @@
+-- Сохраненные поиски по ссылкам, вакансиям и резюме с уведомлениями в боте
+CREATE TABLE IF NOT EXISTS "saved_searches" (
+  "id" SERIAL PRIMARY KEY,
+  "member_id" INTEGER NOT NULL,
+  "name" VARCHAR NOT NULL,
+  "kind" VARCHAR(32) NOT NULL,
+  "filter" JSONB NOT NULL DEFAULT '{}',
+  "frequency" VARCHAR(32) NOT NULL DEFAULT 'DAILY',
+  "checked_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "last_notified_at" TIMESTAMP NULL,
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE "saved_searches"
+ADD FOREIGN KEY("member_id") REFERENCES "members"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS "saved_searches_member_idx" ON "saved_searches" ("member_id");
+CREATE INDEX IF NOT EXISTS "saved_searches_frequency_checked_idx" ON "saved_searches" ("frequency", "checked_at");
+CREATE INDEX IF NOT EXISTS "resumes_created_at_idx" ON "resumes" ("created_at");
+CREATE INDEX IF NOT EXISTS "referal_links_created_at_idx" ON "referal_links" ("created_at");
@@ -0,0 +1,38 @@
+-- Разобранный профиль резюме: опыт, образование, навыки и контакты
+ALTER TABLE "resumes" ADD COLUMN IF NOT EXISTS "profile" JSONB NULL;
+ALTER TABLE "resumes" ADD COLUMN IF NOT EXISTS "experience_years" NUMERIC(4, 1) NULL;
+ALTER TABLE "resumes" ADD COLUMN IF NOT EXISTS "parsed_at" TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS "resumes_experience_years_idx" ON "resumes" ("experience_years");
+
+-- Навыки из резюме, сопоставленные с тегами
+CREATE TABLE IF NOT EXISTS "resume_tags" (
+  "resume_id" INTEGER NOT NULL,
+  "prof_tag_id" INTEGER NOT NULL,
+  PRIMARY KEY ("resume_id", "prof_tag_id")
+);
+
+ALTER TABLE "resume_tags"
+ADD FOREIGN KEY("resume_id") REFERENCES "resumes"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "resume_tags"
+ADD FOREIGN KEY("prof_tag_id") REFERENCES "profTags"("id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS "resume_tags_prof_tag_idx" ON "resume_tags" ("prof_tag_id");
+
+-- Повторный разбор резюме меняет данные, поэтому требует отдельного права
+INSERT INTO permissions (name)
+SELECT p.name
+FROM (VALUES ('can_edit_admin_resumes')) AS p(name)
+WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE permissions.name = p.name);
+
+INSERT INTO role_permissions (role, permission_id)
+SELECT 'ADMIN', p.id
+FROM permissions p
+WHERE p.name = 'can_edit_admin_resumes'
+  AND NOT EXISTS (
+        SELECT 1 FROM role_permissions rp
+        WHERE rp.role = 'ADMIN' AND rp.permission_id = p.id
+    );
@@ -0,0 +1,16 @@
+-- Полнотекстовый поиск по тексту резюме в русской и английской конфигурациях.
+-- Текст старых резюме извлекается через POST /api/admin/resumes/reindex
+ALTER TABLE "resumes" ADD COLUMN IF NOT EXISTS "text" TEXT NULL;
+
+ALTER TABLE "resumes" ADD COLUMN IF NOT EXISTS "search_vector" TSVECTOR
+GENERATED ALWAYS AS (
+  setweight(to_tsvector('russian', coalesce("desired_position", '')), 'A') ||
+  setweight(to_tsvector('english', coalesce("desired_position", '')), 'A') ||
+  setweight(to_tsvector('russian', coalesce("profile"->>'skills', '')), 'B') ||
+  setweight(to_tsvector('english', coalesce("profile"->>'skills', '')), 'B') ||
+  to_tsvector('russian', coalesce("text", '')) ||
+  to_tsvector('english', coalesce("text", ''))
+) STORED;
+
+CREATE INDEX IF NOT EXISTS "resumes_search_vector_idx" ON "resumes" USING GIN ("search_vector");
+CREATE INDEX IF NOT EXISTS "resumes_unindexed_idx" ON "resumes" ("id") WHERE "text" IS NULL;
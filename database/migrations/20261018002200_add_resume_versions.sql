@@ -0,0 +1,48 @@
+-- Версии резюме участника: номер загрузки и основное резюме, которое видят рекрутеры
+ALTER TABLE "resumes" ADD COLUMN IF NOT EXISTS "version" INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE "resumes" ADD COLUMN IF NOT EXISTS "is_primary" BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Уже загруженные резюме нумеруются по дате, основным становится последнее
+UPDATE "resumes" r
+SET "version" = v."rn"
+FROM (
+  SELECT "id", ROW_NUMBER() OVER (PARTITION BY "tg_id" ORDER BY "created_at", "id") AS "rn"
+  FROM "resumes"
+) v
+WHERE r."id" = v."id";
+
+UPDATE "resumes"
+SET "is_primary" = TRUE
+WHERE "id" IN (
+  SELECT DISTINCT ON ("tg_id") "id"
+  FROM "resumes"
+  ORDER BY "tg_id", "created_at" DESC, "id" DESC
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "resumes_tg_id_version_idx" ON "resumes" ("tg_id", "version");
+CREATE UNIQUE INDEX IF NOT EXISTS "resumes_primary_idx" ON "resumes" ("tg_id") WHERE "is_primary";
+
+-- История изменений резюме с разницей разобранных полей
+CREATE TABLE IF NOT EXISTS "resume_changes" (
+  "id" SERIAL PRIMARY KEY,
+  "tg_id" BIGINT NOT NULL,
+  "resume_id" INTEGER NULL,
+  "previous_resume_id" INTEGER NULL,
+  "kind" VARCHAR(32) NOT NULL,
+  "changes" JSONB NOT NULL DEFAULT '[]',
+  "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE "resume_changes"
+ADD FOREIGN KEY("tg_id") REFERENCES "members"("telegram_id")
+ON UPDATE NO ACTION ON DELETE CASCADE;
+
+ALTER TABLE "resume_changes"
+ADD FOREIGN KEY("resume_id") REFERENCES "resumes"("id")
+ON UPDATE NO ACTION ON DELETE SET NULL;
+
+ALTER TABLE "resume_changes"
+ADD FOREIGN KEY("previous_resume_id") REFERENCES "resumes"("id")
+ON UPDATE NO ACTION ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS "resume_changes_tg_id_idx" ON "resume_changes" ("tg_id", "created_at");
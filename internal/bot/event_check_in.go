@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleCheckInCommand выдает коды отметки на офлайн-события участника на ближайшие сутки.
+// Ведущий сканирует код с экрана или вводит его вручную
+func (b *TelegramBot) handleCheckInCommand(message *tgbotapi.Message) {
+	member, err := b.member.GetByTelegramID(message.From.ID)
+	if err != nil {
+		b.sendMessage(message.Chat.ID, "Вы не зарегистрированы на платформе")
+		return
+	}
+
+	passes, err := b.eventCheckIn.GetMemberPasses(member.Id, time.Now())
+	if err != nil {
+		log.Printf("Error getting check-in passes for member %d: %v", member.Id, err)
+		b.sendMessage(message.Chat.ID, "Не удалось получить коды отметки, попробуйте позже")
+		return
+	}
+
+	if len(passes) == 0 {
+		b.sendMessage(message.Chat.ID, "В ближайшие сутки у вас нет офлайн-событий, на которые нужна отметка")
+		return
+	}
+
+	var builder strings.Builder
+	builder.WriteString("🎟 <b>Коды для отметки на событиях</b>\n")
+	builder.WriteString("Покажите код ведущему на входе.\n")
+	for _, pass := range passes {
+		builder.WriteString(fmt.Sprintf("\n<b>%s</b>\n", pass.Event.Title))
+		builder.WriteString(fmt.Sprintf("📆 %s (МСК)\n", b.formatMoscowDate(pass.Event.Date)))
+		if pass.Event.Place != "" {
+			builder.WriteString(fmt.Sprintf("📍 %s\n", pass.Event.Place))
+		}
+		builder.WriteString(fmt.Sprintf("<code>%s</code>\n", pass.Code))
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, builder.String())
+	msg.ParseMode = "HTML"
+	if _, err := b.bot.Send(msg); err != nil {
+		log.Printf("Error sending check-in passes: %v", err)
+	}
+}
+
+// formatMoscowDate форматирует дату в московском времени
+func (b *TelegramBot) formatMoscowDate(date time.Time) string {
+	moscowLocation, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		return date.UTC().Add(3 * time.Hour).Format("02.01.2006 в 15:04")
+	}
+	return date.In(moscowLocation).Format("02.01.2006 в 15:04")
+}
@@ -0,0 +1,223 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"ithozyeva/config"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/service"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// feedbackLookback ограничивает, насколько давно прошедшие события еще опрашиваются
+	feedbackLookback = 24 * time.Hour
+	// feedbackCommentTimeout сколько бот ждет комментарий после оценки
+	feedbackCommentTimeout = 24 * time.Hour
+)
+
+// pendingFeedbackComment отзыв, к которому участник может дописать комментарий следующим сообщением
+type pendingFeedbackComment struct {
+	feedbackId int64
+	expiresAt  time.Time
+}
+
+// feedbackComments ожидающие комментарии по telegram_id участника
+type feedbackComments struct {
+	mu      sync.Mutex
+	pending map[int64]pendingFeedbackComment
+}
+
+func newFeedbackComments() *feedbackComments {
+	return &feedbackComments{pending: make(map[int64]pendingFeedbackComment)}
+}
+
+func (f *feedbackComments) set(telegramID int64, feedbackId int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pending[telegramID] = pendingFeedbackComment{feedbackId: feedbackId, expiresAt: time.Now().Add(feedbackCommentTimeout)}
+}
+
+// take возвращает и удаляет ожидающий комментарий участника
+func (f *feedbackComments) take(telegramID int64) (int64, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pending, ok := f.pending[telegramID]
+	if !ok {
+		return 0, false
+	}
+	delete(f.pending, telegramID)
+	if time.Now().After(pending.expiresAt) {
+		return 0, false
+	}
+	return pending.feedbackId, true
+}
+
+func (f *feedbackComments) remove(telegramID int64, feedbackId int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if pending, ok := f.pending[telegramID]; ok && pending.feedbackId == feedbackId {
+		delete(f.pending, telegramID)
+	}
+}
+
+func (b *TelegramBot) startFeedbackScheduler() {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		b.checkAndSendFeedbackRequests()
+	}
+}
+
+// checkAndSendFeedbackRequests рассылает запрос оценки участникам событий, прошедших FEEDBACK_DELAY_MINUTES назад
+func (b *TelegramBot) checkAndSendFeedbackRequests() {
+	now := time.Now()
+	delay := time.Duration(config.CFG.FeedbackDelayMinutes) * time.Minute
+
+	requests, err := b.eventFeedback.GetDueRequests(now, delay, feedbackLookback)
+	if err != nil {
+		log.Printf("Error getting events for feedback requests: %v", err)
+		return
+	}
+
+	for i := range requests {
+		request := &requests[i]
+		for _, member := range request.Members {
+			if member.TelegramID == 0 {
+				continue
+			}
+
+			feedback, err := b.eventFeedback.CreateRequest(request, member.Id)
+			if err != nil {
+				log.Printf("Error creating feedback request for member %d: %v", member.Id, err)
+				continue
+			}
+			if feedback == nil {
+				continue
+			}
+
+			if err := b.sendFeedbackRequest(member.TelegramID, request.Event, feedback); err != nil {
+				if strings.Contains(err.Error(), "chat not found") {
+					continue
+				}
+				log.Printf("Error sending feedback request to user %d: %v", member.TelegramID, err)
+			}
+		}
+
+		if err := b.eventFeedback.MarkRequested(request, now); err != nil {
+			log.Printf("Error marking feedback requested for event %d: %v", request.Event.Id, err)
+		}
+	}
+}
+
+func (b *TelegramBot) sendFeedbackRequest(telegramID int64, event *models.Event, feedback *models.EventFeedback) error {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("🙌 <b>Как прошло событие «%s»?</b>\n\n", event.Title))
+	builder.WriteString("Оцените его от 1 до 5 — это поможет ведущим сделать следующие встречи лучше.")
+
+	buttons := make([]tgbotapi.InlineKeyboardButton, 0, 5)
+	for rating := 1; rating <= 5; rating++ {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("%d ⭐", rating),
+			fmt.Sprintf("event_feedback:%d:%d", feedback.Id, rating),
+		))
+	}
+
+	msg := tgbotapi.NewMessage(telegramID, builder.String())
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(buttons)
+
+	_, err := b.bot.Send(msg)
+	return err
+}
+
+// handleFeedbackRating сохраняет оценку из inline-кнопки и предлагает оставить комментарий
+func (b *TelegramBot) handleFeedbackRating(callback *tgbotapi.CallbackQuery, data string) {
+	var feedbackId int64
+	var rating int
+	if _, err := fmt.Sscanf(strings.TrimPrefix(data, "event_feedback:"), "%d:%d", &feedbackId, &rating); err != nil {
+		b.answerCallbackQuery(callback.ID, "Ошибка: неверные данные")
+		return
+	}
+
+	member, err := b.member.GetByTelegramID(callback.From.ID)
+	if err != nil {
+		log.Printf("Error getting member by telegram ID %d: %v", callback.From.ID, err)
+		b.answerCallbackQuery(callback.ID, "Ошибка: пользователь не найден")
+		return
+	}
+
+	if _, err := b.eventFeedback.Rate(feedbackId, member.Id, rating); err != nil {
+		log.Printf("Error saving feedback rating: %v", err)
+		b.answerCallbackQuery(callback.ID, "Ошибка при сохранении оценки")
+		return
+	}
+
+	b.answerCallbackQuery(callback.ID, "Спасибо за оценку!")
+
+	// Обновляем сообщение, убирая кнопки
+	text := fmt.Sprintf("%s\n\nВаша оценка: %s", callback.Message.Text, strings.Repeat("⭐", rating))
+	editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	b.bot.Send(editMsg)
+
+	b.feedbackComments.set(callback.From.ID, feedbackId)
+
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, "Если хотите, напишите пару слов о событии ответным сообщением — ведущие их прочитают.")
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Пропустить", fmt.Sprintf("event_feedback_skip:%d", feedbackId)),
+		),
+	)
+	if _, err := b.bot.Send(msg); err != nil {
+		log.Printf("Error sending feedback comment prompt: %v", err)
+	}
+}
+
+// handleFeedbackSkip отменяет ожидание комментария
+func (b *TelegramBot) handleFeedbackSkip(callback *tgbotapi.CallbackQuery, data string) {
+	var feedbackId int64
+	fmt.Sscanf(strings.TrimPrefix(data, "event_feedback_skip:"), "%d", &feedbackId)
+
+	b.feedbackComments.remove(callback.From.ID, feedbackId)
+	b.answerCallbackQuery(callback.ID, "Хорошо, спасибо за оценку!")
+
+	editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, "Спасибо за оценку!")
+	b.bot.Send(editMsg)
+}
+
+// handleFeedbackComment сохраняет комментарий, если участник только что поставил оценку.
+// Возвращает false, если сообщение не относится к отзыву
+func (b *TelegramBot) handleFeedbackComment(message *tgbotapi.Message) bool {
+	if message.From == nil || message.Text == "" || !message.Chat.IsPrivate() {
+		return false
+	}
+
+	feedbackId, ok := b.feedbackComments.take(message.From.ID)
+	if !ok {
+		return false
+	}
+
+	member, err := b.member.GetByTelegramID(message.From.ID)
+	if err != nil {
+		log.Printf("Error getting member by telegram ID %d: %v", message.From.ID, err)
+		return true
+	}
+
+	if _, err := b.eventFeedback.Comment(feedbackId, member.Id, message.Text); err != nil {
+		if err == service.ErrFeedbackNotFound {
+			return false
+		}
+		log.Printf("Error saving feedback comment: %v", err)
+		b.sendMessage(message.Chat.ID, "Не удалось сохранить комментарий, попробуйте позже")
+		return true
+	}
+
+	b.sendMessage(message.Chat.ID, "Спасибо за отзыв! 💜")
+	return true
+}
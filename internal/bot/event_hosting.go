@@ -0,0 +1,134 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/service"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// SendCoHostInvitation отправляет приглашение стать соведущим с кнопками ответа
+func (b *TelegramBot) SendCoHostInvitation(invitation *models.EventHostInvitation) error {
+	if invitation.Member.TelegramID == 0 || invitation.Event == nil {
+		return nil
+	}
+
+	var builder strings.Builder
+	builder.WriteString("🎤 <b>Приглашение стать соведущим</b>\n\n")
+	builder.WriteString(fmt.Sprintf("%s приглашает вас вести событие <b>%s</b>",
+		strings.TrimSpace(invitation.InvitedBy.FirstName+" "+invitation.InvitedBy.LastName), invitation.Event.Title))
+	builder.WriteString(fmt.Sprintf(" %s (МСК).", b.formatMoscowDate(invitation.Event.Date)))
+
+	msg := tgbotapi.NewMessage(invitation.Member.TelegramID, builder.String())
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Принять", fmt.Sprintf("event_cohost_accept:%d", invitation.Id)),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отклонить", fmt.Sprintf("event_cohost_decline:%d", invitation.Id)),
+		),
+	)
+
+	if _, err := b.bot.Send(msg); err != nil {
+		if strings.Contains(err.Error(), "chat not found") {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// SendCoHostInvitationResponse сообщает пригласившему, что участник ответил на приглашение
+func (b *TelegramBot) SendCoHostInvitationResponse(invitation *models.EventHostInvitation) error {
+	if invitation.InvitedBy.TelegramID == 0 || invitation.Event == nil {
+		return nil
+	}
+
+	name := strings.TrimSpace(invitation.Member.FirstName + " " + invitation.Member.LastName)
+	text := fmt.Sprintf("%s отклонил(а) приглашение вести событие <b>%s</b>.", name, invitation.Event.Title)
+	if invitation.Status == models.EventHostInvitationAccepted {
+		text = fmt.Sprintf("%s теперь соведущий(ая) события <b>%s</b> 🎉", name, invitation.Event.Title)
+	}
+
+	msg := tgbotapi.NewMessage(invitation.InvitedBy.TelegramID, text)
+	msg.ParseMode = "HTML"
+	if _, err := b.bot.Send(msg); err != nil {
+		if strings.Contains(err.Error(), "chat not found") {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// SendEventPublishedAlert сообщает ведущим, что администратор опубликовал их черновик
+func (b *TelegramBot) SendEventPublishedAlert(event *models.Event) error {
+	text := fmt.Sprintf("✅ Событие <b>%s</b> опубликовано, запись открыта.", event.Title)
+
+	for _, host := range event.Hosts {
+		if host.TelegramID == 0 {
+			continue
+		}
+
+		msg := tgbotapi.NewMessage(host.TelegramID, text)
+		msg.ParseMode = "HTML"
+		if _, err := b.bot.Send(msg); err != nil {
+			if strings.Contains(err.Error(), "chat not found") {
+				continue
+			}
+			log.Printf("Error sending event published alert to host %d: %v", host.Id, err)
+		}
+	}
+	return nil
+}
+
+// handleCoHostResponse обрабатывает ответ на приглашение стать соведущим
+func (b *TelegramBot) handleCoHostResponse(callback *tgbotapi.CallbackQuery, data string, accept bool) {
+	prefix := "event_cohost_decline:"
+	if accept {
+		prefix = "event_cohost_accept:"
+	}
+
+	invitationId, err := strconv.ParseInt(strings.TrimPrefix(data, prefix), 10, 64)
+	if err != nil {
+		b.answerCallbackQuery(callback.ID, "Ошибка: неверные данные")
+		return
+	}
+
+	member, err := b.member.GetByTelegramID(callback.From.ID)
+	if err != nil {
+		log.Printf("Error getting member by telegram ID %d: %v", callback.From.ID, err)
+		b.answerCallbackQuery(callback.ID, "Ошибка: пользователь не найден")
+		return
+	}
+
+	invitation, err := b.eventHosting.RespondInvitation(invitationId, member.Id, accept)
+	if err != nil {
+		if err == service.ErrInvitationAnswered || err == service.ErrInvitationNotFound {
+			b.answerCallbackQuery(callback.ID, err.Error())
+			return
+		}
+		log.Printf("Error responding to co-host invitation %d: %v", invitationId, err)
+		b.answerCallbackQuery(callback.ID, "Ошибка при сохранении ответа")
+		return
+	}
+
+	answer := "Приглашение отклонено"
+	if accept {
+		answer = "Теперь вы соведущий события!"
+	}
+	b.answerCallbackQuery(callback.ID, answer)
+
+	// Обновляем сообщение, убирая кнопки
+	editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID,
+		fmt.Sprintf("%s\n\n%s", callback.Message.Text, answer))
+	b.bot.Send(editMsg)
+
+	if err := b.SendCoHostInvitationResponse(invitation); err != nil {
+		log.Printf("Error sending co-host response alert %d: %v", invitation.Id, err)
+	}
+}
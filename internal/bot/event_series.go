@@ -0,0 +1,102 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/service"
+	"ithozyeva/internal/utils"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// formatSeries описывает место события в серии. Для события вне серии возвращает пустую строку
+func (b *TelegramBot) formatSeries(event *models.Event) string {
+	label := utils.EventSeriesLabel(event)
+	if label == "" {
+		return ""
+	}
+	return fmt.Sprintf("📚 %s\n", label)
+}
+
+// SendSeriesEnrollmentAlert подтверждает запись на серию и перечисляет ее части
+func (b *TelegramBot) SendSeriesEnrollmentAlert(member *models.Member, enrollment *service.SeriesEnrollment) error {
+	if member.TelegramID == 0 {
+		return nil
+	}
+
+	msg := tgbotapi.NewMessage(member.TelegramID, b.formatSeriesEnrollment(enrollment))
+	msg.ParseMode = "HTML"
+	if _, err := b.bot.Send(msg); err != nil {
+		if strings.Contains(err.Error(), "chat not found") {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (b *TelegramBot) formatSeriesEnrollment(enrollment *service.SeriesEnrollment) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("📚 <b>Вы записаны на серию «%s»</b>\n", html.EscapeString(enrollment.Series.Title)))
+
+	if len(enrollment.Parts) == 0 {
+		builder.WriteString("\nПредстоящих частей пока нет — мы запишем вас на новые части, как только они появятся.")
+		return builder.String()
+	}
+
+	builder.WriteString("\n")
+	waitlisted := false
+	for _, part := range enrollment.Parts {
+		line := html.EscapeString(part.Title)
+		if part.Track != "" {
+			line = fmt.Sprintf("%s [%s]", line, html.EscapeString(part.Track))
+		}
+		line = fmt.Sprintf("%d. %s — %s (МСК)", part.SeriesPosition, line, b.formatMoscowDate(part.Date))
+		if part.AttendanceStatus == models.EventAttendanceWaitlisted {
+			line += " — ⏳ лист ожидания"
+			waitlisted = true
+		}
+		builder.WriteString(line + "\n")
+	}
+	builder.WriteString("\nНапоминания придут перед каждой частью, на которую вы записаны.")
+	if waitlisted {
+		builder.WriteString("\nЕсли в части из листа ожидания освободится место, мы запишем вас и пришлем уведомление.")
+	}
+
+	return builder.String()
+}
+
+// handleSeriesApply записывает участника на серию по кнопке из анонса части
+func (b *TelegramBot) handleSeriesApply(callback *tgbotapi.CallbackQuery, data string) {
+	var seriesId int64
+	if _, err := fmt.Sscanf(strings.TrimPrefix(data, "series_apply:"), "%d", &seriesId); err != nil {
+		b.answerCallbackQuery(callback.ID, "Ошибка: неверные данные")
+		return
+	}
+
+	member, err := b.member.GetByTelegramID(callback.From.ID)
+	if err != nil {
+		log.Printf("Error getting member by telegram ID %d: %v", callback.From.ID, err)
+		b.answerCallbackQuery(callback.ID, "Ошибка: пользователь не найден")
+		return
+	}
+
+	enrollment, err := b.eventSeries.Apply(seriesId, member.Id)
+	if err != nil {
+		log.Printf("Error applying member %d to series %d: %v", member.Id, seriesId, err)
+		b.answerCallbackQuery(callback.ID, "Ошибка при записи на серию")
+		return
+	}
+
+	b.answerCallbackQuery(callback.ID, "Вы записаны на всю серию!")
+
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, b.formatSeriesEnrollment(enrollment))
+	msg.ParseMode = "HTML"
+	if _, err := b.bot.Send(msg); err != nil {
+		log.Printf("Error sending series enrollment message: %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+
+	"ithozyeva/internal/models"
+)
+
+// SendMentorApplicationDecision сообщает участнику решение по заявке стать ментором
+func (b *TelegramBot) SendMentorApplicationDecision(application *models.MentorApplication) error {
+	if application.Member == nil {
+		return nil
+	}
+
+	var text string
+	switch application.Status {
+	case models.MentorApplicationApproved:
+		text = "🎉 <b>Заявка на менторство одобрена</b>\n\n" +
+			"Теперь вы ментор сообщества. Заполните расписание и проверьте профиль на платформе."
+	case models.MentorApplicationRejected:
+		text = "😔 <b>Заявка на менторство отклонена</b>"
+	default:
+		return nil
+	}
+	if application.ReviewComment != "" {
+		text += fmt.Sprintf("\n\n💬 %s", html.EscapeString(application.ReviewComment))
+	}
+
+	return b.sendHTMLMessage(application.Member.TelegramID, text, nil)
+}
@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"log"
+	"time"
+
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/service"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func (b *TelegramBot) startMentorVacationReminders() {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		b.checkAndSendVacationReminders()
+	}
+}
+
+// checkAndSendVacationReminders напоминает менторам, что отпуск закончился и пора снова открыть запись
+func (b *TelegramBot) checkAndSendVacationReminders() {
+	now := time.Now()
+
+	mentors, err := b.mentor.GetEndedVacations(now)
+	if err != nil {
+		log.Printf("Error getting mentors with ended vacations: %v", err)
+		return
+	}
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Принимаю менти", "mentor_vacation_resume"),
+			tgbotapi.NewInlineKeyboardButtonData("🏖 Еще неделю", "mentor_vacation_extend"),
+		),
+	)
+
+	for _, mentor := range mentors {
+		text := "🏖 <b>Отпуск закончился</b>\n\n" +
+			"Пока вы в статусе отпуска, участники не могут к вам записаться. Вернуться к приему менти?"
+		if err := b.sendHTMLMessage(mentor.Member.TelegramID, text, &markup); err != nil {
+			log.Printf("Error sending vacation reminder to user %d: %v", mentor.Member.TelegramID, err)
+		}
+
+		if err := b.mentor.MarkVacationReminderSent(mentor.Id, now); err != nil {
+			log.Printf("Error marking vacation reminder sent for mentor %d: %v", mentor.Id, err)
+		}
+	}
+}
+
+// handleMentorVacationAction возвращает ментора к приему менти или продлевает отпуск из inline-кнопки
+func (b *TelegramBot) handleMentorVacationAction(callback *tgbotapi.CallbackQuery, resume bool) {
+	member, err := b.member.GetByTelegramID(callback.From.ID)
+	if err != nil {
+		log.Printf("Error getting member by telegram ID %d: %v", callback.From.ID, err)
+		b.answerCallbackQuery(callback.ID, "Ошибка: пользователь не найден")
+		return
+	}
+
+	var mentor *models.MentorModel
+	if resume {
+		mentor, err = b.mentor.ResumeAccepting(member)
+	} else {
+		mentor, err = b.mentor.ExtendVacation(member)
+	}
+	if err != nil {
+		if err == service.ErrNotMentor {
+			b.answerCallbackQuery(callback.ID, err.Error())
+			return
+		}
+		log.Printf("Error updating availability for member %d: %v", member.Id, err)
+		b.answerCallbackQuery(callback.ID, "Ошибка при обновлении статуса")
+		return
+	}
+
+	var text string
+	if resume {
+		b.answerCallbackQuery(callback.ID, "Запись открыта")
+		text = "✅ Вы снова принимаете менти"
+	} else {
+		b.answerCallbackQuery(callback.ID, "Отпуск продлен")
+		text = "🏖 Отпуск продлен до " + b.formatMoscowDate(*mentor.VacationUntil)
+	}
+
+	editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	b.bot.Send(editMsg)
+}
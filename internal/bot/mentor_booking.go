@@ -0,0 +1,225 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"ithozyeva/config"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/service"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// bookingSides участник и ментор встречи
+func bookingSides(booking *models.MentorBooking) (member *models.Member, mentor *models.Member) {
+	if booking.Mentor != nil {
+		mentor = &booking.Mentor.Member
+	}
+	return booking.Member, mentor
+}
+
+func memberName(member *models.Member) string {
+	if member == nil {
+		return ""
+	}
+	name := strings.TrimSpace(member.FirstName + " " + member.LastName)
+	if member.Username != "" {
+		name = fmt.Sprintf("%s (@%s)", name, member.Username)
+	}
+	return html.EscapeString(name)
+}
+
+// formatBooking описывает встречу: услуга, время и вторая сторона
+func (b *TelegramBot) formatBooking(booking *models.MentorBooking, counterpart *models.Member) string {
+	var builder strings.Builder
+	if booking.Service != nil {
+		builder.WriteString(fmt.Sprintf("💼 %s", booking.Service.Name))
+		if booking.Service.Price > 0 {
+			builder.WriteString(fmt.Sprintf(" — %d ₽", booking.Service.Price))
+		}
+		builder.WriteString("\n")
+	}
+	if booking.Slot != nil {
+		builder.WriteString(fmt.Sprintf("📅 %s (МСК), %d мин\n",
+			b.formatMoscowDate(booking.Slot.StartsAt), int(booking.Slot.EndsAt.Sub(booking.Slot.StartsAt).Minutes())))
+	}
+	if counterpart != nil {
+		builder.WriteString(fmt.Sprintf("👤 %s\n", memberName(counterpart)))
+	}
+	if booking.Comment != "" {
+		builder.WriteString(fmt.Sprintf("💬 %s\n", booking.Comment))
+	}
+	return builder.String()
+}
+
+// sendHTMLMessage отправляет сообщение с HTML-разметкой. Пользовательские данные в тексте должны быть экранированы
+func (b *TelegramBot) sendHTMLMessage(telegramID int64, text string, markup *tgbotapi.InlineKeyboardMarkup) error {
+	if telegramID == 0 {
+		return nil
+	}
+
+	msg := tgbotapi.NewMessage(telegramID, text)
+	msg.ParseMode = "HTML"
+	if markup != nil {
+		msg.ReplyMarkup = *markup
+	}
+	if _, err := b.bot.Send(msg); err != nil {
+		if strings.Contains(err.Error(), "chat not found") {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func bookingActions(booking *models.MentorBooking) *tgbotapi.InlineKeyboardMarkup {
+	markup := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Подтвердить", fmt.Sprintf("mentor_booking_confirm:%d", booking.Id)),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отменить", fmt.Sprintf("mentor_booking_cancel:%d", booking.Id)),
+		),
+	)
+	return &markup
+}
+
+// SendBookingCreatedAlert сообщает ментору о новой записи и предлагает ее подтвердить
+func (b *TelegramBot) SendBookingCreatedAlert(booking *models.MentorBooking) error {
+	member, mentor := bookingSides(booking)
+	if mentor == nil {
+		return nil
+	}
+
+	text := "🗓 <b>Новая запись на консультацию</b>\n\n" + b.formatBooking(booking, member)
+	return b.sendHTMLMessage(mentor.TelegramID, text, bookingActions(booking))
+}
+
+// SendBookingChangedAlert сообщает второй стороне, что запись подтверждена, отменена или перенесена
+func (b *TelegramBot) SendBookingChangedAlert(booking *models.MentorBooking, actor *models.Member) error {
+	member, mentor := bookingSides(booking)
+	recipient, counterpart := mentor, member
+	if mentor != nil && actor.Id == mentor.Id {
+		recipient, counterpart = member, mentor
+	}
+	if recipient == nil {
+		return nil
+	}
+
+	var title string
+	var markup *tgbotapi.InlineKeyboardMarkup
+	switch booking.Status {
+	case models.MentorBookingConfirmed:
+		title = "✅ <b>Встреча подтверждена</b>"
+	case models.MentorBookingCancelled:
+		title = "❌ <b>Встреча отменена</b>"
+	case models.MentorBookingRescheduled:
+		title = "🔁 <b>Встречу предлагают перенести</b>"
+		markup = bookingActions(booking)
+	default:
+		return nil
+	}
+
+	text := title + "\n\n" + b.formatBooking(booking, counterpart)
+	if booking.Status == models.MentorBookingCancelled && booking.CancelReason != "" {
+		text += fmt.Sprintf("\nПричина: %s", booking.CancelReason)
+	}
+	return b.sendHTMLMessage(recipient.TelegramID, text, markup)
+}
+
+func (b *TelegramBot) startMentorBookingReminders() {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		b.checkAndSendBookingReminders()
+	}
+}
+
+// checkAndSendBookingReminders напоминает обеим сторонам о встрече за MENTOR_BOOKING_REMINDER_MINUTES до начала
+func (b *TelegramBot) checkAndSendBookingReminders() {
+	now := time.Now()
+	window := time.Duration(config.CFG.MentorBookingReminderMinutes) * time.Minute
+
+	bookings, err := b.mentorBooking.GetDueReminders(now, window)
+	if err != nil {
+		log.Printf("Error getting mentor bookings for reminders: %v", err)
+		return
+	}
+
+	for i := range bookings {
+		booking := &bookings[i]
+		member, mentor := bookingSides(booking)
+		timeUntil := b.formatTimeRemaining(booking.Slot.StartsAt.Sub(now))
+
+		if member != nil {
+			text := fmt.Sprintf("⏰ <b>Встреча с ментором через %s</b>\n\n", timeUntil) + b.formatBooking(booking, mentor)
+			if err := b.sendHTMLMessage(member.TelegramID, text, nil); err != nil {
+				log.Printf("Error sending booking reminder to user %d: %v", member.TelegramID, err)
+			}
+		}
+		if mentor != nil {
+			text := fmt.Sprintf("⏰ <b>Консультация через %s</b>\n\n", timeUntil) + b.formatBooking(booking, member)
+			if err := b.sendHTMLMessage(mentor.TelegramID, text, nil); err != nil {
+				log.Printf("Error sending booking reminder to user %d: %v", mentor.TelegramID, err)
+			}
+		}
+
+		if err := b.mentorBooking.MarkReminderSent(booking.Id, now); err != nil {
+			log.Printf("Error marking reminder sent for booking %d: %v", booking.Id, err)
+		}
+	}
+}
+
+// handleMentorBookingAction подтверждает или отменяет запись из inline-кнопки
+func (b *TelegramBot) handleMentorBookingAction(callback *tgbotapi.CallbackQuery, data string, confirm bool) {
+	prefix := "mentor_booking_cancel:"
+	if confirm {
+		prefix = "mentor_booking_confirm:"
+	}
+	bookingId, err := strconv.ParseInt(strings.TrimPrefix(data, prefix), 10, 64)
+	if err != nil {
+		b.answerCallbackQuery(callback.ID, "Ошибка: неверная запись")
+		return
+	}
+
+	member, err := b.member.GetByTelegramID(callback.From.ID)
+	if err != nil {
+		log.Printf("Error getting member by telegram ID %d: %v", callback.From.ID, err)
+		b.answerCallbackQuery(callback.ID, "Ошибка: пользователь не найден")
+		return
+	}
+
+	var booking *models.MentorBooking
+	if confirm {
+		booking, err = b.mentorBooking.Confirm(bookingId, member)
+	} else {
+		booking, err = b.mentorBooking.Cancel(bookingId, member, "")
+	}
+	if err != nil {
+		switch err {
+		case service.ErrBookingNotActive, service.ErrBookingConfirmation, service.ErrNotBookingSide:
+			b.answerCallbackQuery(callback.ID, err.Error())
+		default:
+			log.Printf("Error handling mentor booking %d: %v", bookingId, err)
+			b.answerCallbackQuery(callback.ID, "Ошибка при обновлении записи")
+		}
+		return
+	}
+
+	if confirm {
+		b.answerCallbackQuery(callback.ID, "Встреча подтверждена")
+	} else {
+		b.answerCallbackQuery(callback.ID, "Встреча отменена")
+	}
+
+	editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, callback.Message.Text)
+	b.bot.Send(editMsg)
+
+	if err := b.SendBookingChangedAlert(booking, member); err != nil {
+		log.Printf("Error sending booking change alert: %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"ithozyeva/config"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/service"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func (b *TelegramBot) startReferalLinkChecker() {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		b.checkReferalLinks()
+	}
+}
+
+// checkReferalLinks архивирует ссылки без вакансий, замораживает неподтвержденные
+// и просит авторов подтвердить ссылки, которые давно не обновлялись
+func (b *TelegramBot) checkReferalLinks() {
+	now := time.Now()
+
+	archived, err := b.referalLink.ArchiveEmpty()
+	if err != nil {
+		log.Printf("Error archiving referal links: %v", err)
+	}
+	for i := range archived {
+		link := &archived[i]
+		text := "📦 <b>Реферальная ссылка в архиве</b>\n\n" + formatReferalLink(link) +
+			"\nВакансии по ссылке закончились. Чтобы открыть ее снова, укажите количество вакансий на платформе."
+		if err := b.sendHTMLMessage(link.Author.TelegramID, text, nil); err != nil {
+			log.Printf("Error sending referal link archive alert to user %d: %v", link.Author.TelegramID, err)
+		}
+	}
+
+	grace := time.Duration(config.CFG.ReferalLinkConfirmGraceDays) * 24 * time.Hour
+	frozen, err := b.referalLink.FreezeUnconfirmed(now.Add(-grace))
+	if err != nil {
+		log.Printf("Error freezing referal links: %v", err)
+	}
+	for i := range frozen {
+		link := &frozen[i]
+		text := "🧊 <b>Реферальная ссылка заморожена</b>\n\n" + formatReferalLink(link) +
+			"\nВы не подтвердили, что ссылка актуальна, поэтому участники ее больше не видят как активную."
+		markup := referalLinkConfirmMarkup(link.Id, "🔥 Активировать снова")
+		if err := b.sendHTMLMessage(link.Author.TelegramID, text, &markup); err != nil {
+			log.Printf("Error sending referal link freeze alert to user %d: %v", link.Author.TelegramID, err)
+		}
+	}
+
+	staleAfter := time.Duration(config.CFG.ReferalLinkConfirmDays) * 24 * time.Hour
+	stale, err := b.referalLink.GetStale(now.Add(-staleAfter))
+	if err != nil {
+		log.Printf("Error getting stale referal links: %v", err)
+		return
+	}
+	for i := range stale {
+		link := &stale[i]
+		text := "🔎 <b>Ссылка еще актуальна?</b>\n\n" + formatReferalLink(link) +
+			fmt.Sprintf("\nЕсли не подтвердить ее за %d дн., ссылка будет заморожена.", config.CFG.ReferalLinkConfirmGraceDays)
+		markup := referalLinkConfirmMarkup(link.Id, "✅ Да, актуальна")
+		if err := b.sendHTMLMessage(link.Author.TelegramID, text, &markup); err != nil {
+			log.Printf("Error sending referal link confirmation to user %d: %v", link.Author.TelegramID, err)
+			continue
+		}
+
+		if err := b.referalLink.MarkConfirmationRequested(link.Id, now); err != nil {
+			log.Printf("Error marking confirmation requested for referal link %d: %v", link.Id, err)
+		}
+	}
+}
+
+func formatReferalLink(link *models.ReferalLink) string {
+	text := fmt.Sprintf("🏢 %s", html.EscapeString(link.Company))
+	if link.Grade != "" {
+		text += fmt.Sprintf(", %s", html.EscapeString(link.Grade))
+	}
+	return text + fmt.Sprintf("\n💼 Вакансий: %d\n", link.VacationsCount)
+}
+
+func referalLinkConfirmMarkup(linkId int64, title string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(title, fmt.Sprintf("referal_link_confirm:%d", linkId)),
+		),
+	)
+}
+
+// handleReferalLinkConfirm подтверждает актуальность ссылки или активирует замороженную
+func (b *TelegramBot) handleReferalLinkConfirm(callback *tgbotapi.CallbackQuery, data string) {
+	linkId, err := strconv.ParseInt(strings.TrimPrefix(data, "referal_link_confirm:"), 10, 64)
+	if err != nil {
+		b.answerCallbackQuery(callback.ID, "Ошибка: неверная ссылка")
+		return
+	}
+
+	member, err := b.member.GetByTelegramID(callback.From.ID)
+	if err != nil {
+		log.Printf("Error getting member by telegram ID %d: %v", callback.From.ID, err)
+		b.answerCallbackQuery(callback.ID, "Ошибка: пользователь не найден")
+		return
+	}
+
+	link, err := b.referalLink.Confirm(linkId, member)
+	if err != nil {
+		switch err {
+		case service.ErrReferalLinkNotAuthor, service.ErrReferalLinkArchived:
+			b.answerCallbackQuery(callback.ID, err.Error())
+		default:
+			log.Printf("Error confirming referal link %d: %v", linkId, err)
+			b.answerCallbackQuery(callback.ID, "Ошибка при обновлении ссылки")
+		}
+		return
+	}
+
+	b.answerCallbackQuery(callback.ID, "Ссылка активна")
+
+	editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID,
+		"✅ Ссылка подтверждена и активна\n\n"+formatReferalLink(link))
+	editMsg.ParseMode = "HTML"
+	b.bot.Send(editMsg)
+}
@@ -0,0 +1,130 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/service"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+var referalStatusTitles = map[models.ReferalRequestStatus]string{
+	models.ReferalRequestSubmitted:    "📨 <b>Новая заявка на рекомендацию</b>",
+	models.ReferalRequestReferred:     "🤝 <b>Рекомендация отправлена</b>",
+	models.ReferalRequestInterviewing: "🗣 <b>Кандидат на собеседованиях</b>",
+	models.ReferalRequestHired:        "🎉 <b>Кандидат принят на работу</b>",
+	models.ReferalRequestRejected:     "❌ <b>Заявка на рекомендацию закрыта</b>",
+}
+
+// formatReferalRequest описывает заявку: компания, грейд, вторая сторона и сообщение
+func formatReferalRequest(request *models.ReferalRequest, counterpart *models.Member) string {
+	var builder strings.Builder
+	if request.Link != nil {
+		builder.WriteString(fmt.Sprintf("🏢 %s", html.EscapeString(request.Link.Company)))
+		if request.Link.Grade != "" {
+			builder.WriteString(fmt.Sprintf(", %s", html.EscapeString(request.Link.Grade)))
+		}
+		builder.WriteString("\n")
+	}
+	if counterpart != nil {
+		builder.WriteString(fmt.Sprintf("👤 %s\n", memberName(counterpart)))
+	}
+	if request.Resume != nil && request.Resume.DesiredPosition != "" {
+		builder.WriteString(fmt.Sprintf("📄 %s\n", html.EscapeString(request.Resume.DesiredPosition)))
+	}
+	if request.Message != "" {
+		builder.WriteString(fmt.Sprintf("💬 %s\n", html.EscapeString(request.Message)))
+	}
+	return builder.String()
+}
+
+// SendReferalRequestCreated сообщает автору ссылки о новой заявке и предлагает принять или отклонить ее
+func (b *TelegramBot) SendReferalRequestCreated(request *models.ReferalRequest) error {
+	if request.Link == nil {
+		return nil
+	}
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🤝 Порекомендую", fmt.Sprintf("referal_request_accept:%d", request.Id)),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отклонить", fmt.Sprintf("referal_request_decline:%d", request.Id)),
+		),
+	)
+
+	text := referalStatusTitles[models.ReferalRequestSubmitted] + "\n\n" + formatReferalRequest(request, request.Member)
+	return b.sendHTMLMessage(request.Link.Author.TelegramID, text, &markup)
+}
+
+// SendReferalRequestChanged сообщает второй стороне о новом статусе заявки
+func (b *TelegramBot) SendReferalRequestChanged(request *models.ReferalRequest, actor *models.Member) error {
+	if request.Link == nil {
+		return nil
+	}
+
+	author := &request.Link.Author
+	recipient, counterpart := author, request.Member
+	if actor.Id == author.Id {
+		recipient, counterpart = request.Member, author
+	}
+	if recipient == nil {
+		return nil
+	}
+
+	text := referalStatusTitles[request.Status] + "\n\n" + formatReferalRequest(request, counterpart)
+	if request.Comment != "" {
+		text += fmt.Sprintf("\nКомментарий: %s", html.EscapeString(request.Comment))
+	}
+	return b.sendHTMLMessage(recipient.TelegramID, text, nil)
+}
+
+// handleReferalRequestAction принимает или отклоняет заявку из inline-кнопки
+func (b *TelegramBot) handleReferalRequestAction(callback *tgbotapi.CallbackQuery, data string, accept bool) {
+	prefix := "referal_request_decline:"
+	status := models.ReferalRequestRejected
+	if accept {
+		prefix = "referal_request_accept:"
+		status = models.ReferalRequestReferred
+	}
+	requestId, err := strconv.ParseInt(strings.TrimPrefix(data, prefix), 10, 64)
+	if err != nil {
+		b.answerCallbackQuery(callback.ID, "Ошибка: неверная заявка")
+		return
+	}
+
+	member, err := b.member.GetByTelegramID(callback.From.ID)
+	if err != nil {
+		log.Printf("Error getting member by telegram ID %d: %v", callback.From.ID, err)
+		b.answerCallbackQuery(callback.ID, "Ошибка: пользователь не найден")
+		return
+	}
+
+	request, err := b.referalRequest.ChangeStatus(requestId, member, &models.UpdateReferalRequestStatus{Status: status})
+	if err != nil {
+		switch err {
+		case service.ErrReferalTransition, service.ErrReferalAuthorDecides, service.ErrReferalNotSide:
+			b.answerCallbackQuery(callback.ID, err.Error())
+		default:
+			log.Printf("Error handling referal request %d: %v", requestId, err)
+			b.answerCallbackQuery(callback.ID, "Ошибка при обновлении заявки")
+		}
+		return
+	}
+
+	if accept {
+		b.answerCallbackQuery(callback.ID, "Заявка принята")
+	} else {
+		b.answerCallbackQuery(callback.ID, "Заявка отклонена")
+	}
+
+	editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, callback.Message.Text)
+	b.bot.Send(editMsg)
+
+	if err := b.SendReferalRequestChanged(request, member); err != nil {
+		log.Printf("Error sending referal request alert: %v", err)
+	}
+}
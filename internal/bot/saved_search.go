@@ -0,0 +1,138 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"ithozyeva/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+var savedSearchTitles = map[models.SavedSearchFrequency]string{
+	models.SavedSearchInstant: "🔔 <b>Новое по сохраненному поиску</b>",
+	models.SavedSearchDaily:   "🗞 <b>Дайджест за сутки</b>",
+}
+
+func (b *TelegramBot) startSavedSearchAlerts() {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		b.checkSavedSearches()
+	}
+}
+
+// checkSavedSearches присылает участникам новые результаты их сохраненных поисков
+func (b *TelegramBot) checkSavedSearches() {
+	now := time.Now()
+
+	searches, err := b.savedSearch.GetDue(now)
+	if err != nil {
+		log.Printf("Error getting saved searches: %v", err)
+		return
+	}
+
+	for i := range searches {
+		search := &searches[i]
+
+		matches, err := b.savedSearch.Collect(search, now)
+		if err != nil {
+			log.Printf("Error collecting saved search %d: %v", search.Id, err)
+			continue
+		}
+
+		notified := false
+		if matches.Total > 0 && search.Member != nil && search.Member.TelegramID != 0 {
+			markup := tgbotapi.NewInlineKeyboardMarkup(
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData("🔕 Больше не присылать", fmt.Sprintf("saved_search_delete:%d", search.Id)),
+				),
+			)
+			// При ошибке отправки граница все равно сдвигается: иначе недоступный участник
+			// получал бы повторную попытку с той же подборкой каждую минуту
+			if err := b.sendHTMLMessage(search.Member.TelegramID, formatSavedSearchMatches(matches), &markup); err != nil {
+				log.Printf("Error sending saved search %d to user %d: %v", search.Id, search.Member.TelegramID, err)
+			} else {
+				notified = true
+			}
+		}
+
+		if err := b.savedSearch.MarkChecked(search.Id, now, notified); err != nil {
+			log.Printf("Error marking saved search %d checked: %v", search.Id, err)
+		}
+	}
+}
+
+// formatSavedSearchMatches описывает новые результаты поиска, лишние показываются одним числом
+func formatSavedSearchMatches(matches *models.SavedSearchMatches) string {
+	var builder strings.Builder
+	builder.WriteString(savedSearchTitles[matches.Search.Frequency])
+	builder.WriteString(fmt.Sprintf("\n«%s»\n\n", html.EscapeString(matches.Search.Name)))
+
+	shown := 0
+	for i := range matches.Links {
+		builder.WriteString("🤝 " + formatReferalLink(&matches.Links[i]) + "\n")
+		shown++
+	}
+	for i := range matches.Vacancies {
+		builder.WriteString(formatVacancy(&matches.Vacancies[i]) + "\n")
+		shown++
+	}
+	for i := range matches.Resumes {
+		builder.WriteString(formatSavedSearchResume(&matches.Resumes[i]) + "\n")
+		shown++
+	}
+
+	if rest := matches.Total - shown; rest > 0 {
+		builder.WriteString(fmt.Sprintf("…и еще %d на платформе\n", rest))
+	}
+	return builder.String()
+}
+
+func formatSavedSearchResume(resume *models.Resume) string {
+	text := "📄 "
+	if resume.DesiredPosition != "" {
+		text += html.EscapeString(resume.DesiredPosition)
+	} else {
+		text += html.EscapeString(resume.FileName)
+	}
+	if format, ok := vacancyWorkFormats[resume.WorkFormat]; ok {
+		text += fmt.Sprintf(", %s", format)
+	}
+	if resume.Member != nil {
+		text += fmt.Sprintf("\n👤 %s", memberName(resume.Member))
+	}
+	return text + "\n"
+}
+
+// handleSavedSearchDelete удаляет сохраненный поиск из inline-кнопки уведомления
+func (b *TelegramBot) handleSavedSearchDelete(callback *tgbotapi.CallbackQuery, data string) {
+	searchId, err := strconv.ParseInt(strings.TrimPrefix(data, "saved_search_delete:"), 10, 64)
+	if err != nil {
+		b.answerCallbackQuery(callback.ID, "Ошибка: неверный поиск")
+		return
+	}
+
+	member, err := b.member.GetByTelegramID(callback.From.ID)
+	if err != nil {
+		log.Printf("Error getting member by telegram ID %d: %v", callback.From.ID, err)
+		b.answerCallbackQuery(callback.ID, "Ошибка: пользователь не найден")
+		return
+	}
+
+	if err := b.savedSearch.DeleteMine(searchId, member); err != nil {
+		log.Printf("Error deleting saved search %d: %v", searchId, err)
+		b.answerCallbackQuery(callback.ID, "Поиск уже удален")
+		return
+	}
+
+	b.answerCallbackQuery(callback.ID, "Поиск удален, уведомлений больше не будет")
+
+	editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, callback.Message.Text)
+	b.bot.Send(editMsg)
+}
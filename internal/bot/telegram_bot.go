@@ -15,6 +15,7 @@ import (
 	"ithozyeva/database"
 	"ithozyeva/internal/models"
 	"ithozyeva/internal/service"
+	"ithozyeva/internal/utils"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -44,6 +45,17 @@ type TelegramBot struct {
 	member                 *service.MemberService
 	eventAlertSubscription *service.EventAlertSubscriptionService
 	eventService           *service.EventsService
+	eventFeedback          *service.EventFeedbackService
+	feedbackComments       *feedbackComments
+	eventCheckIn           *service.EventCheckInService
+	eventHosting           *service.EventHostingService
+	eventSeries            *service.EventSeriesService
+	mentorBooking          *service.MentorBookingService
+	mentor                 *service.MentorService
+	referalRequest         *service.ReferalRequestService
+	referalLink            *service.ReferalLinkService
+	vacancy                *service.VacancyService
+	savedSearch            *service.SavedSearchService
 }
 
 func NewTelegramBot() (*TelegramBot, error) {
@@ -73,6 +85,17 @@ func NewTelegramBot() (*TelegramBot, error) {
 		member:                 member_service,
 		eventAlertSubscription: eventAlertSubscriptionService,
 		eventService:           eventService,
+		eventFeedback:          service.NewEventFeedbackService(),
+		feedbackComments:       newFeedbackComments(),
+		eventCheckIn:           service.NewEventCheckInService(),
+		eventHosting:           service.NewEventHostingService(),
+		eventSeries:            service.NewEventSeriesService(),
+		mentorBooking:          service.NewMentorBookingService(),
+		mentor:                 service.NewMentorService(),
+		referalRequest:         service.NewReferalRequestService(),
+		referalLink:            service.NewReferalLinkService(),
+		vacancy:                service.NewVacancyService(),
+		savedSearch:            service.NewSavedSearchService(),
 	}, nil
 }
 
@@ -83,6 +106,24 @@ func (b *TelegramBot) Start() {
 	// Start event alerts scheduler
 	go b.startEventAlertsScheduler()
 
+	// Start post-event feedback requests
+	go b.startFeedbackScheduler()
+
+	// Start mentor session reminders
+	go b.startMentorBookingReminders()
+
+	// Start mentor vacation end reminders
+	go b.startMentorVacationReminders()
+
+	// Start stale referal links checker
+	go b.startReferalLinkChecker()
+
+	// Start new vacancies announcements
+	go b.startVacancyAnnouncements()
+
+	// Start saved search alerts and digests
+	go b.startSavedSearchAlerts()
+
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 
@@ -103,8 +144,13 @@ func (b *TelegramBot) Start() {
 			switch update.Message.Command() {
 			case "start":
 				b.handleStartCommand(update.Message)
+			case "checkin":
+				b.handleCheckInCommand(update.Message)
 			}
+			continue
 		}
+
+		b.handleFeedbackComment(update.Message)
 	}
 }
 
@@ -216,13 +262,19 @@ func (b *TelegramBot) SendEventAlert(telegramID int64, event *models.Event, isIn
 	msg.ParseMode = "HTML"
 
 	if isInitial {
-		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		rows := [][]tgbotapi.InlineKeyboardButton{
 			tgbotapi.NewInlineKeyboardRow(
 				tgbotapi.NewInlineKeyboardButtonData("✅ Приду", fmt.Sprintf("event_attend:%d", event.Id)),
 				tgbotapi.NewInlineKeyboardButtonData("❌ Не приду", fmt.Sprintf("event_decline:%d", event.Id)),
 			),
-		)
-		msg.ReplyMarkup = keyboard
+		}
+		// Часть серии можно сразу записать целиком
+		if event.SeriesId != nil {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("📚 Записаться на всю серию", fmt.Sprintf("series_apply:%d", *event.SeriesId)),
+			))
+		}
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
 	}
 
 	_, err := b.bot.Send(msg)
@@ -242,6 +294,7 @@ func (b *TelegramBot) formatEventAlert(event *models.Event, isInitial bool, time
 	}
 
 	builder.WriteString(fmt.Sprintf("<b>%s</b>\n", event.Title))
+	builder.WriteString(b.formatSeries(event))
 
 	if event.Description != "" {
 		builder.WriteString(fmt.Sprintf("\n%s\n", event.Description))
@@ -288,44 +341,83 @@ func (b *TelegramBot) formatEventAlert(event *models.Event, isInitial bool, time
 	}
 
 	// Добавляем информацию о повторениях
-	if event.IsRepeating && event.RepeatPeriod != nil {
-		builder.WriteString("\n🔄 <b>Повторяющееся событие:</b> ")
-		interval := 1
-		if event.RepeatInterval != nil {
-			interval = *event.RepeatInterval
-		}
+	builder.WriteString(b.formatRecurrence(event))
+
+	return builder.String()
+}
+
+// formatRecurrence описывает правило повторения события. Для разового события возвращает пустую строку
+func (b *TelegramBot) formatRecurrence(event *models.Event) string {
+	rule, err := utils.EventRule(event)
+	if err != nil || rule == nil {
+		return ""
+	}
 
-		periodLabels := map[string]string{
-			"DAILY":   "день",
-			"WEEKLY":  "неделя",
-			"MONTHLY": "месяц",
-			"YEARLY":  "год",
+	var builder strings.Builder
+	builder.WriteString("\n🔄 <b>Повторяющееся событие:</b> ")
+
+	periodLabels := map[models.RepeatPeriod]string{
+		models.RepeatDaily:   "день",
+		models.RepeatWeekly:  "неделя",
+		models.RepeatMonthly: "месяц",
+		models.RepeatYearly:  "год",
+	}
+
+	periodLabel := periodLabels[rule.Freq]
+	if rule.Interval <= 1 {
+		builder.WriteString(fmt.Sprintf("каждый %s", periodLabel))
+	} else {
+		builder.WriteString(fmt.Sprintf("каждые %d %s", rule.Interval, b.pluralizePeriod(rule.Interval, periodLabel)))
+	}
+
+	if len(rule.ByDay) > 0 {
+		weekdayLabels := map[time.Weekday]string{
+			time.Monday:    "пн",
+			time.Tuesday:   "вт",
+			time.Wednesday: "ср",
+			time.Thursday:  "чт",
+			time.Friday:    "пт",
+			time.Saturday:  "сб",
+			time.Sunday:    "вс",
+		}
+		days := make([]string, 0, len(rule.ByDay))
+		for _, day := range rule.ByDay {
+			switch {
+			case day.N == -1:
+				days = append(days, "последний "+weekdayLabels[day.Day])
+			case day.N != 0:
+				days = append(days, fmt.Sprintf("%d-й %s", day.N, weekdayLabels[day.Day]))
+			default:
+				days = append(days, weekdayLabels[day.Day])
+			}
 		}
+		builder.WriteString(fmt.Sprintf(" (%s)", strings.Join(days, ", ")))
+	}
 
-		periodLabel := periodLabels[*event.RepeatPeriod]
-		if periodLabel == "" {
-			periodLabel = strings.ToLower(*event.RepeatPeriod)
+	if len(rule.ByMonthDay) > 0 {
+		days := make([]string, 0, len(rule.ByMonthDay))
+		for _, day := range rule.ByMonthDay {
+			days = append(days, fmt.Sprintf("%d", day))
 		}
+		builder.WriteString(fmt.Sprintf(", числа: %s", strings.Join(days, ", ")))
+	}
 
-		if interval == 1 {
-			builder.WriteString(fmt.Sprintf("каждый %s", periodLabel))
+	if rule.Until != nil {
+		moscowLocation, err := time.LoadLocation("Europe/Moscow")
+		if err != nil {
+			dateInMoscow := rule.Until.In(time.UTC).Add(3 * time.Hour)
+			builder.WriteString(fmt.Sprintf(" до %s", dateInMoscow.Format("02.01.2006")))
 		} else {
-			builder.WriteString(fmt.Sprintf("каждые %d %s", interval, b.pluralizePeriod(interval, periodLabel)))
+			dateInMoscow := rule.Until.In(moscowLocation)
+			builder.WriteString(fmt.Sprintf(" до %s", dateInMoscow.Format("02.01.2006")))
 		}
+	}
 
-		if event.RepeatEndDate != nil {
-			moscowLocation, err := time.LoadLocation("Europe/Moscow")
-			if err != nil {
-				dateInMoscow := event.RepeatEndDate.In(time.UTC).Add(3 * time.Hour)
-				builder.WriteString(fmt.Sprintf(" до %s", dateInMoscow.Format("02.01.2006")))
-			} else {
-				dateInMoscow := event.RepeatEndDate.In(moscowLocation)
-				builder.WriteString(fmt.Sprintf(" до %s", dateInMoscow.Format("02.01.2006")))
-			}
-		}
-		builder.WriteString("\n")
+	if rule.Count > 0 {
+		builder.WriteString(fmt.Sprintf(", всего %d %s", rule.Count, b.pluralize(rule.Count, "раз", "раза", "раз")))
 	}
 
+	builder.WriteString("\n")
 	return builder.String()
 }
 
@@ -393,7 +485,33 @@ func (b *TelegramBot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
 	userID := callback.From.ID
 
 	// Парсим callback data
-	if strings.HasPrefix(data, "event_attend:") {
+	if strings.HasPrefix(data, "event_feedback:") {
+		b.handleFeedbackRating(callback, data)
+	} else if strings.HasPrefix(data, "event_feedback_skip:") {
+		b.handleFeedbackSkip(callback, data)
+	} else if strings.HasPrefix(data, "event_cohost_accept:") {
+		b.handleCoHostResponse(callback, data, true)
+	} else if strings.HasPrefix(data, "event_cohost_decline:") {
+		b.handleCoHostResponse(callback, data, false)
+	} else if strings.HasPrefix(data, "mentor_booking_confirm:") {
+		b.handleMentorBookingAction(callback, data, true)
+	} else if strings.HasPrefix(data, "mentor_booking_cancel:") {
+		b.handleMentorBookingAction(callback, data, false)
+	} else if data == "mentor_vacation_resume" {
+		b.handleMentorVacationAction(callback, true)
+	} else if data == "mentor_vacation_extend" {
+		b.handleMentorVacationAction(callback, false)
+	} else if strings.HasPrefix(data, "referal_request_accept:") {
+		b.handleReferalRequestAction(callback, data, true)
+	} else if strings.HasPrefix(data, "referal_request_decline:") {
+		b.handleReferalRequestAction(callback, data, false)
+	} else if strings.HasPrefix(data, "referal_link_confirm:") {
+		b.handleReferalLinkConfirm(callback, data)
+	} else if strings.HasPrefix(data, "saved_search_delete:") {
+		b.handleSavedSearchDelete(callback, data)
+	} else if strings.HasPrefix(data, "series_apply:") {
+		b.handleSeriesApply(callback, data)
+	} else if strings.HasPrefix(data, "event_attend:") {
 		eventIdStr := strings.TrimPrefix(data, "event_attend:")
 		var eventId int64
 		fmt.Sscanf(eventIdStr, "%d", &eventId)
@@ -490,8 +608,15 @@ func (b *TelegramBot) SendInitialEventAlerts(event *models.Event) error {
 	return nil
 }
 
-func (b *TelegramBot) SendRepeatingEventAlert(event *models.Event) error {
-	members, err := b.eventAlertSubscription.GetSubscribedMembersForEvent(event.Id)
+// SendRepeatingEventAlert отправляет напоминание о событии. Для повторения учитываются подписки на конкретную дату
+func (b *TelegramBot) SendRepeatingEventAlert(event *models.Event, occurrence *models.EventOccurrence) error {
+	var members []models.Member
+	var err error
+	if occurrence != nil {
+		members, err = b.eventAlertSubscription.GetSubscribedMembersForOccurrence(event.Id, occurrence.Id)
+	} else {
+		members, err = b.eventAlertSubscription.GetSubscribedMembersForEvent(event.Id)
+	}
 	if err != nil {
 		return fmt.Errorf("error getting subscribed members for event: %v", err)
 	}
@@ -543,12 +668,98 @@ func (b *TelegramBot) SendEventUpdateAlert(event *models.Event) error {
 	return nil
 }
 
+// SendWaitlistPromotionAlert уведомляет участника о том, что он переведен из листа ожидания в участники события
+func (b *TelegramBot) SendWaitlistPromotionAlert(member *models.Member, event *models.Event) error {
+	if member.TelegramID == 0 {
+		return nil
+	}
+
+	var builder strings.Builder
+	builder.WriteString("🎟 <b>Освободилось место!</b>\n\n")
+	if event.Occurrence != nil {
+		occurrenceDate := event.Occurrence.OccurrenceDate
+		if moscowLocation, err := time.LoadLocation("Europe/Moscow"); err == nil {
+			occurrenceDate = occurrenceDate.In(moscowLocation)
+		}
+		builder.WriteString(fmt.Sprintf("Вы были в листе ожидания и теперь записаны на событие <b>%s</b> %s (МСК).\n", event.Title, occurrenceDate.Format("02.01.2006 в 15:04")))
+	} else {
+		builder.WriteString(fmt.Sprintf("Вы были в листе ожидания и теперь записаны на событие <b>%s</b>.\n", event.Title))
+	}
+	builder.WriteString("\nЕсли планы изменились, пожалуйста, отмените запись на платформе, чтобы место досталось следующему в очереди.")
+
+	msg := tgbotapi.NewMessage(member.TelegramID, builder.String())
+	msg.ParseMode = "HTML"
+
+	_, err := b.bot.Send(msg)
+	if err != nil && strings.Contains(err.Error(), "chat not found") {
+		return nil
+	}
+	return err
+}
+
+// SendOccurrenceChangeAlert уведомляет записавшихся на повторение события об отмене, переносе или смене ведущих
+func (b *TelegramBot) SendOccurrenceChangeAlert(event *models.Event, occurrence *models.EventOccurrence) error {
+	members, err := b.eventAlertSubscription.GetSubscribedMembersForOccurrence(event.Id, occurrence.Id)
+	if err != nil {
+		return fmt.Errorf("error getting subscribed members for occurrence: %v", err)
+	}
+
+	moscowLocation, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		moscowLocation = time.FixedZone("MSK", 3*60*60)
+	}
+
+	var builder strings.Builder
+	originalDate := occurrence.OccurrenceDate.In(moscowLocation).Format("02.01.2006 в 15:04")
+	switch {
+	case occurrence.Cancelled:
+		builder.WriteString("❌ <b>Встреча отменена</b>\n\n")
+		builder.WriteString(fmt.Sprintf("Событие <b>%s</b> %s (МСК) не состоится.\n", event.Title, originalDate))
+	case occurrence.OverrideDate != nil:
+		builder.WriteString("🕐 <b>Встреча перенесена</b>\n\n")
+		builder.WriteString(fmt.Sprintf("Событие <b>%s</b> %s (МСК) переносится на %s (МСК).\n",
+			event.Title, originalDate, occurrence.OverrideDate.In(moscowLocation).Format("02.01.2006 в 15:04")))
+	default:
+		builder.WriteString("📝 <b>Изменения во встрече</b>\n\n")
+		builder.WriteString(fmt.Sprintf("Событие <b>%s</b> %s (МСК) пройдет по обновленной программе.\n", event.Title, originalDate))
+	}
+
+	if !occurrence.Cancelled && len(occurrence.Hosts) > 0 {
+		names := make([]string, 0, len(occurrence.Hosts))
+		for _, host := range occurrence.Hosts {
+			names = append(names, strings.TrimSpace(host.FirstName+" "+host.LastName))
+		}
+		builder.WriteString(fmt.Sprintf("\n🎤 <b>Ведущие:</b> %s\n", strings.Join(names, ", ")))
+	}
+
+	for _, member := range members {
+		if member.TelegramID == 0 {
+			continue
+		}
+
+		msg := tgbotapi.NewMessage(member.TelegramID, builder.String())
+		msg.ParseMode = "HTML"
+
+		_, err = b.bot.Send(msg)
+		if err != nil {
+			if strings.Contains(err.Error(), "chat not found") {
+				continue
+			}
+			log.Printf("Error sending occurrence change alert to user %d: %v", member.TelegramID, err)
+			continue
+		}
+	}
+
+	return nil
+}
+
 // formatEventUpdateAlert форматирует сообщение об изменении события
 func (b *TelegramBot) formatEventUpdateAlert(event *models.Event) string {
 	var builder strings.Builder
 
 	builder.WriteString("📝 <b>Событие изменено!</b>\n\n")
 	builder.WriteString(fmt.Sprintf("<b>%s</b>\n", event.Title))
+	builder.WriteString(b.formatSeries(event))
 
 	if event.Description != "" {
 		builder.WriteString(fmt.Sprintf("\n%s\n", event.Description))
@@ -595,43 +806,7 @@ func (b *TelegramBot) formatEventUpdateAlert(event *models.Event) string {
 	}
 
 	// Добавляем информацию о повторениях
-	if event.IsRepeating && event.RepeatPeriod != nil {
-		builder.WriteString("\n🔄 <b>Повторяющееся событие:</b> ")
-		interval := 1
-		if event.RepeatInterval != nil {
-			interval = *event.RepeatInterval
-		}
-
-		periodLabels := map[string]string{
-			"DAILY":   "день",
-			"WEEKLY":  "неделя",
-			"MONTHLY": "месяц",
-			"YEARLY":  "год",
-		}
-
-		periodLabel := periodLabels[*event.RepeatPeriod]
-		if periodLabel == "" {
-			periodLabel = strings.ToLower(*event.RepeatPeriod)
-		}
-
-		if interval == 1 {
-			builder.WriteString(fmt.Sprintf("каждый %s", periodLabel))
-		} else {
-			builder.WriteString(fmt.Sprintf("каждые %d %s", interval, b.pluralizePeriod(interval, periodLabel)))
-		}
-
-		if event.RepeatEndDate != nil {
-			moscowLocation, err := time.LoadLocation("Europe/Moscow")
-			if err != nil {
-				dateInMoscow := event.RepeatEndDate.In(time.UTC).Add(3 * time.Hour)
-				builder.WriteString(fmt.Sprintf(" до %s", dateInMoscow.Format("02.01.2006")))
-			} else {
-				dateInMoscow := event.RepeatEndDate.In(moscowLocation)
-				builder.WriteString(fmt.Sprintf(" до %s", dateInMoscow.Format("02.01.2006")))
-			}
-		}
-		builder.WriteString("\n")
-	}
+	builder.WriteString(b.formatRecurrence(event))
 
 	builder.WriteString("\n💡 <i>Пожалуйста, проверьте актуальную информацию о событии</i>")
 
@@ -659,88 +834,34 @@ func (b *TelegramBot) checkAndSendEventAlerts() {
 		b.checkReminderAlert(&event, now)
 
 		// Для повторяющихся событий проверяем все будущие повторения
-		if event.IsRepeating && event.RepeatPeriod != nil {
+		if event.IsRecurring() {
 			b.checkRepeatingEventOccurrences(&event, now)
 		} else {
 			// Для обычных событий проверяем только исходную дату
-			b.checkRepeatingAlerts(&event, now)
+			b.checkRepeatingAlerts(&event, nil, now)
 		}
 	}
 }
 
-// getNextOccurrence вычисляет следующее повторение события после указанной даты
-func (b *TelegramBot) getNextOccurrence(event *models.Event, after time.Time) *time.Time {
-	if !event.IsRepeating || event.RepeatPeriod == nil {
-		return nil
-	}
-
-	interval := 1
-	if event.RepeatInterval != nil {
-		interval = *event.RepeatInterval
-	}
-
-	// Проверяем, не истекло ли событие
-	if event.RepeatEndDate != nil && after.After(*event.RepeatEndDate) {
-		return nil
-	}
-
-	// Начинаем с исходной даты события
-	currentDate := event.Date
-
-	// Если исходная дата уже прошла, вычисляем следующее повторение
-	if currentDate.Before(after) || currentDate.Equal(after) {
-		switch *event.RepeatPeriod {
-		case "DAILY":
-			daysSinceStart := int(after.Sub(currentDate).Hours() / 24)
-			nextOccurrenceDays := ((daysSinceStart / interval) + 1) * interval
-			currentDate = currentDate.AddDate(0, 0, nextOccurrenceDays)
-		case "WEEKLY":
-			weeksSinceStart := int(after.Sub(currentDate).Hours() / (24 * 7))
-			nextOccurrenceWeeks := ((weeksSinceStart / interval) + 1) * interval
-			currentDate = currentDate.AddDate(0, 0, nextOccurrenceWeeks*7)
-		case "MONTHLY":
-			monthsSinceStart := 0
-			tempDate := currentDate
-			for tempDate.Before(after) || tempDate.Equal(after) {
-				tempDate = tempDate.AddDate(0, interval, 0)
-				if tempDate.Before(after) || tempDate.Equal(after) {
-					monthsSinceStart++
-				}
-			}
-			currentDate = currentDate.AddDate(0, (monthsSinceStart+1)*interval, 0)
-		case "YEARLY":
-			yearsSinceStart := 0
-			tempDate := currentDate
-			for tempDate.Before(after) || tempDate.Equal(after) {
-				tempDate = tempDate.AddDate(interval, 0, 0)
-				if tempDate.Before(after) || tempDate.Equal(after) {
-					yearsSinceStart++
-				}
-			}
-			currentDate = currentDate.AddDate((yearsSinceStart+1)*interval, 0, 0)
-		}
-	}
-
-	// Проверяем ограничения по дате окончания
-	if event.RepeatEndDate != nil && currentDate.After(*event.RepeatEndDate) {
-		return nil
+// checkRepeatingEventOccurrences проверяет и отправляет алерты для ближайшего проведения события
+func (b *TelegramBot) checkRepeatingEventOccurrences(event *models.Event, now time.Time) {
+	// Получаем ближайшее проведение с учетом отмен и переносов
+	instance := utils.NextEventInstance(event, now.Add(-2*time.Minute))
+	if instance == nil {
+		return
 	}
 
-	return &currentDate
-}
-
-// checkRepeatingEventOccurrences проверяет и отправляет алерты для всех будущих повторений события
-func (b *TelegramBot) checkRepeatingEventOccurrences(event *models.Event, now time.Time) {
-	// Получаем следующее повторение события
-	nextOccurrence := b.getNextOccurrence(event, now)
-	if nextOccurrence == nil {
+	// Состояние алертов хранится отдельно для каждого повторения
+	occurrence, err := b.eventService.GetOrCreateOccurrence(event.Id, instance.RecurrenceId)
+	if err != nil {
+		log.Printf("Error getting occurrence of event %d: %v", event.Id, err)
 		return
 	}
 
-	// Создаем временное событие с датой следующего повторения для проверки алертов
-	tempEvent := *event
-	tempEvent.Date = *nextOccurrence
-	b.checkRepeatingAlerts(&tempEvent, now)
+	// Создаем временное событие с датой и ведущими ближайшего проведения для проверки алертов
+	tempEvent := utils.EventForInstance(event, instance)
+	tempEvent.LastRepeatingAlertSentAt = occurrence.LastAlertSentAt
+	b.checkRepeatingAlerts(&tempEvent, occurrence, now)
 }
 
 func (b *TelegramBot) getReminderInterval() time.Duration {
@@ -803,7 +924,8 @@ func (b *TelegramBot) getAlertIntervals() (alertFirst, alertSecond, alertThird t
 		time.Duration(config.CFG.AlertReminderThirdIntervalMinutes) * time.Minute
 }
 
-func (b *TelegramBot) checkRepeatingAlerts(event *models.Event, now time.Time) {
+// checkRepeatingAlerts отправляет алерты по расписанию. Для повторяющихся событий передается конкретное повторение
+func (b *TelegramBot) checkRepeatingAlerts(event *models.Event, occurrence *models.EventOccurrence, now time.Time) {
 	eventTime := event.Date
 	timeUntilEvent := eventTime.Sub(now)
 
@@ -862,11 +984,18 @@ func (b *TelegramBot) checkRepeatingAlerts(event *models.Event, now time.Time) {
 		}
 
 		log.Printf("Sending repeating alert for event %d, type: %s, timeUntilEvent: %v", event.Id, alertType, timeUntilEvent)
-		if err := b.SendRepeatingEventAlert(event); err != nil {
+		if err := b.SendRepeatingEventAlert(event, occurrence); err != nil {
 			log.Printf("Error sending repeating alert: %v", err)
 			return
 		}
 
+		if occurrence != nil {
+			if err := b.eventService.UpdateOccurrenceAlertSentAt(occurrence.Id, now); err != nil {
+				log.Printf("Error updating occurrence last alert sent time: %v", err)
+			}
+			return
+		}
+
 		if err := database.DB.Model(&models.Event{}).
 			Where("id = ?", event.Id).
 			Update("last_repeating_alert_sent_at", now).Error; err != nil {
@@ -0,0 +1,124 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	"ithozyeva/internal/models"
+)
+
+var vacancyWorkFormats = map[models.WorkFormat]string{
+	models.WorkFormatRemote: "удаленно",
+	models.WorkFormatHybrid: "гибрид",
+	models.WorkFormatOffice: "офис",
+}
+
+func (b *TelegramBot) startVacancyAnnouncements() {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		b.announceVacancies()
+	}
+}
+
+// announceVacancies рассылает опубликованные вакансии участникам, подписанным на их теги
+func (b *TelegramBot) announceVacancies() {
+	vacancies, err := b.vacancy.GetUnannounced()
+	if err != nil {
+		log.Printf("Error getting unannounced vacancies: %v", err)
+		return
+	}
+
+	for i := range vacancies {
+		vacancy := &vacancies[i]
+
+		members, err := b.vacancy.GetTagSubscribers(vacancy)
+		if err != nil {
+			log.Printf("Error getting subscribers for vacancy %d: %v", vacancy.Id, err)
+			continue
+		}
+
+		text := "💼 <b>Новая вакансия в сообществе</b>\n\n" + formatVacancy(vacancy) +
+			"\nПодробности и контакты автора на платформе. Теги подписки можно изменить там же."
+		sent, failed := 0, 0
+		for _, member := range members {
+			if err := b.sendHTMLMessage(member.TelegramID, text, nil); err != nil {
+				log.Printf("Error sending vacancy %d to user %d: %v", vacancy.Id, member.TelegramID, err)
+				failed++
+				continue
+			}
+			sent++
+		}
+
+		// Если не дошло ни одно сообщение, рассылка повторится на следующем тике.
+		// После частичной доставки вакансия отмечается, чтобы никто не получил ее дважды
+		if failed > 0 && sent == 0 {
+			continue
+		}
+		if err := b.vacancy.MarkAnnounced(vacancy.Id, time.Now()); err != nil {
+			log.Printf("Error marking vacancy %d announced: %v", vacancy.Id, err)
+		}
+	}
+}
+
+// SendVacancyDecision сообщает автору решение модератора по вакансии
+func (b *TelegramBot) SendVacancyDecision(vacancy *models.Vacancy) error {
+	if vacancy.Author == nil {
+		return nil
+	}
+
+	var text string
+	switch vacancy.Status {
+	case models.VacancyPublished:
+		text = "✅ <b>Вакансия опубликована</b>\n\n" + formatVacancy(vacancy)
+	case models.VacancyRejected:
+		text = "❌ <b>Вакансия отклонена</b>\n\n" + formatVacancy(vacancy)
+	default:
+		return nil
+	}
+	if vacancy.ReviewComment != "" {
+		text += fmt.Sprintf("\n💬 %s", html.EscapeString(vacancy.ReviewComment))
+	}
+
+	return b.sendHTMLMessage(vacancy.Author.TelegramID, text, nil)
+}
+
+// formatVacancy описывает вакансию: должность, компания, формат, вилка и теги
+func formatVacancy(vacancy *models.Vacancy) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("<b>%s</b>\n🏢 %s", html.EscapeString(vacancy.Title), html.EscapeString(vacancy.Company)))
+	if vacancy.Grade != "" {
+		builder.WriteString(fmt.Sprintf(", %s", html.EscapeString(vacancy.Grade)))
+	}
+	builder.WriteString("\n")
+	if format, ok := vacancyWorkFormats[vacancy.WorkFormat]; ok {
+		builder.WriteString(fmt.Sprintf("📍 %s\n", format))
+	}
+	if salary := formatSalary(vacancy.SalaryFrom, vacancy.SalaryTo); salary != "" {
+		builder.WriteString(fmt.Sprintf("💰 %s\n", salary))
+	}
+	if len(vacancy.ProfTags) > 0 {
+		titles := make([]string, 0, len(vacancy.ProfTags))
+		for _, tag := range vacancy.ProfTags {
+			titles = append(titles, html.EscapeString(tag.Title))
+		}
+		builder.WriteString(fmt.Sprintf("🏷 %s\n", strings.Join(titles, ", ")))
+	}
+	return builder.String()
+}
+
+func formatSalary(from *int, to *int) string {
+	switch {
+	case from != nil && to != nil:
+		return fmt.Sprintf("%d – %d ₽", *from, *to)
+	case from != nil:
+		return fmt.Sprintf("от %d ₽", *from)
+	case to != nil:
+		return fmt.Sprintf("до %d ₽", *to)
+	}
+	return ""
+}
@@ -0,0 +1,267 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/repository"
+	"ithozyeva/internal/service"
+	"mime"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// maxCompanyLogoSize максимальный размер логотипа компании
+const maxCompanyLogoSize = 2 * 1024 * 1024
+
+type CompanyHandler struct {
+	BaseHandler[models.Company]
+	svc *service.CompanyService
+}
+
+func NewCompanyHandler() *CompanyHandler {
+	svc := service.NewCompanyService()
+	return &CompanyHandler{
+		BaseHandler: *NewBaseHandler[models.Company](svc),
+		svc:         svc,
+	}
+}
+
+type SearchCompaniesRequest struct {
+	Limit  *int   `query:"limit"`
+	Offset *int   `query:"offset"`
+	Name   string `query:"name"`
+}
+
+// Search ищет компании по названию и алиасам
+func (h *CompanyHandler) Search(c *fiber.Ctx) error {
+	req := new(SearchCompaniesRequest)
+	if err := c.QueryParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	result, err := h.svc.SearchByName(req.Name, req.Limit, req.Offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(result)
+}
+
+// GetOverview возвращает компанию с реферальными ссылками и участниками, которые в ней работают
+func (h *CompanyHandler) GetOverview(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	overview, err := h.svc.GetOverview(id)
+	if err != nil {
+		return sendCompanyError(c, err)
+	}
+
+	return c.JSON(overview)
+}
+
+// Create добавляет компанию в справочник
+func (h *CompanyHandler) Create(c *fiber.Ctx) error {
+	req := new(models.CompanyRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	company, err := h.svc.CreateCompany(req)
+	if err != nil {
+		return sendCompanyError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(company)
+}
+
+// Update меняет название и сайт компании
+func (h *CompanyHandler) Update(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	req := new(models.CompanyRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	company, err := h.svc.UpdateCompany(id, req)
+	if err != nil {
+		return sendCompanyError(c, err)
+	}
+
+	return c.JSON(company)
+}
+
+// AddAlias добавляет компании другое написание названия
+func (h *CompanyHandler) AddAlias(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	req := new(models.CompanyAliasRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	company, err := h.svc.AddAlias(id, req.Alias)
+	if err != nil {
+		return sendCompanyError(c, err)
+	}
+
+	return c.JSON(company)
+}
+
+// DeleteAlias удаляет алиас компании
+func (h *CompanyHandler) DeleteAlias(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+	aliasId, err := strconv.ParseInt(c.Params("aliasId"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	company, err := h.svc.DeleteAlias(id, aliasId)
+	if err != nil {
+		return sendCompanyError(c, err)
+	}
+
+	return c.JSON(company)
+}
+
+// Merge объединяет дубль с компанией: ссылки, участники и алиасы переходят к ней
+func (h *CompanyHandler) Merge(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	req := new(models.CompanyMergeRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	company, err := h.svc.Merge(id, req.SourceId)
+	if err != nil {
+		return sendCompanyError(c, err)
+	}
+
+	return c.JSON(company)
+}
+
+// GetLogo перенаправляет на временную ссылку на логотип компании
+func (h *CompanyHandler) GetLogo(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	url, err := h.svc.GetLogoURL(id)
+	if err != nil {
+		return sendCompanyError(c, err)
+	}
+
+	return c.Redirect(url, fiber.StatusTemporaryRedirect)
+}
+
+// UploadLogo загружает логотип компании в S3
+func (h *CompanyHandler) UploadLogo(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Файл обязателен"})
+	}
+	if fileHeader.Size > maxCompanyLogoSize {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Файл превышает 2MB"})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	contentType := fileHeader.Header.Get("Content-Type")
+	if contentType == "" {
+		if guessed := mime.TypeByExtension(filepath.Ext(fileHeader.Filename)); guessed != "" {
+			contentType = guessed
+		} else {
+			contentType = "application/octet-stream"
+		}
+	}
+
+	company, err := h.svc.UploadLogo(id, fileHeader.Filename, contentType, data)
+	if err != nil {
+		return sendCompanyError(c, err)
+	}
+
+	return c.JSON(company)
+}
+
+// DeleteLogo удаляет логотип компании
+func (h *CompanyHandler) DeleteLogo(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	company, err := h.svc.DeleteLogo(id)
+	if err != nil {
+		return sendCompanyError(c, err)
+	}
+
+	return c.JSON(company)
+}
+
+// SetMyCompany меняет место работы текущего участника
+func (h *CompanyHandler) SetMyCompany(c *fiber.Ctx) error {
+	req := new(models.SetMemberCompanyRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	company, err := h.svc.SetMemberCompany(member, req)
+	if err != nil {
+		return sendCompanyError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"company": company})
+}
+
+func sendCompanyError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Компания не найдена"})
+	case errors.Is(err, service.ErrCompanyNoLogo):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, repository.ErrCompanyNameTaken):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrCompanyNameRequired),
+		errors.Is(err, service.ErrCompanyInvalidLogo),
+		errors.Is(err, service.ErrCompanyMergeSelf):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
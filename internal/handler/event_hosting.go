@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"errors"
+	"ithozyeva/internal/bot"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/service"
+	"ithozyeva/internal/utils"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// EventHostingHandler управление событиями ведущими на платформе
+type EventHostingHandler struct {
+	svc *service.EventHostingService
+}
+
+func NewEventHostingHandler() *EventHostingHandler {
+	return &EventHostingHandler{
+		svc: service.NewEventHostingService(),
+	}
+}
+
+type EventCoHostRequest struct {
+	MemberId int64 `json:"memberId"`
+}
+
+// GetHosted возвращает события, которые ведет участник, включая черновики
+func (h *EventHostingHandler) GetHosted(c *fiber.Ctx) error {
+	member := c.Locals("member").(*models.Member)
+
+	events, err := h.svc.GetHosted(member.Id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(models.RegistrySearch[models.Event]{Items: events, Total: len(events)})
+}
+
+// CreateDraft создает черновик события, который публикует администратор
+func (h *EventHostingHandler) CreateDraft(c *fiber.Ctx) error {
+	event := new(models.Event)
+	if err := c.BodyParser(event); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	result, err := h.svc.CreateDraft(event, member)
+	if err != nil {
+		return sendEventHostingError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(result)
+}
+
+// UpdateHosted сохраняет изменения события его ведущим
+func (h *EventHostingHandler) UpdateHosted(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	event := new(models.Event)
+	if err := c.BodyParser(event); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+	event.Id = id
+
+	member := c.Locals("member").(*models.Member)
+
+	result, promoted, err := h.svc.UpdateHosted(event, member)
+	if err != nil {
+		return sendEventHostingError(c, err)
+	}
+
+	notifyPromotedMembers(result, promoted)
+
+	// Об изменениях черновика участникам сообщать не нужно
+	if !result.IsDraft() {
+		go func() {
+			telegramBot := bot.GetGlobalBot()
+			if telegramBot == nil {
+				log.Printf("Telegram bot is not initialized, skipping update alerts for event %d", result.Id)
+				return
+			}
+			if err := telegramBot.SendEventUpdateAlert(result); err != nil {
+				log.Printf("Error sending event update alerts: %v", err)
+			}
+		}()
+	}
+
+	return c.JSON(result)
+}
+
+// InviteCoHost приглашает участника стать соведущим, приглашение приходит в Telegram
+func (h *EventHostingHandler) InviteCoHost(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	req := new(EventCoHostRequest)
+	if err := c.BodyParser(req); err != nil || req.MemberId == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	invitation, err := h.svc.InviteCoHost(id, member, req.MemberId)
+	if err != nil {
+		return sendEventHostingError(c, err)
+	}
+
+	go func() {
+		telegramBot := bot.GetGlobalBot()
+		if telegramBot == nil {
+			log.Printf("Telegram bot is not initialized, skipping co-host invitation %d", invitation.Id)
+			return
+		}
+		if err := telegramBot.SendCoHostInvitation(invitation); err != nil {
+			log.Printf("Error sending co-host invitation %d: %v", invitation.Id, err)
+		}
+	}()
+
+	return c.Status(fiber.StatusCreated).JSON(invitation)
+}
+
+// RemoveCoHost убирает соведущего события
+func (h *EventHostingHandler) RemoveCoHost(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+	hostId, err := strconv.ParseInt(c.Params("memberId"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID участника"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	result, err := h.svc.RemoveCoHost(id, member, hostId)
+	if err != nil {
+		return sendEventHostingError(c, err)
+	}
+
+	return c.JSON(result)
+}
+
+// GetInvitations возвращает приглашения участника в соведущие, на которые он еще не ответил
+func (h *EventHostingHandler) GetInvitations(c *fiber.Ctx) error {
+	member := c.Locals("member").(*models.Member)
+
+	invitations, err := h.svc.GetInvitations(member.Id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(invitations)
+}
+
+func (h *EventHostingHandler) AcceptInvitation(c *fiber.Ctx) error {
+	return h.respondInvitation(c, true)
+}
+
+func (h *EventHostingHandler) DeclineInvitation(c *fiber.Ctx) error {
+	return h.respondInvitation(c, false)
+}
+
+func (h *EventHostingHandler) respondInvitation(c *fiber.Ctx, accept bool) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	invitation, err := h.svc.RespondInvitation(id, member.Id, accept)
+	if err != nil {
+		return sendEventHostingError(c, err)
+	}
+
+	go func() {
+		telegramBot := bot.GetGlobalBot()
+		if telegramBot == nil {
+			log.Printf("Telegram bot is not initialized, skipping co-host response alert %d", invitation.Id)
+			return
+		}
+		if err := telegramBot.SendCoHostInvitationResponse(invitation); err != nil {
+			log.Printf("Error sending co-host response alert %d: %v", invitation.Id, err)
+		}
+	}()
+
+	return c.JSON(invitation)
+}
+
+// Publish публикует черновик события и рассылает анонс, как при создании события администратором
+func (h *EventHostingHandler) Publish(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	result, err := h.svc.Publish(id)
+	if err != nil {
+		return sendEventHostingError(c, err)
+	}
+
+	go func() {
+		telegramBot := bot.GetGlobalBot()
+		if telegramBot == nil {
+			log.Printf("Telegram bot is not initialized, skipping alerts for event %d", result.Id)
+			return
+		}
+		if err := telegramBot.SendEventPublishedAlert(result); err != nil {
+			log.Printf("Error sending event published alert: %v", err)
+		}
+		if err := telegramBot.SendInitialEventAlerts(result); err != nil {
+			log.Printf("Error sending initial event alerts: %v", err)
+		}
+	}()
+
+	return c.JSON(result)
+}
+
+func sendEventHostingError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Не найдено"})
+	case errors.Is(err, service.ErrNotEventHost):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrInvitationNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrEventAlreadyHosted),
+		errors.Is(err, service.ErrInvitationAnswered),
+		errors.Is(err, service.ErrEventPublished):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrLastEventHost),
+		errors.Is(err, utils.ErrInvalidRRule):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"errors"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/service"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type EventRecordingHandler struct {
+	BaseHandler[models.EventRecording]
+	svc *service.EventRecordingService
+}
+
+func NewEventRecordingHandler() *EventRecordingHandler {
+	svc := service.NewEventRecordingService()
+	return &EventRecordingHandler{
+		BaseHandler: *NewBaseHandler[models.EventRecording](svc),
+		svc:         svc,
+	}
+}
+
+// currentMember участник из авторизации через Telegram. На публичных маршрутах его нет
+func currentMember(c *fiber.Ctx) *models.Member {
+	member, _ := c.Locals("member").(*models.Member)
+	return member
+}
+
+// Search ищет по всем записям для админки: теги (tagIds), ведущий (hostId), даты (dateFrom, dateTo) и название (q)
+func (h *EventRecordingHandler) Search(c *fiber.Ctx) error {
+	req := new(models.RecordingSearchRequest)
+	if err := c.QueryParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	result, err := h.svc.SearchAll(req)
+	if err != nil {
+		return sendRecordingError(c, err)
+	}
+
+	return c.JSON(result)
+}
+
+// SearchAvailable ищет по каталогу записей, доступных текущему пользователю
+func (h *EventRecordingHandler) SearchAvailable(c *fiber.Ctx) error {
+	req := new(models.RecordingSearchRequest)
+	if err := c.QueryParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	result, err := h.svc.SearchForMember(req, currentMember(c))
+	if err != nil {
+		return sendRecordingError(c, err)
+	}
+
+	return c.JSON(result)
+}
+
+// GetAvailable возвращает запись с главами, если она доступна текущему пользователю
+func (h *EventRecordingHandler) GetAvailable(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	recording, err := h.svc.GetForMember(id, currentMember(c))
+	if err != nil {
+		return sendRecordingError(c, err)
+	}
+
+	return c.JSON(recording)
+}
+
+// GetFile перенаправляет на временную ссылку на файл записи в S3
+func (h *EventRecordingHandler) GetFile(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	url, err := h.svc.GetFileURL(id, currentMember(c))
+	if err != nil {
+		return sendRecordingError(c, err)
+	}
+
+	return c.Redirect(url, fiber.StatusTemporaryRedirect)
+}
+
+// CreateUpload выдает временную ссылку для загрузки видео записи напрямую в S3
+func (h *EventRecordingHandler) CreateUpload(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	req := new(models.RecordingUploadRequest)
+	if err := c.BodyParser(req); err != nil || req.FileName == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Имя файла обязательно"})
+	}
+
+	upload, err := h.svc.CreateUpload(id, req)
+	if err != nil {
+		return sendRecordingError(c, err)
+	}
+
+	return c.JSON(upload)
+}
+
+// AttachFile привязывает к записи видео, загруженное по временной ссылке
+func (h *EventRecordingHandler) AttachFile(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	req := new(models.RecordingFileRequest)
+	if err := c.BodyParser(req); err != nil || req.Key == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Ключ файла обязателен"})
+	}
+
+	recording, err := h.svc.AttachFile(id, req)
+	if err != nil {
+		return sendRecordingError(c, err)
+	}
+
+	return c.JSON(recording)
+}
+
+// DeleteFile удаляет загруженный файл записи из S3
+func (h *EventRecordingHandler) DeleteFile(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	recording, err := h.svc.DeleteFile(id)
+	if err != nil {
+		return sendRecordingError(c, err)
+	}
+
+	return c.JSON(recording)
+}
+
+// Create создает запись события
+func (h *EventRecordingHandler) Create(c *fiber.Ctx) error {
+	recording := new(models.EventRecording)
+	if err := c.BodyParser(recording); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	result, err := h.svc.Create(recording)
+	if err != nil {
+		return sendRecordingError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(result)
+}
+
+// Update сохраняет запись и ее главы
+func (h *EventRecordingHandler) Update(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	recording := new(models.EventRecording)
+	if err := c.BodyParser(recording); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+	recording.Id = id
+
+	result, err := h.svc.Update(recording)
+	if err != nil {
+		return sendRecordingError(c, err)
+	}
+
+	return c.JSON(result)
+}
+
+func sendRecordingError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Запись не найдена"})
+	case errors.Is(err, service.ErrRecordingMembersOnly):
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrRecordingSubscribersOnly):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrRecordingNoFile):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrRecordingInvalidVideo),
+		errors.Is(err, service.ErrRecordingInvalidAccess),
+		errors.Is(err, service.ErrRecordingInvalidChapter),
+		errors.Is(err, service.ErrRecordingInvalidDate),
+		errors.Is(err, service.ErrRecordingTooLarge),
+		errors.Is(err, service.ErrRecordingUploadNotFound):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"ithozyeva/internal/bot"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/service"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type EventSeriesHandler struct {
+	BaseHandler[models.EventSeries]
+	svc *service.EventSeriesService
+}
+
+func NewEventSeriesHandler() *EventSeriesHandler {
+	svc := service.NewEventSeriesService()
+	return &EventSeriesHandler{
+		BaseHandler: *NewBaseHandler[models.EventSeries](svc),
+		svc:         svc,
+	}
+}
+
+// EventSeriesResponse серия для публичной страницы вместе со списком треков
+type EventSeriesResponse struct {
+	*models.EventSeries
+	Tracks []string `json:"tracks"`
+}
+
+// GetPublished возвращает серии с опубликованными частями
+func (h *EventSeriesHandler) GetPublished(c *fiber.Ctx) error {
+	req := new(models.SearchRequest)
+	if err := c.QueryParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	result, err := h.svc.GetPublished(req.Limit, req.Offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(result)
+}
+
+// GetPublishedById возвращает страницу серии: части по порядку и треки
+func (h *EventSeriesHandler) GetPublishedById(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	series, err := h.svc.GetPublishedById(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Серия не найдена"})
+	}
+
+	return c.JSON(EventSeriesResponse{EventSeries: series, Tracks: series.Tracks()})
+}
+
+// GetICSFile отдает календарь со всеми частями серии
+func (h *EventSeriesHandler) GetICSFile(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	ics, err := h.svc.GetICS(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Серия не найдена"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Set("Content-Type", "text/calendar")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=series_%d.ics", id))
+	return c.SendString(ics)
+}
+
+// Apply записывает участника на все части серии
+func (h *EventSeriesHandler) Apply(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	enrollment, err := h.svc.Apply(id, member.Id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Серия не найдена"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	go func() {
+		telegramBot := bot.GetGlobalBot()
+		if telegramBot == nil {
+			log.Printf("Telegram bot is not initialized, skipping series enrollment alert for series %d", id)
+			return
+		}
+		if err := telegramBot.SendSeriesEnrollmentAlert(member, enrollment); err != nil {
+			log.Printf("Error sending series enrollment alert: %v", err)
+		}
+	}()
+
+	return c.JSON(enrollment)
+}
+
+// Decline отписывает участника от серии и всех ее предстоящих частей
+func (h *EventSeriesHandler) Decline(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	series, promotions, err := h.svc.Decline(id, member.Id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Серия не найдена"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	for _, promotion := range promotions {
+		notifyPromotedMembers(promotion.Event, promotion.Promoted)
+	}
+
+	return c.JSON(series)
+}
@@ -1,13 +1,18 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
+	"ithozyeva/config"
 	"ithozyeva/internal/bot"
 	"ithozyeva/internal/models"
 	"ithozyeva/internal/repository"
 	"ithozyeva/internal/service"
 	"ithozyeva/internal/utils"
 	"log"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
@@ -15,7 +20,9 @@ import (
 
 type EventsHandler struct {
 	BaseHandler[models.Event]
-	svc *service.EventsService
+	svc         *service.EventsService
+	feedbackSvc *service.EventFeedbackService
+	checkInSvc  *service.EventCheckInService
 }
 
 func NewEventsHandler() *EventsHandler {
@@ -23,12 +30,15 @@ func NewEventsHandler() *EventsHandler {
 	return &EventsHandler{
 		BaseHandler: *NewBaseHandler(svc),
 		svc:         svc,
+		feedbackSvc: service.NewEventFeedbackService(),
+		checkInSvc:  service.NewEventCheckInService(),
 	}
 }
 
 var EventsSearchFields = map[string]string{
 	"dateFrom": "date >= ?",
 	"dateTo":   "date < ?",
+	"status":   "status = ?",
 }
 
 type EventsSearchRequest struct {
@@ -36,9 +46,20 @@ type EventsSearchRequest struct {
 	Offset   *int    `query:"offset"`
 	DateFrom *string `query:"dateFrom"`
 	DateTo   *string `query:"dateTo"`
+	Status   *string `query:"status"`
 }
 
+// Search ищет события для админки, включая черновики (фильтр status=DRAFT)
 func (h *EventsHandler) Search(c *fiber.Ctx) error {
+	return h.search(c, false)
+}
+
+// SearchPublished ищет опубликованные события для платформы
+func (h *EventsHandler) SearchPublished(c *fiber.Ctx) error {
+	return h.search(c, true)
+}
+
+func (h *EventsHandler) search(c *fiber.Ctx, publishedOnly bool) error {
 	req := new(EventsSearchRequest)
 	if err := c.QueryParser(req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
@@ -53,6 +74,11 @@ func (h *EventsHandler) Search(c *fiber.Ctx) error {
 	if req.DateTo != nil {
 		filter[EventsSearchFields["dateTo"]] = *req.DateTo
 	}
+	if publishedOnly {
+		filter[EventsSearchFields["status"]] = models.EventStatusPublished
+	} else if req.Status != nil {
+		filter[EventsSearchFields["status"]] = *req.Status
+	}
 
 	result, err := h.service.Search(req.Limit, req.Offset, &filter, nil)
 	if err != nil {
@@ -65,7 +91,8 @@ func (h *EventsHandler) Search(c *fiber.Ctx) error {
 func (h *EventsHandler) GetOld(c *fiber.Ctx) error {
 
 	result, err := h.service.Search(nil, nil, &repository.SearchFilter{
-		"date < ?": gorm.Expr("CURRENT_TIMESTAMP"),
+		"date < ?":   gorm.Expr("CURRENT_TIMESTAMP"),
+		"status = ?": models.EventStatusPublished,
 	}, &repository.Order{
 		ColumnBy: "date",
 		Order:    "DESC",
@@ -73,32 +100,59 @@ func (h *EventsHandler) GetOld(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	if err := h.svc.HidePrivateVideoLinks(result.Items); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
 
 	return c.JSON(result)
 }
 
 func (h *EventsHandler) GetNext(c *fiber.Ctx) error {
-	result, err := h.service.Search(nil, nil, &repository.SearchFilter{
-		"date >= ?": gorm.Expr("CURRENT_TIMESTAMP"),
-	}, &repository.Order{
-		ColumnBy: "date",
-		Order:    "ASC",
-	})
+	result, err := h.svc.GetNext(time.Now())
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	if err := h.svc.HidePrivateVideoLinks(result.Items); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
 
 	return c.JSON(result)
 }
 
+// EventAttendanceRequest запрос на запись на событие или отказ от участия.
+// Для повторяющихся событий указывается дата конкретного повторения
+type EventAttendanceRequest struct {
+	EventId        int        `json:"eventId"`
+	OccurrenceDate *time.Time `json:"occurrenceDate"`
+}
+
 func (h *EventsHandler) AddMember(c *fiber.Ctx) error {
-	req := new(WorkWithEventRequest)
+	req := new(EventAttendanceRequest)
 	if err := c.BodyParser(req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
 	}
 
 	member := c.Locals("member").(*models.Member)
 
+	event, err := h.svc.GetById(int64(req.EventId))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Событие не найдено"})
+	}
+	if event.IsDraft() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": service.ErrEventNotPublished.Error()})
+	}
+
+	if event.IsRecurring() {
+		result, err := h.svc.AddOccurrenceMember(event, req.OccurrenceDate, member.Id)
+		if errors.Is(err, service.ErrInvalidOccurrence) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(result)
+	}
+
 	result, err := h.svc.AddMember(req.EventId, int(member.Id))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
@@ -112,14 +166,111 @@ type WorkWithEventRequest struct {
 }
 
 func (h *EventsHandler) RemoveMember(c *fiber.Ctx) error {
-	req := new(WorkWithEventRequest)
+	req := new(EventAttendanceRequest)
 	if err := c.BodyParser(req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
 	}
 
 	member := c.Locals("member").(*models.Member)
 
-	result, err := h.svc.RemoveMember(req.EventId, int(member.Id))
+	event, err := h.svc.GetById(int64(req.EventId))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Событие не найдено"})
+	}
+
+	if event.IsRecurring() {
+		result, promoted, err := h.svc.RemoveOccurrenceMember(event, req.OccurrenceDate, member.Id)
+		if errors.Is(err, service.ErrInvalidOccurrence) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		notifyPromotedMembers(result, promoted)
+		return c.JSON(result)
+	}
+
+	result, promoted, err := h.svc.RemoveMember(req.EventId, int(member.Id))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	notifyPromotedMembers(result, promoted)
+
+	return c.JSON(result)
+}
+
+// notifyPromotedMembers отправляет в фоне уведомления участникам, переведенным из листа ожидания
+func notifyPromotedMembers(event *models.Event, promoted []models.Member) {
+	if len(promoted) == 0 {
+		return
+	}
+
+	go func() {
+		telegramBot := bot.GetGlobalBot()
+		if telegramBot == nil {
+			log.Printf("Telegram bot is not initialized, skipping waitlist alerts for event %d", event.Id)
+			return
+		}
+		for i := range promoted {
+			if err := telegramBot.SendWaitlistPromotionAlert(&promoted[i], event); err != nil {
+				log.Printf("Error sending waitlist promotion alert to member %d: %v", promoted[i].Id, err)
+			}
+		}
+	}()
+}
+
+type EventOccurrencesRequest struct {
+	From *string `query:"from"`
+	To   *string `query:"to"`
+}
+
+// defaultOccurrencesWindow период, за который возвращаются повторения, если он не указан в запросе
+const defaultOccurrencesWindow = 60 * 24 * time.Hour
+
+// maxOccurrencesWindow наибольший период в одном запросе: ежедневное правило без конца
+// иначе разворачивается в неограниченное число повторений
+const maxOccurrencesWindow = 366 * 24 * time.Hour
+
+// GetOccurrences возвращает повторения события с участниками каждого повторения
+func (h *EventsHandler) GetOccurrences(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	req := new(EventOccurrencesRequest)
+	if err := c.QueryParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	from := time.Now()
+	if req.From != nil {
+		if from, err = parseEventDateParam(*req.From); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный формат даты from"})
+		}
+	}
+
+	to := from.Add(defaultOccurrencesWindow)
+	if req.To != nil {
+		if to, err = parseEventDateParam(*req.To); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный формат даты to"})
+		}
+	}
+	if !to.After(from) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Дата to должна быть позже from"})
+	}
+	if to.Sub(from) > maxOccurrencesWindow {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Период не может быть больше года"})
+	}
+
+	event, err := h.svc.GetById(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Событие не найдено"})
+	}
+
+	result, err := h.svc.GetOccurrences(event, from, to)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -127,6 +278,68 @@ func (h *EventsHandler) RemoveMember(c *fiber.Ctx) error {
 	return c.JSON(result)
 }
 
+// EventOccurrenceOverrideRequest изменение конкретного повторения события.
+// OccurrenceDate — исходная дата повторения по правилу
+type EventOccurrenceOverrideRequest struct {
+	OccurrenceDate time.Time       `json:"occurrenceDate"`
+	Cancelled      bool            `json:"cancelled"`
+	OverrideDate   *time.Time      `json:"overrideDate"`
+	Hosts          []models.Member `json:"hosts"`
+}
+
+// SetOccurrenceOverride отменяет, переносит или меняет ведущих повторения события
+func (h *EventsHandler) SetOccurrenceOverride(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	req := new(EventOccurrenceOverrideRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	event, err := h.svc.GetById(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Событие не найдено"})
+	}
+
+	result, err := h.svc.SetOccurrenceOverride(event, &models.EventOccurrence{
+		OccurrenceDate: req.OccurrenceDate,
+		Cancelled:      req.Cancelled,
+		OverrideDate:   req.OverrideDate,
+		Hosts:          req.Hosts,
+	})
+	if errors.Is(err, service.ErrInvalidOccurrence) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// Уведомляем записавшихся на повторение об отмене или переносе в фоне
+	go func() {
+		telegramBot := bot.GetGlobalBot()
+		if telegramBot == nil {
+			log.Printf("Telegram bot is not initialized, skipping occurrence alerts for event %d", event.Id)
+			return
+		}
+		if err := telegramBot.SendOccurrenceChangeAlert(event, result); err != nil {
+			log.Printf("Error sending occurrence change alerts: %v", err)
+		}
+	}()
+
+	return c.JSON(result)
+}
+
+// parseEventDateParam разбирает дату из query-параметра в формате RFC3339 или YYYY-MM-DD
+func parseEventDateParam(value string) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+	return time.Parse(models.DateFormat, value)
+}
+
 func (h *EventsHandler) GetICSFile(c *fiber.Ctx) error {
 	req := new(WorkWithEventRequest)
 	if err := c.QueryParser(req); err != nil {
@@ -135,7 +348,14 @@ func (h *EventsHandler) GetICSFile(c *fiber.Ctx) error {
 
 	event, err := h.svc.GetById(int64(req.EventId))
 	if err != nil {
-
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Событие не найдено"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	// Черновик видят только ведущие и администраторы, публичная ссылка его не отдает
+	if event.IsDraft() {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Событие не найдено"})
 	}
 
 	ics := utils.GenerateICS(event)
@@ -145,6 +365,123 @@ func (h *EventsHandler) GetICSFile(c *fiber.Ctx) error {
 	return c.SendString(ics)
 }
 
+type CalendarFeedRequest struct {
+	Tags *string `query:"tags"`
+}
+
+// MemberCalendarResponse ссылки на персональную ленту календаря участника
+type MemberCalendarResponse struct {
+	Url       string `json:"url"`
+	WebcalUrl string `json:"webcalUrl"`
+}
+
+// GetCalendarFeed отдает подписываемую ленту открытых событий. Фильтр по тегам: ?tags=1,2
+func (h *EventsHandler) GetCalendarFeed(c *fiber.Ctx) error {
+	req := new(CalendarFeedRequest)
+	if err := c.QueryParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	var tagIds []int64
+	if req.Tags != nil && *req.Tags != "" {
+		for _, value := range strings.Split(*req.Tags, ",") {
+			tagId, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный формат тегов"})
+			}
+			tagIds = append(tagIds, tagId)
+		}
+	}
+
+	ics, err := h.svc.GetCalendarFeed(tagIds)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return sendCalendar(c, ics, "events.ics")
+}
+
+// GetMemberCalendarFeed отдает персональную ленту участника по секретному токену
+func (h *EventsHandler) GetMemberCalendarFeed(c *fiber.Ctx) error {
+	ics, err := h.svc.GetMemberCalendarFeed(c.Params("token"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Календарь не найден"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return sendCalendar(c, ics, "my-events.ics")
+}
+
+// GetMyCalendar возвращает ссылки на персональную ленту текущего участника
+func (h *EventsHandler) GetMyCalendar(c *fiber.Ctx) error {
+	member := c.Locals("member").(*models.Member)
+
+	token, err := h.svc.GetMemberCalendarToken(member.Id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(newMemberCalendarResponse(token.Token))
+}
+
+// ResetMyCalendar выпускает новую ссылку на персональную ленту, старая перестает работать
+func (h *EventsHandler) ResetMyCalendar(c *fiber.Ctx) error {
+	member := c.Locals("member").(*models.Member)
+
+	token, err := h.svc.ResetMemberCalendarToken(member.Id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(newMemberCalendarResponse(token.Token))
+}
+
+// GetFeedbackReport возвращает оценки и комментарии участников события
+func (h *EventsHandler) GetFeedbackReport(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	// Для JWT-администратора участника в контексте нет
+	member, _ := c.Locals("member").(*models.Member)
+
+	report, err := h.feedbackSvc.GetReport(id, member)
+	if err != nil {
+		if errors.Is(err, service.ErrFeedbackAccess) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Событие не найдено"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(report)
+}
+
+func newMemberCalendarResponse(token string) MemberCalendarResponse {
+	url := fmt.Sprintf("%s/api/events/calendar/%s.ics", strings.TrimRight(config.CFG.BackendDomain, "/"), token)
+
+	webcalUrl := url
+	for _, scheme := range []string{"https://", "http://"} {
+		if strings.HasPrefix(url, scheme) {
+			webcalUrl = "webcal://" + strings.TrimPrefix(url, scheme)
+			break
+		}
+	}
+
+	return MemberCalendarResponse{Url: url, WebcalUrl: webcalUrl}
+}
+
+func sendCalendar(c *fiber.Ctx, ics string, filename string) error {
+	c.Set("Content-Type", "text/calendar; charset=utf-8")
+	c.Set("Content-Disposition", fmt.Sprintf("inline; filename=%s", filename))
+	return c.SendString(ics)
+}
+
 // Create переопределяет базовый метод Create для отправки алертов при создании события
 func (h *EventsHandler) Create(c *fiber.Ctx) error {
 	event := new(models.Event)
@@ -153,10 +490,18 @@ func (h *EventsHandler) Create(c *fiber.Ctx) error {
 	}
 
 	result, err := h.service.Create(event)
+	if errors.Is(err, utils.ErrInvalidRRule) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	// Черновик анонсируется при публикации
+	if result.IsDraft() {
+		return c.Status(fiber.StatusCreated).JSON(result)
+	}
+
 	// Отправляем инициализирующие алерты в фоне
 	go func() {
 		telegramBot := bot.GetGlobalBot()
@@ -179,11 +524,22 @@ func (h *EventsHandler) Update(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
 	}
 
-	result, err := h.service.Update(event)
+	updated, err := h.service.Update(event)
+	if errors.Is(err, utils.ErrInvalidRRule) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	// Вместимость могла увеличиться — переводим участников из листа ожидания
+	result, promoted, err := h.svc.PromoteFromWaitlist(updated.Id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	notifyPromotedMembers(result, promoted)
+
 	// Отправляем уведомления об изменении события в фоне
 	go func() {
 		telegramBot := bot.GetGlobalBot()
@@ -200,3 +556,112 @@ func (h *EventsHandler) Update(c *fiber.Ctx) error {
 
 	return c.JSON(result)
 }
+
+type EventCheckInCodeRequest struct {
+	OccurrenceDate *string `query:"occurrenceDate"`
+}
+
+type EventCheckInCodeResponse struct {
+	Code  string    `json:"code"`
+	Date  time.Time `json:"date"`
+	Title string    `json:"title"`
+}
+
+type EventCheckInRequest struct {
+	Code string `json:"code"`
+}
+
+// GetCheckInCode выдает участнику подписанный код отметки, который показывается ведущему как QR-код
+func (h *EventsHandler) GetCheckInCode(c *fiber.Ctx) error {
+	id, date, err := parseCheckInParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	pass, err := h.checkInSvc.GetCode(id, date, member.Id)
+	if err != nil {
+		return sendCheckInError(c, err)
+	}
+
+	return c.JSON(EventCheckInCodeResponse{Code: pass.Code, Date: pass.Event.Date, Title: pass.Event.Title})
+}
+
+// CheckIn отмечает посещение участника по отсканированному ведущим коду
+func (h *EventsHandler) CheckIn(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	req := new(EventCheckInRequest)
+	if err := c.BodyParser(req); err != nil || strings.TrimSpace(req.Code) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	attendance, err := h.checkInSvc.CheckIn(id, member, req.Code)
+	if err != nil {
+		return sendCheckInError(c, err)
+	}
+
+	return c.JSON(attendance)
+}
+
+// GetAttendanceReport возвращает ведущему отчет о посещаемости с участниками, которые не пришли
+func (h *EventsHandler) GetAttendanceReport(c *fiber.Ctx) error {
+	id, date, err := parseCheckInParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	report, err := h.checkInSvc.GetReport(id, date, member)
+	if err != nil {
+		return sendCheckInError(c, err)
+	}
+
+	return c.JSON(report)
+}
+
+func parseCheckInParams(c *fiber.Ctx) (int64, *time.Time, error) {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return 0, nil, errors.New("Неверный ID")
+	}
+
+	req := new(EventCheckInCodeRequest)
+	if err := c.QueryParser(req); err != nil {
+		return 0, nil, errors.New("Неверный запрос")
+	}
+	if req.OccurrenceDate == nil {
+		return id, nil, nil
+	}
+
+	date, err := parseEventDateParam(*req.OccurrenceDate)
+	if err != nil {
+		return 0, nil, errors.New("Неверный формат даты occurrenceDate")
+	}
+	return id, &date, nil
+}
+
+func sendCheckInError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Событие не найдено"})
+	case errors.Is(err, service.ErrNotEventHost):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrAlreadyCheckedIn):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, utils.ErrInvalidCheckInCode),
+		errors.Is(err, service.ErrCheckInUnavailable),
+		errors.Is(err, service.ErrCheckInClosed),
+		errors.Is(err, service.ErrNotEventApplicant),
+		errors.Is(err, service.ErrInvalidOccurrence):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
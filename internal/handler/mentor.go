@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"ithozyeva/internal/models"
 	"ithozyeva/internal/service"
 	"strconv"
@@ -11,7 +12,8 @@ import (
 // MentorHandler обработчик для работы с менторами
 type MentorHandler struct {
 	BaseHandler[models.MentorDbShortModel]
-	svc *service.MentorService
+	svc       *service.MentorService
+	revisions *service.MentorRevisionService
 }
 
 // NewMentorHandler создает новый экземпляр обработчика менторов
@@ -20,6 +22,7 @@ func NewMentorHandler() *MentorHandler {
 	return &MentorHandler{
 		BaseHandler: *NewBaseHandler[models.MentorDbShortModel](svc),
 		svc:         svc,
+		revisions:   service.NewMentorRevisionService(),
 	}
 }
 
@@ -38,14 +41,19 @@ func (h *MentorHandler) GetById(c *fiber.Ctx) error {
 	return c.JSON(entity)
 }
 
-// AddReviewToService добавляет отзыв к услуге ментора
+// AddReviewToService добавляет отзыв к услуге ментора. Участник, запись и статус из запроса не берутся
 func (h *MentorHandler) AddReviewToService(c *fiber.Ctx) error {
-	review := new(models.ReviewOnService)
-	if err := c.BodyParser(review); err != nil {
+	request := new(models.ReviewOnServiceRequest)
+	if err := c.BodyParser(request); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
 	}
 
-	result, err := h.svc.AddReviewToService(review)
+	result, err := h.svc.AddReviewToService(&models.ReviewOnService{
+		Text:      request.Text,
+		Author:    request.Author,
+		ServiceId: request.ServiceId,
+		Date:      request.Date,
+	})
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -80,9 +88,9 @@ func (h *MentorHandler) Update(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID ментора не указан"})
 	}
 
-	result, err := h.svc.UpdateWithRelations(request)
+	result, err := h.revisions.UpdateByAdmin(request)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return sendMentorRevisionError(c, err)
 	}
 
 	return c.JSON(result)
@@ -103,13 +111,37 @@ func (h *MentorHandler) GetServices(c *fiber.Ctx) error {
 	return c.JSON(result)
 }
 
+// GetAllWithRelations возвращает менторов. С фильтрами по тегам (tagIds), цене услуг (priceFrom, priceTo),
+// опыту (minExperience) и тексту (q) результаты ранжируются по релевантности и оценкам в отзывах
 func (h *MentorHandler) GetAllWithRelations(c *fiber.Ctx) error {
-	req := new(models.SearchRequest)
+	req := new(models.MentorSearchRequest)
 	if err := c.QueryParser(req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
 	}
 
-	result, err := h.svc.GetAllWithRelations(req.Limit, req.Offset)
+	result, err := h.svc.SearchWithRelations(req)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(result)
+}
+
+// Match подбирает менторов под желаемую позицию и теги текущего участника
+func (h *MentorHandler) Match(c *fiber.Ctx) error {
+	req := new(models.MentorMatchRequest)
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+		}
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	result, err := h.svc.Match(member, req)
+	if errors.Is(err, service.ErrNothingToMatch) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -122,27 +154,19 @@ type UpdateInfoRequest struct {
 	Experience string `json:"experience"`
 }
 
+// UpdateInfo отправляет специализацию и опыт на модерацию
 func (h *MentorHandler) UpdateInfo(c *fiber.Ctx) error {
 	req := new(UpdateInfoRequest)
 	if err := c.BodyParser(req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
 	}
 
-	existedMentor, err := h.svc.GetByMemberID(c.Locals("member").(*models.Member).Id)
-
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	existedMentor.Occupation = req.Occupation
-	existedMentor.Experience = req.Experience
-
-	result, err := h.svc.UpdateWithRelations(existedMentor)
+	revision, err := h.revisions.ProposeInfo(c.Locals("member").(*models.Member), req.Occupation, req.Experience)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return sendMentorRevisionError(c, err)
 	}
 
-	return c.JSON(result)
+	return c.Status(fiber.StatusAccepted).JSON(revision)
 }
 
 type UpdateProfTagsRequest struct {
@@ -175,50 +199,73 @@ type UpdateContactsRequest struct {
 	Contacts []models.Contact `json:"contacts"`
 }
 
+// UpdateContacts отправляет контакты на модерацию
 func (h *MentorHandler) UpdateContacts(c *fiber.Ctx) error {
 	req := new(UpdateContactsRequest)
 	if err := c.BodyParser(req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
 	}
 
-	existedMentor, err := h.svc.GetByMemberID(c.Locals("member").(*models.Member).Id)
-
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	existedMentor.Contacts = req.Contacts
-
-	result, err := h.svc.UpdateWithRelations(existedMentor)
+	revision, err := h.revisions.ProposeContacts(c.Locals("member").(*models.Member), req.Contacts)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return sendMentorRevisionError(c, err)
 	}
 
-	return c.JSON(result)
+	return c.Status(fiber.StatusAccepted).JSON(revision)
 }
 
 type UpdateServicesRequest struct {
 	Services []models.Service `json:"services"`
 }
 
+// UpdateServices отправляет услуги и цены на модерацию
 func (h *MentorHandler) UpdateServices(c *fiber.Ctx) error {
 	req := new(UpdateServicesRequest)
 	if err := c.BodyParser(req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
 	}
 
-	existedMentor, err := h.svc.GetByMemberID(c.Locals("member").(*models.Member).Id)
+	revision, err := h.revisions.ProposeServices(c.Locals("member").(*models.Member), req.Services)
+	if err != nil {
+		return sendMentorRevisionError(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(revision)
+}
 
+// GetAvailability возвращает доступность текущего ментора и число активных менти
+func (h *MentorHandler) GetAvailability(c *fiber.Ctx) error {
+	result, err := h.svc.GetMyAvailability(c.Locals("member").(*models.Member))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return sendMentorAvailabilityError(c, err)
 	}
 
-	existedMentor.Services = req.Services
+	return c.JSON(result)
+}
 
-	result, err := h.svc.UpdateWithRelations(existedMentor)
+// UpdateAvailability меняет доступность текущего ментора: прием менти, лист ожидания или отпуск
+func (h *MentorHandler) UpdateAvailability(c *fiber.Ctx) error {
+	req := new(models.MentorAvailabilityRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	result, err := h.svc.SetAvailability(c.Locals("member").(*models.Member), req)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return sendMentorAvailabilityError(c, err)
 	}
 
 	return c.JSON(result)
 }
+
+func sendMentorAvailabilityError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrNotMentor):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidAvailability),
+		errors.Is(err, service.ErrVacationUntil),
+		errors.Is(err, service.ErrInvalidMaxMentees):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
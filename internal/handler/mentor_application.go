@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"errors"
+	"ithozyeva/internal/bot"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/repository"
+	"ithozyeva/internal/service"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// MentorApplicationHandler заявки на менторство
+type MentorApplicationHandler struct {
+	BaseHandler[models.MentorApplication]
+	svc *service.MentorApplicationService
+}
+
+func NewMentorApplicationHandler() *MentorApplicationHandler {
+	svc := service.NewMentorApplicationService()
+	return &MentorApplicationHandler{
+		BaseHandler: *NewBaseHandler[models.MentorApplication](svc),
+		svc:         svc,
+	}
+}
+
+// Submit отправляет заявку текущего участника
+func (h *MentorApplicationHandler) Submit(c *fiber.Ctx) error {
+	req := new(models.MentorApplication)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	application, err := h.svc.Submit(member, req)
+	if err != nil {
+		return sendMentorApplicationError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(application)
+}
+
+// GetMine возвращает заявки текущего участника
+func (h *MentorApplicationHandler) GetMine(c *fiber.Ctx) error {
+	member := c.Locals("member").(*models.Member)
+
+	applications, err := h.svc.GetMine(member)
+	if err != nil {
+		return sendMentorApplicationError(c, err)
+	}
+
+	return c.JSON(applications)
+}
+
+// Search очередь заявок. По умолчанию показываются заявки на рассмотрении
+func (h *MentorApplicationHandler) Search(c *fiber.Ctx) error {
+	req := new(models.SearchRequest)
+	if err := c.QueryParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	status := strings.ToUpper(c.Query("status", string(models.MentorApplicationPending)))
+	filter := repository.SearchFilter{"status = ?": status}
+
+	result, err := h.svc.Search(req.Limit, req.Offset, &filter, nil)
+	if err != nil {
+		return sendMentorApplicationError(c, err)
+	}
+
+	return c.JSON(result)
+}
+
+// Approve одобряет заявку и создает профиль ментора
+func (h *MentorApplicationHandler) Approve(c *fiber.Ctx) error {
+	return h.review(c, h.svc.Approve)
+}
+
+// Reject отклоняет заявку
+func (h *MentorApplicationHandler) Reject(c *fiber.Ctx) error {
+	return h.review(c, h.svc.Reject)
+}
+
+func (h *MentorApplicationHandler) review(c *fiber.Ctx, decide func(id int64, comment string) (*models.MentorApplication, error)) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	req := new(models.MentorApplicationReviewRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	application, err := decide(id, req.Comment)
+	if err != nil {
+		return sendMentorApplicationError(c, err)
+	}
+
+	go func() {
+		telegramBot := bot.GetGlobalBot()
+		if telegramBot == nil {
+			log.Printf("Telegram bot is not initialized, skipping decision for mentor application %d", application.Id)
+			return
+		}
+		if err := telegramBot.SendMentorApplicationDecision(application); err != nil {
+			log.Printf("Error sending mentor application decision: %v", err)
+		}
+	}()
+
+	return c.JSON(application)
+}
+
+func sendMentorApplicationError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Не найдено"})
+	case errors.Is(err, service.ErrAlreadyMentor),
+		errors.Is(err, service.ErrMentorApplicationPending),
+		errors.Is(err, service.ErrMentorApplicationClosed),
+		errors.Is(err, repository.ErrMentorApplicationReviewed):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrMentorApplicationInvalid),
+		errors.Is(err, service.ErrRejectCommentRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
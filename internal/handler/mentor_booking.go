@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"errors"
+	"ithozyeva/internal/bot"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/repository"
+	"ithozyeva/internal/service"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// MentorBookingHandler расписание менторов и записи на их услуги
+type MentorBookingHandler struct {
+	svc *service.MentorBookingService
+}
+
+func NewMentorBookingHandler() *MentorBookingHandler {
+	return &MentorBookingHandler{
+		svc: service.NewMentorBookingService(),
+	}
+}
+
+// GetMySlots возвращает предстоящие слоты текущего ментора
+func (h *MentorBookingHandler) GetMySlots(c *fiber.Ctx) error {
+	member := c.Locals("member").(*models.Member)
+
+	slots, err := h.svc.GetMySlots(member)
+	if err != nil {
+		return sendMentorBookingError(c, err)
+	}
+
+	return c.JSON(slots)
+}
+
+type AddSlotsRequest struct {
+	Slots []models.MentorSlotRequest `json:"slots"`
+}
+
+// AddSlots публикует слоты в расписании текущего ментора
+func (h *MentorBookingHandler) AddSlots(c *fiber.Ctx) error {
+	req := new(AddSlotsRequest)
+	if err := c.BodyParser(req); err != nil || len(req.Slots) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	slots, err := h.svc.AddSlots(member, req.Slots)
+	if err != nil {
+		return sendMentorBookingError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(slots)
+}
+
+// DeleteSlot снимает свободный слот с публикации
+func (h *MentorBookingHandler) DeleteSlot(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	if err := h.svc.DeleteSlot(member, id); err != nil {
+		return sendMentorBookingError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetMentorBookings возвращает записи к текущему ментору
+func (h *MentorBookingHandler) GetMentorBookings(c *fiber.Ctx) error {
+	member := c.Locals("member").(*models.Member)
+
+	bookings, err := h.svc.GetMentorBookings(member)
+	if err != nil {
+		return sendMentorBookingError(c, err)
+	}
+
+	return c.JSON(bookings)
+}
+
+// GetFreeSlots возвращает свободные слоты ментора
+func (h *MentorBookingHandler) GetFreeSlots(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	slots, err := h.svc.GetFreeSlots(id)
+	if err != nil {
+		return sendMentorBookingError(c, err)
+	}
+
+	return c.JSON(slots)
+}
+
+// GetMyBookings возвращает записи текущего участника к менторам
+func (h *MentorBookingHandler) GetMyBookings(c *fiber.Ctx) error {
+	member := c.Locals("member").(*models.Member)
+
+	bookings, err := h.svc.GetMemberBookings(member)
+	if err != nil {
+		return sendMentorBookingError(c, err)
+	}
+
+	return c.JSON(bookings)
+}
+
+// Book записывает текущего участника на слот ментора
+func (h *MentorBookingHandler) Book(c *fiber.Ctx) error {
+	req := new(models.MentorBookingRequest)
+	if err := c.BodyParser(req); err != nil || req.SlotId == 0 || req.ServiceId == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	booking, err := h.svc.Book(member, req)
+	if err != nil {
+		return sendMentorBookingError(c, err)
+	}
+
+	go func() {
+		telegramBot := bot.GetGlobalBot()
+		if telegramBot == nil {
+			log.Printf("Telegram bot is not initialized, skipping booking alert for booking %d", booking.Id)
+			return
+		}
+		if err := telegramBot.SendBookingCreatedAlert(booking); err != nil {
+			log.Printf("Error sending booking alert: %v", err)
+		}
+	}()
+
+	return c.Status(fiber.StatusCreated).JSON(booking)
+}
+
+// Confirm подтверждает запись или перенос
+func (h *MentorBookingHandler) Confirm(c *fiber.Ctx) error {
+	return h.changeBooking(c, func(id int64, member *models.Member) (*models.MentorBooking, error) {
+		return h.svc.Confirm(id, member)
+	})
+}
+
+type CancelBookingRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Cancel отменяет запись
+func (h *MentorBookingHandler) Cancel(c *fiber.Ctx) error {
+	req := new(CancelBookingRequest)
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+		}
+	}
+
+	return h.changeBooking(c, func(id int64, member *models.Member) (*models.MentorBooking, error) {
+		return h.svc.Cancel(id, member, req.Reason)
+	})
+}
+
+type RescheduleBookingRequest struct {
+	SlotId int64 `json:"slotId"`
+}
+
+// Reschedule переносит запись на другой слот ментора
+func (h *MentorBookingHandler) Reschedule(c *fiber.Ctx) error {
+	req := new(RescheduleBookingRequest)
+	if err := c.BodyParser(req); err != nil || req.SlotId == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	return h.changeBooking(c, func(id int64, member *models.Member) (*models.MentorBooking, error) {
+		return h.svc.Reschedule(id, member, req.SlotId)
+	})
+}
+
+// changeBooking выполняет действие над записью и уведомляет вторую сторону
+func (h *MentorBookingHandler) changeBooking(c *fiber.Ctx, action func(id int64, member *models.Member) (*models.MentorBooking, error)) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	booking, err := action(id, member)
+	if err != nil {
+		return sendMentorBookingError(c, err)
+	}
+
+	go func() {
+		telegramBot := bot.GetGlobalBot()
+		if telegramBot == nil {
+			log.Printf("Telegram bot is not initialized, skipping booking alert for booking %d", booking.Id)
+			return
+		}
+		if err := telegramBot.SendBookingChangedAlert(booking, member); err != nil {
+			log.Printf("Error sending booking alert: %v", err)
+		}
+	}()
+
+	return c.JSON(booking)
+}
+
+func sendMentorBookingError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Не найдено"})
+	case errors.Is(err, service.ErrNotMentor),
+		errors.Is(err, service.ErrNotBookingSide),
+		errors.Is(err, service.ErrBookingConfirmation):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, repository.ErrSlotTaken),
+		errors.Is(err, repository.ErrBookingOverlap),
+		errors.Is(err, service.ErrSlotOverlap),
+		errors.Is(err, service.ErrSlotBooked),
+		errors.Is(err, service.ErrBookingNotActive),
+		errors.Is(err, service.ErrMentorOnVacation),
+		errors.Is(err, service.ErrMentorNotAccepting):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidSlot),
+		errors.Is(err, service.ErrSlotInPast),
+		errors.Is(err, service.ErrNotMentorService),
+		errors.Is(err, service.ErrSelfBooking):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
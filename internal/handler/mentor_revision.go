@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"errors"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/repository"
+	"ithozyeva/internal/service"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// MentorRevisionHandler модерация правок и история профилей менторов
+type MentorRevisionHandler struct {
+	BaseHandler[models.MentorRevision]
+	svc *service.MentorRevisionService
+}
+
+func NewMentorRevisionHandler() *MentorRevisionHandler {
+	svc := service.NewMentorRevisionService()
+	return &MentorRevisionHandler{
+		BaseHandler: *NewBaseHandler[models.MentorRevision](svc),
+		svc:         svc,
+	}
+}
+
+// Search очередь правок. По умолчанию показываются правки на модерации
+func (h *MentorRevisionHandler) Search(c *fiber.Ctx) error {
+	req := new(models.SearchRequest)
+	if err := c.QueryParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	status := strings.ToUpper(c.Query("status", string(models.MentorRevisionPending)))
+	filter := repository.SearchFilter{"status = ?": status}
+
+	result, err := h.svc.Search(req.Limit, req.Offset, &filter, nil)
+	if err != nil {
+		return sendMentorRevisionError(c, err)
+	}
+
+	return c.JSON(result)
+}
+
+// GetHistory возвращает все версии профиля ментора
+func (h *MentorRevisionHandler) GetHistory(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	revisions, err := h.svc.GetHistory(id)
+	if err != nil {
+		return sendMentorRevisionError(c, err)
+	}
+
+	return c.JSON(revisions)
+}
+
+// GetMine возвращает историю профиля текущего ментора
+func (h *MentorRevisionHandler) GetMine(c *fiber.Ctx) error {
+	member := c.Locals("member").(*models.Member)
+
+	revisions, err := h.svc.GetMine(member)
+	if err != nil {
+		return sendMentorRevisionError(c, err)
+	}
+
+	return c.JSON(revisions)
+}
+
+// Diff сравнивает версию с текущим профилем или с версией из ?against=
+func (h *MentorRevisionHandler) Diff(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	var againstId *int64
+	if against := c.Query("against"); against != "" {
+		value, err := strconv.ParseInt(against, 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+		}
+		againstId = &value
+	}
+
+	diff, err := h.svc.Diff(id, againstId)
+	if err != nil {
+		return sendMentorRevisionError(c, err)
+	}
+
+	return c.JSON(diff)
+}
+
+// Approve публикует правку
+func (h *MentorRevisionHandler) Approve(c *fiber.Ctx) error {
+	return h.review(c, h.svc.Approve)
+}
+
+// Reject отклоняет правку
+func (h *MentorRevisionHandler) Reject(c *fiber.Ctx) error {
+	return h.review(c, h.svc.Reject)
+}
+
+// Rollback возвращает профиль ментора к выбранной версии
+func (h *MentorRevisionHandler) Rollback(c *fiber.Ctx) error {
+	return h.review(c, h.svc.Rollback)
+}
+
+func (h *MentorRevisionHandler) review(c *fiber.Ctx, decide func(id int64, comment string) (*models.MentorRevision, error)) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	req := new(models.MentorRevisionReviewRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	revision, err := decide(id, req.Comment)
+	if err != nil {
+		return sendMentorRevisionError(c, err)
+	}
+
+	return c.JSON(revision)
+}
+
+func sendMentorRevisionError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Не найдено"})
+	case errors.Is(err, service.ErrRevisionNotPending),
+		errors.Is(err, repository.ErrMentorRevisionReviewed),
+		errors.Is(err, service.ErrRevisionNotApproved),
+		errors.Is(err, repository.ErrServiceHasBookings):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrRevisionNoChanges),
+		errors.Is(err, service.ErrRevisionInvalid),
+		errors.Is(err, service.ErrRevisionOtherMentor),
+		errors.Is(err, service.ErrRejectCommentRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"errors"
 	"ithozyeva/internal/models"
 	"ithozyeva/internal/service"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 type ReferalLinkHandler struct {
@@ -20,13 +23,24 @@ func NewReferalLinkHandler() *ReferalLinkHandler {
 	}
 }
 
+// Search ищет ссылки по компании, грейду, тегам, статусу и автору
 func (h *ReferalLinkHandler) Search(c *fiber.Ctx) error {
-	req := new(models.SearchRequest)
+	req := new(models.ReferalLinkSearchRequest)
 	if err := c.QueryParser(req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
 	}
 
-	result, err := h.service.Search(req.Limit, req.Offset, nil, nil)
+	result, err := h.svc.SearchLinks(req)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(result)
+}
+
+// GetCompanySummary публичная сводка: сколько ссылок и вакансий в каждой компании, без авторов
+func (h *ReferalLinkHandler) GetCompanySummary(c *fiber.Ctx) error {
+	result, err := h.svc.GetCompanySummary()
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -48,7 +62,7 @@ func (h *ReferalLinkHandler) AddLink(c *fiber.Ctx) error {
 
 	result, err := h.svc.AddLink(req, member)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return sendReferalLinkCompanyError(c, err)
 	}
 
 	return c.JSON(result)
@@ -73,7 +87,7 @@ func (h *ReferalLinkHandler) UpdateLink(c *fiber.Ctx) error {
 
 	result, err := h.svc.UpdateLink(req, member)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return sendReferalLinkCompanyError(c, err)
 	}
 
 	return c.JSON(result)
@@ -103,3 +117,38 @@ func (h *ReferalLinkHandler) DeleteLink(c *fiber.Ctx) error {
 
 	return c.JSON(nil)
 }
+
+// ConfirmLink подтверждает, что ссылка актуальна. Замороженная ссылка снова становится активной
+func (h *ReferalLinkHandler) ConfirmLink(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	result, err := h.svc.Confirm(id, member)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Не найдено"})
+	case errors.Is(err, service.ErrReferalLinkNotAuthor):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrReferalLinkArchived):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(result)
+}
+
+// sendReferalLinkCompanyError отвечает на ошибки выбора компании для ссылки
+func sendReferalLinkCompanyError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Компания не найдена"})
+	case errors.Is(err, service.ErrCompanyNameRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"errors"
+	"ithozyeva/internal/bot"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/repository"
+	"ithozyeva/internal/service"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// ReferalRequestHandler заявки на рекомендацию по реферальным ссылкам
+type ReferalRequestHandler struct {
+	svc *service.ReferalRequestService
+}
+
+func NewReferalRequestHandler() *ReferalRequestHandler {
+	return &ReferalRequestHandler{
+		svc: service.NewReferalRequestService(),
+	}
+}
+
+// Submit отправляет заявку на рекомендацию с приложенным резюме
+func (h *ReferalRequestHandler) Submit(c *fiber.Ctx) error {
+	req := new(models.AddReferalRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	request, err := h.svc.Submit(member, req)
+	if err != nil {
+		return sendReferalRequestError(c, err)
+	}
+
+	go func() {
+		telegramBot := bot.GetGlobalBot()
+		if telegramBot == nil {
+			log.Printf("Telegram bot is not initialized, skipping alert for referal request %d", request.Id)
+			return
+		}
+		if err := telegramBot.SendReferalRequestCreated(request); err != nil {
+			log.Printf("Error sending referal request alert: %v", err)
+		}
+	}()
+
+	return c.Status(fiber.StatusCreated).JSON(request)
+}
+
+// GetMine возвращает заявки текущего участника
+func (h *ReferalRequestHandler) GetMine(c *fiber.Ctx) error {
+	member := c.Locals("member").(*models.Member)
+
+	requests, err := h.svc.GetMine(member)
+	if err != nil {
+		return sendReferalRequestError(c, err)
+	}
+
+	return c.JSON(requests)
+}
+
+// GetIncoming возвращает заявки на ссылки текущего участника
+func (h *ReferalRequestHandler) GetIncoming(c *fiber.Ctx) error {
+	member := c.Locals("member").(*models.Member)
+
+	requests, err := h.svc.GetIncoming(member)
+	if err != nil {
+		return sendReferalRequestError(c, err)
+	}
+
+	return c.JSON(requests)
+}
+
+// ChangeStatus переводит заявку в следующий статус
+func (h *ReferalRequestHandler) ChangeStatus(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	req := new(models.UpdateReferalRequestStatus)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	request, err := h.svc.ChangeStatus(id, member, req)
+	if err != nil {
+		return sendReferalRequestError(c, err)
+	}
+
+	go func() {
+		telegramBot := bot.GetGlobalBot()
+		if telegramBot == nil {
+			log.Printf("Telegram bot is not initialized, skipping alert for referal request %d", request.Id)
+			return
+		}
+		if err := telegramBot.SendReferalRequestChanged(request, member); err != nil {
+			log.Printf("Error sending referal request alert: %v", err)
+		}
+	}()
+
+	return c.JSON(request)
+}
+
+// GetResume перенаправляет на временную ссылку резюме из заявки
+func (h *ReferalRequestHandler) GetResume(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	url, err := h.svc.GetResumeURL(id, c.Locals("member").(*models.Member))
+	if err != nil {
+		return sendReferalRequestError(c, err)
+	}
+
+	return c.Redirect(url, fiber.StatusTemporaryRedirect)
+}
+
+func sendReferalRequestError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Не найдено"})
+	case errors.Is(err, service.ErrReferalNotSide),
+		errors.Is(err, service.ErrReferalAuthorDecides):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, repository.ErrReferalRequestExists),
+		errors.Is(err, repository.ErrReferalRequestChanged),
+		errors.Is(err, service.ErrReferalLinkInactive),
+		errors.Is(err, service.ErrReferalTransition):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrReferalOwnLink),
+		errors.Is(err, service.ErrReferalResume):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrReferalNoResume):
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
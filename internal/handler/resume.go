@@ -1,13 +1,13 @@
 package handler
 
 import (
+	"errors"
 	"io"
-	"mime"
-	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 
 	"ithozyeva/internal/models"
 	"ithozyeva/internal/service"
@@ -50,15 +50,6 @@ func (h *ResumeHandler) Upload(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
-	contentType := fileHeader.Header.Get("Content-Type")
-	if contentType == "" {
-		if guessed := mime.TypeByExtension(filepath.Ext(fileHeader.Filename)); guessed != "" {
-			contentType = guessed
-		} else {
-			contentType = "application/octet-stream"
-		}
-	}
-
 	req := &models.CreateResumeRequest{
 		WorkExperience:  c.FormValue("workExperience"),
 		DesiredPosition: c.FormValue("desiredPosition"),
@@ -71,7 +62,7 @@ func (h *ResumeHandler) Upload(c *fiber.Ctx) error {
 		}
 	}
 
-	resume, parsed, err := h.svc.UploadResume(member, fileHeader.Filename, contentType, data, req)
+	resume, parsed, err := h.svc.UploadResume(member, fileHeader.Filename, data, req)
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
@@ -127,6 +118,42 @@ func (h *ResumeHandler) UpdateMy(c *fiber.Ctx) error {
 	return c.JSON(resume)
 }
 
+// SetPrimaryMy делает версию основным резюме, которое видят рекрутеры
+func (h *ResumeHandler) SetPrimaryMy(c *fiber.Ctx) error {
+	member, ok := c.Locals("member").(*models.Member)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Некорректный идентификатор"})
+	}
+
+	resume, err := h.svc.SetPrimary(id, member.TelegramID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(resume)
+}
+
+// HistoryMy возвращает версии резюме участника и изменения между ними
+func (h *ResumeHandler) HistoryMy(c *fiber.Ctx) error {
+	member, ok := c.Locals("member").(*models.Member)
+	if !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	history, err := h.svc.GetHistory(member.TelegramID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(history)
+}
+
 func (h *ResumeHandler) DeleteMy(c *fiber.Ctx) error {
 	member, ok := c.Locals("member").(*models.Member)
 	if !ok {
@@ -183,9 +210,63 @@ func (h *ResumeHandler) AdminGet(c *fiber.Ctx) error {
 	return c.JSON(resume)
 }
 
+// AdminHistory возвращает все версии резюме участника и историю изменений
+func (h *ResumeHandler) AdminHistory(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Некорректный идентификатор"})
+	}
+
+	history, err := h.svc.GetHistoryForAdmin(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(history)
+}
+
+// AdminReparse заново разбирает файл резюме и обновляет профиль, стаж и теги
+func (h *ResumeHandler) AdminReparse(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Некорректный идентификатор"})
+	}
+
+	resume, err := h.svc.Reparse(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		case errors.Is(err, service.ErrResumeUnreadable):
+			return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(resume)
+}
+
+// AdminReindex извлекает текст для полнотекстового поиска из старых резюме порциями
+func (h *ResumeHandler) AdminReindex(c *fiber.Ctx) error {
+	indexed, failed, err := h.svc.ReindexMissing()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"indexed": indexed,
+		"failed":  failed,
+	})
+}
+
 func parseAdminResumeFilter(c *fiber.Ctx) *models.ResumeFilter {
 	filter := &models.ResumeFilter{}
 
+	// По умолчанию показываются только основные резюме, allVersions=true — все версии
+	filter.AllVersions = c.QueryBool("allVersions")
+
 	if wf := strings.TrimSpace(c.Query("workFormat")); wf != "" {
 		value := models.WorkFormat(strings.ToUpper(wf))
 		if value.IsValid() {
@@ -197,10 +278,24 @@ func parseAdminResumeFilter(c *fiber.Ctx) *models.ResumeFilter {
 		filter.DesiredPosition = &desired
 	}
 
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		filter.Query = &q
+	}
+
 	if exp := strings.TrimSpace(c.Query("workExperience")); exp != "" {
 		filter.WorkExperience = &exp
 	}
 
+	if years, err := strconv.ParseFloat(c.Query("minExperienceYears"), 64); err == nil && years > 0 {
+		filter.MinExperienceYears = &years
+	}
+
+	for _, value := range strings.Split(c.Query("tagIds"), ",") {
+		if tagId, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
+			filter.TagIds = append(filter.TagIds, tagId)
+		}
+	}
+
 	return filter
 }
 
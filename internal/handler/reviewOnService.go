@@ -1,11 +1,15 @@
 package handler
 
 import (
+	"errors"
 	"ithozyeva/internal/models"
+	"ithozyeva/internal/repository"
 	"ithozyeva/internal/service"
 	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 type ReviewOnServiceHandler struct {
@@ -21,14 +25,19 @@ func NewReviewOnServiceHandler() *ReviewOnServiceHandler {
 	}
 }
 
-// Search выполняет поиск отзывов с пагинацией
+// Search выполняет поиск отзывов с пагинацией, ?status=DRAFT показывает отзывы на модерации
 func (h *ReviewOnServiceHandler) Search(c *fiber.Ctx) error {
 	req := new(models.SearchRequest)
 	if err := c.QueryParser(req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
 	}
 
-	result, err := h.svc.Search(req.Limit, req.Offset, nil, nil)
+	var filter *repository.SearchFilter
+	if status := c.Query("status"); status != "" {
+		filter = &repository.SearchFilter{"status = ?": strings.ToUpper(status)}
+	}
+
+	result, err := h.svc.Search(req.Limit, req.Offset, filter, nil)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -85,3 +94,52 @@ func (h *ReviewOnServiceHandler) GetById(c *fiber.Ctx) error {
 
 	return c.JSON(result)
 }
+
+// Approve публикует отзыв после модерации
+func (h *ReviewOnServiceHandler) Approve(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	result, err := h.svc.Approve(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Отзыв не найден"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(result)
+}
+
+// AddVerifiedReview оставляет отзыв участника на услугу по итогам встречи с ментором
+func (h *ReviewOnServiceHandler) AddVerifiedReview(c *fiber.Ctx) error {
+	bookingId, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	request := new(models.AddReviewOnServiceRequest)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	result, err := h.svc.AddVerifiedReview(member, bookingId, request)
+	switch {
+	case err == nil:
+		return c.Status(fiber.StatusCreated).JSON(result)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Встреча не найдена"})
+	case errors.Is(err, service.ErrNotBookingSide):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrBookingReviewed):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidRating),
+		errors.Is(err, service.ErrBookingNotCompleted):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/service"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// SavedSearchHandler сохраненные поиски с уведомлениями в боте
+type SavedSearchHandler struct {
+	svc *service.SavedSearchService
+}
+
+func NewSavedSearchHandler() *SavedSearchHandler {
+	return &SavedSearchHandler{
+		svc: service.NewSavedSearchService(),
+	}
+}
+
+// GetMine возвращает сохраненные поиски текущего участника
+func (h *SavedSearchHandler) GetMine(c *fiber.Ctx) error {
+	member := c.Locals("member").(*models.Member)
+
+	searches, err := h.svc.GetMine(member)
+	if err != nil {
+		return sendSavedSearchError(c, err)
+	}
+
+	return c.JSON(searches)
+}
+
+// Save сохраняет новый поиск
+func (h *SavedSearchHandler) Save(c *fiber.Ctx) error {
+	req := new(models.SavedSearchRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	search, err := h.svc.Save(member, req)
+	if err != nil {
+		return sendSavedSearchError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(search)
+}
+
+// Update меняет условия и частоту уведомлений поиска
+func (h *SavedSearchHandler) Update(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	req := new(models.SavedSearchRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	search, err := h.svc.UpdateMine(id, member, req)
+	if err != nil {
+		return sendSavedSearchError(c, err)
+	}
+
+	return c.JSON(search)
+}
+
+// Delete удаляет сохраненный поиск
+func (h *SavedSearchHandler) Delete(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	if err := h.svc.DeleteMine(id, member); err != nil {
+		return sendSavedSearchError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func sendSavedSearchError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Не найдено"})
+	case errors.Is(err, service.ErrSavedSearchForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrSavedSearchLimit):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrSavedSearchInvalidKind),
+		errors.Is(err, service.ErrSavedSearchInvalidFrequency),
+		errors.Is(err, service.ErrSavedSearchEmpty),
+		errors.Is(err, service.ErrVacancyWorkFormat):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
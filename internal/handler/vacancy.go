@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"errors"
+	"ithozyeva/internal/bot"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/repository"
+	"ithozyeva/internal/service"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// VacancyHandler доска вакансий от участников
+type VacancyHandler struct {
+	BaseHandler[models.Vacancy]
+	svc *service.VacancyService
+}
+
+func NewVacancyHandler() *VacancyHandler {
+	svc := service.NewVacancyService()
+	return &VacancyHandler{
+		BaseHandler: *NewBaseHandler[models.Vacancy](svc),
+		svc:         svc,
+	}
+}
+
+// SearchPublished список опубликованных вакансий с фильтрами по тегам, грейду, формату работы, компании и зарплате
+func (h *VacancyHandler) SearchPublished(c *fiber.Ctx) error {
+	req := new(models.VacancySearchRequest)
+	if err := c.QueryParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	result, err := h.svc.SearchPublished(req, currentMember(c))
+	if err != nil {
+		return sendVacancyError(c, err)
+	}
+
+	return c.JSON(result)
+}
+
+// GetPublished возвращает опубликованную вакансию
+func (h *VacancyHandler) GetPublished(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	vacancy, err := h.svc.GetPublished(id, currentMember(c))
+	if err != nil {
+		return sendVacancyError(c, err)
+	}
+
+	return c.JSON(vacancy)
+}
+
+// GetMine возвращает вакансии текущего участника
+func (h *VacancyHandler) GetMine(c *fiber.Ctx) error {
+	member := c.Locals("member").(*models.Member)
+
+	vacancies, err := h.svc.GetMine(member)
+	if err != nil {
+		return sendVacancyError(c, err)
+	}
+
+	return c.JSON(vacancies)
+}
+
+// Submit отправляет вакансию на модерацию
+func (h *VacancyHandler) Submit(c *fiber.Ctx) error {
+	req := new(models.VacancyRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	vacancy, err := h.svc.Submit(member, req)
+	if err != nil {
+		return sendVacancyError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(vacancy)
+}
+
+// UpdateMine сохраняет изменения вакансии и заново отправляет ее на модерацию
+func (h *VacancyHandler) UpdateMine(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	req := new(models.VacancyRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	vacancy, err := h.svc.UpdateMine(id, member, req)
+	if err != nil {
+		return sendVacancyError(c, err)
+	}
+
+	return c.JSON(vacancy)
+}
+
+// Close снимает вакансию с публикации
+func (h *VacancyHandler) Close(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	vacancy, err := h.svc.Close(id, member)
+	if err != nil {
+		return sendVacancyError(c, err)
+	}
+
+	return c.JSON(vacancy)
+}
+
+// DeleteMine удаляет вакансию текущего участника
+func (h *VacancyHandler) DeleteMine(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	if err := h.svc.DeleteMine(id, member); err != nil {
+		return sendVacancyError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetSubscription возвращает теги, новые вакансии по которым приходят в бот
+func (h *VacancyHandler) GetSubscription(c *fiber.Ctx) error {
+	member := c.Locals("member").(*models.Member)
+
+	tags, err := h.svc.GetSubscription(member)
+	if err != nil {
+		return sendVacancyError(c, err)
+	}
+
+	return c.JSON(tags)
+}
+
+// UpdateSubscription заменяет теги подписки на вакансии
+func (h *VacancyHandler) UpdateSubscription(c *fiber.Ctx) error {
+	req := new(models.VacancySubscriptionRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	member := c.Locals("member").(*models.Member)
+
+	tags, err := h.svc.SetSubscription(member, req.TagIds)
+	if err != nil {
+		return sendVacancyError(c, err)
+	}
+
+	return c.JSON(tags)
+}
+
+// Search очередь модерации. По умолчанию показываются вакансии на проверке
+func (h *VacancyHandler) Search(c *fiber.Ctx) error {
+	req := new(models.SearchRequest)
+	if err := c.QueryParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	status := strings.ToUpper(c.Query("status", string(models.VacancyPending)))
+	filter := repository.SearchFilter{"status = ?": status}
+
+	result, err := h.svc.Search(req.Limit, req.Offset, &filter, nil)
+	if err != nil {
+		return sendVacancyError(c, err)
+	}
+
+	return c.JSON(result)
+}
+
+// Approve публикует вакансию
+func (h *VacancyHandler) Approve(c *fiber.Ctx) error {
+	return h.review(c, h.svc.Approve)
+}
+
+// Reject отклоняет вакансию
+func (h *VacancyHandler) Reject(c *fiber.Ctx) error {
+	return h.review(c, h.svc.Reject)
+}
+
+func (h *VacancyHandler) review(c *fiber.Ctx, decide func(id int64, comment string) (*models.Vacancy, error)) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный ID"})
+	}
+
+	req := new(models.VacancyReviewRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Неверный запрос"})
+	}
+
+	vacancy, err := decide(id, req.Comment)
+	if err != nil {
+		return sendVacancyError(c, err)
+	}
+
+	go func() {
+		telegramBot := bot.GetGlobalBot()
+		if telegramBot == nil {
+			log.Printf("Telegram bot is not initialized, skipping decision for vacancy %d", vacancy.Id)
+			return
+		}
+		if err := telegramBot.SendVacancyDecision(vacancy); err != nil {
+			log.Printf("Error sending vacancy decision: %v", err)
+		}
+	}()
+
+	return c.JSON(vacancy)
+}
+
+func sendVacancyError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Не найдено"})
+	case errors.Is(err, service.ErrVacancyNotAuthor):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrVacancyClosed),
+		errors.Is(err, repository.ErrVacancyChanged):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrVacancyInvalid),
+		errors.Is(err, service.ErrVacancySalary),
+		errors.Is(err, service.ErrVacancyWorkFormat),
+		errors.Is(err, service.ErrVacancyExpiry),
+		errors.Is(err, service.ErrCompanyNameRequired),
+		errors.Is(err, service.ErrRejectCommentRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
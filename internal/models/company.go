@@ -0,0 +1,77 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Company компания-работодатель из справочника. Ссылки и профили участников ссылаются на нее вместо свободного текста
+type Company struct {
+	Id             int64          `json:"id" gorm:"primaryKey"`
+	Name           string         `json:"name" gorm:"column:name"`
+	NormalizedName string         `json:"-" gorm:"column:normalized_name"`
+	Website        string         `json:"website" gorm:"column:website"`
+	LogoPath       *string        `json:"-" gorm:"column:logo_path"`
+	HasLogo        bool           `json:"hasLogo" gorm:"-"`
+	Aliases        []CompanyAlias `json:"aliases" gorm:"foreignKey:CompanyId;references:Id"`
+	CreatedAt      time.Time      `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt      time.Time      `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+func (Company) TableName() string {
+	return "companies"
+}
+
+func (c *Company) AfterFind(tx *gorm.DB) (err error) {
+	c.HasLogo = c.LogoPath != nil && *c.LogoPath != ""
+	return nil
+}
+
+// CompanyAlias другое написание названия компании, например «Яндекс» для Yandex
+type CompanyAlias struct {
+	Id              int64  `json:"id" gorm:"primaryKey"`
+	CompanyId       int64  `json:"companyId" gorm:"column:company_id;not null"`
+	Alias           string `json:"alias" gorm:"column:alias"`
+	NormalizedAlias string `json:"-" gorm:"column:normalized_alias"`
+}
+
+func (CompanyAlias) TableName() string {
+	return "company_aliases"
+}
+
+// NormalizeCompanyName приводит название к виду для сравнения: без регистра, кавычек и лишних пробелов
+func NormalizeCompanyName(name string) string {
+	name = strings.ToLower(name)
+	name = strings.NewReplacer(`"`, " ", "'", " ", "«", " ", "»", " ", "`", " ").Replace(name)
+	return strings.Join(strings.Fields(name), " ")
+}
+
+type CompanyRequest struct {
+	Name    string   `json:"name"`
+	Website string   `json:"website"`
+	Aliases []string `json:"aliases"`
+}
+
+type CompanyAliasRequest struct {
+	Alias string `json:"alias"`
+}
+
+// CompanyMergeRequest переносит все ссылки, участников и алиасы компании SourceId в выбранную компанию
+type CompanyMergeRequest struct {
+	SourceId int64 `json:"sourceId"`
+}
+
+// SetMemberCompanyRequest место работы участника: компания из справочника или название, по которому она найдется или будет создана
+type SetMemberCompanyRequest struct {
+	CompanyId *int64 `json:"companyId"`
+	Name      string `json:"name"`
+}
+
+// CompanyOverview страница компании: актуальные реферальные ссылки и участники, которые в ней работают
+type CompanyOverview struct {
+	*Company
+	Links   []ReferalLink `json:"links"`
+	Members []Member      `json:"members"`
+}
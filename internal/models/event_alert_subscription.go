@@ -19,13 +19,15 @@ type EventAlertSubscription struct {
 	Id                    int64                        `json:"id" gorm:"primaryKey"`
 	EventId               int64                        `json:"eventId" gorm:"column:event_id;not null;index"`
 	MemberId              int64                        `json:"memberId" gorm:"column:member_id;not null;index"`
+	OccurrenceId          *int64                       `json:"occurrenceId" gorm:"column:occurrence_id"`
 	Status                EventAlertSubscriptionStatus `json:"status" gorm:"type:varchar(50);not null;default:'PENDING'"`
 	ReminderSentAt        *time.Time                   `json:"reminderSentAt" gorm:"column:reminder_sent_at"`
 	CreatedAt             time.Time                    `json:"createdAt" gorm:"column:created_at"`
 	UpdatedAt             time.Time                    `json:"updatedAt" gorm:"column:updated_at"`
 	
-	Event  Event  `json:"event,omitempty" gorm:"foreignKey:EventId;references:Id"`
-	Member Member `json:"member,omitempty" gorm:"foreignKey:MemberId;references:Id"`
+	Event      Event            `json:"event,omitempty" gorm:"foreignKey:EventId;references:Id"`
+	Member     Member           `json:"member,omitempty" gorm:"foreignKey:MemberId;references:Id"`
+	Occurrence *EventOccurrence `json:"occurrence,omitempty" gorm:"foreignKey:OccurrenceId;references:Id"`
 }
 
 func (EventAlertSubscription) TableName() string {
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// EventAttendance фактическое посещение офлайн-события, отмеченное ведущим по коду участника.
+// Хранится отдельно от записи на событие (event_members), чтобы видеть, кто записался, но не пришел
+type EventAttendance struct {
+	Id           int64     `json:"id" gorm:"primaryKey"`
+	EventId      int64     `json:"eventId" gorm:"column:event_id;not null"`
+	OccurrenceId *int64    `json:"occurrenceId" gorm:"column:occurrence_id"`
+	MemberId     int64     `json:"memberId" gorm:"column:member_id;not null"`
+	Member       Member    `json:"member" gorm:"foreignKey:MemberId;references:Id"`
+	CheckedInBy  int64     `json:"checkedInBy" gorm:"column:checked_in_by;not null"`
+	CheckedInAt  time.Time `json:"checkedInAt" gorm:"column:checked_in_at"`
+}
+
+func (EventAttendance) TableName() string {
+	return "event_attendances"
+}
+
+// EventAttendanceReport отчет о посещаемости события или повторения: кто пришел и кто записался, но не пришел
+type EventAttendanceReport struct {
+	EventId      int64             `json:"eventId"`
+	OccurrenceId *int64            `json:"occurrenceId"`
+	Date         time.Time         `json:"date"`
+	Applied      int               `json:"applied"`
+	Attended     int               `json:"attended"`
+	Attendees    []EventAttendance `json:"attendees"`
+	NoShows      []Member          `json:"noShows"`
+}
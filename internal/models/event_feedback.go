@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// EventFeedback отзыв участника о прошедшем событии. Запись создается при отправке запроса на оценку,
+// Rating и Comment заполняются, когда участник ответит в боте
+type EventFeedback struct {
+	Id           int64     `json:"id" gorm:"primaryKey"`
+	EventId      int64     `json:"eventId" gorm:"column:event_id;not null"`
+	OccurrenceId *int64    `json:"occurrenceId" gorm:"column:occurrence_id"`
+	MemberId     int64     `json:"memberId" gorm:"column:member_id;not null"`
+	Member       Member    `json:"member" gorm:"foreignKey:MemberId;references:Id"`
+	Rating       *int      `json:"rating" gorm:"column:rating"`
+	Comment      *string   `json:"comment" gorm:"column:comment"`
+	CreatedAt    time.Time `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt    time.Time `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+func (EventFeedback) TableName() string {
+	return "event_feedback"
+}
+
+// EventFeedbackReport сводный отчет по отзывам о событии
+type EventFeedbackReport struct {
+	EventId       int64           `json:"eventId"`
+	Requested     int64           `json:"requested"`
+	Responded     int64           `json:"responded"`
+	AverageRating *float64        `json:"averageRating"`
+	Distribution  map[int]int64   `json:"distribution"`
+	Comments      []EventFeedback `json:"comments"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+type EventHostInvitationStatus string
+
+const (
+	EventHostInvitationPending  EventHostInvitationStatus = "PENDING"
+	EventHostInvitationAccepted EventHostInvitationStatus = "ACCEPTED"
+	EventHostInvitationDeclined EventHostInvitationStatus = "DECLINED"
+)
+
+// EventHostInvitation приглашение участника стать соведущим события.
+// Участник добавляется в ведущие только после того, как примет приглашение
+type EventHostInvitation struct {
+	Id          int64                     `json:"id" gorm:"primaryKey"`
+	EventId     int64                     `json:"eventId" gorm:"column:event_id;not null"`
+	Event       *Event                    `json:"event,omitempty" gorm:"foreignKey:EventId;references:Id"`
+	MemberId    int64                     `json:"memberId" gorm:"column:member_id;not null"`
+	Member      Member                    `json:"member" gorm:"foreignKey:MemberId;references:Id"`
+	InvitedById int64                     `json:"invitedById" gorm:"column:invited_by;not null"`
+	InvitedBy   Member                    `json:"invitedBy" gorm:"foreignKey:InvitedById;references:Id"`
+	Status      EventHostInvitationStatus `json:"status" gorm:"column:status;default:PENDING"`
+	CreatedAt   time.Time                 `json:"createdAt" gorm:"column:created_at"`
+	RespondedAt *time.Time                `json:"respondedAt" gorm:"column:responded_at"`
+}
+
+func (EventHostInvitation) TableName() string {
+	return "event_host_invitations"
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecordingAccess кто может смотреть запись события
+type RecordingAccess string
+
+const (
+	// RecordingAccessPublic запись доступна всем без авторизации
+	RecordingAccessPublic RecordingAccess = "PUBLIC"
+	// RecordingAccessMembers запись доступна авторизованным участникам платформы
+	RecordingAccessMembers RecordingAccess = "MEMBERS"
+	// RecordingAccessSubscribers запись доступна только подписчикам (роль SUBSCRIBER) и администраторам
+	RecordingAccessSubscribers RecordingAccess = "SUBSCRIBERS"
+)
+
+func (a RecordingAccess) IsValid() bool {
+	switch a {
+	case RecordingAccessPublic, RecordingAccessMembers, RecordingAccessSubscribers:
+		return true
+	}
+	return false
+}
+
+// EventRecording запись прошедшего события. Видео хранится по внешней ссылке (VideoLink)
+// либо загружается в S3 (FilePath) как запасное хранилище
+type EventRecording struct {
+	Id          int64              `json:"id" gorm:"primaryKey"`
+	EventId     int64              `json:"eventId" gorm:"column:event_id;not null"`
+	Event       *Event             `json:"event,omitempty" gorm:"foreignKey:EventId;references:Id"`
+	Title       string             `json:"title" gorm:"column:title"`
+	Description string             `json:"description" gorm:"column:description"`
+	VideoLink   string             `json:"videoLink" gorm:"column:video_link"`
+	FilePath    *string            `json:"-" gorm:"column:file_path"`
+	FileName    *string            `json:"fileName" gorm:"column:file_name"`
+	ContentType *string            `json:"-" gorm:"column:content_type"`
+	HasFile     bool               `json:"hasFile" gorm:"-"`
+	Duration    *int               `json:"duration" gorm:"column:duration"`
+	Access      RecordingAccess    `json:"access" gorm:"column:access;default:MEMBERS"`
+	RecordedAt  time.Time          `json:"recordedAt" gorm:"column:recorded_at"`
+	Chapters    []RecordingChapter `json:"chapters" gorm:"foreignKey:RecordingId;references:Id"`
+	CreatedAt   time.Time          `json:"createdAt" gorm:"column:created_at"`
+}
+
+func (EventRecording) TableName() string {
+	return "event_recordings"
+}
+
+func (r *EventRecording) AfterFind(tx *gorm.DB) (err error) {
+	r.HasFile = r.FilePath != nil && *r.FilePath != ""
+	return nil
+}
+
+// RecordingChapter глава записи с отметкой времени от начала видео
+type RecordingChapter struct {
+	Id           int64  `json:"id" gorm:"primaryKey"`
+	RecordingId  int64  `json:"recordingId" gorm:"column:recording_id;not null"`
+	Title        string `json:"title" gorm:"column:title"`
+	StartSeconds int    `json:"startSeconds" gorm:"column:start_seconds"`
+	// Timestamp отметка времени в виде 01:02:03, при сохранении можно передать ее вместо StartSeconds
+	Timestamp string `json:"timestamp" gorm:"-"`
+}
+
+func (RecordingChapter) TableName() string {
+	return "event_recording_chapters"
+}
+
+func (c *RecordingChapter) AfterFind(tx *gorm.DB) (err error) {
+	c.Timestamp = FormatTimestamp(c.StartSeconds)
+	return nil
+}
+
+// FormatTimestamp форматирует секунды от начала видео в вид 05:30 или 01:02:03
+func FormatTimestamp(seconds int) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	hours, minutes, secs := seconds/3600, (seconds%3600)/60, seconds%60
+	if hours > 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", hours, minutes, secs)
+	}
+	return fmt.Sprintf("%02d:%02d", minutes, secs)
+}
+
+// ParseTimestamp разбирает отметку времени вида 1:02:03, 02:03 или 123 в секунды
+func ParseTimestamp(value string) (int, error) {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", value)
+	}
+	seconds := 0
+	for i, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 || (i > 0 && number >= 60) {
+			return 0, fmt.Errorf("invalid timestamp %q", value)
+		}
+		seconds = seconds*60 + number
+	}
+	return seconds, nil
+}
+
+// RecordingUploadRequest видео, которое администратор собирается загрузить в S3
+type RecordingUploadRequest struct {
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+}
+
+// RecordingUpload временная ссылка для загрузки видео записи напрямую в S3.
+// Загрузка выполняется PUT-запросом с заголовком Content-Type из ContentType
+type RecordingUpload struct {
+	UploadURL   string    `json:"uploadUrl"`
+	Key         string    `json:"key"`
+	ContentType string    `json:"contentType"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// RecordingFileRequest видео, загруженное по временной ссылке, которое нужно привязать к записи
+type RecordingFileRequest struct {
+	Key         string `json:"key"`
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+}
+
+// RecordingSearchRequest параметры поиска по каталогу записей
+type RecordingSearchRequest struct {
+	Limit    *int    `query:"limit"`
+	Offset   *int    `query:"offset"`
+	TagIds   []int64 `query:"tagIds"`
+	HostId   *int64  `query:"hostId"`
+	DateFrom *string `query:"dateFrom"`
+	DateTo   *string `query:"dateTo"`
+	Query    string  `query:"q"`
+}
+
+// RecordingFilter фильтр каталога записей
+type RecordingFilter struct {
+	TagIds   []int64
+	HostId   *int64
+	DateFrom *time.Time
+	DateTo   *time.Time
+	Query    string
+	// Access уровни доступа, которые видит пользователь
+	Access []RecordingAccess
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+type EventSeriesKind string
+
+const (
+	EventSeriesCourse     EventSeriesKind = "COURSE"
+	EventSeriesConference EventSeriesKind = "CONFERENCE"
+)
+
+// EventSeries серия событий: многочастный курс или конференция с несколькими докладами.
+// Части серии — обычные события, упорядоченные по SeriesPosition; в конференции доклады
+// могут быть разбиты по трекам (Event.Track). Участник серии записывается на все ее части
+type EventSeries struct {
+	Id          int64           `json:"id" gorm:"primaryKey"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Kind        EventSeriesKind `json:"kind" gorm:"column:kind;default:COURSE"`
+	// EventIds порядок частей серии при создании и редактировании
+	EventIds   []int64   `json:"eventIds,omitempty" gorm:"-"`
+	Events     []Event   `json:"events" gorm:"foreignKey:SeriesId;references:Id"`
+	Members    []Member  `json:"members" gorm:"many2many:event_series_members;foreignKey:id;joinForeignKey:series_id;References:id;joinReferences:member_id"`
+	PartsCount int       `json:"partsCount" gorm:"column:parts_count;->"`
+	CreatedAt  time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+func (EventSeries) TableName() string {
+	return "event_series"
+}
+
+// Tracks возвращает треки серии в порядке первого появления
+func (s *EventSeries) Tracks() []string {
+	tracks := []string{}
+	seen := map[string]bool{}
+	for _, event := range s.Events {
+		if event.Track == "" || seen[event.Track] {
+			continue
+		}
+		seen[event.Track] = true
+		tracks = append(tracks, event.Track)
+	}
+	return tracks
+}
@@ -19,29 +19,124 @@ const (
 	RepeatYearly  RepeatPeriod = "YEARLY"
 )
 
+// EventStatus статус публикации события. Черновики видят только ведущие и администраторы,
+// публикует их администратор
+type EventStatus string
+
+const (
+	EventStatusDraft     EventStatus = "DRAFT"
+	EventStatusPublished EventStatus = "PUBLISHED"
+)
+
 type Event struct {
-	Id                       int64      `json:"id" gorm:"primaryKey"`
-	Title                    string     `json:"title"`
-	Description              string     `json:"description"`
-	Date                     time.Time  `json:"date" time_format:"2006-01-02T15:04" time_location:"UTC"`
-	Timezone                 string     `json:"timezone" gorm:"default:'UTC'"`
-	PlaceType                PlaceType  `json:"placeType"`
-	Place                    string     `json:"place"`
-	CustomPlaceType          string     `json:"customPlaceType"`
-	EventType                string     `json:"eventType"`
-	Open                     bool       `json:"open"`
-	VideoLink                string     `json:"videoLink" gorm:"column:video_link"`
-	IsRepeating              bool       `json:"isRepeating" gorm:"default:false"`
-	RepeatPeriod             *string    `json:"repeatPeriod" gorm:"column:repeat_period"`
-	RepeatInterval           *int       `json:"repeatInterval" gorm:"column:repeat_interval;default:1"`
-	RepeatEndDate            *time.Time `json:"repeatEndDate" gorm:"column:repeat_end_date"`
-	EventTags                []EventTag `json:"eventTags" gorm:"many2many:event_event_tags;foreignKey:id;joinForeignKey:event_id;References:id;joinReferences:event_tag_id;replace:true"`
-	Hosts                    []Member   `json:"hosts" gorm:"many2many:event_hosts;foreignKey:id;joinForeignKey:event_id;References:id;joinReferences:member_id;replace:true"`
-	Members                  []Member   `json:"members" gorm:"many2many:event_members;foreignKey:id;joinForeignKey:event_id;References:id;joinReferences:member_id;replace:true"`
-	LastRepeatingAlertSentAt *time.Time `json:"lastRepeatingAlertSentAt" gorm:"column:last_repeating_alert_sent_at"`
+	Id                       int64                `json:"id" gorm:"primaryKey"`
+	Title                    string               `json:"title"`
+	Description              string               `json:"description"`
+	Date                     time.Time            `json:"date" time_format:"2006-01-02T15:04" time_location:"UTC"`
+	Timezone                 string               `json:"timezone" gorm:"default:'UTC'"`
+	PlaceType                PlaceType            `json:"placeType"`
+	Place                    string               `json:"place"`
+	CustomPlaceType          string               `json:"customPlaceType"`
+	EventType                string               `json:"eventType"`
+	Open                     bool                 `json:"open"`
+	Status                   EventStatus          `json:"status" gorm:"column:status;default:PUBLISHED"`
+	CreatedBy                *int64               `json:"createdBy" gorm:"column:created_by"`
+	VideoLink                string               `json:"videoLink" gorm:"column:video_link"`
+	IsRepeating              bool                 `json:"isRepeating" gorm:"default:false"`
+	RepeatPeriod             *string              `json:"repeatPeriod" gorm:"column:repeat_period"`
+	RepeatInterval           *int                 `json:"repeatInterval" gorm:"column:repeat_interval;default:1"`
+	RepeatEndDate            *time.Time           `json:"repeatEndDate" gorm:"column:repeat_end_date"`
+	RRule                    *string              `json:"rrule" gorm:"column:rrule"`
+	Capacity                 *int                 `json:"capacity" gorm:"column:capacity"`
+	EventTags                []EventTag           `json:"eventTags" gorm:"many2many:event_event_tags;foreignKey:id;joinForeignKey:event_id;References:id;joinReferences:event_tag_id;replace:true"`
+	Hosts                    []Member             `json:"hosts" gorm:"many2many:event_hosts;foreignKey:id;joinForeignKey:event_id;References:id;joinReferences:member_id;replace:true"`
+	Members                  []Member             `json:"members" gorm:"many2many:event_members;foreignKey:id;joinForeignKey:event_id;References:id;joinReferences:member_id;replace:true"`
+	Waitlist                 []EventWaitlistEntry `json:"waitlist" gorm:"foreignKey:EventId;references:Id"`
+	LastRepeatingAlertSentAt *time.Time           `json:"lastRepeatingAlertSentAt" gorm:"column:last_repeating_alert_sent_at"`
+	FeedbackRequestedAt      *time.Time           `json:"feedbackRequestedAt" gorm:"column:feedback_requested_at"`
+	Exceptions               []EventOccurrence    `json:"exceptions" gorm:"foreignKey:EventId;references:Id"`
+	SeriesId                 *int64               `json:"seriesId" gorm:"column:series_id"`
+	SeriesPosition           int                  `json:"seriesPosition" gorm:"column:series_position;default:0"`
+	Track                    string               `json:"track" gorm:"column:track"`
+	Series                   *EventSeries         `json:"series,omitempty" gorm:"foreignKey:SeriesId;references:Id"`
+	Occurrence               *EventOccurrence     `json:"occurrence,omitempty" gorm:"-"`
+}
+
+// IsDraft проверяет, что событие еще не опубликовано
+func (e *Event) IsDraft() bool {
+	return e.Status == EventStatusDraft
+}
+
+// IsRecurring проверяет, повторяется ли событие: по правилу RRULE или по устаревшим полям Repeat*
+func (e *Event) IsRecurring() bool {
+	if e.RRule != nil && *e.RRule != "" {
+		return true
+	}
+	return e.IsRepeating && e.RepeatPeriod != nil
 }
 
 type EventTag struct {
 	Id   int64  `json:"id" gorm:"primaryKey"`
 	Name string `json:"name" gorm:"unique"`
 }
+
+// EventWaitlistEntry запись в листе ожидания события, порядок определяется CreatedAt
+type EventWaitlistEntry struct {
+	Id           int64     `json:"id" gorm:"primaryKey"`
+	EventId      int64     `json:"eventId" gorm:"column:event_id;not null"`
+	MemberId     int64     `json:"memberId" gorm:"column:member_id;not null"`
+	OccurrenceId *int64    `json:"occurrenceId" gorm:"column:occurrence_id"`
+	Member       Member    `json:"member" gorm:"foreignKey:MemberId;references:Id"`
+	CreatedAt    time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+func (EventWaitlistEntry) TableName() string {
+	return "event_waitlist"
+}
+
+// EventAttendanceStatus итог записи на событие: участнику досталось место или он попал в лист ожидания
+type EventAttendanceStatus string
+
+const (
+	EventAttendanceEnrolled   EventAttendanceStatus = "ENROLLED"
+	EventAttendanceWaitlisted EventAttendanceStatus = "WAITLISTED"
+)
+
+// EventOccurrence конкретное повторение повторяющегося события.
+// Запись создается при первой записи участника, при первом алерте по этому повторению
+// или при изменении повторения (отмена, перенос, другие ведущие).
+// OccurrenceDate — исходная дата по правилу повторения (RECURRENCE-ID), OverrideDate — дата после переноса
+type EventOccurrence struct {
+	Id                  int64                `json:"id" gorm:"primaryKey"`
+	EventId             int64                `json:"eventId" gorm:"column:event_id;not null"`
+	OccurrenceDate      time.Time            `json:"occurrenceDate" gorm:"column:occurrence_date;not null"`
+	Cancelled           bool                 `json:"cancelled" gorm:"column:cancelled;default:false"`
+	OverrideDate        *time.Time           `json:"overrideDate" gorm:"column:override_date"`
+	Hosts               []Member             `json:"hosts" gorm:"many2many:event_occurrence_hosts;foreignKey:id;joinForeignKey:occurrence_id;References:id;joinReferences:member_id"`
+	Members             []Member             `json:"members" gorm:"many2many:event_occurrence_members;foreignKey:id;joinForeignKey:occurrence_id;References:id;joinReferences:member_id"`
+	Waitlist            []EventWaitlistEntry `json:"waitlist" gorm:"foreignKey:OccurrenceId;references:Id"`
+	LastAlertSentAt     *time.Time           `json:"lastAlertSentAt" gorm:"column:last_alert_sent_at"`
+	FeedbackRequestedAt *time.Time           `json:"feedbackRequestedAt" gorm:"column:feedback_requested_at"`
+	Event               *Event               `json:"event,omitempty" gorm:"foreignKey:EventId;references:Id"`
+	CreatedAt           time.Time            `json:"createdAt" gorm:"column:created_at"`
+}
+
+func (EventOccurrence) TableName() string {
+	return "event_occurrences"
+}
+
+// IsOverridden проверяет, отличается ли повторение от правила
+func (o *EventOccurrence) IsOverridden() bool {
+	return o.Cancelled || o.OverrideDate != nil || len(o.Hosts) > 0
+}
+
+// MemberCalendarToken секретный токен персональной ленты календаря участника
+type MemberCalendarToken struct {
+	MemberId  int64     `json:"memberId" gorm:"primaryKey;column:member_id"`
+	Token     string    `json:"token" gorm:"column:token;uniqueIndex"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+func (MemberCalendarToken) TableName() string {
+	return "member_calendar_tokens"
+}
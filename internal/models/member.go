@@ -27,6 +27,8 @@ type Member struct {
 	MemberRoles []MemberRole `json:"-" gorm:"foreignKey:MemberId;references:Id"`
 	Roles       []Role       `json:"roles" gorm:"-:all"`
 	Birthday    *DateOnly    `json:"birthday" gorm:"column:birthday"`
+	// CompanyId текущее место работы из справочника компаний
+	CompanyId *int64 `json:"companyId" gorm:"column:company_id"`
 }
 
 type ReviewOnCommunity struct {
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type MentorDbShortModel struct {
 	Id         int64  `json:"id" gorm:"primaryKey"`
 	MemberId   int64  `json:"memberId" gorm:"column:memberId"`
@@ -18,6 +20,13 @@ type MentorDbModel struct {
 	ProfTags   []ProfTag `json:"profTags" gorm:"many2many:mentors_tags;foreignKey:id;joinForeignKey:mentor_id;References:id;joinReferences:tagId"`
 	Contacts   []Contact `json:"contacts" gorm:"foreignKey:ownerId;references:id"`
 	Services   []Service `json:"services" gorm:"foreignKey:ownerId;references:id"`
+
+	// Availability принимает ли ментор новых менти, по умолчанию принимает
+	Availability  MentorAvailability `json:"availability" gorm:"column:availability;default:ACCEPTING"`
+	VacationUntil *time.Time         `json:"vacationUntil" gorm:"column:vacation_until"`
+	// MaxMentees сколько менти ментор ведет одновременно, nil без ограничения
+	MaxMentees             *int       `json:"maxMentees" gorm:"column:max_mentees"`
+	VacationReminderSentAt *time.Time `json:"-" gorm:"column:vacation_reminder_sent_at"`
 }
 
 type MentorModel struct {
@@ -34,6 +43,16 @@ type MentorModel struct {
 	ProfTags   []ProfTag `json:"profTags"`
 	Contacts   []Contact `json:"contacts"`
 	Services   []Service `json:"services"`
+	// Rating средняя оценка по одобренным отзывам, nil если оценок еще нет
+	Rating        *float64           `json:"rating"`
+	ReviewsCount  int                `json:"reviewsCount"`
+	Availability  MentorAvailability `json:"availability"`
+	VacationUntil *time.Time         `json:"vacationUntil"`
+	MaxMentees    *int               `json:"maxMentees"`
+	// ActiveMentees участники с активными записями к ментору
+	ActiveMentees int `json:"activeMentees"`
+	// AcceptsMentees можно ли сейчас записаться к ментору новому участнику
+	AcceptsMentees bool `json:"acceptsMentees"`
 }
 
 type MentorsTag struct {
@@ -76,5 +95,46 @@ func (m *MentorDbModel) ToModel() MentorModel {
 		ProfTags:   m.ProfTags,
 		Contacts:   m.Contacts,
 		Services:   m.Services,
+
+		Availability:  m.Availability,
+		VacationUntil: m.VacationUntil,
+		MaxMentees:    m.MaxMentees,
 	}
 }
+
+// MentorSearchRequest фильтры каталога менторов
+type MentorSearchRequest struct {
+	Limit     *int    `query:"limit"`
+	Offset    *int    `query:"offset"`
+	TagIds    []int64 `query:"tagIds"`
+	PriceFrom *int    `query:"priceFrom"`
+	PriceTo   *int    `query:"priceTo"`
+	// MinExperience минимальный опыт в годах
+	MinExperience *int `query:"minExperience"`
+	// Query поиск по специализации, опыту и названиям тегов
+	Query string `query:"q"`
+	// Availability ACCEPTING, WAITLIST или VACATION. Ментор без свободных мест считается WAITLIST
+	Availability MentorAvailability `query:"availability"`
+	// Sort availability ставит первыми менторов, которые принимают менти
+	Sort string `query:"sort"`
+}
+
+// HasFilters задан ли хотя бы один фильтр. Без фильтров менторы выводятся в порядке, заданном в админке
+func (r *MentorSearchRequest) HasFilters() bool {
+	return len(r.TagIds) > 0 || r.PriceFrom != nil || r.PriceTo != nil || r.MinExperience != nil || r.Query != "" ||
+		r.Availability != "" || r.Sort == MentorSortAvailability
+}
+
+// MentorMatchRequest запрос на подбор менторов под желаемую позицию участника
+type MentorMatchRequest struct {
+	DesiredPosition string  `json:"desiredPosition"`
+	TagIds          []int64 `json:"tagIds"`
+	Limit           int     `json:"limit"`
+}
+
+// MentorMatch ментор, подобранный под запрос участника
+type MentorMatch struct {
+	Mentor      MentorModel `json:"mentor"`
+	Score       float64     `json:"score"`
+	MatchedTags []ProfTag   `json:"matchedTags"`
+}
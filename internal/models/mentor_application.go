@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+type MentorApplicationStatus string
+
+const (
+	MentorApplicationPending  MentorApplicationStatus = "PENDING"
+	MentorApplicationApproved MentorApplicationStatus = "APPROVED"
+	MentorApplicationRejected MentorApplicationStatus = "REJECTED"
+)
+
+// MentorApplication заявка участника стать ментором. После одобрения по ней создается профиль ментора
+type MentorApplication struct {
+	Id         int64                      `json:"id" gorm:"primaryKey"`
+	MemberId   int64                      `json:"memberId" gorm:"column:member_id;not null"`
+	Member     *Member                    `json:"member,omitempty" gorm:"foreignKey:MemberId;references:Id"`
+	Occupation string                     `json:"occupation" gorm:"column:occupation"`
+	Experience string                     `json:"experience" gorm:"column:experience"`
+	About      string                     `json:"about" gorm:"column:about"`
+	ProfTags   []ProfTag                  `json:"profTags" gorm:"many2many:mentor_application_tags;foreignKey:Id;joinForeignKey:application_id;References:Id;joinReferences:tag_id"`
+	Services   []MentorApplicationService `json:"services" gorm:"foreignKey:ApplicationId;references:Id"`
+	Contacts   []MentorApplicationContact `json:"contacts" gorm:"foreignKey:ApplicationId;references:Id"`
+	Status     MentorApplicationStatus    `json:"status" gorm:"column:status;default:PENDING"`
+	// ReviewComment комментарий администратора при одобрении или отклонении
+	ReviewComment string     `json:"reviewComment" gorm:"column:review_comment"`
+	ReviewedAt    *time.Time `json:"reviewedAt" gorm:"column:reviewed_at"`
+	// MentorId профиль ментора, созданный по одобренной заявке
+	MentorId  *int64    `json:"mentorId" gorm:"column:mentor_id"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+func (MentorApplication) TableName() string {
+	return "mentor_applications"
+}
+
+// MentorApplicationService услуга, которую участник предлагает в заявке
+type MentorApplicationService struct {
+	Id            int64  `json:"id" gorm:"primaryKey"`
+	ApplicationId int64  `json:"applicationId" gorm:"column:application_id;not null"`
+	Name          string `json:"name" gorm:"column:name"`
+	Price         int    `json:"price" gorm:"column:price"`
+}
+
+func (MentorApplicationService) TableName() string {
+	return "mentor_application_services"
+}
+
+// MentorApplicationContact контакт ментора из заявки
+type MentorApplicationContact struct {
+	Id            int64  `json:"id" gorm:"primaryKey"`
+	ApplicationId int64  `json:"applicationId" gorm:"column:application_id;not null"`
+	Type          int16  `json:"type" gorm:"column:type"`
+	Link          string `json:"link" gorm:"column:link"`
+}
+
+func (MentorApplicationContact) TableName() string {
+	return "mentor_application_contacts"
+}
+
+// MentorApplicationReviewRequest решение администратора по заявке
+type MentorApplicationReviewRequest struct {
+	Comment string `json:"comment"`
+}
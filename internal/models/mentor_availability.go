@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+type MentorAvailability string
+
+const (
+	MentorAccepting MentorAvailability = "ACCEPTING"
+	// MentorWaitlist ментор продолжает работу с текущими менти, новых записывает только в лист ожидания
+	MentorWaitlist MentorAvailability = "WAITLIST"
+	MentorVacation MentorAvailability = "VACATION"
+)
+
+// MentorSortAvailability сортировка каталога по доступности ментора
+const MentorSortAvailability = "availability"
+
+func (a MentorAvailability) IsValid() bool {
+	switch a {
+	case MentorAccepting, MentorWaitlist, MentorVacation:
+		return true
+	}
+	return false
+}
+
+// EffectiveAvailability доступность с учетом свободных мест: ментор без мест фактически в листе ожидания
+func (m *MentorModel) EffectiveAvailability() MentorAvailability {
+	if m.Availability == MentorAccepting && !m.AcceptsMentees {
+		return MentorWaitlist
+	}
+	if m.Availability == "" {
+		return MentorAccepting
+	}
+	return m.Availability
+}
+
+// MentorAvailabilityRequest изменение доступности ментором
+type MentorAvailabilityRequest struct {
+	Availability  MentorAvailability `json:"availability"`
+	VacationUntil *time.Time         `json:"vacationUntil"`
+	MaxMentees    *int               `json:"maxMentees"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// MentorSlot свободное окно в расписании ментора, на которое можно записаться
+type MentorSlot struct {
+	Id       int64     `json:"id" gorm:"primaryKey"`
+	MentorId int64     `json:"mentorId" gorm:"column:mentor_id;not null"`
+	StartsAt time.Time `json:"startsAt" gorm:"column:starts_at"`
+	EndsAt   time.Time `json:"endsAt" gorm:"column:ends_at"`
+	// Booked занят ли слот активной записью, заполняется при выборке
+	Booked    bool      `json:"booked" gorm:"column:booked;->"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+func (MentorSlot) TableName() string {
+	return "mentor_slots"
+}
+
+type MentorBookingStatus string
+
+const (
+	// MentorBookingPending участник записался, ментор еще не подтвердил
+	MentorBookingPending MentorBookingStatus = "PENDING"
+	// MentorBookingConfirmed встреча подтверждена ментором
+	MentorBookingConfirmed MentorBookingStatus = "CONFIRMED"
+	// MentorBookingRescheduled встреча перенесена на другой слот, перенос подтверждает вторая сторона
+	MentorBookingRescheduled MentorBookingStatus = "RESCHEDULED"
+	// MentorBookingCancelled встреча отменена одной из сторон
+	MentorBookingCancelled MentorBookingStatus = "CANCELLED"
+)
+
+// MentorBookingActiveStatuses статусы, при которых запись занимает слот
+var MentorBookingActiveStatuses = []MentorBookingStatus{
+	MentorBookingPending,
+	MentorBookingConfirmed,
+	MentorBookingRescheduled,
+}
+
+func (s MentorBookingStatus) IsActive() bool {
+	for _, status := range MentorBookingActiveStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// MentorBooking запись участника к ментору на услугу в конкретный слот
+type MentorBooking struct {
+	Id        int64               `json:"id" gorm:"primaryKey"`
+	SlotId    int64               `json:"slotId" gorm:"column:slot_id;not null"`
+	Slot      *MentorSlot         `json:"slot,omitempty" gorm:"foreignKey:SlotId;references:Id"`
+	MentorId  int64               `json:"mentorId" gorm:"column:mentor_id;not null"`
+	Mentor    *MentorDbModel      `json:"mentor,omitempty" gorm:"foreignKey:MentorId;references:Id"`
+	ServiceId int                 `json:"serviceId" gorm:"column:service_id;not null"`
+	Service   *Service            `json:"service,omitempty" gorm:"foreignKey:ServiceId;references:Id"`
+	MemberId  int64               `json:"memberId" gorm:"column:member_id;not null"`
+	Member    *Member             `json:"member,omitempty" gorm:"foreignKey:MemberId;references:Id"`
+	Status    MentorBookingStatus `json:"status" gorm:"column:status;default:PENDING"`
+	Comment   string              `json:"comment" gorm:"column:comment"`
+	// CancelReason причина отмены, которую указала отменившая сторона
+	CancelReason string `json:"cancelReason" gorm:"column:cancel_reason"`
+	// ChangedBy участник, который последним изменил запись. Перенос подтверждает другая сторона
+	ChangedBy      *int64     `json:"changedBy" gorm:"column:changed_by"`
+	ReminderSentAt *time.Time `json:"reminderSentAt" gorm:"column:reminder_sent_at"`
+	CreatedAt      time.Time  `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt      time.Time  `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+func (MentorBooking) TableName() string {
+	return "mentor_bookings"
+}
+
+// MentorSlotRequest окно, которое ментор публикует в расписании
+type MentorSlotRequest struct {
+	StartsAt time.Time `json:"startsAt"`
+	EndsAt   time.Time `json:"endsAt"`
+}
+
+// MentorBookingRequest запрос на запись к ментору
+type MentorBookingRequest struct {
+	SlotId    int64  `json:"slotId"`
+	ServiceId int    `json:"serviceId"`
+	Comment   string `json:"comment"`
+}
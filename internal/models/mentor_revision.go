@@ -0,0 +1,195 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+type MentorRevisionStatus string
+
+const (
+	MentorRevisionPending  MentorRevisionStatus = "PENDING"
+	MentorRevisionApproved MentorRevisionStatus = "APPROVED"
+	MentorRevisionRejected MentorRevisionStatus = "REJECTED"
+	// MentorRevisionSuperseded ментор отправил новую правку, не дождавшись решения по этой
+	MentorRevisionSuperseded MentorRevisionStatus = "SUPERSEDED"
+)
+
+// MentorRevision версия публичного профиля ментора: специализация, опыт, услуги и контакты.
+// Правки ментора попадают на модерацию, одобренные версии образуют историю профиля
+type MentorRevision struct {
+	Id         int64                   `json:"id" gorm:"primaryKey"`
+	MentorId   int64                   `json:"mentorId" gorm:"column:mentor_id;not null"`
+	Mentor     *MentorDbModel          `json:"mentor,omitempty" gorm:"foreignKey:MentorId;references:Id"`
+	Version    int                     `json:"version" gorm:"column:version"`
+	Occupation string                  `json:"occupation" gorm:"column:occupation"`
+	Experience string                  `json:"experience" gorm:"column:experience"`
+	Services   []MentorRevisionService `json:"services" gorm:"foreignKey:RevisionId;references:Id"`
+	Contacts   []MentorRevisionContact `json:"contacts" gorm:"foreignKey:RevisionId;references:Id"`
+	Status     MentorRevisionStatus    `json:"status" gorm:"column:status;default:PENDING"`
+	// ReviewComment комментарий администратора при одобрении, отклонении или откате
+	ReviewComment string     `json:"reviewComment" gorm:"column:review_comment"`
+	ReviewedAt    *time.Time `json:"reviewedAt" gorm:"column:reviewed_at"`
+	// RestoredFromId версия, к которой откатили профиль
+	RestoredFromId *int64    `json:"restoredFromId" gorm:"column:restored_from_id"`
+	CreatedAt      time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+func (MentorRevision) TableName() string {
+	return "mentor_revisions"
+}
+
+// MentorRevisionService услуга в версии профиля. ServiceId ссылается на услугу ментора, 0 для новой
+type MentorRevisionService struct {
+	Id         int64  `json:"id" gorm:"primaryKey"`
+	RevisionId int64  `json:"revisionId" gorm:"column:revision_id;not null"`
+	ServiceId  int    `json:"serviceId" gorm:"column:service_id"`
+	Name       string `json:"name" gorm:"column:name"`
+	Price      int    `json:"price" gorm:"column:price"`
+}
+
+func (MentorRevisionService) TableName() string {
+	return "mentor_revision_services"
+}
+
+// MentorRevisionContact контакт в версии профиля. ContactId ссылается на контакт ментора, 0 для нового
+type MentorRevisionContact struct {
+	Id         int64  `json:"id" gorm:"primaryKey"`
+	RevisionId int64  `json:"revisionId" gorm:"column:revision_id;not null"`
+	ContactId  int    `json:"contactId" gorm:"column:contact_id"`
+	Type       int16  `json:"type" gorm:"column:type"`
+	Link       string `json:"link" gorm:"column:link"`
+}
+
+func (MentorRevisionContact) TableName() string {
+	return "mentor_revision_contacts"
+}
+
+// MentorRevisionReviewRequest решение администратора по правке или причина отката
+type MentorRevisionReviewRequest struct {
+	Comment string `json:"comment"`
+}
+
+// NewMentorRevision снимок текущего профиля ментора
+func NewMentorRevision(mentor *MentorDbModel) *MentorRevision {
+	revision := &MentorRevision{
+		MentorId:   mentor.Id,
+		Occupation: mentor.Occupation,
+		Experience: mentor.Experience,
+		Services:   make([]MentorRevisionService, 0, len(mentor.Services)),
+		Contacts:   make([]MentorRevisionContact, 0, len(mentor.Contacts)),
+	}
+	for _, service := range mentor.Services {
+		revision.Services = append(revision.Services, MentorRevisionService{ServiceId: service.Id, Name: service.Name, Price: service.Price})
+	}
+	for _, contact := range mentor.Contacts {
+		revision.Contacts = append(revision.Contacts, MentorRevisionContact{ContactId: contact.Id, Type: contact.Type, Link: contact.Link})
+	}
+	return revision
+}
+
+// Clone копия версии без идентификаторов, основа для следующей правки
+func (r *MentorRevision) Clone() *MentorRevision {
+	clone := &MentorRevision{
+		MentorId:   r.MentorId,
+		Occupation: r.Occupation,
+		Experience: r.Experience,
+		Services:   make([]MentorRevisionService, 0, len(r.Services)),
+		Contacts:   make([]MentorRevisionContact, 0, len(r.Contacts)),
+	}
+	for _, service := range r.Services {
+		clone.Services = append(clone.Services, MentorRevisionService{ServiceId: service.ServiceId, Name: service.Name, Price: service.Price})
+	}
+	for _, contact := range r.Contacts {
+		clone.Contacts = append(clone.Contacts, MentorRevisionContact{ContactId: contact.ContactId, Type: contact.Type, Link: contact.Link})
+	}
+	return clone
+}
+
+// ApplyTo переносит поля версии в профиль ментора. Теги и порядок не затрагиваются
+func (r *MentorRevision) ApplyTo(mentor *MentorDbModel) {
+	mentor.Occupation = r.Occupation
+	mentor.Experience = r.Experience
+
+	mentor.Services = make([]Service, 0, len(r.Services))
+	for _, service := range r.Services {
+		mentor.Services = append(mentor.Services, Service{Id: service.ServiceId, Name: service.Name, Price: service.Price})
+	}
+	mentor.Contacts = make([]Contact, 0, len(r.Contacts))
+	for _, contact := range r.Contacts {
+		mentor.Contacts = append(mentor.Contacts, Contact{Id: contact.ContactId, Type: contact.Type, Link: contact.Link})
+	}
+}
+
+// MentorRevisionChange изменение одного поля между версиями. Для добавленной услуги или контакта Old пуст, для удаленной пуст New
+type MentorRevisionChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// MentorRevisionDiff отличия версии от базовой. Base не заполнен, если сравнение идет с текущим профилем
+type MentorRevisionDiff struct {
+	Revision *MentorRevision        `json:"revision"`
+	Base     *MentorRevision        `json:"base"`
+	Changes  []MentorRevisionChange `json:"changes"`
+}
+
+// DiffMentorRevisions сравнивает две версии. Услуги сопоставляются по названию, контакты по типу и ссылке
+func DiffMentorRevisions(base, revision *MentorRevision) []MentorRevisionChange {
+	changes := make([]MentorRevisionChange, 0)
+	if base.Occupation != revision.Occupation {
+		changes = append(changes, MentorRevisionChange{Field: "occupation", Old: base.Occupation, New: revision.Occupation})
+	}
+	if base.Experience != revision.Experience {
+		changes = append(changes, MentorRevisionChange{Field: "experience", Old: base.Experience, New: revision.Experience})
+	}
+
+	oldServices := make(map[string]MentorRevisionService, len(base.Services))
+	for _, service := range base.Services {
+		oldServices[serviceKey(service.Name)] = service
+	}
+	for _, service := range revision.Services {
+		key := serviceKey(service.Name)
+		old, ok := oldServices[key]
+		switch {
+		case !ok:
+			changes = append(changes, MentorRevisionChange{Field: "services", New: service})
+		case old.Name != service.Name || old.Price != service.Price:
+			changes = append(changes, MentorRevisionChange{Field: "services", Old: old, New: service})
+		}
+		delete(oldServices, key)
+	}
+	for _, service := range base.Services {
+		if _, removed := oldServices[serviceKey(service.Name)]; removed {
+			changes = append(changes, MentorRevisionChange{Field: "services", Old: service})
+		}
+	}
+
+	oldContacts := make(map[MentorRevisionContact]bool, len(base.Contacts))
+	for _, contact := range base.Contacts {
+		oldContacts[contactKey(contact)] = true
+	}
+	newContacts := make(map[MentorRevisionContact]bool, len(revision.Contacts))
+	for _, contact := range revision.Contacts {
+		newContacts[contactKey(contact)] = true
+		if !oldContacts[contactKey(contact)] {
+			changes = append(changes, MentorRevisionChange{Field: "contacts", New: contact})
+		}
+	}
+	for _, contact := range base.Contacts {
+		if !newContacts[contactKey(contact)] {
+			changes = append(changes, MentorRevisionChange{Field: "contacts", Old: contact})
+		}
+	}
+
+	return changes
+}
+
+func serviceKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func contactKey(contact MentorRevisionContact) MentorRevisionContact {
+	return MentorRevisionContact{Type: contact.Type, Link: strings.TrimSpace(contact.Link)}
+}
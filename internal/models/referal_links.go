@@ -13,6 +13,12 @@ type ReferalLink struct {
 	VacationsCount int               `json:"vacationsCount"`
 	CreatedAt      time.Time         `json:"-"`
 	UpdatedAt      time.Time         `json:"updatedAt"`
+	// ConfirmedAt когда автор последний раз подтвердил в боте, что ссылка актуальна
+	ConfirmedAt             *time.Time `json:"confirmedAt" gorm:"column:confirmed_at"`
+	ConfirmationRequestedAt *time.Time `json:"-" gorm:"column:confirmation_requested_at"`
+	// CompanyId компания из справочника, Company хранит ее каноничное название
+	CompanyId   *int64   `json:"companyId" gorm:"column:company_id"`
+	CompanyInfo *Company `json:"companyInfo,omitempty" gorm:"foreignKey:CompanyId;references:Id"`
 }
 
 type Grade string
@@ -28,9 +34,12 @@ type ReferalLinkStatus string
 const (
 	ReferalLinkFreezed ReferalLinkStatus = "freezed"
 	ReferalLinkActive  ReferalLinkStatus = "active"
+	// ReferalLinkArchived вакансии по ссылке закончились
+	ReferalLinkArchived ReferalLinkStatus = "archived"
 )
 
 type AddLinkRequest struct {
+	CompanyId      *int64    `json:"companyId"`
 	Company        string    `json:"company"`
 	Grade          string    `json:"grade"`
 	ProfTags       []ProfTag `json:"profTags"`
@@ -39,6 +48,7 @@ type AddLinkRequest struct {
 
 type UpdateLinkRequest struct {
 	Id             int64             `json:"id"`
+	CompanyId      *int64            `json:"companyId"`
 	Company        string            `json:"company"`
 	Grade          string            `json:"grade"`
 	ProfTags       []ProfTag         `json:"profTags"`
@@ -49,3 +59,40 @@ type UpdateLinkRequest struct {
 type DeleteLinkRequest struct {
 	Id int64 `json:"id"`
 }
+
+const (
+	// ReferalSortFresh сначала недавно обновленные или подтвержденные ссылки
+	ReferalSortFresh = "fresh"
+	// ReferalSortVacancies сначала ссылки с большим числом вакансий
+	ReferalSortVacancies = "vacancies"
+)
+
+// ReferalLinkSearchRequest фильтры списка реферальных ссылок
+type ReferalLinkSearchRequest struct {
+	Limit  *int `query:"limit"`
+	Offset *int `query:"offset"`
+	// Company нечеткий поиск по названию компании
+	Company   string  `query:"company"`
+	CompanyId *int64  `query:"companyId"`
+	Grade     string  `query:"grade"`
+	TagIds    []int64 `query:"tagIds"`
+	// Status без фильтра возвращаются все ссылки, кроме архивных
+	Status   ReferalLinkStatus `query:"status"`
+	AuthorId *int64            `query:"authorId"`
+	// Sort fresh или vacancies, при поиске по компании по умолчанию сначала самые похожие названия
+	Sort string `query:"sort"`
+	// Order asc или desc, по умолчанию desc
+	Order string `query:"order"`
+	// CreatedAfter и CreatedBefore окно появления ссылок для сохраненных поисков
+	CreatedAfter  *time.Time `query:"-"`
+	CreatedBefore *time.Time `query:"-"`
+}
+
+// ReferalCompanySummary сводка по активным ссылкам компании без данных авторов
+type ReferalCompanySummary struct {
+	CompanyId      int64    `json:"companyId"`
+	Company        string   `json:"company"`
+	LinksCount     int      `json:"linksCount"`
+	VacanciesCount int      `json:"vacanciesCount"`
+	Grades         []string `json:"grades"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+type ReferalRequestStatus string
+
+const (
+	ReferalRequestSubmitted    ReferalRequestStatus = "submitted"
+	ReferalRequestReferred     ReferalRequestStatus = "referred"
+	ReferalRequestInterviewing ReferalRequestStatus = "interviewing"
+	ReferalRequestHired        ReferalRequestStatus = "hired"
+	ReferalRequestRejected     ReferalRequestStatus = "rejected"
+)
+
+// IsFinal завершена ли работа по заявке
+func (s ReferalRequestStatus) IsFinal() bool {
+	return s == ReferalRequestHired || s == ReferalRequestRejected
+}
+
+// ReferalRequest заявка участника на рекомендацию по реферальной ссылке
+type ReferalRequest struct {
+	Id       int64        `json:"id" gorm:"primaryKey"`
+	LinkId   int64        `json:"linkId" gorm:"column:link_id;not null"`
+	Link     *ReferalLink `json:"link,omitempty" gorm:"foreignKey:LinkId;references:Id"`
+	MemberId int64        `json:"memberId" gorm:"column:member_id;not null"`
+	Member   *Member      `json:"member,omitempty" gorm:"foreignKey:MemberId;references:Id"`
+	// ResumeId резюме, приложенное к заявке. Обнуляется, если участник удалил резюме
+	ResumeId *int64               `json:"resumeId" gorm:"column:resume_id"`
+	Resume   *Resume              `json:"resume,omitempty" gorm:"foreignKey:ResumeId;references:Id"`
+	Message  string               `json:"message" gorm:"column:message"`
+	Status   ReferalRequestStatus `json:"status" gorm:"column:status;default:submitted"`
+	// Comment пояснение к последнему изменению статуса, например причина отказа
+	Comment   string    `json:"comment" gorm:"column:comment"`
+	ChangedBy *int64    `json:"changedBy" gorm:"column:changed_by"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+func (ReferalRequest) TableName() string {
+	return "referal_requests"
+}
+
+type AddReferalRequest struct {
+	LinkId int64 `json:"linkId"`
+	// ResumeId 0 — приложить основное резюме
+	ResumeId int64  `json:"resumeId"`
+	Message  string `json:"message"`
+}
+
+type UpdateReferalRequestStatus struct {
+	Status  ReferalRequestStatus `json:"status"`
+	Comment string               `json:"comment"`
+}
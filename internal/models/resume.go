@@ -11,18 +11,62 @@ const (
 )
 
 type Resume struct {
-	Id               int64      `json:"id" gorm:"primaryKey"`
-	TgID             int64      `json:"tgId" gorm:"column:tg_id"`
-	FilePath         string     `json:"filePath" gorm:"column:file_path"`
-	FileName         string     `json:"fileName" gorm:"column:file_name"`
-	WorkExperience   string     `json:"workExperience" gorm:"column:work_experience"`
-	DesiredPosition  string     `json:"desiredPosition" gorm:"column:desired_position"`
-	WorkFormat       WorkFormat `json:"workFormat" gorm:"column:work_format"`
-	CreatedAt        time.Time  `json:"createdAt" gorm:"column:created_at"`
-	UpdatedAt        time.Time  `json:"updatedAt" gorm:"column:updated_at"`
-	Member           *Member    `json:"member,omitempty" gorm:"foreignKey:TgID;references:TelegramID"`
-	ParsedAt         *time.Time `json:"parsedAt,omitempty" gorm:"-:all"`
-	ParsedConfidence float64    `json:"parsedConfidence,omitempty" gorm:"-:all"`
+	Id              int64      `json:"id" gorm:"primaryKey"`
+	TgID            int64      `json:"tgId" gorm:"column:tg_id"`
+	FilePath        string     `json:"filePath" gorm:"column:file_path"`
+	FileName        string     `json:"fileName" gorm:"column:file_name"`
+	WorkExperience  string     `json:"workExperience" gorm:"column:work_experience"`
+	DesiredPosition string     `json:"desiredPosition" gorm:"column:desired_position"`
+	WorkFormat      WorkFormat `json:"workFormat" gorm:"column:work_format"`
+	CreatedAt       time.Time  `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt       time.Time  `json:"updatedAt" gorm:"column:updated_at"`
+	Member          *Member    `json:"member,omitempty" gorm:"foreignKey:TgID;references:TelegramID"`
+	// Version номер загрузки у участника, IsPrimary — текущее резюме, которое видят рекрутеры
+	Version   int  `json:"version" gorm:"column:version"`
+	IsPrimary bool `json:"isPrimary" gorm:"column:is_primary"`
+	// Profile структура, разобранная из файла резюме
+	Profile         *ResumeProfile `json:"profile,omitempty" gorm:"column:profile;serializer:json"`
+	ExperienceYears *float64       `json:"experienceYears,omitempty" gorm:"column:experience_years"`
+	ProfTags        []ProfTag      `json:"profTags" gorm:"many2many:resume_tags"`
+	ParsedAt        *time.Time     `json:"parsedAt,omitempty" gorm:"column:parsed_at"`
+	// Text извлеченный из файла текст, по нему работает полнотекстовый поиск
+	Text string `json:"-" gorm:"column:text"`
+	// Snippet фрагменты текста с найденными словами в <mark>, заполняется только при поиске
+	Snippet string `json:"snippet,omitempty" gorm:"-"`
+}
+
+// ResumeExperience место работы из раздела опыта. End пустой, если работа текущая
+type ResumeExperience struct {
+	Company  string     `json:"company,omitempty"`
+	Position string     `json:"position,omitempty"`
+	Start    *time.Time `json:"start,omitempty"`
+	End      *time.Time `json:"end,omitempty"`
+	Current  bool       `json:"current,omitempty"`
+	Months   int        `json:"months,omitempty"`
+}
+
+// ResumeEducation учебное заведение из раздела образования
+type ResumeEducation struct {
+	Institution string `json:"institution,omitempty"`
+	Details     string `json:"details,omitempty"`
+	Year        *int   `json:"year,omitempty"`
+}
+
+type ResumeContacts struct {
+	Emails []string `json:"emails,omitempty"`
+	Phones []string `json:"phones,omitempty"`
+	Links  []string `json:"links,omitempty"`
+}
+
+// ResumeProfile результат разбора резюме по разделам.
+// Confidence — доля найденных разделов от 0 до 1
+type ResumeProfile struct {
+	Experience      []ResumeExperience `json:"experience,omitempty"`
+	Education       []ResumeEducation  `json:"education,omitempty"`
+	Skills          []string           `json:"skills,omitempty"`
+	ExperienceYears float64            `json:"experienceYears"`
+	Contacts        ResumeContacts     `json:"contacts"`
+	Confidence      float64            `json:"confidence"`
 }
 
 func (Resume) TableName() string {
@@ -30,9 +74,60 @@ func (Resume) TableName() string {
 }
 
 type ResumeFilter struct {
+	// AllVersions показывает все версии резюме, по умолчанию только основные
+	AllVersions bool `query:"allVersions"`
+	// Query поисковый запрос по тексту резюме: слова через пробел или +, "точная фраза", OR, -исключение
+	Query           *string     `query:"q"`
 	WorkFormat      *WorkFormat `query:"workFormat"`
 	DesiredPosition *string     `query:"desiredPosition"`
 	WorkExperience  *string     `query:"workExperience"`
+	// MinExperienceYears и TagIds фильтры по разобранному профилю резюме
+	MinExperienceYears *float64 `query:"minExperienceYears"`
+	TagIds             []int64  `query:"tagIds"`
+	// CreatedAfter и CreatedBefore окно загрузки резюме для сохраненных поисков
+	CreatedAfter  *time.Time `query:"-"`
+	CreatedBefore *time.Time `query:"-"`
+}
+
+// ResumeChangeKind событие в истории резюме участника
+type ResumeChangeKind string
+
+const (
+	ResumeChangeUploaded ResumeChangeKind = "UPLOADED"
+	ResumeChangeUpdated  ResumeChangeKind = "UPDATED"
+	ResumeChangeReparsed ResumeChangeKind = "REPARSED"
+	ResumeChangePrimary  ResumeChangeKind = "PRIMARY"
+)
+
+// ResumeFieldChange изменение поля резюме. Для строк заполнены Before и After, для списков — Added и Removed
+type ResumeFieldChange struct {
+	Field   string   `json:"field"`
+	Before  string   `json:"before,omitempty"`
+	After   string   `json:"after,omitempty"`
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// ResumeChange запись истории: загрузка новой версии, правка полей, повторный разбор или смена основного резюме.
+// При загрузке и смене основного резюме поля сравниваются с предыдущим основным
+type ResumeChange struct {
+	Id               int64               `json:"id" gorm:"primaryKey"`
+	TgID             int64               `json:"tgId" gorm:"column:tg_id"`
+	ResumeId         *int64              `json:"resumeId" gorm:"column:resume_id"`
+	PreviousResumeId *int64              `json:"previousResumeId,omitempty" gorm:"column:previous_resume_id"`
+	Kind             ResumeChangeKind    `json:"kind" gorm:"column:kind"`
+	Changes          []ResumeFieldChange `json:"changes" gorm:"column:changes;serializer:json"`
+	CreatedAt        time.Time           `json:"createdAt" gorm:"column:created_at"`
+}
+
+func (ResumeChange) TableName() string {
+	return "resume_changes"
+}
+
+// ResumeHistory версии резюме участника и история изменений, новые сверху
+type ResumeHistory struct {
+	Versions []Resume       `json:"versions"`
+	Changes  []ResumeChange `json:"changes"`
 }
 
 type CreateResumeRequest struct {
@@ -20,6 +20,7 @@ const (
 	PermissionCanEditAdminMembers          Permission = "can_edit_admin_members"
 	PermissionCanEditAdminMentors          Permission = "can_edit_admin_mentors"
 	PermissionCanEditAdminEvents           Permission = "can_edit_admin_events"
+	PermissionCanPublishAdminEvents        Permission = "can_publish_admin_events"
 	PermissionCanViewAdminReviews          Permission = "can_view_admin_reviews"
 	PermissionCanEditAdminReviews          Permission = "can_edit_admin_reviews"
 	PermissionCanApprovedAdminReviews      Permission = "can_approved_admin_reviews"
@@ -27,7 +28,13 @@ const (
 	PermissionCanEditAdminMentorsReview    Permission = "can_edit_admin_mentors_review"
 	PermissionCanApproveAdminMentorsReview Permission = "can_approve_admin_mentors_review"
 	PermissionCanViewAdminResumes          Permission = "can_view_admin_resumes"
+	PermissionCanEditAdminResumes          Permission = "can_edit_admin_resumes"
 	PermissionCanEditPlatformMentors       Permission = "can_edit_platform_mentor"
+	PermissionCanEditPlatformEvents        Permission = "can_edit_platform_events"
+	PermissionCanViewAdminCompanies        Permission = "can_view_admin_companies"
+	PermissionCanEditAdminCompanies        Permission = "can_edit_admin_companies"
+	PermissionCanViewAdminVacancies        Permission = "can_view_admin_vacancies"
+	PermissionCanEditAdminVacancies        Permission = "can_edit_admin_vacancies"
 )
 
 type PermissionModel struct {
@@ -0,0 +1,84 @@
+package models
+
+import "time"
+
+// SavedSearchKind что ищет сохраненный поиск
+type SavedSearchKind string
+
+const (
+	SavedSearchReferalLinks SavedSearchKind = "REFERAL_LINKS"
+	SavedSearchVacancies    SavedSearchKind = "VACANCIES"
+	// SavedSearchResumes поиск по резюме доступен только с правом can_view_admin_resumes
+	SavedSearchResumes SavedSearchKind = "RESUMES"
+)
+
+func (k SavedSearchKind) IsValid() bool {
+	switch k {
+	case SavedSearchReferalLinks, SavedSearchVacancies, SavedSearchResumes:
+		return true
+	}
+	return false
+}
+
+// SavedSearchFrequency как часто бот присылает новые результаты
+type SavedSearchFrequency string
+
+const (
+	// SavedSearchInstant новые результаты приходят сразу после появления
+	SavedSearchInstant SavedSearchFrequency = "INSTANT"
+	// SavedSearchDaily новые результаты собираются в дайджест раз в сутки
+	SavedSearchDaily SavedSearchFrequency = "DAILY"
+)
+
+func (f SavedSearchFrequency) IsValid() bool {
+	return f == SavedSearchInstant || f == SavedSearchDaily
+}
+
+// SavedSearchFilter условия поиска. Для каждого вида используются только подходящие поля:
+// ссылки — теги, грейд и компания; вакансии — теги, грейд, компания, формат и зарплата; резюме — формат, должность и опыт
+type SavedSearchFilter struct {
+	TagIds          []int64    `json:"tagIds,omitempty"`
+	Grade           string     `json:"grade,omitempty"`
+	Company         string     `json:"company,omitempty"`
+	WorkFormat      WorkFormat `json:"workFormat,omitempty"`
+	SalaryMin       *int       `json:"salaryMin,omitempty"`
+	DesiredPosition string     `json:"desiredPosition,omitempty"`
+	WorkExperience  string     `json:"workExperience,omitempty"`
+}
+
+// SavedSearch сохраненный поиск участника, по которому бот присылает новые результаты
+type SavedSearch struct {
+	Id        int64                `json:"id" gorm:"primaryKey"`
+	MemberId  int64                `json:"memberId" gorm:"column:member_id;not null"`
+	Member    *Member              `json:"-" gorm:"foreignKey:MemberId;references:Id"`
+	Name      string               `json:"name" gorm:"column:name"`
+	Kind      SavedSearchKind      `json:"kind" gorm:"column:kind"`
+	Filter    SavedSearchFilter    `json:"filter" gorm:"column:filter;serializer:json"`
+	Frequency SavedSearchFrequency `json:"frequency" gorm:"column:frequency;default:DAILY"`
+	// CheckedAt до какого момента результаты уже просмотрены, новыми считаются появившиеся позже
+	CheckedAt      time.Time  `json:"checkedAt" gorm:"column:checked_at"`
+	LastNotifiedAt *time.Time `json:"lastNotifiedAt" gorm:"column:last_notified_at"`
+	CreatedAt      time.Time  `json:"createdAt" gorm:"column:created_at"`
+}
+
+func (SavedSearch) TableName() string {
+	return "saved_searches"
+}
+
+type SavedSearchRequest struct {
+	Name      string               `json:"name"`
+	Kind      SavedSearchKind      `json:"kind"`
+	Filter    SavedSearchFilter    `json:"filter"`
+	Frequency SavedSearchFrequency `json:"frequency"`
+}
+
+// SavedSearchMatches новые результаты сохраненного поиска с CheckedAt до Until.
+// В списках только первые результаты, Total — сколько их всего
+type SavedSearchMatches struct {
+	Search    *SavedSearch
+	Until     time.Time
+	Total     int
+	Links     []ReferalLink
+	Vacancies []Vacancy
+	Resumes   []Resume
+}
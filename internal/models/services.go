@@ -12,6 +12,13 @@ func (Service) TableName() string {
 	return "services"
 }
 
+type ReviewOnServiceStatus string
+
+const (
+	ReviewOnServiceStatusDraft    ReviewOnServiceStatus = "DRAFT"
+	ReviewOnServiceStatusApproved ReviewOnServiceStatus = "APPROVED"
+)
+
 type ReviewOnService struct {
 	Id        int     `json:"id" gorm:"primaryKey"`
 	ServiceId int     `json:"serviceId" gorm:"column:serviceId"`
@@ -19,6 +26,13 @@ type ReviewOnService struct {
 	Author    string  `json:"author"`
 	Text      string  `json:"text"`
 	Date      string  `json:"date"`
+	// MemberId автор отзыва с платформы. У отзывов, добавленных из админки, не заполнен
+	MemberId *int64  `json:"memberId" gorm:"column:memberId"`
+	Member   *Member `json:"member,omitempty" gorm:"foreignKey:MemberId;references:Id"`
+	// BookingId встреча с ментором, по итогам которой оставлен отзыв
+	BookingId *int64                `json:"bookingId" gorm:"column:bookingId"`
+	Rating    *int                  `json:"rating" gorm:"column:rating"`
+	Status    ReviewOnServiceStatus `json:"status" gorm:"column:status;default:DRAFT"`
 }
 
 // TableName указывает GORM использовать правильное имя таблицы
@@ -36,6 +50,8 @@ type ReviewOnServiceWithMentor struct {
 	Author      string `json:"author"`
 	Text        string `json:"text"`
 	Date        string `json:"date"`
+	Rating      *int   `json:"rating"`
+	Verified    bool   `json:"verified"`
 }
 
 // ReviewOnServiceRequest представляет запрос на создание отзыва на услугу
@@ -45,3 +61,16 @@ type ReviewOnServiceRequest struct {
 	Text      string `json:"text" binding:"required"`
 	Date      string `json:"date"`
 }
+
+// AddReviewOnServiceRequest отзыв участника на услугу по итогам встречи с ментором
+type AddReviewOnServiceRequest struct {
+	Rating int    `json:"rating"`
+	Text   string `json:"text"`
+}
+
+// MentorRating средняя оценка ментора по одобренным отзывам
+type MentorRating struct {
+	MentorId     int64   `json:"mentorId" gorm:"column:mentor_id"`
+	Rating       float64 `json:"rating" gorm:"column:rating"`
+	ReviewsCount int     `json:"reviewsCount" gorm:"column:reviews_count"`
+}
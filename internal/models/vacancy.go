@@ -0,0 +1,94 @@
+package models
+
+import "time"
+
+type VacancyStatus string
+
+const (
+	// VacancyPending вакансия ждет модерации
+	VacancyPending   VacancyStatus = "PENDING"
+	VacancyPublished VacancyStatus = "PUBLISHED"
+	VacancyRejected  VacancyStatus = "REJECTED"
+	// VacancyClosed автор закрыл вакансию, она больше не показывается в списке
+	VacancyClosed VacancyStatus = "CLOSED"
+)
+
+// Vacancy вакансия, которой участник делится с сообществом. В общий список попадает после модерации и до ExpiresAt
+type Vacancy struct {
+	Id          int64      `json:"id" gorm:"primaryKey"`
+	AuthorId    int64      `json:"authorId" gorm:"column:author_id;not null"`
+	Author      *Member    `json:"author,omitempty" gorm:"foreignKey:AuthorId;references:Id"`
+	Title       string     `json:"title" gorm:"column:title"`
+	CompanyId   *int64     `json:"companyId" gorm:"column:company_id"`
+	Company     string     `json:"company" gorm:"column:company"`
+	CompanyInfo *Company   `json:"companyInfo,omitempty" gorm:"foreignKey:CompanyId;references:Id"`
+	Grade       string     `json:"grade" gorm:"column:grade"`
+	ProfTags    []ProfTag  `json:"profTags" gorm:"many2many:vacancy_tags"`
+	WorkFormat  WorkFormat `json:"workFormat" gorm:"column:work_format"`
+	// SalaryFrom и SalaryTo вилка в рублях, любая из границ может быть не указана
+	SalaryFrom  *int          `json:"salaryFrom" gorm:"column:salary_from"`
+	SalaryTo    *int          `json:"salaryTo" gorm:"column:salary_to"`
+	Description string        `json:"description" gorm:"column:description"`
+	ExpiresAt   time.Time     `json:"expiresAt" gorm:"column:expires_at"`
+	Status      VacancyStatus `json:"status" gorm:"column:status;default:PENDING"`
+	// ReviewComment комментарий модератора, обязателен при отклонении
+	ReviewComment string     `json:"reviewComment" gorm:"column:review_comment"`
+	ReviewedAt    *time.Time `json:"reviewedAt" gorm:"column:reviewed_at"`
+	// AnnouncedAt когда бот разослал вакансию подписчикам ее тегов
+	AnnouncedAt *time.Time `json:"-" gorm:"column:announced_at"`
+	CreatedAt   time.Time  `json:"createdAt" gorm:"column:created_at"`
+	UpdatedAt   time.Time  `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+func (Vacancy) TableName() string {
+	return "vacancies"
+}
+
+// VacancyRequest вакансия от автора. Компания выбирается из справочника или создается по названию
+type VacancyRequest struct {
+	Title       string     `json:"title"`
+	CompanyId   *int64     `json:"companyId"`
+	Company     string     `json:"company"`
+	Grade       string     `json:"grade"`
+	ProfTags    []ProfTag  `json:"profTags"`
+	WorkFormat  WorkFormat `json:"workFormat"`
+	SalaryFrom  *int       `json:"salaryFrom"`
+	SalaryTo    *int       `json:"salaryTo"`
+	Description string     `json:"description"`
+	ExpiresAt   *time.Time `json:"expiresAt"`
+}
+
+// VacancySearchRequest фильтры списка вакансий
+type VacancySearchRequest struct {
+	Limit      *int       `query:"limit"`
+	Offset     *int       `query:"offset"`
+	TagIds     []int64    `query:"tagIds"`
+	Grade      string     `query:"grade"`
+	WorkFormat WorkFormat `query:"workFormat"`
+	CompanyId  *int64     `query:"companyId"`
+	Company    string     `query:"company"`
+	// SalaryMin вакансии, вилка которых доходит до этой суммы
+	SalaryMin *int `query:"salaryMin"`
+	// PublishedAfter и PublishedBefore окно публикации вакансий для сохраненных поисков
+	PublishedAfter  *time.Time `query:"-"`
+	PublishedBefore *time.Time `query:"-"`
+}
+
+// VacancyReviewRequest решение модератора по вакансии
+type VacancyReviewRequest struct {
+	Comment string `json:"comment"`
+}
+
+// VacancySubscription тег, новые вакансии с которым участник получает в боте
+type VacancySubscription struct {
+	MemberId  int64 `json:"memberId" gorm:"primaryKey;column:member_id"`
+	ProfTagId int64 `json:"profTagId" gorm:"primaryKey;column:prof_tag_id"`
+}
+
+func (VacancySubscription) TableName() string {
+	return "vacancy_subscriptions"
+}
+
+type VacancySubscriptionRequest struct {
+	TagIds []int64 `json:"tagIds"`
+}
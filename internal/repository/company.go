@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"errors"
+	"ithozyeva/database"
+	"ithozyeva/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrCompanyNameTaken название или алиас уже принадлежит другой компании
+var ErrCompanyNameTaken = errors.New("компания с таким названием уже есть в справочнике")
+
+type CompanyRepository struct {
+	BaseRepository[models.Company]
+}
+
+func NewCompanyRepository() *CompanyRepository {
+	return &CompanyRepository{
+		BaseRepository: NewBaseRepository(database.DB, &models.Company{}),
+	}
+}
+
+// SearchByName ищет компании по названию и алиасам
+func (r *CompanyRepository) SearchByName(name string, limit *int, offset *int) ([]models.Company, int64, error) {
+	var companies []models.Company
+	var count int64
+
+	query := database.DB.Model(&models.Company{})
+	if name != "" {
+		pattern := "%" + name + "%"
+		query = query.Where("normalized_name LIKE ? OR id IN (SELECT company_id FROM company_aliases WHERE normalized_alias LIKE ?)", pattern, pattern)
+	}
+
+	if err := query.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if limit != nil {
+		query = query.Limit(*limit)
+	}
+	if offset != nil {
+		query = query.Offset(*offset)
+	}
+
+	if err := query.Preload("Aliases").Order("name").Find(&companies).Error; err != nil {
+		return nil, 0, err
+	}
+	return companies, count, nil
+}
+
+func (r *CompanyRepository) GetById(id int64) (*models.Company, error) {
+	var company models.Company
+	if err := database.DB.Preload("Aliases").First(&company, id).Error; err != nil {
+		return nil, err
+	}
+	return &company, nil
+}
+
+// FindByName ищет компанию по нормализованному названию или алиасу
+func (r *CompanyRepository) FindByName(normalized string) (*models.Company, error) {
+	var company models.Company
+	err := database.DB.Preload("Aliases").
+		Where("normalized_name = ? OR id IN (SELECT company_id FROM company_aliases WHERE normalized_alias = ?)", normalized, normalized).
+		First(&company).Error
+	if err != nil {
+		return nil, err
+	}
+	return &company, nil
+}
+
+// nameTaken проверяет, что название не занято другой компанией ни как название, ни как алиас
+func nameTaken(tx *gorm.DB, normalized string, exceptId int64) (bool, error) {
+	var count int64
+	err := tx.Model(&models.Company{}).
+		Where("id <> ?", exceptId).
+		Where("normalized_name = ? OR id IN (SELECT company_id FROM company_aliases WHERE normalized_alias = ?)", normalized, normalized).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Create создает компанию вместе с алиасами
+func (r *CompanyRepository) Create(company *models.Company) (*models.Company, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		taken, err := nameTaken(tx, company.NormalizedName, 0)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrCompanyNameTaken
+		}
+		for _, alias := range company.Aliases {
+			if taken, err := nameTaken(tx, alias.NormalizedAlias, 0); err != nil {
+				return err
+			} else if taken {
+				return ErrCompanyNameTaken
+			}
+		}
+		return tx.Create(company).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(company.Id)
+}
+
+// Update меняет название и сайт компании и переименовывает ссылки на нее
+func (r *CompanyRepository) Update(company *models.Company) (*models.Company, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		taken, err := nameTaken(tx, company.NormalizedName, company.Id)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrCompanyNameTaken
+		}
+
+		result := tx.Model(&models.Company{}).Where("id = ?", company.Id).Updates(map[string]interface{}{
+			"name":            company.Name,
+			"normalized_name": company.NormalizedName,
+			"website":         company.Website,
+			"updated_at":      time.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Model(&models.ReferalLink{}).Where("company_id = ?", company.Id).Update("company", company.Name).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(company.Id)
+}
+
+// AddAlias добавляет компании другое написание названия
+func (r *CompanyRepository) AddAlias(alias *models.CompanyAlias) (*models.Company, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		taken, err := nameTaken(tx, alias.NormalizedAlias, 0)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrCompanyNameTaken
+		}
+		return tx.Create(alias).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(alias.CompanyId)
+}
+
+// DeleteAlias удаляет алиас компании
+func (r *CompanyRepository) DeleteAlias(companyId int64, aliasId int64) (*models.Company, error) {
+	result := database.DB.Where("id = ? AND company_id = ?", aliasId, companyId).Delete(&models.CompanyAlias{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.GetById(companyId)
+}
+
+// SetLogo сохраняет или очищает ключ логотипа в S3
+func (r *CompanyRepository) SetLogo(id int64, logoPath *string) error {
+	return database.DB.Model(&models.Company{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"logo_path":  logoPath,
+		"updated_at": time.Now(),
+	}).Error
+}
+
+// Merge переносит ссылки, участников и алиасы компании source в target. Название source становится алиасом target
+func (r *CompanyRepository) Merge(sourceId int64, targetId int64) (*models.Company, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var companies []models.Company
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", []int64{sourceId, targetId}).
+			Find(&companies).Error; err != nil {
+			return err
+		}
+		if len(companies) != 2 {
+			return gorm.ErrRecordNotFound
+		}
+
+		var source, target models.Company
+		for _, company := range companies {
+			if company.Id == sourceId {
+				source = company
+			} else {
+				target = company
+			}
+		}
+
+		if err := tx.Model(&models.ReferalLink{}).Where("company_id = ?", sourceId).
+			Updates(map[string]interface{}{"company_id": targetId, "company": target.Name}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Member{}).Where("company_id = ?", sourceId).
+			Update("company_id", targetId).Error; err != nil {
+			return err
+		}
+
+		// Алиасы, совпадающие с названием или алиасами target, не переносятся
+		if err := tx.Where("company_id = ? AND (normalized_alias = ? OR normalized_alias IN (SELECT normalized_alias FROM company_aliases WHERE company_id = ?))",
+			sourceId, target.NormalizedName, targetId).
+			Delete(&models.CompanyAlias{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.CompanyAlias{}).Where("company_id = ?", sourceId).
+			Update("company_id", targetId).Error; err != nil {
+			return err
+		}
+
+		var exists int64
+		if err := tx.Model(&models.CompanyAlias{}).
+			Where("company_id = ? AND normalized_alias = ?", targetId, source.NormalizedName).
+			Count(&exists).Error; err != nil {
+			return err
+		}
+		if exists == 0 && source.NormalizedName != target.NormalizedName {
+			if err := tx.Create(&models.CompanyAlias{
+				CompanyId:       targetId,
+				Alias:           source.Name,
+				NormalizedAlias: source.NormalizedName,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		// Логотип source остается, только если у target его нет
+		if target.LogoPath == nil && source.LogoPath != nil {
+			if err := tx.Model(&models.Company{}).Where("id = ?", targetId).Update("logo_path", source.LogoPath).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(&models.Company{}, sourceId).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(targetId)
+}
+
+// GetMembers возвращает участников, которые работают в компании
+func (r *CompanyRepository) GetMembers(companyId int64) ([]models.Member, error) {
+	var members []models.Member
+	err := database.DB.Preload("MemberRoles").
+		Where("company_id = ?", companyId).
+		Order("first_name, last_name").
+		Find(&members).Error
+	return members, err
+}
+
+// SetMemberCompany меняет место работы участника
+func (r *CompanyRepository) SetMemberCompany(memberId int64, companyId *int64) error {
+	return database.DB.Model(&models.Member{}).Where("id = ?", memberId).Update("company_id", companyId).Error
+}
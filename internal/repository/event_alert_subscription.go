@@ -18,13 +18,40 @@ func NewEventAlertSubscriptionRepository() *EventAlertSubscriptionRepository {
 // GetByEventAndMember получает подписку по событию и пользователю
 func (r *EventAlertSubscriptionRepository) GetByEventAndMember(eventId int64, memberId int64) (*models.EventAlertSubscription, error) {
 	var subscription models.EventAlertSubscription
-	err := database.DB.Where("event_id = ? AND member_id = ?", eventId, memberId).First(&subscription).Error
+	err := database.DB.Where("event_id = ? AND member_id = ? AND occurrence_id IS NULL", eventId, memberId).First(&subscription).Error
 	if err != nil {
 		return nil, err
 	}
 	return &subscription, nil
 }
 
+// GetSubscribedMembersForOccurrence получает пользователей, которые должны получить алерт о конкретном повторении:
+// подписанных на само повторение и подписанных на событие целиком, если они не отказались от этого повторения
+func (r *EventAlertSubscriptionRepository) GetSubscribedMembersForOccurrence(eventId int64, occurrenceId int64) ([]models.Member, error) {
+	var members []models.Member
+	err := database.DB.
+		Table("members").
+		Where(`EXISTS (
+			SELECT 1 FROM event_alert_subscriptions s
+			WHERE s.member_id = members.id AND s.event_id = ? AND s.occurrence_id = ? AND s.status = ?
+		) OR (
+			EXISTS (
+				SELECT 1 FROM event_alert_subscriptions s
+				WHERE s.member_id = members.id AND s.event_id = ? AND s.occurrence_id IS NULL AND s.status = ?
+			) AND NOT EXISTS (
+				SELECT 1 FROM event_alert_subscriptions s
+				WHERE s.member_id = members.id AND s.event_id = ? AND s.occurrence_id = ? AND s.status = ?
+			)
+		)`,
+			eventId, occurrenceId, models.EventAlertStatusSubscribed,
+			eventId, models.EventAlertStatusSubscribed,
+			eventId, occurrenceId, models.EventAlertStatusUnsubscribed,
+		).
+		Where("members.telegram_id IS NOT NULL AND members.telegram_id != 0").
+		Find(&members).Error
+	return members, err
+}
+
 // GetSubscribedMembersForEvent получает всех подписанных пользователей для события
 func (r *EventAlertSubscriptionRepository) GetSubscribedMembersForEvent(eventId int64) ([]models.Member, error) {
 	var members []models.Member
@@ -32,6 +59,7 @@ func (r *EventAlertSubscriptionRepository) GetSubscribedMembersForEvent(eventId
 		Table("members").
 		Joins("INNER JOIN event_alert_subscriptions ON members.id = event_alert_subscriptions.member_id").
 		Where("event_alert_subscriptions.event_id = ? AND event_alert_subscriptions.status = ?", eventId, models.EventAlertStatusSubscribed).
+		Where("event_alert_subscriptions.occurrence_id IS NULL").
 		Where("members.telegram_id IS NOT NULL AND members.telegram_id != 0").
 		Find(&members).Error
 	return members, err
@@ -41,7 +69,7 @@ func (r *EventAlertSubscriptionRepository) GetSubscribedMembersForEvent(eventId
 func (r *EventAlertSubscriptionRepository) GetPendingSubscriptionsForEvent(eventId int64) ([]models.EventAlertSubscription, error) {
 	var subscriptions []models.EventAlertSubscription
 	err := database.DB.
-		Where("event_id = ? AND status = ?", eventId, models.EventAlertStatusPending).
+		Where("event_id = ? AND status = ? AND occurrence_id IS NULL", eventId, models.EventAlertStatusPending).
 		Preload("Member").
 		Preload("Event").
 		Find(&subscriptions).Error
@@ -51,7 +79,13 @@ func (r *EventAlertSubscriptionRepository) GetPendingSubscriptionsForEvent(event
 // CreateOrUpdate создает или обновляет подписку
 func (r *EventAlertSubscriptionRepository) CreateOrUpdate(subscription *models.EventAlertSubscription) (*models.EventAlertSubscription, error) {
 	var existing models.EventAlertSubscription
-	err := database.DB.Where("event_id = ? AND member_id = ?", subscription.EventId, subscription.MemberId).First(&existing).Error
+	query := database.DB.Where("event_id = ? AND member_id = ?", subscription.EventId, subscription.MemberId)
+	if subscription.OccurrenceId != nil {
+		query = query.Where("occurrence_id = ?", *subscription.OccurrenceId)
+	} else {
+		query = query.Where("occurrence_id IS NULL")
+	}
+	err := query.First(&existing).Error
 	
 	if err != nil {
 		return r.Create(subscription)
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"ithozyeva/database"
+	"ithozyeva/internal/models"
+
+	"gorm.io/gorm/clause"
+)
+
+type EventAttendanceRepository struct {
+	BaseRepository[models.EventAttendance]
+}
+
+func NewEventAttendanceRepository() *EventAttendanceRepository {
+	return &EventAttendanceRepository{
+		BaseRepository: NewBaseRepository(database.DB, &models.EventAttendance{}),
+	}
+}
+
+// CheckIn отмечает посещение. Возвращает false, если участник уже отмечен на этом событии или повторении
+func (r *EventAttendanceRepository) CheckIn(attendance *models.EventAttendance) (bool, error) {
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(attendance)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetByEvent получает отметки о посещении события или конкретного повторения
+func (r *EventAttendanceRepository) GetByEvent(eventId int64, occurrenceId *int64) ([]models.EventAttendance, error) {
+	var attendances []models.EventAttendance
+
+	query := database.DB.Preload("Member").Where("event_id = ?", eventId)
+	if occurrenceId != nil {
+		query = query.Where("occurrence_id = ?", *occurrenceId)
+	} else {
+		query = query.Where("occurrence_id IS NULL")
+	}
+
+	err := query.Order("checked_in_at ASC").Find(&attendances).Error
+	return attendances, err
+}
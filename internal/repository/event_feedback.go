@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"ithozyeva/database"
+	"ithozyeva/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EventFeedbackRepository struct {
+	BaseRepository[models.EventFeedback]
+}
+
+func NewEventFeedbackRepository() *EventFeedbackRepository {
+	return &EventFeedbackRepository{
+		BaseRepository: NewBaseRepository(database.DB, &models.EventFeedback{}),
+	}
+}
+
+// CreateRequest создает запись о запросе отзыва. Возвращает false, если участника уже спрашивали
+func (r *EventFeedbackRepository) CreateRequest(feedback *models.EventFeedback) (bool, error) {
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(feedback)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// UpdateAnswer сохраняет оценку или комментарий участника
+func (r *EventFeedbackRepository) UpdateAnswer(id int64, values map[string]interface{}) error {
+	return database.DB.Model(&models.EventFeedback{}).Where("id = ?", id).Updates(values).Error
+}
+
+// GetReport собирает сводку по отзывам о событии (по всем повторениям)
+func (r *EventFeedbackRepository) GetReport(eventId int64) (*models.EventFeedbackReport, error) {
+	report := &models.EventFeedbackReport{
+		EventId:      eventId,
+		Distribution: map[int]int64{1: 0, 2: 0, 3: 0, 4: 0, 5: 0},
+		Comments:     []models.EventFeedback{},
+	}
+
+	query := database.DB.Model(&models.EventFeedback{}).Where("event_id = ?", eventId)
+
+	if err := query.Session(&gorm.Session{}).Count(&report.Requested).Error; err != nil {
+		return nil, err
+	}
+
+	var distribution []struct {
+		Rating int
+		Count  int64
+	}
+	if err := query.Session(&gorm.Session{}).
+		Select("rating, COUNT(*) AS count").
+		Where("rating IS NOT NULL").
+		Group("rating").
+		Scan(&distribution).Error; err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, row := range distribution {
+		report.Distribution[row.Rating] = row.Count
+		report.Responded += row.Count
+		total += int64(row.Rating) * row.Count
+	}
+	if report.Responded > 0 {
+		average := float64(total) / float64(report.Responded)
+		report.AverageRating = &average
+	}
+
+	if err := database.DB.
+		Preload("Member").
+		Where("event_id = ? AND comment IS NOT NULL AND comment != ''", eventId).
+		Order("updated_at DESC").
+		Find(&report.Comments).Error; err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
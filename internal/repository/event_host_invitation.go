@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"ithozyeva/database"
+	"ithozyeva/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EventHostInvitationRepository struct {
+	BaseRepository[models.EventHostInvitation]
+}
+
+func NewEventHostInvitationRepository() *EventHostInvitationRepository {
+	return &EventHostInvitationRepository{
+		BaseRepository: NewBaseRepository(database.DB, &models.EventHostInvitation{}),
+	}
+}
+
+// Invite создает приглашение или повторно отправляет отклоненное
+func (r *EventHostInvitationRepository) Invite(invitation *models.EventHostInvitation) (*models.EventHostInvitation, error) {
+	invitation.Status = models.EventHostInvitationPending
+	invitation.CreatedAt = time.Now()
+	invitation.RespondedAt = nil
+
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "event_id"}, {Name: "member_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"invited_by", "status", "created_at", "responded_at"}),
+	}).Create(invitation).Error; err != nil {
+		return nil, err
+	}
+
+	return r.GetByEventAndMember(invitation.EventId, invitation.MemberId)
+}
+
+// GetById получает приглашение с событием, приглашенным и пригласившим
+func (r *EventHostInvitationRepository) GetById(id int64) (*models.EventHostInvitation, error) {
+	var invitation models.EventHostInvitation
+	if err := preloadInvitation(database.DB).First(&invitation, id).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *EventHostInvitationRepository) GetByEventAndMember(eventId int64, memberId int64) (*models.EventHostInvitation, error) {
+	var invitation models.EventHostInvitation
+	if err := preloadInvitation(database.DB).
+		Where("event_id = ? AND member_id = ?", eventId, memberId).
+		First(&invitation).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// GetPendingByMember получает приглашения, на которые участник еще не ответил
+func (r *EventHostInvitationRepository) GetPendingByMember(memberId int64) ([]models.EventHostInvitation, error) {
+	var invitations []models.EventHostInvitation
+	err := preloadInvitation(database.DB).
+		Where("member_id = ? AND status = ?", memberId, models.EventHostInvitationPending).
+		Order("created_at DESC").
+		Find(&invitations).Error
+	return invitations, err
+}
+
+// Respond сохраняет ответ на приглашение и при согласии добавляет участника в ведущие события
+func (r *EventHostInvitationRepository) Respond(invitation *models.EventHostInvitation, status models.EventHostInvitationStatus) error {
+	now := time.Now()
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.EventHostInvitation{}).
+			Where("id = ? AND status = ?", invitation.Id, models.EventHostInvitationPending).
+			Updates(map[string]interface{}{"status": status, "responded_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		invitation.Status = status
+		invitation.RespondedAt = &now
+
+		if status != models.EventHostInvitationAccepted {
+			return nil
+		}
+		return tx.Exec(
+			"INSERT INTO event_hosts (event_id, member_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+			invitation.EventId, invitation.MemberId,
+		).Error
+	})
+}
+
+func preloadInvitation(query *gorm.DB) *gorm.DB {
+	return query.
+		Preload("Event").
+		Preload("Member").
+		Preload("InvitedBy")
+}
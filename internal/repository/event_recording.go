@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"errors"
+	"ithozyeva/database"
+	"ithozyeva/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type EventRecordingRepository struct {
+	BaseRepository[models.EventRecording]
+}
+
+func NewEventRecordingRepository() *EventRecordingRepository {
+	return &EventRecordingRepository{
+		BaseRepository: NewBaseRepository(database.DB, &models.EventRecording{}),
+	}
+}
+
+// preloadRecording подгружает событие с ведущими и тегами и главы по порядку
+func preloadRecording(query *gorm.DB) *gorm.DB {
+	return query.
+		Preload("Event").
+		Preload("Event.Hosts").
+		Preload("Event.EventTags").
+		Preload("Chapters", func(db *gorm.DB) *gorm.DB {
+			return db.Order("start_seconds ASC")
+		})
+}
+
+// SearchRecordings ищет записи по тегам события, ведущему, дате и названию с учетом уровней доступа
+func (r *EventRecordingRepository) SearchRecordings(limit *int, offset *int, filter *models.RecordingFilter) ([]models.EventRecording, int64, error) {
+	var recordings []models.EventRecording
+	var count int64
+
+	query := database.DB.Model(&models.EventRecording{})
+	if len(filter.Access) > 0 {
+		query = query.Where("access IN ?", filter.Access)
+	}
+	if len(filter.TagIds) > 0 {
+		query = query.Where("event_id IN (SELECT event_id FROM event_event_tags WHERE event_tag_id IN ?)", filter.TagIds)
+	}
+	if filter.HostId != nil {
+		query = query.Where("event_id IN (SELECT event_id FROM event_hosts WHERE member_id = ?)", *filter.HostId)
+	}
+	if filter.DateFrom != nil {
+		query = query.Where("recorded_at >= ?", *filter.DateFrom)
+	}
+	if filter.DateTo != nil {
+		query = query.Where("recorded_at < ?", *filter.DateTo)
+	}
+	if filter.Query != "" {
+		query = query.Where("title ILIKE ?", "%"+filter.Query+"%")
+	}
+
+	if err := query.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = preloadRecording(query).Order("recorded_at DESC")
+	if limit != nil {
+		query = query.Limit(*limit)
+	}
+	if offset != nil {
+		query = query.Offset(*offset)
+	}
+
+	if err := query.Find(&recordings).Error; err != nil {
+		return nil, 0, err
+	}
+	return recordings, count, nil
+}
+
+// GetById получает запись с событием и главами
+func (r *EventRecordingRepository) GetById(id int64) (*models.EventRecording, error) {
+	var recording models.EventRecording
+	if err := preloadRecording(database.DB).First(&recording, id).Error; err != nil {
+		return nil, err
+	}
+	return &recording, nil
+}
+
+// Create создает запись вместе с главами
+func (r *EventRecordingRepository) Create(entity *models.EventRecording) (*models.EventRecording, error) {
+	if err := database.DB.Omit("Event").Create(entity).Error; err != nil {
+		return nil, err
+	}
+	return r.GetById(entity.Id)
+}
+
+// Update сохраняет запись и полностью заменяет ее главы. Файл в S3 меняется отдельно через SetFile
+func (r *EventRecordingRepository) Update(entity *models.EventRecording) (*models.EventRecording, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.EventRecording{}).
+			Where("id = ?", entity.Id).
+			Updates(map[string]interface{}{
+				"event_id":    entity.EventId,
+				"title":       entity.Title,
+				"description": entity.Description,
+				"video_link":  entity.VideoLink,
+				"duration":    entity.Duration,
+				"access":      entity.Access,
+				"recorded_at": entity.RecordedAt,
+			}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("recording_id = ?", entity.Id).Delete(&models.RecordingChapter{}).Error; err != nil {
+			return err
+		}
+		for i := range entity.Chapters {
+			entity.Chapters[i].Id = 0
+			entity.Chapters[i].RecordingId = entity.Id
+		}
+		if len(entity.Chapters) > 0 {
+			return tx.Create(&entity.Chapters).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(entity.Id)
+}
+
+// SetFile сохраняет или очищает ключ загруженного в S3 файла
+func (r *EventRecordingRepository) SetFile(id int64, filePath, fileName, contentType *string) error {
+	return database.DB.Model(&models.EventRecording{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"file_path":    filePath,
+			"file_name":    fileName,
+			"content_type": contentType,
+		}).Error
+}
+
+// SyncEventVideoLink создает запись для участников платформы по видео события или обновляет ссылку у существующей.
+// Открыть запись всем администратор может отдельно в каталоге
+func (r *EventRecordingRepository) SyncEventVideoLink(event *models.Event) error {
+	if event.VideoLink == "" {
+		return nil
+	}
+
+	var recording models.EventRecording
+	err := database.DB.Where("event_id = ?", event.Id).Order("id ASC").First(&recording).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return database.DB.Omit("Event").Create(&models.EventRecording{
+			EventId:    event.Id,
+			Title:      event.Title,
+			VideoLink:  event.VideoLink,
+			Access:     models.RecordingAccessMembers,
+			RecordedAt: event.Date,
+		}).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	return database.DB.Model(&models.EventRecording{}).
+		Where("id = ?", recording.Id).
+		Update("video_link", event.VideoLink).Error
+}
+
+// GetPublicEventIds возвращает события из списка, у которых есть публичная запись
+func (r *EventRecordingRepository) GetPublicEventIds(eventIds []int64) (map[int64]bool, error) {
+	result := make(map[int64]bool)
+	if len(eventIds) == 0 {
+		return result, nil
+	}
+
+	var ids []int64
+	err := database.DB.Model(&models.EventRecording{}).
+		Where("event_id IN ? AND access = ?", eventIds, models.RecordingAccessPublic).
+		Distinct().
+		Pluck("event_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		result[id] = true
+	}
+	return result, nil
+}
@@ -0,0 +1,312 @@
+package repository
+
+import (
+	"fmt"
+	"ithozyeva/database"
+	"ithozyeva/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type EventSeriesRepository struct {
+	BaseRepository[models.EventSeries]
+}
+
+func NewEventSeriesRepository() *EventSeriesRepository {
+	return &EventSeriesRepository{
+		BaseRepository: NewBaseRepository(database.DB, &models.EventSeries{}),
+	}
+}
+
+// preloadSeriesEvents подгружает части серии по порядку. Для публичных страниц черновики не показываются
+func preloadSeriesEvents(query *gorm.DB, publishedOnly bool) *gorm.DB {
+	query = query.
+		Preload("Events", func(db *gorm.DB) *gorm.DB {
+			if publishedOnly {
+				db = db.Where("status = ?", models.EventStatusPublished)
+			}
+			return db.Order("series_position ASC, date ASC")
+		}).
+		Preload("Events.Hosts").
+		Preload("Events.EventTags")
+	return preloadExceptionsAt(query, "Events.Exceptions")
+}
+
+// Search ищет серии со всеми частями, включая черновики
+func (r *EventSeriesRepository) Search(limit *int, offset *int, filter *SearchFilter, order *Order) ([]models.EventSeries, int64, error) {
+	return r.search(limit, offset, filter, order, false)
+}
+
+// SearchPublished ищет серии, у которых есть опубликованные части
+func (r *EventSeriesRepository) SearchPublished(limit *int, offset *int) ([]models.EventSeries, int64, error) {
+	filter := SearchFilter{
+		"id IN (SELECT series_id FROM events WHERE series_id IS NOT NULL AND status = ?)": models.EventStatusPublished,
+	}
+	return r.search(limit, offset, &filter, nil, true)
+}
+
+func (r *EventSeriesRepository) search(limit *int, offset *int, filter *SearchFilter, order *Order, publishedOnly bool) ([]models.EventSeries, int64, error) {
+	var series []models.EventSeries
+	var count int64
+
+	query := database.DB.Model(&models.EventSeries{})
+	if filter != nil {
+		for key, value := range *filter {
+			query = query.Where(key, value)
+		}
+	}
+
+	if err := query.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = preloadSeriesEvents(query, publishedOnly)
+	if order != nil {
+		query = query.Order(fmt.Sprintf("\"%s\" %s", order.ColumnBy, order.Order))
+	} else {
+		query = query.Order("created_at DESC")
+	}
+	if limit != nil {
+		query = query.Limit(*limit)
+	}
+	if offset != nil {
+		query = query.Offset(*offset)
+	}
+
+	if err := query.Find(&series).Error; err != nil {
+		return nil, 0, err
+	}
+
+	for i := range series {
+		series[i].PartsCount = len(series[i].Events)
+	}
+	return series, count, nil
+}
+
+// GetById получает серию со всеми частями, включая черновики, и участниками
+func (r *EventSeriesRepository) GetById(id int64) (*models.EventSeries, error) {
+	return r.get(id, false)
+}
+
+// GetPublishedById получает серию с опубликованными частями
+func (r *EventSeriesRepository) GetPublishedById(id int64) (*models.EventSeries, error) {
+	return r.get(id, true)
+}
+
+func (r *EventSeriesRepository) get(id int64, publishedOnly bool) (*models.EventSeries, error) {
+	var series models.EventSeries
+	if err := preloadSeriesEvents(database.DB, publishedOnly).Preload("Members").First(&series, id).Error; err != nil {
+		return nil, err
+	}
+	series.PartsCount = len(series.Events)
+	return &series, nil
+}
+
+// Create создает серию и привязывает к ней части в порядке EventIds
+func (r *EventSeriesRepository) Create(entity *models.EventSeries) (*models.EventSeries, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Events", "Members").Create(entity).Error; err != nil {
+			return err
+		}
+		return setSeriesEvents(tx, entity.Id, entity.EventIds)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(entity.Id)
+}
+
+// Update сохраняет серию и порядок частей. Части, которых нет в EventIds, отвязываются от серии
+func (r *EventSeriesRepository) Update(entity *models.EventSeries) (*models.EventSeries, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.EventSeries{}).
+			Where("id = ?", entity.Id).
+			Updates(map[string]interface{}{
+				"title":       entity.Title,
+				"description": entity.Description,
+				"kind":        entity.Kind,
+			}).Error; err != nil {
+			return err
+		}
+		return setSeriesEvents(tx, entity.Id, entity.EventIds)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(entity.Id)
+}
+
+func setSeriesEvents(tx *gorm.DB, seriesId int64, eventIds []int64) error {
+	detach := tx.Model(&models.Event{}).Where("series_id = ?", seriesId)
+	if len(eventIds) > 0 {
+		detach = detach.Where("id NOT IN ?", eventIds)
+	}
+	if err := detach.Updates(map[string]interface{}{"series_id": nil, "series_position": 0}).Error; err != nil {
+		return err
+	}
+
+	for i, eventId := range eventIds {
+		if err := tx.Model(&models.Event{}).
+			Where("id = ?", eventId).
+			Updates(map[string]interface{}{"series_id": seriesId, "series_position": i + 1}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SeriesPart часть серии, на которую записывается или от которой отписывается участник.
+// Для повторяющейся части OccurrenceDate — исходная дата ближайшего повторения
+type SeriesPart struct {
+	EventId        int64
+	OccurrenceDate *time.Time
+}
+
+// SeriesPartAttendance итог записи или отписки участника по части серии. OccurrenceId заполнен
+// для повторяющихся частей, Status — только при записи, Promoted — только при отписке
+type SeriesPartAttendance struct {
+	EventId      int64
+	OccurrenceId *int64
+	MemberId     int64
+	Status       models.EventAttendanceStatus
+	Promoted     []models.Member
+}
+
+// seriesPartScope возвращает, куда записывать участника части, создавая запись о повторении при необходимости
+func seriesPartScope(tx *gorm.DB, part SeriesPart) (attendanceScope, error) {
+	scope := attendanceScope{eventId: part.EventId}
+	if part.OccurrenceDate == nil {
+		return scope, nil
+	}
+
+	occurrence, err := getOrCreateOccurrence(tx, part.EventId, *part.OccurrenceDate)
+	if err != nil {
+		return scope, err
+	}
+	scope.occurrenceId = &occurrence.Id
+	return scope, nil
+}
+
+// enrollSeriesMembers записывает всех участников серии, к которой относится часть, внутри транзакции tx
+func enrollSeriesMembers(tx *gorm.DB, part SeriesPart) ([]SeriesPartAttendance, error) {
+	var memberIds []int64
+	if err := tx.Raw(
+		"SELECT member_id FROM event_series_members WHERE series_id = (SELECT series_id FROM events WHERE id = ?) ORDER BY member_id",
+		part.EventId,
+	).Scan(&memberIds).Error; err != nil {
+		return nil, err
+	}
+	if len(memberIds) == 0 {
+		return nil, nil
+	}
+
+	scope, err := seriesPartScope(tx, part)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]SeriesPartAttendance, 0, len(memberIds))
+	for _, memberId := range memberIds {
+		status, err := addAttendee(tx, scope, memberId)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, SeriesPartAttendance{
+			EventId:      part.EventId,
+			OccurrenceId: scope.occurrenceId,
+			MemberId:     memberId,
+			Status:       status,
+		})
+	}
+	return results, nil
+}
+
+// AddMember записывает участника на серию и на ее части в одной транзакции.
+// Результаты возвращаются в порядке parts
+func (r *EventSeriesRepository) AddMember(seriesId int64, memberId int64, parts []SeriesPart) ([]SeriesPartAttendance, error) {
+	results := make([]SeriesPartAttendance, 0, len(parts))
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(
+			"INSERT INTO event_series_members (series_id, member_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+			seriesId, memberId,
+		).Error; err != nil {
+			return err
+		}
+
+		for _, part := range parts {
+			scope, err := seriesPartScope(tx, part)
+			if err != nil {
+				return err
+			}
+			status, err := addAttendee(tx, scope, memberId)
+			if err != nil {
+				return err
+			}
+			results = append(results, SeriesPartAttendance{
+				EventId:      part.EventId,
+				OccurrenceId: scope.occurrenceId,
+				MemberId:     memberId,
+				Status:       status,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// RemoveMember отписывает участника от серии и от ее частей в одной транзакции
+// и переводит на освободившиеся места участников из листов ожидания
+func (r *EventSeriesRepository) RemoveMember(seriesId int64, memberId int64, parts []SeriesPart) ([]SeriesPartAttendance, error) {
+	results := make([]SeriesPartAttendance, 0, len(parts))
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(
+			"DELETE FROM event_series_members WHERE series_id = ? AND member_id = ?",
+			seriesId, memberId,
+		).Error; err != nil {
+			return err
+		}
+
+		for _, part := range parts {
+			scope, err := seriesPartScope(tx, part)
+			if err != nil {
+				return err
+			}
+			if err := removeAttendee(tx, scope, memberId); err != nil {
+				return err
+			}
+			promoted, err := promoteAttendees(tx, scope)
+			if err != nil {
+				return err
+			}
+			results = append(results, SeriesPartAttendance{
+				EventId:      part.EventId,
+				OccurrenceId: scope.occurrenceId,
+				MemberId:     memberId,
+				Promoted:     promoted,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// EnrollMembers записывает всех участников серии на ее новую часть в одной транзакции
+func (r *EventSeriesRepository) EnrollMembers(part SeriesPart) ([]SeriesPartAttendance, error) {
+	var results []SeriesPartAttendance
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		results, err = enrollSeriesMembers(tx, part)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -5,6 +5,9 @@ import (
 	"ithozyeva/database"
 	"ithozyeva/internal/models"
 	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type EventRepository struct {
@@ -25,7 +28,7 @@ func (e *EventRepository) Search(limit *int, offset *int, filter *SearchFilter,
 		return nil, 0, err
 	}
 
-	query := database.DB.Model(&models.Event{}).Preload("Hosts").Preload("Members").Preload("EventTags")
+	query := preloadSeries(preloadExceptions(preloadWaitlist(database.DB.Model(&models.Event{}).Preload("Hosts").Preload("Members").Preload("EventTags"))))
 
 	if filter != nil {
 		for key, value := range *filter {
@@ -64,7 +67,7 @@ func (r *EventRepository) Update(entity *models.Event) (*models.Event, error) {
 		entity.LastRepeatingAlertSentAt = nil
 	}
 
-	err = database.DB.Model(&entity).Save(entity).Error
+	err = database.DB.Model(&entity).Omit("Waitlist", "Exceptions", "Series", "FeedbackRequestedAt").Save(entity).Error
 
 	if err != nil {
 		return nil, err
@@ -86,49 +89,501 @@ func (r *EventRepository) Update(entity *models.Event) (*models.Event, error) {
 // GetById получает отзыв по ID с информацией о услуге
 func (r *EventRepository) GetById(id int64) (*models.Event, error) {
 	var event models.Event
-	if err := database.DB.Preload("Hosts").Preload("Members").Preload("EventTags").First(&event, id).Error; err != nil {
+	if err := preloadSeries(preloadExceptions(preloadWaitlist(database.DB.Preload("Hosts").Preload("Members").Preload("EventTags")))).First(&event, id).Error; err != nil {
 		return nil, err
 	}
 	return &event, nil
 }
 
-func (r *EventRepository) AddMember(eventId int, memberId int) (*models.Event, error) {
-	entity, err := r.GetById(int64(eventId))
+// preloadWaitlist подгружает лист ожидания события (без учета повторений) в порядке очереди
+func preloadWaitlist(query *gorm.DB) *gorm.DB {
+	return query.
+		Preload("Waitlist", func(db *gorm.DB) *gorm.DB {
+			return db.Where("occurrence_id IS NULL").Order("created_at ASC, id ASC")
+		}).
+		Preload("Waitlist.Member")
+}
+
+// preloadExceptions подгружает измененные повторения события: отмененные, перенесенные и с другими ведущими
+func preloadExceptions(query *gorm.DB) *gorm.DB {
+	return preloadExceptionsAt(query, "Exceptions")
+}
+
+// preloadExceptionsAt подгружает измененные повторения по пути связи, например "Events.Exceptions"
+func preloadExceptionsAt(query *gorm.DB, path string) *gorm.DB {
+	return query.
+		Preload(path, func(db *gorm.DB) *gorm.DB {
+			return db.
+				Where("cancelled OR override_date IS NOT NULL OR EXISTS (SELECT 1 FROM event_occurrence_hosts h WHERE h.occurrence_id = event_occurrences.id)").
+				Order("occurrence_date ASC")
+		}).
+		Preload(path + ".Hosts")
+}
+
+// preloadSeries подгружает серию события с числом опубликованных частей
+func preloadSeries(query *gorm.DB) *gorm.DB {
+	return query.Preload("Series", func(db *gorm.DB) *gorm.DB {
+		return db.Select("event_series.*, (SELECT COUNT(*) FROM events e WHERE e.series_id = event_series.id AND e.status = ?) AS parts_count", models.EventStatusPublished)
+	})
+}
+
+// attendanceScope определяет, куда записывается участник:
+// на событие целиком или на конкретное повторение повторяющегося события
+type attendanceScope struct {
+	eventId      int64
+	occurrenceId *int64
+}
+
+func (s attendanceScope) members(tx *gorm.DB) *gorm.DB {
+	if s.occurrenceId != nil {
+		return tx.Table("event_occurrence_members").Where("occurrence_id = ?", *s.occurrenceId)
+	}
+	return tx.Table("event_members").Where("event_id = ?", s.eventId)
+}
+
+func (s attendanceScope) waitlist(tx *gorm.DB) *gorm.DB {
+	query := tx.Model(&models.EventWaitlistEntry{}).Where("event_id = ?", s.eventId)
+	if s.occurrenceId != nil {
+		return query.Where("occurrence_id = ?", *s.occurrenceId)
+	}
+	return query.Where("occurrence_id IS NULL")
+}
+
+func (s attendanceScope) insertMember(tx *gorm.DB, memberId int64) error {
+	if s.occurrenceId != nil {
+		return tx.Exec(
+			"INSERT INTO event_occurrence_members (occurrence_id, member_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+			*s.occurrenceId, memberId,
+		).Error
+	}
+	return tx.Exec(
+		"INSERT INTO event_members (event_id, member_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+		s.eventId, memberId,
+	).Error
+}
+
+func (s attendanceScope) deleteMember(tx *gorm.DB, memberId int64) error {
+	if s.occurrenceId != nil {
+		return tx.Exec("DELETE FROM event_occurrence_members WHERE occurrence_id = ? AND member_id = ?", *s.occurrenceId, memberId).Error
+	}
+	return tx.Exec("DELETE FROM event_members WHERE event_id = ? AND member_id = ?", s.eventId, memberId).Error
+}
+
+// addMember записывает участника, а если мест не осталось — ставит в лист ожидания
+func (r *EventRepository) addMember(scope attendanceScope, memberId int64) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		_, err := addAttendee(tx, scope, memberId)
+		return err
+	})
+}
+
+// removeMember убирает участника из списка и из листа ожидания
+func (r *EventRepository) removeMember(scope attendanceScope, memberId int64) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		return removeAttendee(tx, scope, memberId)
+	})
+}
+
+// promoteFromWaitlist переводит участников из листа ожидания на освободившиеся места
+func (r *EventRepository) promoteFromWaitlist(scope attendanceScope) ([]models.Member, error) {
+	var promoted []models.Member
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		promoted, err = promoteAttendees(tx, scope)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	err = database.DB.Model(&entity).Association("Members").Append(&models.Member{Id: int64(memberId)})
+	return promoted, nil
+}
 
-	if err != nil {
+// addAttendee записывает участника внутри транзакции tx и сообщает, досталось ли ему место
+func addAttendee(tx *gorm.DB, scope attendanceScope, memberId int64) (models.EventAttendanceStatus, error) {
+	var event models.Event
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, scope.eventId).Error; err != nil {
+		return "", err
+	}
+
+	var alreadyApplied int64
+	if err := scope.members(tx).Where("member_id = ?", memberId).Count(&alreadyApplied).Error; err != nil {
+		return "", err
+	}
+	if alreadyApplied > 0 {
+		return models.EventAttendanceEnrolled, nil
+	}
+
+	if event.Capacity != nil {
+		var membersCount int64
+		if err := scope.members(tx).Count(&membersCount).Error; err != nil {
+			return "", err
+		}
+
+		if membersCount >= int64(*event.Capacity) {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.EventWaitlistEntry{
+				EventId:      scope.eventId,
+				MemberId:     memberId,
+				OccurrenceId: scope.occurrenceId,
+			}).Error; err != nil {
+				return "", err
+			}
+			return models.EventAttendanceWaitlisted, nil
+		}
+	}
+
+	if err := scope.insertMember(tx, memberId); err != nil {
+		return "", err
+	}
+
+	if err := scope.waitlist(tx).Where("member_id = ?", memberId).Delete(&models.EventWaitlistEntry{}).Error; err != nil {
+		return "", err
+	}
+	return models.EventAttendanceEnrolled, nil
+}
+
+// removeAttendee убирает участника из списка и из листа ожидания внутри транзакции tx
+func removeAttendee(tx *gorm.DB, scope attendanceScope, memberId int64) error {
+	if err := scope.deleteMember(tx, memberId); err != nil {
+		return err
+	}
+
+	return scope.waitlist(tx).Where("member_id = ?", memberId).Delete(&models.EventWaitlistEntry{}).Error
+}
+
+// promoteAttendees переводит участников из листа ожидания на свободные места внутри транзакции tx
+func promoteAttendees(tx *gorm.DB, scope attendanceScope) ([]models.Member, error) {
+	var event models.Event
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, scope.eventId).Error; err != nil {
 		return nil, err
 	}
 
-	entity, err = r.GetById(int64(eventId))
-	if err != nil {
+	var membersCount int64
+	if err := scope.members(tx).Count(&membersCount).Error; err != nil {
+		return nil, err
+	}
+
+	freePlaces, limited := waitlistFreePlaces(event.Capacity, membersCount)
+	if limited && freePlaces == 0 {
+		return nil, nil
+	}
+
+	query := scope.waitlist(tx).Preload("Member").Order("created_at ASC, id ASC")
+	if limited {
+		query = query.Limit(freePlaces)
+	}
+	var entries []models.EventWaitlistEntry
+	if err := query.Find(&entries).Error; err != nil {
 		return nil, err
 	}
 
-	return entity, nil
+	var promoted []models.Member
+	for _, entry := range entries {
+		if err := scope.insertMember(tx, entry.MemberId); err != nil {
+			return nil, err
+		}
+
+		if err := tx.Delete(&models.EventWaitlistEntry{}, entry.Id).Error; err != nil {
+			return nil, err
+		}
+
+		promoted = append(promoted, entry.Member)
+	}
+
+	return promoted, nil
+}
+
+// waitlistFreePlaces считает, сколько участников можно перевести из листа ожидания.
+// limited == false, если мест не ограничено и переводятся все
+func waitlistFreePlaces(capacity *int, membersCount int64) (freePlaces int, limited bool) {
+	if capacity == nil {
+		return 0, false
+	}
+	if free := int64(*capacity) - membersCount; free > 0 {
+		return int(free), true
+	}
+	return 0, true
 }
 
+// AddMember записывает участника на событие, а если мест не осталось — ставит в лист ожидания
+func (r *EventRepository) AddMember(eventId int, memberId int) (*models.Event, error) {
+	if err := r.addMember(attendanceScope{eventId: int64(eventId)}, int64(memberId)); err != nil {
+		return nil, err
+	}
+
+	return r.GetById(int64(eventId))
+}
+
+// RemoveMember убирает участника из события и из листа ожидания
 func (r *EventRepository) RemoveMember(eventId int, memberId int) (*models.Event, error) {
-	entity, err := r.GetById(int64(eventId))
-	if err != nil {
+	if err := r.removeMember(attendanceScope{eventId: int64(eventId)}, int64(memberId)); err != nil {
 		return nil, err
 	}
 
-	err = database.DB.Model(&entity).Association("Members").Delete(&models.Member{Id: int64(memberId)})
+	return r.GetById(int64(eventId))
+}
 
+// PromoteFromWaitlist переводит участников из листа ожидания на освободившиеся места
+// и возвращает тех, кого удалось записать
+func (r *EventRepository) PromoteFromWaitlist(eventId int64) ([]models.Member, error) {
+	return r.promoteFromWaitlist(attendanceScope{eventId: eventId})
+}
+
+// GetOrCreateOccurrence возвращает запись о повторении события, создавая ее при необходимости
+func (r *EventRepository) GetOrCreateOccurrence(eventId int64, occurrenceDate time.Time) (*models.EventOccurrence, error) {
+	occurrence, err := getOrCreateOccurrence(database.DB, eventId, occurrenceDate)
 	if err != nil {
 		return nil, err
 	}
 
-	entity, err = r.GetById(int64(eventId))
+	return r.GetOccurrenceById(occurrence.Id)
+}
+
+func getOrCreateOccurrence(tx *gorm.DB, eventId int64, occurrenceDate time.Time) (*models.EventOccurrence, error) {
+	occurrence := models.EventOccurrence{
+		EventId:        eventId,
+		OccurrenceDate: occurrenceDate.UTC(),
+	}
+
+	if err := tx.
+		Where("event_id = ? AND occurrence_date = ?", eventId, occurrence.OccurrenceDate).
+		FirstOrCreate(&occurrence).Error; err != nil {
+		return nil, err
+	}
+
+	return &occurrence, nil
+}
+
+// GetOccurrenceById получает повторение события с ведущими, участниками и листом ожидания
+func (r *EventRepository) GetOccurrenceById(id int64) (*models.EventOccurrence, error) {
+	var occurrence models.EventOccurrence
+	if err := preloadOccurrence(database.DB).First(&occurrence, id).Error; err != nil {
+		return nil, err
+	}
+	return &occurrence, nil
+}
+
+// GetOccurrencesByDates получает сохраненные повторения события по исходным датам
+func (r *EventRepository) GetOccurrencesByDates(eventId int64, dates []time.Time) ([]models.EventOccurrence, error) {
+	var occurrences []models.EventOccurrence
+	if len(dates) == 0 {
+		return occurrences, nil
+	}
+
+	utcDates := make([]time.Time, 0, len(dates))
+	for _, date := range dates {
+		utcDates = append(utcDates, date.UTC())
+	}
+
+	err := preloadOccurrence(database.DB).
+		Where("event_id = ? AND occurrence_date IN ?", eventId, utcDates).
+		Order("occurrence_date ASC").
+		Find(&occurrences).Error
+	return occurrences, err
+}
+
+func preloadOccurrence(query *gorm.DB) *gorm.DB {
+	return query.
+		Preload("Hosts").
+		Preload("Members").
+		Preload("Waitlist", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC, id ASC")
+		}).
+		Preload("Waitlist.Member")
+}
+
+// SaveOccurrenceOverride сохраняет отмену, перенос и ведущих конкретного повторения
+func (r *EventRepository) SaveOccurrenceOverride(occurrence *models.EventOccurrence) (*models.EventOccurrence, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.EventOccurrence{}).
+			Where("id = ?", occurrence.Id).
+			Updates(map[string]interface{}{
+				"cancelled":     occurrence.Cancelled,
+				"override_date": occurrence.OverrideDate,
+			}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(occurrence).Association("Hosts").Replace(occurrence.Hosts)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return entity, nil
+	return r.GetOccurrenceById(occurrence.Id)
+}
+
+// AddOccurrenceMember записывает участника на конкретное повторение события
+func (r *EventRepository) AddOccurrenceMember(occurrence *models.EventOccurrence, memberId int64) (*models.EventOccurrence, error) {
+	scope := attendanceScope{eventId: occurrence.EventId, occurrenceId: &occurrence.Id}
+	if err := r.addMember(scope, memberId); err != nil {
+		return nil, err
+	}
+
+	return r.GetOccurrenceById(occurrence.Id)
+}
+
+// RemoveOccurrenceMember отписывает участника от конкретного повторения события
+func (r *EventRepository) RemoveOccurrenceMember(occurrence *models.EventOccurrence, memberId int64) (*models.EventOccurrence, error) {
+	scope := attendanceScope{eventId: occurrence.EventId, occurrenceId: &occurrence.Id}
+	if err := r.removeMember(scope, memberId); err != nil {
+		return nil, err
+	}
+
+	return r.GetOccurrenceById(occurrence.Id)
+}
+
+// PromoteOccurrenceWaitlist переводит участников из листа ожидания повторения на освободившиеся места
+func (r *EventRepository) PromoteOccurrenceWaitlist(occurrence *models.EventOccurrence) ([]models.Member, error) {
+	return r.promoteFromWaitlist(attendanceScope{eventId: occurrence.EventId, occurrenceId: &occurrence.Id})
+}
+
+// UpdateOccurrenceAlertSentAt сохраняет время последнего алерта по повторению
+func (r *EventRepository) UpdateOccurrenceAlertSentAt(occurrenceId int64, sentAt time.Time) error {
+	return database.DB.Model(&models.EventOccurrence{}).
+		Where("id = ?", occurrenceId).
+		Update("last_alert_sent_at", sentAt).Error
+}
+
+// GetCalendarEvents получает открытые события для общей ленты календаря, опционально по тегам
+func (r *EventRepository) GetCalendarEvents(tagIds []int64) ([]models.Event, error) {
+	var events []models.Event
+
+	query := preloadSeries(preloadExceptions(database.DB.Preload("Hosts").Preload("EventTags"))).
+		Where("open = ? AND status = ?", true, models.EventStatusPublished)
+	if len(tagIds) > 0 {
+		query = query.Where("id IN (SELECT event_id FROM event_event_tags WHERE event_tag_id IN ?)", tagIds)
+	}
+
+	err := query.Order("date ASC").Find(&events).Error
+	return events, err
+}
+
+// GetMemberCalendarEvents получает события, на которые участник записан целиком или которые он ведет
+func (r *EventRepository) GetMemberCalendarEvents(memberId int64) ([]models.Event, error) {
+	var events []models.Event
+	err := preloadSeries(preloadExceptions(database.DB.Preload("Hosts").Preload("EventTags"))).
+		Where("id IN (SELECT event_id FROM event_members WHERE member_id = ?) OR id IN (SELECT event_id FROM event_hosts WHERE member_id = ?)", memberId, memberId).
+		Order("date ASC").
+		Find(&events).Error
+	return events, err
+}
+
+// GetMemberCalendarOccurrences получает отдельные повторения, на которые участник записан или которые он ведет
+func (r *EventRepository) GetMemberCalendarOccurrences(memberId int64) ([]models.EventOccurrence, error) {
+	var occurrences []models.EventOccurrence
+	err := database.DB.
+		Preload("Hosts").
+		Preload("Event").
+		Preload("Event.Hosts").
+		Where("id IN (SELECT occurrence_id FROM event_occurrence_members WHERE member_id = ?) OR id IN (SELECT occurrence_id FROM event_occurrence_hosts WHERE member_id = ?)", memberId, memberId).
+		Order("occurrence_date ASC").
+		Find(&occurrences).Error
+	return occurrences, err
+}
+
+// GetFeedbackDueEvents получает разовые события с датой в интервале [from, to],
+// по которым еще не рассылался запрос отзыва
+func (r *EventRepository) GetFeedbackDueEvents(from, to time.Time) ([]models.Event, error) {
+	var events []models.Event
+	err := database.DB.
+		Preload("Members").
+		Where("date >= ? AND date <= ? AND feedback_requested_at IS NULL", from, to).
+		Where("(rrule IS NULL OR rrule = '') AND NOT (is_repeating AND repeat_period IS NOT NULL)").
+		Find(&events).Error
+	return events, err
+}
+
+// GetFeedbackDueOccurrences получает прошедшие в интервале [from, to] повторения,
+// по которым еще не рассылался запрос отзыва
+func (r *EventRepository) GetFeedbackDueOccurrences(from, to time.Time) ([]models.EventOccurrence, error) {
+	var occurrences []models.EventOccurrence
+	err := database.DB.
+		Preload("Members").
+		Preload("Event").
+		Where("NOT cancelled AND feedback_requested_at IS NULL").
+		Where("COALESCE(override_date, occurrence_date) >= ? AND COALESCE(override_date, occurrence_date) <= ?", from, to).
+		Find(&occurrences).Error
+	return occurrences, err
+}
+
+// MarkEventFeedbackRequested отмечает, что запрос отзыва по событию разослан
+func (r *EventRepository) MarkEventFeedbackRequested(eventId int64, requestedAt time.Time) error {
+	return database.DB.Model(&models.Event{}).
+		Where("id = ?", eventId).
+		Update("feedback_requested_at", requestedAt).Error
+}
+
+// MarkOccurrenceFeedbackRequested отмечает, что запрос отзыва по повторению разослан
+func (r *EventRepository) MarkOccurrenceFeedbackRequested(occurrenceId int64, requestedAt time.Time) error {
+	return database.DB.Model(&models.EventOccurrence{}).
+		Where("id = ?", occurrenceId).
+		Update("feedback_requested_at", requestedAt).Error
+}
+
+// GetMemberCheckInEvents получает разовые офлайн и гибридные события в интервале [from, to], на которые записан участник
+func (r *EventRepository) GetMemberCheckInEvents(memberId int64, from, to time.Time) ([]models.Event, error) {
+	var events []models.Event
+	err := database.DB.
+		Where("id IN (SELECT event_id FROM event_members WHERE member_id = ?)", memberId).
+		Where("place_type IN ?", []models.PlaceType{models.EventOffline, models.EventHybrid}).
+		Where("date >= ? AND date <= ?", from, to).
+		Where("(rrule IS NULL OR rrule = '') AND NOT (is_repeating AND repeat_period IS NOT NULL)").
+		Order("date ASC").
+		Find(&events).Error
+	return events, err
+}
+
+// GetMemberCheckInOccurrences получает повторения офлайн и гибридных событий в интервале [from, to], на которые записан участник
+func (r *EventRepository) GetMemberCheckInOccurrences(memberId int64, from, to time.Time) ([]models.EventOccurrence, error) {
+	var occurrences []models.EventOccurrence
+	err := database.DB.
+		Preload("Event").
+		Where("id IN (SELECT occurrence_id FROM event_occurrence_members WHERE member_id = ?)", memberId).
+		Where("event_id IN (SELECT id FROM events WHERE place_type IN ?)", []models.PlaceType{models.EventOffline, models.EventHybrid}).
+		Where("NOT cancelled").
+		Where("COALESCE(override_date, occurrence_date) >= ? AND COALESCE(override_date, occurrence_date) <= ?", from, to).
+		Order("COALESCE(override_date, occurrence_date) ASC").
+		Find(&occurrences).Error
+	return occurrences, err
 }
 
+// GetHostedEvents получает события, которые ведет участник, включая черновики
+func (r *EventRepository) GetHostedEvents(memberId int64) ([]models.Event, error) {
+	var events []models.Event
+	err := preloadExceptions(preloadWaitlist(database.DB.Preload("Hosts").Preload("Members").Preload("EventTags"))).
+		Where("id IN (SELECT event_id FROM event_hosts WHERE member_id = ?)", memberId).
+		Order("date DESC").
+		Find(&events).Error
+	return events, err
+}
+
+// RemoveHost убирает участника из ведущих события
+func (r *EventRepository) RemoveHost(eventId int64, memberId int64) error {
+	return database.DB.Exec("DELETE FROM event_hosts WHERE event_id = ? AND member_id = ?", eventId, memberId).Error
+}
+
+// Publish публикует черновик события и открывает запись на него. Если событие — часть серии,
+// в той же транзакции на seriesPart записываются участники серии
+func (r *EventRepository) Publish(eventId int64, seriesPart *SeriesPart) ([]SeriesPartAttendance, error) {
+	var results []SeriesPartAttendance
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Event{}).
+			Where("id = ?", eventId).
+			Updates(map[string]interface{}{"status": models.EventStatusPublished, "open": true}).Error; err != nil {
+			return err
+		}
+		if seriesPart == nil {
+			return nil
+		}
+
+		var err error
+		results, err = enrollSeriesMembers(tx, *seriesPart)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,31 @@
+package repository
+
+import "testing"
+
+func TestWaitlistFreePlaces(t *testing.T) {
+	capacity := func(n int) *int { return &n }
+
+	tests := []struct {
+		name         string
+		capacity     *int
+		membersCount int64
+		wantPlaces   int
+		wantLimited  bool
+	}{
+		{name: "без ограничения мест переводятся все", membersCount: 100, wantLimited: false},
+		{name: "мест нет", capacity: capacity(5), membersCount: 5, wantPlaces: 0, wantLimited: true},
+		{name: "вместимость уменьшили ниже числа участников", capacity: capacity(3), membersCount: 5, wantPlaces: 0, wantLimited: true},
+		{name: "одно место", capacity: capacity(5), membersCount: 4, wantPlaces: 1, wantLimited: true},
+		{name: "два места", capacity: capacity(6), membersCount: 4, wantPlaces: 2, wantLimited: true},
+		{name: "пустое событие", capacity: capacity(10), membersCount: 0, wantPlaces: 10, wantLimited: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			places, limited := waitlistFreePlaces(tt.capacity, tt.membersCount)
+			if places != tt.wantPlaces || limited != tt.wantLimited {
+				t.Errorf("waitlistFreePlaces() = (%d, %t), want (%d, %t)", places, limited, tt.wantPlaces, tt.wantLimited)
+			}
+		})
+	}
+}
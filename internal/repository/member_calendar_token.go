@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"ithozyeva/database"
+	"ithozyeva/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MemberCalendarTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewMemberCalendarTokenRepository() *MemberCalendarTokenRepository {
+	return &MemberCalendarTokenRepository{db: database.DB}
+}
+
+// GetByMemberId получает токен календаря участника
+func (r *MemberCalendarTokenRepository) GetByMemberId(memberId int64) (*models.MemberCalendarToken, error) {
+	var token models.MemberCalendarToken
+	if err := r.db.Where("member_id = ?", memberId).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// GetByToken получает токен календаря по его значению
+func (r *MemberCalendarTokenRepository) GetByToken(value string) (*models.MemberCalendarToken, error) {
+	var token models.MemberCalendarToken
+	if err := r.db.Where("token = ?", value).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Upsert сохраняет токен участника, заменяя предыдущий
+func (r *MemberCalendarTokenRepository) Upsert(token *models.MemberCalendarToken) (*models.MemberCalendarToken, error) {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "member_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"token", "created_at"}),
+	}).Create(token).Error
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
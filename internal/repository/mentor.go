@@ -5,10 +5,14 @@ import (
 	"ithozyeva/database"
 	"ithozyeva/internal/models"
 	"reflect"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// ErrServiceHasBookings услугу нельзя удалить, пока на нее есть предстоящие записи
+var ErrServiceHasBookings = errors.New("на услугу есть предстоящие записи, ее нельзя удалить")
+
 // MentorRepository интерфейс для репозитория менторов
 type MentorRepositoryInterface interface {
 	BaseRepository[models.MentorDbShortModel]
@@ -102,14 +106,20 @@ func (r *MentorRepository) AddReviewToService(review *models.ReviewOnService) (*
 
 // CreateWithRelations создает нового ментора со всеми связанными сущностями
 func (r *MentorRepository) CreateWithRelations(mentor *models.MentorDbModel) (*models.MentorDbModel, error) {
-	// Начинаем транзакцию
-	tx := database.DB.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
+	var result *models.MentorDbModel
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = r.createWithRelations(tx, mentor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
 
+// createWithRelations создает ментора со связанными сущностями внутри транзакции tx
+func (r *MentorRepository) createWithRelations(tx *gorm.DB, mentor *models.MentorDbModel) (*models.MentorDbModel, error) {
 	// Создаем базовую модель ментора
 	mentorDb := &models.MentorDbShortModel{
 		MemberId:   mentor.MemberId,
@@ -119,19 +129,14 @@ func (r *MentorRepository) CreateWithRelations(mentor *models.MentorDbModel) (*m
 	}
 
 	if err := tx.Create(&mentorDb).Error; err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 
-	// Важно: выполняем явный commit и начинаем новую транзакцию
-	// чтобы гарантировать, что ID ментора сохранен в базе
-	if err := tx.Commit().Error; err != nil {
-		return nil, err
+	// Проверяем, что ID ментора не равен 0
+	if mentorDb.Id == 0 {
+		return nil, errors.New("ID ментора не может быть 0")
 	}
 
-	// Начинаем новую транзакцию для связанных сущностей
-	tx = database.DB.Begin()
-
 	// Создаем полную модель для возврата
 	result := &models.MentorDbModel{
 		Id:         mentorDb.Id,
@@ -145,26 +150,17 @@ func (r *MentorRepository) CreateWithRelations(mentor *models.MentorDbModel) (*m
 	var err error
 	result.ProfTags, err = r.handleProfTags(tx, mentorDb.Id, mentor.ProfTags)
 	if err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 
-	// Проверяем, что ID ментора не равен 0
-	if mentorDb.Id == 0 {
-		tx.Rollback()
-		return nil, errors.New("ID ментора не может быть 0")
-	}
-
 	contactsInterface, err := r.handleRelatedEntities(tx, mentorDb.Id, mentor.Contacts, "contacts", "ownerId")
 	if err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 	result.Contacts = contactsInterface.([]models.Contact)
 
 	servicesInterface, err := r.handleRelatedEntities(tx, mentorDb.Id, mentor.Services, "services", "ownerId")
 	if err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 	result.Services = servicesInterface.([]models.Service)
@@ -172,29 +168,29 @@ func (r *MentorRepository) CreateWithRelations(mentor *models.MentorDbModel) (*m
 	// Загружаем информацию о пользователе
 	var member models.Member
 	if err := tx.First(&member, mentorDb.MemberId).Error; err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 	result.Member = member
 
-	// Фиксируем транзакцию
-	if err := tx.Commit().Error; err != nil {
-		return nil, err
-	}
-
 	return result, nil
 }
 
 // UpdateWithRelations обновляет ментора со всеми связанными сущностями
 func (r *MentorRepository) UpdateWithRelations(mentor *models.MentorDbModel) (*models.MentorDbModel, error) {
-	// Начинаем транзакцию
-	tx := database.DB.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
+	var result *models.MentorDbModel
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = r.updateWithRelations(tx, mentor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
 
+// updateWithRelations обновляет ментора со связанными сущностями в переданной транзакции
+func (r *MentorRepository) updateWithRelations(tx *gorm.DB, mentor *models.MentorDbModel) (*models.MentorDbModel, error) {
 	// Проверяем существование ментора
 	var existingMentor, memeberExistenceError = r.GetByMemberID(mentor.MemberId)
 	if memeberExistenceError != nil {
@@ -208,7 +204,6 @@ func (r *MentorRepository) UpdateWithRelations(mentor *models.MentorDbModel) (*m
 	existingMentor.Order = mentor.Order
 
 	if err := tx.Save(&existingMentor).Error; err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 
@@ -227,29 +222,21 @@ func (r *MentorRepository) UpdateWithRelations(mentor *models.MentorDbModel) (*m
 
 	result.ProfTags, err = r.handleProfTags(tx, existingMentor.Id, mentor.ProfTags)
 	if err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 
 	contactsInterface, err := r.handleRelatedEntities(tx, existingMentor.Id, mentor.Contacts, "contacts", "ownerId")
 	if err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 	result.Contacts = contactsInterface.([]models.Contact)
 
 	servicesInterface, err := r.handleRelatedEntities(tx, existingMentor.Id, mentor.Services, "services", "ownerId")
 	if err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 	result.Services = servicesInterface.([]models.Service)
 
-	// Фиксируем транзакцию
-	if err := tx.Commit().Error; err != nil {
-		return nil, err
-	}
-
 	return result, nil
 }
 
@@ -454,7 +441,24 @@ func (r *MentorRepository) handleRelatedEntities(tx *gorm.DB, mentorId int64, re
 		}
 	}
 
-	// Удаляем записи, которые не были обновлены
+	// Удаляем записи, которые не были обновлены. Услугу с предстоящими встречами удалить нельзя
+	if tableName == "services" && len(existingMap) > 0 {
+		removed := make([]int64, 0, len(existingMap))
+		for id := range existingMap {
+			removed = append(removed, id)
+		}
+		var bookings int64
+		if err := tx.Model(&models.MentorBooking{}).
+			Joins("JOIN mentor_slots ON mentor_slots.id = mentor_bookings.slot_id").
+			Where("mentor_bookings.service_id IN ? AND mentor_bookings.status IN ? AND mentor_slots.ends_at > ?",
+				removed, models.MentorBookingActiveStatuses, time.Now()).
+			Count(&bookings).Error; err != nil {
+			return nil, err
+		}
+		if bookings > 0 {
+			return nil, ErrServiceHasBookings
+		}
+	}
 	for id := range existingMap {
 		if err := tx.Table(tableName).Delete(reflect.New(modelType).Interface(), id).Error; err != nil {
 			return nil, err
@@ -499,44 +503,132 @@ func (r *MentorRepository) GetAllWithRelations(limit *int, offset *int) ([]model
 		return nil, 0, err
 	}
 
-	// Для каждого ментора загружаем связанные данные
-	for i := range mentors {
-		// Загружаем информацию о пользователе
-		if err := tx.First(&mentors[i].Member, mentors[i].MemberId).Error; err != nil {
-			return nil, 0, err
-		}
+	if err := loadMentorsRelations(tx, mentors); err != nil {
+		return nil, 0, err
+	}
 
-		// Загружаем профессиональные теги
-		var mentorTags []models.MentorsTag
-		if err := tx.Where("mentor_id = ?", mentors[i].Id).Find(&mentorTags).Error; err != nil {
-			return nil, 0, err
-		}
+	tx.Commit()
+	return mentors, count, nil
+}
+
+// loadMentorsRelations загружает для менторов пользователя, теги, контакты и услуги пачкой запросов на всех
+func loadMentorsRelations(tx *gorm.DB, mentors []models.MentorDbModel) error {
+	if len(mentors) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(mentors))
+	memberIds := make([]int64, 0, len(mentors))
+	for _, mentor := range mentors {
+		ids = append(ids, mentor.Id)
+		memberIds = append(memberIds, mentor.MemberId)
+	}
+
+	var members []models.Member
+	if err := tx.Where("id IN ?", memberIds).Find(&members).Error; err != nil {
+		return err
+	}
+	membersById := make(map[int64]models.Member, len(members))
+	for _, member := range members {
+		membersById[member.Id] = member
+	}
 
-		// Загружаем теги по их ID
-		var tagIds []int64
-		for _, mt := range mentorTags {
-			tagIds = append(tagIds, mt.TagId)
+	var mentorTags []models.MentorsTag
+	if err := tx.Where("mentor_id IN ?", ids).Find(&mentorTags).Error; err != nil {
+		return err
+	}
+	tagIds := make([]int64, 0, len(mentorTags))
+	for _, mt := range mentorTags {
+		tagIds = append(tagIds, mt.TagId)
+	}
+	var tags []models.ProfTag
+	if len(tagIds) > 0 {
+		if err := tx.Where("id IN ?", tagIds).Order("id ASC").Find(&tags).Error; err != nil {
+			return err
 		}
+	}
+	tagsById := make(map[int64]models.ProfTag, len(tags))
+	for _, tag := range tags {
+		tagsById[tag.Id] = tag
+	}
+	mentorTagIds := make(map[int64][]int64, len(mentors))
+	for _, mt := range mentorTags {
+		mentorTagIds[mt.MentorId] = append(mentorTagIds[mt.MentorId], mt.TagId)
+	}
 
-		if len(tagIds) > 0 {
-			if err := tx.Where("id IN ?", tagIds).Find(&mentors[i].ProfTags).Error; err != nil {
-				return nil, 0, err
+	var contacts []models.Contact
+	if err := tx.Where("\"ownerId\" IN ?", ids).Order("id ASC").Find(&contacts).Error; err != nil {
+		return err
+	}
+	var services []models.Service
+	if err := tx.Where("\"ownerId\" IN ?", ids).Order("id ASC").Find(&services).Error; err != nil {
+		return err
+	}
+
+	for i := range mentors {
+		mentor := &mentors[i]
+		mentor.Member = membersById[mentor.MemberId]
+
+		mentor.ProfTags = []models.ProfTag{}
+		for _, tagId := range mentorTagIds[mentor.Id] {
+			if tag, ok := tagsById[tagId]; ok {
+				mentor.ProfTags = append(mentor.ProfTags, tag)
 			}
 		}
 
-		// Загружаем контакты
-		if err := tx.Where("\"ownerId\" = ?", mentors[i].Id).Find(&mentors[i].Contacts).Error; err != nil {
-			return nil, 0, err
+		mentor.Contacts = []models.Contact{}
+		for _, contact := range contacts {
+			if contact.OwnerId == mentor.Id {
+				mentor.Contacts = append(mentor.Contacts, contact)
+			}
 		}
 
-		// Загружаем услуги
-		if err := tx.Where("\"ownerId\" = ?", mentors[i].Id).Find(&mentors[i].Services).Error; err != nil {
-			return nil, 0, err
+		mentor.Services = []models.Service{}
+		for _, service := range services {
+			if service.OwnerId == mentor.Id {
+				mentor.Services = append(mentor.Services, service)
+			}
 		}
 	}
+	return nil
+}
 
-	tx.Commit()
-	return mentors, count, nil
+// SetAvailability сохраняет доступность ментора. Новый отпуск снова требует напоминания о возвращении
+func (r *MentorRepository) SetAvailability(mentorId int64, request *models.MentorAvailabilityRequest) error {
+	return database.DB.Model(&models.MentorDbModel{}).
+		Where("id = ?", mentorId).
+		Updates(map[string]interface{}{
+			"availability":              request.Availability,
+			"vacation_until":            request.VacationUntil,
+			"max_mentees":               request.MaxMentees,
+			"vacation_reminder_sent_at": nil,
+		}).Error
+}
+
+// GetEndedVacations возвращает менторов, чей отпуск закончился, а напоминание еще не отправлено
+func (r *MentorRepository) GetEndedVacations(now time.Time) ([]models.MentorDbModel, error) {
+	var mentors []models.MentorDbModel
+	err := database.DB.Model(&models.MentorDbModel{}).
+		Preload("Member").
+		Where("availability = ? AND vacation_until <= ? AND vacation_reminder_sent_at IS NULL", models.MentorVacation, now).
+		Find(&mentors).Error
+	return mentors, err
+}
+
+// MarkVacationReminderSent отмечает отправку напоминания об окончании отпуска
+func (r *MentorRepository) MarkVacationReminderSent(mentorId int64, sentAt time.Time) error {
+	return database.DB.Model(&models.MentorDbModel{}).
+		Where("id = ?", mentorId).
+		Update("vacation_reminder_sent_at", sentAt).Error
+}
+
+// NextOrder порядковый номер для нового ментора в конце списка
+func (r *MentorRepository) NextOrder() (int, error) {
+	var order int
+	err := database.DB.Model(&models.MentorDbShortModel{}).
+		Select("COALESCE(MAX(\"order\"), 0) + 1").
+		Scan(&order).Error
+	return order, err
 }
 
 func (r *MentorRepository) GetByMemberID(memberId int64) (*models.MentorDbModel, error) {
@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"errors"
+	"ithozyeva/database"
+	"ithozyeva/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrMentorApplicationReviewed заявку уже рассмотрел другой администратор
+var ErrMentorApplicationReviewed = errors.New("заявка уже рассмотрена")
+
+type MentorApplicationRepository struct {
+	BaseRepository[models.MentorApplication]
+	mentors *MentorRepository
+}
+
+func NewMentorApplicationRepository() *MentorApplicationRepository {
+	return &MentorApplicationRepository{
+		BaseRepository: NewBaseRepository(database.DB, &models.MentorApplication{}),
+		mentors:        NewMentorRepository(),
+	}
+}
+
+func preloadMentorApplication(query *gorm.DB) *gorm.DB {
+	return query.
+		Preload("Member").
+		Preload("ProfTags").
+		Preload("Services").
+		Preload("Contacts")
+}
+
+// Search возвращает очередь заявок, старые сверху
+func (r *MentorApplicationRepository) Search(limit *int, offset *int, filter *SearchFilter, order *Order) ([]models.MentorApplication, int64, error) {
+	var applications []models.MentorApplication
+	var count int64
+
+	query := database.DB.Model(&models.MentorApplication{})
+	if filter != nil {
+		for key, value := range *filter {
+			query = query.Where(key, value)
+		}
+	}
+
+	if err := query.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = preloadMentorApplication(query).Order("created_at ASC")
+	if limit != nil {
+		query = query.Limit(*limit)
+	}
+	if offset != nil {
+		query = query.Offset(*offset)
+	}
+
+	if err := query.Find(&applications).Error; err != nil {
+		return nil, 0, err
+	}
+	return applications, count, nil
+}
+
+// GetById получает заявку с тегами, услугами и контактами
+func (r *MentorApplicationRepository) GetById(id int64) (*models.MentorApplication, error) {
+	var application models.MentorApplication
+	if err := preloadMentorApplication(database.DB).First(&application, id).Error; err != nil {
+		return nil, err
+	}
+	return &application, nil
+}
+
+// GetByMember возвращает заявки участника, новые сверху
+func (r *MentorApplicationRepository) GetByMember(memberId int64) ([]models.MentorApplication, error) {
+	var applications []models.MentorApplication
+	err := preloadMentorApplication(database.DB).
+		Where("member_id = ?", memberId).
+		Order("created_at DESC").
+		Find(&applications).Error
+	return applications, err
+}
+
+// HasPending проверяет, есть ли у участника заявка на рассмотрении
+func (r *MentorApplicationRepository) HasPending(memberId int64) (bool, error) {
+	var count int64
+	err := database.DB.Model(&models.MentorApplication{}).
+		Where("member_id = ? AND status = ?", memberId, models.MentorApplicationPending).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Create сохраняет заявку вместе с услугами и контактами. Привязываются только существующие теги
+func (r *MentorApplicationRepository) Create(application *models.MentorApplication) (*models.MentorApplication, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		tagIds := make([]int64, 0, len(application.ProfTags))
+		for _, tag := range application.ProfTags {
+			tagIds = append(tagIds, tag.Id)
+		}
+		application.ProfTags = nil
+
+		if err := tx.Omit("Member").Create(application).Error; err != nil {
+			return err
+		}
+		if len(tagIds) == 0 {
+			return nil
+		}
+
+		var tags []models.ProfTag
+		if err := tx.Where("id IN ?", tagIds).Find(&tags).Error; err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+		return tx.Model(application).Association("ProfTags").Append(tags)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(application.Id)
+}
+
+// SetReview сохраняет решение по заявке, если она еще на рассмотрении
+func (r *MentorApplicationRepository) SetReview(id int64, status models.MentorApplicationStatus, comment string, mentorId *int64) (*models.MentorApplication, error) {
+	if err := setMentorApplicationReview(database.DB, id, status, comment, mentorId); err != nil {
+		return nil, err
+	}
+	return r.GetById(id)
+}
+
+// Approve создает ментора по заявке, выдает участнику роль ментора и отмечает заявку одобренной
+// в одной транзакции: ментор без одобренной заявки или одобренная заявка без ментора не остаются
+func (r *MentorApplicationRepository) Approve(id int64, comment string, mentor *models.MentorDbModel) (*models.MentorApplication, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Блокировка не дает двум администраторам одобрить заявку одновременно
+		var application models.MentorApplication
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ?", id, models.MentorApplicationPending).
+			First(&application).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrMentorApplicationReviewed
+			}
+			return err
+		}
+
+		created, err := r.mentors.createWithRelations(tx, mentor)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.MemberRole{MemberId: mentor.MemberId, Role: models.MemberRoleMentor}).Error; err != nil {
+			return err
+		}
+
+		return setMentorApplicationReview(tx, id, models.MentorApplicationApproved, comment, &created.Id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(id)
+}
+
+func setMentorApplicationReview(tx *gorm.DB, id int64, status models.MentorApplicationStatus, comment string, mentorId *int64) error {
+	result := tx.Model(&models.MentorApplication{}).
+		Where("id = ? AND status = ?", id, models.MentorApplicationPending).
+		Updates(map[string]interface{}{
+			"status":         status,
+			"review_comment": comment,
+			"reviewed_at":    time.Now(),
+			"mentor_id":      mentorId,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMentorApplicationReviewed
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"errors"
+	"ithozyeva/database"
+	"ithozyeva/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrSlotTaken слот уже занят активной записью
+	ErrSlotTaken = errors.New("слот уже занят")
+	// ErrBookingOverlap у участника уже есть встреча, пересекающаяся по времени
+	ErrBookingOverlap = errors.New("у вас уже есть встреча в это время")
+)
+
+type MentorBookingRepository struct {
+	BaseRepository[models.MentorBooking]
+}
+
+func NewMentorBookingRepository() *MentorBookingRepository {
+	return &MentorBookingRepository{
+		BaseRepository: NewBaseRepository(database.DB, &models.MentorBooking{}),
+	}
+}
+
+// slotsWithBooked выбирает слоты с признаком занятости
+func slotsWithBooked(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.MentorSlot{}).
+		Select("mentor_slots.*, EXISTS (SELECT 1 FROM mentor_bookings b WHERE b.slot_id = mentor_slots.id AND b.status IN ?) AS booked",
+			models.MentorBookingActiveStatuses)
+}
+
+// preloadBooking подгружает слот, услугу и обе стороны встречи
+func preloadBooking(query *gorm.DB) *gorm.DB {
+	return query.
+		Preload("Slot").
+		Preload("Service").
+		Preload("Member").
+		Preload("Mentor").
+		Preload("Mentor.Member")
+}
+
+// GetSlots возвращает слоты ментора, которые заканчиваются после from
+func (r *MentorBookingRepository) GetSlots(mentorId int64, from time.Time) ([]models.MentorSlot, error) {
+	var slots []models.MentorSlot
+	err := slotsWithBooked(database.DB).
+		Where("mentor_id = ? AND ends_at > ?", mentorId, from).
+		Order("starts_at ASC").
+		Find(&slots).Error
+	return slots, err
+}
+
+// GetSlot получает слот с признаком занятости
+func (r *MentorBookingRepository) GetSlot(id int64) (*models.MentorSlot, error) {
+	var slot models.MentorSlot
+	if err := slotsWithBooked(database.DB).Where("id = ?", id).First(&slot).Error; err != nil {
+		return nil, err
+	}
+	return &slot, nil
+}
+
+// HasOverlappingSlot проверяет, пересекается ли интервал с уже опубликованными слотами ментора
+func (r *MentorBookingRepository) HasOverlappingSlot(mentorId int64, startsAt, endsAt time.Time) (bool, error) {
+	var count int64
+	err := database.DB.Model(&models.MentorSlot{}).
+		Where("mentor_id = ? AND starts_at < ? AND ends_at > ?", mentorId, endsAt, startsAt).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CreateSlots публикует слоты ментора
+func (r *MentorBookingRepository) CreateSlots(slots []models.MentorSlot) ([]models.MentorSlot, error) {
+	if len(slots) == 0 {
+		return slots, nil
+	}
+	if err := database.DB.Omit("Booked").Create(&slots).Error; err != nil {
+		return nil, err
+	}
+	return slots, nil
+}
+
+// DeleteSlot удаляет слот. Отмененные записи на этот слот удаляются каскадно
+func (r *MentorBookingRepository) DeleteSlot(id int64) error {
+	return database.DB.Delete(&models.MentorSlot{}, id).Error
+}
+
+// GetById получает запись со слотом, услугой и обеими сторонами
+func (r *MentorBookingRepository) GetById(id int64) (*models.MentorBooking, error) {
+	var booking models.MentorBooking
+	if err := preloadBooking(database.DB).First(&booking, id).Error; err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
+
+// GetByMember возвращает записи участника к менторам
+func (r *MentorBookingRepository) GetByMember(memberId int64) ([]models.MentorBooking, error) {
+	return r.find(database.DB.Where("mentor_bookings.member_id = ?", memberId))
+}
+
+// GetByMentor возвращает записи к ментору
+func (r *MentorBookingRepository) GetByMentor(mentorId int64) ([]models.MentorBooking, error) {
+	return r.find(database.DB.Where("mentor_bookings.mentor_id = ?", mentorId))
+}
+
+func (r *MentorBookingRepository) find(query *gorm.DB) ([]models.MentorBooking, error) {
+	var bookings []models.MentorBooking
+	err := preloadBooking(query).
+		Joins("JOIN mentor_slots ON mentor_slots.id = mentor_bookings.slot_id").
+		Order("mentor_slots.starts_at DESC").
+		Find(&bookings).Error
+	return bookings, err
+}
+
+// Book создает запись на слот. Слот блокируется на время транзакции, чтобы два участника
+// не заняли его одновременно
+func (r *MentorBookingRepository) Book(booking *models.MentorBooking) (*models.MentorBooking, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		slot, err := lockFreeSlot(tx, booking.SlotId, 0)
+		if err != nil {
+			return err
+		}
+		if err := checkMemberOverlap(tx, booking.MemberId, slot, 0); err != nil {
+			return err
+		}
+		return tx.Omit("Slot", "Mentor", "Service", "Member").Create(booking).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(booking.Id)
+}
+
+// Reschedule переносит запись на другой слот того же ментора
+func (r *MentorBookingRepository) Reschedule(booking *models.MentorBooking, slotId int64, changedBy int64) (*models.MentorBooking, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		slot, err := lockFreeSlot(tx, slotId, booking.Id)
+		if err != nil {
+			return err
+		}
+		if err := checkMemberOverlap(tx, booking.MemberId, slot, booking.Id); err != nil {
+			return err
+		}
+		return tx.Model(&models.MentorBooking{}).
+			Where("id = ?", booking.Id).
+			Updates(map[string]interface{}{
+				"slot_id":          slotId,
+				"status":           models.MentorBookingRescheduled,
+				"changed_by":       changedBy,
+				"reminder_sent_at": nil,
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(booking.Id)
+}
+
+// lockFreeSlot блокирует слот и проверяет, что он не занят другой активной записью
+func lockFreeSlot(tx *gorm.DB, slotId int64, exceptBookingId int64) (*models.MentorSlot, error) {
+	var slot models.MentorSlot
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&slot, slotId).Error; err != nil {
+		return nil, err
+	}
+
+	var taken int64
+	if err := tx.Model(&models.MentorBooking{}).
+		Where("slot_id = ? AND id <> ? AND status IN ?", slotId, exceptBookingId, models.MentorBookingActiveStatuses).
+		Count(&taken).Error; err != nil {
+		return nil, err
+	}
+	if taken > 0 {
+		return nil, ErrSlotTaken
+	}
+	return &slot, nil
+}
+
+// checkMemberOverlap проверяет, что у участника нет другой активной встречи в это время
+func checkMemberOverlap(tx *gorm.DB, memberId int64, slot *models.MentorSlot, exceptBookingId int64) error {
+	var overlapping int64
+	if err := tx.Model(&models.MentorBooking{}).
+		Joins("JOIN mentor_slots ON mentor_slots.id = mentor_bookings.slot_id").
+		Where("mentor_bookings.member_id = ? AND mentor_bookings.id <> ? AND mentor_bookings.status IN ?",
+			memberId, exceptBookingId, models.MentorBookingActiveStatuses).
+		Where("mentor_slots.starts_at < ? AND mentor_slots.ends_at > ?", slot.EndsAt, slot.StartsAt).
+		Count(&overlapping).Error; err != nil {
+		return err
+	}
+	if overlapping > 0 {
+		return ErrBookingOverlap
+	}
+	return nil
+}
+
+// SetStatus меняет статус записи
+func (r *MentorBookingRepository) SetStatus(id int64, status models.MentorBookingStatus, changedBy int64, cancelReason string) (*models.MentorBooking, error) {
+	updates := map[string]interface{}{
+		"status":     status,
+		"changed_by": changedBy,
+	}
+	if status == models.MentorBookingCancelled {
+		updates["cancel_reason"] = cancelReason
+	}
+	if err := database.DB.Model(&models.MentorBooking{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return r.GetById(id)
+}
+
+// GetDueReminders возвращает подтвержденные встречи, которые начнутся в ближайшие window и по которым еще не было напоминания
+func (r *MentorBookingRepository) GetDueReminders(now time.Time, window time.Duration) ([]models.MentorBooking, error) {
+	var bookings []models.MentorBooking
+	err := preloadBooking(database.DB).
+		Joins("JOIN mentor_slots ON mentor_slots.id = mentor_bookings.slot_id").
+		Where("mentor_bookings.status = ? AND mentor_bookings.reminder_sent_at IS NULL", models.MentorBookingConfirmed).
+		Where("mentor_slots.starts_at > ? AND mentor_slots.starts_at <= ?", now, now.Add(window)).
+		Find(&bookings).Error
+	return bookings, err
+}
+
+// MarkReminderSent отмечает, что напоминание о встрече отправлено
+func (r *MentorBookingRepository) MarkReminderSent(id int64, sentAt time.Time) error {
+	return database.DB.Model(&models.MentorBooking{}).
+		Where("id = ?", id).
+		Update("reminder_sent_at", sentAt).Error
+}
+
+// CountActiveMentees считает для менторов участников с активными предстоящими записями
+func (r *MentorBookingRepository) CountActiveMentees(mentorIds []int64, now time.Time) (map[int64]int, error) {
+	result := make(map[int64]int, len(mentorIds))
+	if len(mentorIds) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		MentorId int64
+		Mentees  int
+	}
+	err := database.DB.Model(&models.MentorBooking{}).
+		Select("mentor_bookings.mentor_id, COUNT(DISTINCT mentor_bookings.member_id) AS mentees").
+		Joins("JOIN mentor_slots ON mentor_slots.id = mentor_bookings.slot_id").
+		Where("mentor_bookings.mentor_id IN ?", mentorIds).
+		Where("mentor_bookings.status IN ?", models.MentorBookingActiveStatuses).
+		Where("mentor_slots.ends_at > ?", now).
+		Group("mentor_bookings.mentor_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.MentorId] = row.Mentees
+	}
+	return result, nil
+}
+
+// IsMentee работает ли участник с ментором: есть хотя бы одна неотмененная запись
+func (r *MentorBookingRepository) IsMentee(mentorId, memberId int64) (bool, error) {
+	var count int64
+	err := database.DB.Model(&models.MentorBooking{}).
+		Where("mentor_id = ? AND member_id = ? AND status <> ?", mentorId, memberId, models.MentorBookingCancelled).
+		Count(&count).Error
+	return count > 0, err
+}
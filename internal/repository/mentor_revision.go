@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"errors"
+	"ithozyeva/database"
+	"ithozyeva/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrMentorRevisionReviewed правку уже рассмотрел другой администратор
+var ErrMentorRevisionReviewed = errors.New("правка уже рассмотрена")
+
+type MentorRevisionRepository struct {
+	BaseRepository[models.MentorRevision]
+	mentors *MentorRepository
+}
+
+func NewMentorRevisionRepository() *MentorRevisionRepository {
+	return &MentorRevisionRepository{
+		BaseRepository: NewBaseRepository(database.DB, &models.MentorRevision{}),
+		mentors:        NewMentorRepository(),
+	}
+}
+
+func preloadMentorRevision(query *gorm.DB) *gorm.DB {
+	return query.
+		Preload("Services").
+		Preload("Contacts")
+}
+
+// Search возвращает очередь правок с данными ментора, старые сверху
+func (r *MentorRevisionRepository) Search(limit *int, offset *int, filter *SearchFilter, order *Order) ([]models.MentorRevision, int64, error) {
+	var revisions []models.MentorRevision
+	var count int64
+
+	query := database.DB.Model(&models.MentorRevision{})
+	if filter != nil {
+		for key, value := range *filter {
+			query = query.Where(key, value)
+		}
+	}
+
+	if err := query.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = preloadMentorRevision(query).
+		Preload("Mentor").
+		Preload("Mentor.Member").
+		Order("created_at ASC")
+	if limit != nil {
+		query = query.Limit(*limit)
+	}
+	if offset != nil {
+		query = query.Offset(*offset)
+	}
+
+	if err := query.Find(&revisions).Error; err != nil {
+		return nil, 0, err
+	}
+	return revisions, count, nil
+}
+
+// GetById получает версию с услугами и контактами
+func (r *MentorRevisionRepository) GetById(id int64) (*models.MentorRevision, error) {
+	var revision models.MentorRevision
+	if err := preloadMentorRevision(database.DB).First(&revision, id).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// GetByMentor возвращает историю версий профиля, новые сверху
+func (r *MentorRevisionRepository) GetByMentor(mentorId int64) ([]models.MentorRevision, error) {
+	var revisions []models.MentorRevision
+	err := preloadMentorRevision(database.DB).
+		Where("mentor_id = ?", mentorId).
+		Order("version DESC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// GetPending возвращает правку ментора, ожидающую модерации
+func (r *MentorRevisionRepository) GetPending(mentorId int64) (*models.MentorRevision, error) {
+	var revision models.MentorRevision
+	err := preloadMentorRevision(database.DB).
+		Where("mentor_id = ? AND status = ?", mentorId, models.MentorRevisionPending).
+		Order("version DESC").
+		First(&revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// HasAny есть ли у ментора сохраненные версии
+func (r *MentorRevisionRepository) HasAny(mentorId int64) (bool, error) {
+	var count int64
+	err := database.DB.Model(&models.MentorRevision{}).
+		Where("mentor_id = ?", mentorId).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Create сохраняет новую версию со следующим номером. Новая правка на модерации заменяет прежнюю
+func (r *MentorRevisionRepository) Create(revision *models.MentorRevision) (*models.MentorRevision, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return createMentorRevision(tx, revision)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(revision.Id)
+}
+
+func createMentorRevision(tx *gorm.DB, revision *models.MentorRevision) error {
+	// Блокируем ментора, чтобы номера версий не пересекались
+	if err := tx.Exec(`SELECT 1 FROM "mentors" WHERE "id" = ? FOR UPDATE`, revision.MentorId).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&models.MentorRevision{}).
+		Where("mentor_id = ?", revision.MentorId).
+		Select("COALESCE(MAX(version), 0) + 1").
+		Scan(&revision.Version).Error; err != nil {
+		return err
+	}
+
+	if revision.Status == models.MentorRevisionPending {
+		if err := tx.Model(&models.MentorRevision{}).
+			Where("mentor_id = ? AND status = ?", revision.MentorId, models.MentorRevisionPending).
+			Update("status", models.MentorRevisionSuperseded).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Omit("Mentor").Create(revision).Error
+}
+
+// SetReview сохраняет решение по правке, если она еще на модерации
+func (r *MentorRevisionRepository) SetReview(id int64, status models.MentorRevisionStatus, comment string) (*models.MentorRevision, error) {
+	if err := setMentorRevisionReview(database.DB, id, status, comment); err != nil {
+		return nil, err
+	}
+	return r.GetById(id)
+}
+
+// Approve публикует правку в профиле ментора и отмечает ее одобренной в одной транзакции:
+// профиль не меняется, если правку уже рассмотрели или заменили новой
+func (r *MentorRevisionRepository) Approve(id int64, comment string, mentor *models.MentorDbModel) (*models.MentorRevision, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := setMentorRevisionReview(tx, id, models.MentorRevisionApproved, comment); err != nil {
+			return err
+		}
+		_, err := r.mentors.updateWithRelations(tx, mentor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(id)
+}
+
+// Restore возвращает профилю ментора одобренную версию и сохраняет откат новой версией в одной транзакции
+func (r *MentorRevisionRepository) Restore(restored *models.MentorRevision, mentor *models.MentorDbModel) (*models.MentorRevision, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := r.mentors.updateWithRelations(tx, mentor); err != nil {
+			return err
+		}
+		return createMentorRevision(tx, restored)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(restored.Id)
+}
+
+func setMentorRevisionReview(tx *gorm.DB, id int64, status models.MentorRevisionStatus, comment string) error {
+	result := tx.Model(&models.MentorRevision{}).
+		Where("id = ? AND status = ?", id, models.MentorRevisionPending).
+		Updates(map[string]interface{}{
+			"status":         status,
+			"review_comment": comment,
+			"reviewed_at":    time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMentorRevisionReviewed
+	}
+	return nil
+}
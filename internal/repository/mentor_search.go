@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"fmt"
+	"ithozyeva/database"
+	"ithozyeva/internal/models"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// mentorRatingPrior сколько оценок нужно, чтобы средняя оценка влияла на ранжирование в полную силу
+const mentorRatingPrior = 3
+
+// mentorWordChars символы, из которых состоит слово при поиске по основам: буквы, цифры, «+» и «#» (C++, C#)
+const mentorWordChars = "[[:alnum:]+#]"
+
+// MentorSearchTerm основа слова из поискового запроса. Whole — слово короче основы и должно совпасть целиком
+type MentorSearchTerm struct {
+	Stem  string
+	Whole bool
+}
+
+// MentorRankQuery параметры ранжированного поиска менторов.
+// Релевантность — среднее из доли совпавших тегов TagIds и доли найденных основ Terms:
+// совпадение в специализации весит 1, в опыте, тегах или услугах — 0.6.
+// Итоговая оценка: 0.7 релевантности и 0.3 средней оценки в отзывах с поправкой на их число
+type MentorRankQuery struct {
+	// Filter фильтры каталога, nil — без фильтров
+	Filter *models.MentorSearchRequest
+	TagIds []int64
+	Terms  []MentorSearchTerm
+	// ExcludeMemberId не выдавать ментора этого участника
+	ExcludeMemberId *int64
+	// SkipVacation не выдавать менторов в отпуске
+	SkipVacation bool
+	// OnlyRelevant не выдавать менторов с нулевой релевантностью
+	OnlyRelevant bool
+	Limit        *int
+	Offset       *int
+	Now          time.Time
+}
+
+// RankedMentor ментор из ранжированного поиска с итоговой оценкой
+type RankedMentor struct {
+	Mentor models.MentorDbModel
+	Score  float64
+}
+
+// SearchWithRelations ищет менторов по фильтрам и ранжирует их в базе. Связи загружаются только
+// для запрошенной страницы. Возвращает страницу и общее число подходящих менторов
+func (r *MentorRepository) SearchWithRelations(query *MentorRankQuery) ([]RankedMentor, int64, error) {
+	tx := database.DB.Begin()
+	defer tx.Rollback()
+
+	ranked := tx.Table("(?) AS ranked", r.rankedMentors(tx, query))
+	if query.Filter != nil && query.Filter.Availability != "" {
+		ranked = ranked.Where("effective_availability = ?", query.Filter.Availability)
+	}
+	if query.OnlyRelevant {
+		ranked = ranked.Where("relevance > 0")
+	}
+
+	var count int64
+	if err := ranked.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	page := ranked.Select("id, 0.7 * relevance + 0.3 * rating_score AS score")
+	if query.Filter != nil && query.Filter.Sort == models.MentorSortAvailability {
+		page = page.Order(gorm.Expr(
+			"CASE effective_availability WHEN ? THEN 0 WHEN ? THEN 1 ELSE 2 END",
+			models.MentorAccepting, models.MentorWaitlist,
+		))
+	}
+	page = page.Order("score DESC").Order("\"order\" ASC").Order("id ASC")
+	if query.Limit != nil {
+		page = page.Limit(*query.Limit)
+	}
+	if query.Offset != nil {
+		page = page.Offset(*query.Offset)
+	}
+
+	var rows []struct {
+		Id    int64
+		Score float64
+	}
+	if err := page.Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	if len(rows) == 0 {
+		tx.Commit()
+		return []RankedMentor{}, count, nil
+	}
+
+	ids := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.Id)
+	}
+	var mentors []models.MentorDbModel
+	if err := tx.Where("id IN ?", ids).Find(&mentors).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := loadMentorsRelations(tx, mentors); err != nil {
+		return nil, 0, err
+	}
+	tx.Commit()
+
+	byId := make(map[int64]models.MentorDbModel, len(mentors))
+	for _, mentor := range mentors {
+		byId[mentor.Id] = mentor
+	}
+	result := make([]RankedMentor, 0, len(rows))
+	for _, row := range rows {
+		if mentor, ok := byId[row.Id]; ok {
+			result = append(result, RankedMentor{Mentor: mentor, Score: row.Score})
+		}
+	}
+	return result, count, nil
+}
+
+// rankedMentors запрос менторов, прошедших фильтры, с релевантностью, оценкой по отзывам
+// и доступностью с учетом свободных мест
+func (r *MentorRepository) rankedMentors(tx *gorm.DB, query *MentorRankQuery) *gorm.DB {
+	ratings := tx.Table("\"reviewOnService\" AS r").
+		Select(`s."ownerId" AS mentor_id, ROUND(AVG(r.rating)::numeric, 1) AS rating, COUNT(r.rating) AS reviews_count`).
+		Joins(`JOIN services s ON r."serviceId" = s.id`).
+		Where("r.status = ? AND r.rating IS NOT NULL", models.ReviewOnServiceStatusApproved).
+		Group(`s."ownerId"`)
+
+	relevance, relevanceArgs := mentorRelevance(query.TagIds, query.Terms)
+	args := append(relevanceArgs,
+		models.MentorWaitlist, models.MentorVacation,
+		models.MentorBookingActiveStatuses, query.Now, models.MentorWaitlist, models.MentorAccepting,
+		mentorRatingPrior,
+	)
+
+	mentors := tx.Model(&models.MentorDbModel{}).
+		Select(`mentors.id, mentors."order", `+relevance+` AS relevance,
+			CASE
+				WHEN mentors.availability IN (?, ?) THEN mentors.availability
+				WHEN mentors.max_mentees IS NOT NULL AND (
+					SELECT COUNT(DISTINCT b.member_id) FROM mentor_bookings b JOIN mentor_slots sl ON sl.id = b.slot_id
+					WHERE b.mentor_id = mentors.id AND b.status IN ? AND sl.ends_at > ?
+				) >= mentors.max_mentees THEN ?
+				ELSE ?
+			END AS effective_availability,
+			COALESCE((ratings.rating / 5 * ratings.reviews_count / (ratings.reviews_count + ?))::float8, 0) AS rating_score`,
+			args...).
+		Joins("LEFT JOIN (?) AS ratings ON ratings.mentor_id = mentors.id", ratings)
+
+	if query.ExcludeMemberId != nil {
+		mentors = mentors.Where("mentors.\"memberId\" <> ?", *query.ExcludeMemberId)
+	}
+	if query.SkipVacation {
+		mentors = mentors.Where("mentors.availability <> ?", models.MentorVacation)
+	}
+
+	filter := query.Filter
+	if filter == nil {
+		return mentors
+	}
+	if len(filter.TagIds) > 0 {
+		mentors = mentors.Where("mentors.id IN (SELECT mentor_id FROM mentors_tags WHERE tag_id IN ?)", filter.TagIds)
+	}
+	if filter.PriceFrom != nil || filter.PriceTo != nil {
+		services := tx.Model(&models.Service{}).Select("\"ownerId\"")
+		if filter.PriceFrom != nil {
+			services = services.Where("price >= ?", *filter.PriceFrom)
+		}
+		if filter.PriceTo != nil {
+			services = services.Where("price <= ?", *filter.PriceTo)
+		}
+		mentors = mentors.Where("mentors.id IN (?)", services)
+	}
+	if filter.MinExperience != nil {
+		// Опыт хранится строкой вида «5 лет», сравниваем по первому числу в ней
+		mentors = mentors.Where("COALESCE(substring(mentors.experience from '[0-9]+')::int, 0) >= ?", *filter.MinExperience)
+	}
+	if filter.Query != "" {
+		pattern := "%" + escapeLike(filter.Query) + "%"
+		mentors = mentors.Where(`mentors.occupation ILIKE ? OR mentors.experience ILIKE ? OR mentors.id IN (
+			SELECT mt.mentor_id FROM mentors_tags mt JOIN "profTags" t ON t.id = mt.tag_id WHERE t.title ILIKE ?
+		)`, pattern, pattern, pattern)
+	}
+	return mentors
+}
+
+// mentorRelevance собирает SQL-выражение релевантности ментора запросу и его параметры
+func mentorRelevance(tagIds []int64, terms []MentorSearchTerm) (string, []interface{}) {
+	var parts []string
+	var args []interface{}
+
+	unique := make(map[int64]bool, len(tagIds))
+	for _, id := range tagIds {
+		unique[id] = true
+	}
+	if len(unique) > 0 {
+		parts = append(parts, "(SELECT COUNT(*) FROM mentors_tags mt WHERE mt.mentor_id = mentors.id AND mt.tag_id IN ?)::float8 / ?")
+		args = append(args, tagIds, float64(len(unique)))
+	}
+
+	if len(terms) > 0 {
+		matches := make([]string, 0, len(terms))
+		for _, term := range terms {
+			pattern := mentorTermPattern(term)
+			matches = append(matches, `CASE
+				WHEN mentors.occupation ~* ? THEN 1
+				WHEN mentors.experience ~* ?
+					OR EXISTS (SELECT 1 FROM mentors_tags mt JOIN "profTags" t ON t.id = mt.tag_id WHERE mt.mentor_id = mentors.id AND t.title ~* ?)
+					OR EXISTS (SELECT 1 FROM services sv WHERE sv."ownerId" = mentors.id AND sv.name ~* ?) THEN 0.6
+				ELSE 0
+			END`)
+			args = append(args, pattern, pattern, pattern, pattern)
+		}
+		parts = append(parts, fmt.Sprintf("(%s)::float8 / %d", strings.Join(matches, " + "), len(terms)))
+	}
+
+	if len(parts) == 0 {
+		return "0::float8", args
+	}
+	return fmt.Sprintf("(%s) / %d", strings.Join(parts, " + "), len(parts)), args
+}
+
+// mentorTermPattern регулярное выражение Postgres для основы: слово начинается с нее,
+// а для короткого слова — совпадает с ней целиком
+func mentorTermPattern(term MentorSearchTerm) string {
+	pattern := "(?<!" + mentorWordChars + ")" + regexp.QuoteMeta(term.Stem)
+	if term.Whole {
+		pattern += "(?!" + mentorWordChars + ")"
+	}
+	return pattern
+}
+
+// escapeLike экранирует спецсимволы LIKE, чтобы строка искалась буквально
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
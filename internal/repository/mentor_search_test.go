@@ -0,0 +1,42 @@
+package repository
+
+import "testing"
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "golang", want: "golang"},
+		{value: "100%", want: `100\%`},
+		{value: "snake_case", want: `snake\_case`},
+		{value: `C:\dev`, want: `C:\\dev`},
+		{value: `%_\`, want: `\%\_\\`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeLike(tt.value); got != tt.want {
+			t.Errorf("escapeLike(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestMentorTermPattern(t *testing.T) {
+	tests := []struct {
+		name string
+		term MentorSearchTerm
+		want string
+	}{
+		{name: "основа длинного слова", term: MentorSearchTerm{Stem: "разрабо"}, want: `(?<![[:alnum:]+#])разрабо`},
+		{name: "короткое слово целиком", term: MentorSearchTerm{Stem: "go", Whole: true}, want: `(?<![[:alnum:]+#])go(?![[:alnum:]+#])`},
+		{name: "спецсимволы экранируются", term: MentorSearchTerm{Stem: "c++", Whole: true}, want: `(?<![[:alnum:]+#])c\+\+(?![[:alnum:]+#])`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mentorTermPattern(tt.term); got != tt.want {
+				t.Errorf("mentorTermPattern() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,8 +4,16 @@ import (
 	"fmt"
 	"ithozyeva/database"
 	"ithozyeva/internal/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// referalCompanySimilarity порог сходства названий компаний по триграммам: «Яндекс» найдется по «яндек» и «Yandex» по «yandx»
+const referalCompanySimilarity = 0.3
+
 type ReferalLinkRepository struct {
 	BaseRepository[models.ReferalLink]
 }
@@ -20,10 +28,6 @@ func (e *ReferalLinkRepository) Search(limit *int, offset *int, filter *SearchFi
 	var links []models.ReferalLink
 	var count int64
 
-	if err := database.DB.Model(&models.ReferalLink{}).Count(&count).Error; err != nil {
-		return nil, 0, err
-	}
-
 	query := database.DB.Model(&models.ReferalLink{}).Preload("Author").Preload("ProfTags")
 
 	if filter != nil {
@@ -32,6 +36,10 @@ func (e *ReferalLinkRepository) Search(limit *int, offset *int, filter *SearchFi
 		}
 	}
 
+	if err := query.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
 	if order != nil {
 		query = query.Order(fmt.Sprintf("\"%s\" %s", order.ColumnBy, order.Order))
 	}
@@ -71,8 +79,194 @@ func (r *ReferalLinkRepository) Update(entity *models.ReferalLink) (*models.Refe
 
 func (r *ReferalLinkRepository) GetById(id int64) (*models.ReferalLink, error) {
 	var event models.ReferalLink
-	if err := database.DB.Preload("Author").Preload("ProfTags").First(&event, id).Error; err != nil {
+	if err := database.DB.Preload("Author").Preload("ProfTags").Preload("CompanyInfo").First(&event, id).Error; err != nil {
 		return nil, err
 	}
 	return &event, nil
 }
+
+// GetStale возвращает активные ссылки, которые не менялись и не подтверждались с before и по которым автора еще не спрашивали
+// Берется самое позднее из подтверждения, изменения и создания: NULL в GREATEST пропускается
+func (r *ReferalLinkRepository) GetStale(before time.Time) ([]models.ReferalLink, error) {
+	var links []models.ReferalLink
+	err := database.DB.Preload("Author").
+		Where("status = ? AND vacations_count > 0 AND confirmation_requested_at IS NULL", models.ReferalLinkActive).
+		Where("GREATEST(confirmed_at, updated_at, created_at) < ?", before).
+		Find(&links).Error
+	return links, err
+}
+
+// MarkConfirmationRequested отмечает, что автора попросили подтвердить ссылку
+func (r *ReferalLinkRepository) MarkConfirmationRequested(id int64, requestedAt time.Time) error {
+	return database.DB.Model(&models.ReferalLink{}).
+		Where("id = ?", id).
+		Update("confirmation_requested_at", requestedAt).Error
+}
+
+// FreezeUnconfirmed замораживает активные ссылки, которые автор не подтвердил после запроса до before
+func (r *ReferalLinkRepository) FreezeUnconfirmed(before time.Time) ([]models.ReferalLink, error) {
+	return r.moveStatus(models.ReferalLinkFreezed, func(query *gorm.DB) *gorm.DB {
+		return query.Where("status = ? AND confirmation_requested_at < ?", models.ReferalLinkActive, before)
+	})
+}
+
+// ArchiveEmpty переносит в архив ссылки, вакансии по которым закончились
+func (r *ReferalLinkRepository) ArchiveEmpty() ([]models.ReferalLink, error) {
+	return r.moveStatus(models.ReferalLinkArchived, func(query *gorm.DB) *gorm.DB {
+		return query.Where("status <> ? AND COALESCE(vacations_count, 0) <= 0", models.ReferalLinkArchived)
+	})
+}
+
+// moveStatus переводит подходящие ссылки в статус и возвращает их с авторами для уведомления
+func (r *ReferalLinkRepository) moveStatus(status models.ReferalLinkStatus, scope func(query *gorm.DB) *gorm.DB) ([]models.ReferalLink, error) {
+	var links []models.ReferalLink
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var ids []int64
+		if err := scope(tx.Model(&models.ReferalLink{}).Clauses(clause.Locking{Strength: "UPDATE"})).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&models.ReferalLink{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"status":                    status,
+				"confirmation_requested_at": nil,
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Preload("Author").Where("id IN ?", ids).Find(&links).Error
+	})
+	return links, err
+}
+
+// Confirm отмечает ссылку актуальной и снова делает ее активной
+func (r *ReferalLinkRepository) Confirm(id int64, confirmedAt time.Time) (*models.ReferalLink, error) {
+	err := database.DB.Model(&models.ReferalLink{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":                    models.ReferalLinkActive,
+			"confirmed_at":              confirmedAt,
+			"confirmation_requested_at": nil,
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(id)
+}
+
+// SearchLinks ищет ссылки по компании, грейду, тегам, статусу и автору. Total считается с учетом фильтров.
+// Компания ищется в справочнике по названию и алиасам, ссылки отбираются по company_id
+func (r *ReferalLinkRepository) SearchLinks(filter *models.ReferalLinkSearchRequest) ([]models.ReferalLink, int64, error) {
+	var links []models.ReferalLink
+	var count int64
+
+	company := models.NormalizeCompanyName(filter.Company)
+	query := database.DB.Model(&models.ReferalLink{})
+	if company != "" {
+		pattern := "%" + escapeLike(company) + "%"
+		companies := database.DB.Model(&models.Company{}).Select("id").
+			Where(`normalized_name LIKE ? OR similarity(normalized_name, ?) > ? OR id IN (
+				SELECT company_id FROM company_aliases WHERE normalized_alias LIKE ? OR similarity(normalized_alias, ?) > ?
+			)`, pattern, company, referalCompanySimilarity, pattern, company, referalCompanySimilarity)
+		query = query.Where("company_id IN (?)", companies)
+	}
+	if filter.CompanyId != nil {
+		query = query.Where("company_id = ?", *filter.CompanyId)
+	}
+	if filter.Grade != "" {
+		query = query.Where("LOWER(grade) = LOWER(?)", filter.Grade)
+	}
+	if len(filter.TagIds) > 0 {
+		query = query.Where("id IN (SELECT referal_link_id FROM referal_links_tags WHERE prof_tag_id IN ?)", filter.TagIds)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	} else {
+		query = query.Where("status <> ?", models.ReferalLinkArchived)
+	}
+	if filter.AuthorId != nil {
+		query = query.Where("author_id = ?", *filter.AuthorId)
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at > ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("created_at <= ?", *filter.CreatedBefore)
+	}
+
+	if err := query.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	direction := "DESC"
+	if filter.Order == "asc" {
+		direction = "ASC"
+	}
+	switch {
+	case filter.Sort == models.ReferalSortVacancies:
+		query = query.Order("vacations_count " + direction)
+	case filter.Sort == "" && company != "":
+		query = query.Order(clause.Expr{SQL: `(
+			SELECT GREATEST(similarity(c.normalized_name, ?), COALESCE(MAX(similarity(a.normalized_alias, ?)), 0))
+			FROM companies c LEFT JOIN company_aliases a ON a.company_id = c.id
+			WHERE c.id = referal_links.company_id
+			GROUP BY c.id
+		) DESC NULLS LAST`, Vars: []interface{}{company, company}})
+	}
+	query = query.Order("GREATEST(confirmed_at, updated_at, created_at) " + direction).Order("id DESC")
+
+	if filter.Limit != nil {
+		query = query.Limit(*filter.Limit)
+	}
+	if filter.Offset != nil {
+		query = query.Offset(*filter.Offset)
+	}
+
+	if err := query.Preload("Author").Preload("ProfTags").Preload("CompanyInfo").Find(&links).Error; err != nil {
+		return nil, 0, err
+	}
+	return links, count, nil
+}
+
+// GetCompanySummary сводка активных ссылок по компаниям справочника: сколько ссылок, вакансий и какие грейды
+func (r *ReferalLinkRepository) GetCompanySummary() ([]models.ReferalCompanySummary, error) {
+	var rows []struct {
+		CompanyId      int64
+		Company        string
+		LinksCount     int
+		VacanciesCount int
+		Grades         string
+	}
+	err := database.DB.Table("referal_links AS rl").
+		Select(`c.id AS company_id, c.name AS company, COUNT(*) AS links_count,
+			COALESCE(SUM(rl.vacations_count), 0) AS vacancies_count,
+			STRING_AGG(DISTINCT LOWER(rl.grade), ',') AS grades`).
+		Joins("JOIN companies c ON c.id = rl.company_id").
+		Where("rl.status = ? AND rl.vacations_count > 0", models.ReferalLinkActive).
+		Group("c.id, c.name").
+		Order("vacancies_count DESC, c.name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	summary := make([]models.ReferalCompanySummary, 0, len(rows))
+	for _, row := range rows {
+		item := models.ReferalCompanySummary{
+			CompanyId:      row.CompanyId,
+			Company:        row.Company,
+			LinksCount:     row.LinksCount,
+			VacanciesCount: row.VacanciesCount,
+			Grades:         []string{},
+		}
+		if row.Grades != "" {
+			item.Grades = strings.Split(row.Grades, ",")
+		}
+		summary = append(summary, item)
+	}
+	return summary, nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"errors"
+	"ithozyeva/database"
+	"ithozyeva/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrReferalRequestExists  = errors.New("вы уже запросили рекомендацию по этой ссылке")
+	ErrReferalRequestChanged = errors.New("статус заявки уже изменился, обновите страницу")
+)
+
+type ReferalRequestRepository struct {
+	BaseRepository[models.ReferalRequest]
+}
+
+func NewReferalRequestRepository() *ReferalRequestRepository {
+	return &ReferalRequestRepository{
+		BaseRepository: NewBaseRepository(database.DB, &models.ReferalRequest{}),
+	}
+}
+
+func preloadReferalRequest(query *gorm.DB) *gorm.DB {
+	return query.
+		Preload("Link").
+		Preload("Link.Author").
+		Preload("Member").
+		Preload("Resume")
+}
+
+// GetById получает заявку со ссылкой, автором, участником и резюме
+func (r *ReferalRequestRepository) GetById(id int64) (*models.ReferalRequest, error) {
+	var request models.ReferalRequest
+	if err := preloadReferalRequest(database.DB).First(&request, id).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetByMember возвращает заявки, отправленные участником
+func (r *ReferalRequestRepository) GetByMember(memberId int64) ([]models.ReferalRequest, error) {
+	var requests []models.ReferalRequest
+	err := preloadReferalRequest(database.DB).
+		Where("member_id = ?", memberId).
+		Order("created_at DESC").
+		Find(&requests).Error
+	return requests, err
+}
+
+// GetByAuthor возвращает заявки на ссылки автора
+func (r *ReferalRequestRepository) GetByAuthor(authorId int64) ([]models.ReferalRequest, error) {
+	var requests []models.ReferalRequest
+	err := preloadReferalRequest(database.DB).
+		Where("link_id IN (SELECT id FROM referal_links WHERE author_id = ?)", authorId).
+		Order("created_at DESC").
+		Find(&requests).Error
+	return requests, err
+}
+
+// Create сохраняет заявку. Повторная заявка по ссылке возможна, только когда прежняя завершена
+func (r *ReferalRequestRepository) Create(request *models.ReferalRequest) (*models.ReferalRequest, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.ReferalRequest{}).
+			Where("link_id = ? AND member_id = ?", request.LinkId, request.MemberId).
+			Where("status NOT IN ?", []models.ReferalRequestStatus{models.ReferalRequestHired, models.ReferalRequestRejected}).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrReferalRequestExists
+		}
+
+		return tx.Omit(clause.Associations).Create(request).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(request.Id)
+}
+
+// SetStatus меняет статус заявки. При найме по ссылке становится на одну вакансию меньше,
+// а когда вакансии заканчиваются, ссылка уходит в архив
+func (r *ReferalRequestRepository) SetStatus(request *models.ReferalRequest, status models.ReferalRequestStatus, comment string, changedBy int64) (*models.ReferalRequest, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Статус меняется, только если его не успела изменить вторая сторона
+		result := tx.Model(&models.ReferalRequest{}).
+			Where("id = ? AND status = ?", request.Id, request.Status).
+			Updates(map[string]interface{}{
+				"status":     status,
+				"comment":    comment,
+				"changed_by": changedBy,
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrReferalRequestChanged
+		}
+
+		if status != models.ReferalRequestHired {
+			return nil
+		}
+
+		var link models.ReferalLink
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&link, request.LinkId).Error; err != nil {
+			return err
+		}
+		if link.VacationsCount > 0 {
+			link.VacationsCount--
+		}
+		updates := map[string]interface{}{
+			"vacations_count": link.VacationsCount,
+			"updated_at":      time.Now(),
+		}
+		if link.VacationsCount == 0 {
+			updates["status"] = models.ReferalLinkArchived
+		}
+		return tx.Model(&models.ReferalLink{}).Where("id = ?", link.Id).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(request.Id)
+}
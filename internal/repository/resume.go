@@ -1,10 +1,22 @@
 package repository
 
 import (
+	"errors"
 	"ithozyeva/database"
 	"ithozyeva/internal/models"
+	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// resumeTsQuery запрос сразу в русской и английской конфигурациях, search_vector построен в обеих
+	resumeTsQuery = "(websearch_to_tsquery('russian', ?) || websearch_to_tsquery('english', ?))"
+	// resumeEscapedText текст без HTML, чтобы во фрагментах размечены были только найденные слова
+	resumeEscapedText      = "replace(replace(replace(coalesce(text, ''), '&', '&amp;'), '<', '&lt;'), '>', '&gt;')"
+	resumeHeadlineOptions  = `StartSel=<mark>, StopSel=</mark>, MaxFragments=3, MinWords=5, MaxWords=20, FragmentDelimiter=" … "`
+	resumeReindexBatchSize = 50
 )
 
 type ResumeRepository struct {
@@ -19,9 +31,163 @@ func NewResumeRepository() *ResumeRepository {
 	}
 }
 
+// CreateVersion сохраняет новую версию резюме участника вместе с навыками и делает ее основной
+func (r *ResumeRepository) CreateVersion(resume *models.Resume, change *models.ResumeChange) (*models.Resume, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Блокировка участника, чтобы параллельные загрузки не получили один номер версии
+		member := new(models.Member)
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("telegram_id = ?", resume.TgID).First(member).Error; err != nil {
+			return err
+		}
+
+		var version int
+		err := tx.Model(&models.Resume{}).Where("tg_id = ?", resume.TgID).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+		if err != nil {
+			return err
+		}
+		resume.Version = version + 1
+
+		tags, err := findProfTags(tx, resume.ProfTags)
+		if err != nil {
+			return err
+		}
+		resume.ProfTags = nil
+
+		if resume.IsPrimary {
+			if err := unsetPrimaryResume(tx, resume.TgID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Omit("Member").Create(resume).Error; err != nil {
+			return err
+		}
+		if len(tags) > 0 {
+			resume.ProfTags = tags
+			if err := tx.Model(resume).Association("ProfTags").Replace(tags); err != nil {
+				return err
+			}
+		}
+		return addResumeChange(tx, resume, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resume, nil
+}
+
+// UpdateVersion сохраняет поля, которые участник поправил вручную
+func (r *ResumeRepository) UpdateVersion(resume *models.Resume, change *models.ResumeChange) (*models.Resume, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Resume{Id: resume.Id}).
+			Select("work_experience", "desired_position", "work_format").
+			Updates(resume).Error
+		if err != nil {
+			return err
+		}
+		return addResumeChange(tx, resume, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByIdWithMember(resume.Id)
+}
+
+// SaveProfile сохраняет повторный разбор файла: профиль, стаж, теги и заполненные поля
+func (r *ResumeRepository) SaveProfile(resume *models.Resume, change *models.ResumeChange) (*models.Resume, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		tags, err := findProfTags(tx, resume.ProfTags)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.Resume{Id: resume.Id}).
+			Select("work_experience", "desired_position", "work_format", "profile", "experience_years", "parsed_at", "text").
+			Updates(resume).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Resume{Id: resume.Id}).Association("ProfTags").Replace(tags); err != nil {
+			return err
+		}
+		return addResumeChange(tx, resume, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByIdWithMember(resume.Id)
+}
+
+// SetPrimary делает версию основным резюме участника
+func (r *ResumeRepository) SetPrimary(resume *models.Resume, change *models.ResumeChange) (*models.Resume, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := unsetPrimaryResume(tx, resume.TgID); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Resume{}).Where("id = ?", resume.Id).Update("is_primary", true).Error; err != nil {
+			return err
+		}
+		return addResumeChange(tx, resume, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByIdWithMember(resume.Id)
+}
+
+// DeleteVersion удаляет версию. Если она была основной, основной становится последняя из оставшихся
+func (r *ResumeRepository) DeleteVersion(resume *models.Resume) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.Resume{}, resume.Id).Error; err != nil {
+			return err
+		}
+		if !resume.IsPrimary {
+			return nil
+		}
+
+		latest := new(models.Resume)
+		err := tx.Where("tg_id = ?", resume.TgID).Order("version DESC").First(latest).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.Resume{}).Where("id = ?", latest.Id).Update("is_primary", true).Error
+	})
+}
+
+// GetPrimary возвращает основное резюме участника
+func (r *ResumeRepository) GetPrimary(tgID int64) (*models.Resume, error) {
+	resume := new(models.Resume)
+	if err := r.db.Preload("ProfTags").Where("tg_id = ? AND is_primary", tgID).First(resume).Error; err != nil {
+		return nil, err
+	}
+	return resume, nil
+}
+
+// ListChanges возвращает историю резюме участника, новые записи сверху
+func (r *ResumeRepository) ListChanges(tgID int64) ([]models.ResumeChange, error) {
+	var changes []models.ResumeChange
+	err := r.db.Where("tg_id = ?", tgID).Order("created_at DESC, id DESC").Find(&changes).Error
+	return changes, err
+}
+
+func unsetPrimaryResume(tx *gorm.DB, tgID int64) error {
+	return tx.Model(&models.Resume{}).Where("tg_id = ? AND is_primary", tgID).Update("is_primary", false).Error
+}
+
+func addResumeChange(tx *gorm.DB, resume *models.Resume, change *models.ResumeChange) error {
+	if change == nil {
+		return nil
+	}
+	change.TgID = resume.TgID
+	change.ResumeId = &resume.Id
+	return tx.Create(change).Error
+}
+
 func (r *ResumeRepository) ListByTelegramID(tgID int64) ([]models.Resume, error) {
 	var resumes []models.Resume
-	err := r.db.Where("tg_id = ?", tgID).Order("\"created_at\" DESC").Find(&resumes).Error
+	err := r.db.Preload("ProfTags").Omit("text").Where("tg_id = ?", tgID).Order("version DESC").Find(&resumes).Error
 	return resumes, err
 }
 
@@ -34,9 +200,20 @@ func (r *ResumeRepository) GetByIDAndTelegram(id, tgID int64) (*models.Resume, e
 }
 
 func (r *ResumeRepository) SearchForAdmin(limit *int, offset *int, filter *models.ResumeFilter) ([]models.Resume, int64, error) {
-	query := r.db.Model(&models.Resume{}).Preload("Member")
+	query := r.db.Model(&models.Resume{}).Preload("Member").Preload("ProfTags")
+
+	if filter == nil || !filter.AllVersions {
+		query = query.Where("is_primary")
+	}
 
+	search := ""
 	if filter != nil {
+		if filter.Query != nil {
+			search = resumeSearchQuery(*filter.Query)
+		}
+		if search != "" {
+			query = query.Where("search_vector @@ "+resumeTsQuery, search, search)
+		}
 		if filter.WorkFormat != nil && *filter.WorkFormat != "" {
 			query = query.Where("work_format = ?", *filter.WorkFormat)
 		}
@@ -46,6 +223,18 @@ func (r *ResumeRepository) SearchForAdmin(limit *int, offset *int, filter *model
 		if filter.WorkExperience != nil && *filter.WorkExperience != "" {
 			query = query.Where("work_experience ILIKE ?", "%"+*filter.WorkExperience+"%")
 		}
+		if filter.MinExperienceYears != nil {
+			query = query.Where("experience_years >= ?", *filter.MinExperienceYears)
+		}
+		if len(filter.TagIds) > 0 {
+			query = query.Where("id IN (SELECT resume_id FROM resume_tags WHERE prof_tag_id IN ?)", filter.TagIds)
+		}
+		if filter.CreatedAfter != nil {
+			query = query.Where("created_at > ?", *filter.CreatedAfter)
+		}
+		if filter.CreatedBefore != nil {
+			query = query.Where("created_at <= ?", *filter.CreatedBefore)
+		}
 	}
 
 	var count int64
@@ -60,14 +249,95 @@ func (r *ResumeRepository) SearchForAdmin(limit *int, offset *int, filter *model
 		query = query.Offset(*offset)
 	}
 
+	if search != "" {
+		query = query.Order(clause.Expr{SQL: "ts_rank_cd(search_vector, " + resumeTsQuery + ") DESC", Vars: []interface{}{search, search}})
+	}
+
 	var items []models.Resume
-	if err := query.Order("\"created_at\" DESC").Find(&items).Error; err != nil {
+	if err := query.Omit("text").Order("\"created_at\" DESC").Find(&items).Error; err != nil {
 		return nil, 0, err
 	}
 
+	if search != "" && len(items) > 0 {
+		if err := r.fillSnippets(items, search); err != nil {
+			return nil, 0, err
+		}
+	}
+
 	return items, count, nil
 }
 
+// fillSnippets подставляет фрагменты текста с подсвеченными словами запроса
+func (r *ResumeRepository) fillSnippets(items []models.Resume, search string) error {
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.Id)
+	}
+
+	var rows []struct {
+		Id      int64
+		Snippet string
+	}
+	err := r.db.Model(&models.Resume{}).
+		Select("id, ts_headline('russian', "+resumeEscapedText+", "+resumeTsQuery+", ?) AS snippet", search, search, resumeHeadlineOptions).
+		Where("id IN ?", ids).
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	snippets := make(map[int64]string, len(rows))
+	for _, row := range rows {
+		snippets[row.Id] = row.Snippet
+	}
+	for i := range items {
+		items[i].Snippet = snippets[items[i].Id]
+	}
+	return nil
+}
+
+// resumeSearchQuery приводит привычные операторы к синтаксису websearch_to_tsquery:
+// «Go + Kafka», «Go AND Kafka», «Go && Kafka» — все слова; OR, ИЛИ, | — любое; NOT, НЕ — исключить слово
+func resumeSearchQuery(raw string) string {
+	var terms []string
+	negate := false
+	for _, token := range strings.Fields(raw) {
+		switch strings.ToUpper(token) {
+		case "+", "&", "&&", "AND", "И":
+			continue
+		case "|", "||", "OR", "ИЛИ":
+			terms = append(terms, "or")
+			continue
+		case "NOT", "НЕ":
+			negate = true
+			continue
+		}
+
+		// «Go+Kafka» без пробелов, но «C++» оставляем как есть
+		if trimmed := strings.TrimRight(token, "+"); strings.Contains(trimmed, "+") {
+			token = strings.ReplaceAll(trimmed, "+", " ")
+		}
+		if negate {
+			token = "-" + token
+			negate = false
+		}
+		terms = append(terms, token)
+	}
+	return strings.TrimSpace(strings.Join(terms, " "))
+}
+
+// ListUnindexed возвращает резюме, текст которых еще не извлечен для поиска
+func (r *ResumeRepository) ListUnindexed() ([]models.Resume, error) {
+	var resumes []models.Resume
+	err := r.db.Where("text IS NULL").Order("id").Limit(resumeReindexBatchSize).Find(&resumes).Error
+	return resumes, err
+}
+
+// MarkUnreadable отмечает резюме, из файла которого не удалось извлечь текст, чтобы не разбирать его снова
+func (r *ResumeRepository) MarkUnreadable(id int64) error {
+	return r.db.Model(&models.Resume{}).Where("id = ?", id).Update("text", "").Error
+}
+
 func (r *ResumeRepository) ListByIDs(ids []int64) ([]models.Resume, error) {
 	var resumes []models.Resume
 	if err := r.db.Where("id IN ?", ids).Find(&resumes).Error; err != nil {
@@ -78,7 +348,7 @@ func (r *ResumeRepository) ListByIDs(ids []int64) ([]models.Resume, error) {
 
 func (r *ResumeRepository) GetByIdWithMember(id int64) (*models.Resume, error) {
 	resume := new(models.Resume)
-	if err := r.db.Preload("Member").First(resume, id).Error; err != nil {
+	if err := r.db.Preload("Member").Preload("ProfTags").First(resume, id).Error; err != nil {
 		return nil, err
 	}
 	return resume, nil
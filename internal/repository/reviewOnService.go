@@ -3,6 +3,8 @@ package repository
 import (
 	"ithozyeva/database"
 	"ithozyeva/internal/models"
+
+	"gorm.io/gorm"
 )
 
 type ReviewOnServiceRepository struct {
@@ -19,14 +21,22 @@ func (r *ReviewOnServiceRepository) Search(limit *int, offset *int, filter *Sear
 	var reviews []models.ReviewOnService
 	var count int64
 
+	base := database.DB.Model(&models.ReviewOnService{})
+	if filter != nil {
+		for key, value := range *filter {
+			base = base.Where(key, value)
+		}
+	}
+
 	// Сначала считаем общее количество всех записей
-	if err := database.DB.Model(&models.ReviewOnService{}).Count(&count).Error; err != nil {
+	if err := base.Session(&gorm.Session{}).Count(&count).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// Создаем запрос с предзагрузкой связанных данных
-	query := database.DB.Model(&models.ReviewOnService{}).
-		Preload("Service")
+	query := base.
+		Preload("Service").
+		Preload("Member")
 
 	// Применяем limit только если он передан
 	if limit != nil {
@@ -60,11 +70,14 @@ func (r *ReviewOnServiceRepository) GetReviewsWithMentorInfo(limit *int, offset
 			m.username as mentor_name,
 			r.author, 
 			r.text, 
-			r.date
+			r.date,
+			r.rating,
+			r."memberId" IS NOT NULL as verified
 		`).
 		Joins(`JOIN services s ON r."serviceId" = s.id`).
 		Joins(`JOIN mentors mt ON s."ownerId" = mt.id`).
-		Joins(`JOIN members m ON mt."memberId" = m.id`)
+		Joins(`JOIN members m ON mt."memberId" = m.id`).
+		Where("r.status = ?", models.ReviewOnServiceStatusApproved)
 
 	// Сначала считаем общее количество
 	if err := query.Count(&count).Error; err != nil {
@@ -90,7 +103,7 @@ func (r *ReviewOnServiceRepository) GetReviewsWithMentorInfo(limit *int, offset
 // GetById получает отзыв по ID с информацией о услуге
 func (r *ReviewOnServiceRepository) GetById(id int64) (*models.ReviewOnService, error) {
 	var review models.ReviewOnService
-	if err := database.DB.Preload("Service").First(&review, id).Error; err != nil {
+	if err := database.DB.Preload("Service").Preload("Member").First(&review, id).Error; err != nil {
 		return nil, err
 	}
 	return &review, nil
@@ -116,3 +129,28 @@ func (r *ReviewOnServiceRepository) CreateReview(review *models.ReviewOnServiceR
 
 	return newReview, nil
 }
+
+// ExistsForBooking проверяет, оставлен ли уже отзыв по итогам встречи
+func (r *ReviewOnServiceRepository) ExistsForBooking(bookingId int64) (bool, error) {
+	var count int64
+	err := database.DB.Model(&models.ReviewOnService{}).
+		Where("\"bookingId\" = ?", bookingId).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetMentorRatings считает среднюю оценку менторов по одобренным отзывам на их услуги
+func (r *ReviewOnServiceRepository) GetMentorRatings(mentorIds []int64) ([]models.MentorRating, error) {
+	var ratings []models.MentorRating
+	if len(mentorIds) == 0 {
+		return ratings, nil
+	}
+
+	err := database.DB.Table("\"reviewOnService\" AS r").
+		Select(`s."ownerId" AS mentor_id, AVG(r.rating) AS rating, COUNT(r.rating) AS reviews_count`).
+		Joins(`JOIN services s ON r."serviceId" = s.id`).
+		Where(`s."ownerId" IN ? AND r.status = ? AND r.rating IS NOT NULL`, mentorIds, models.ReviewOnServiceStatusApproved).
+		Group(`s."ownerId"`).
+		Scan(&ratings).Error
+	return ratings, err
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"ithozyeva/database"
+	"ithozyeva/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SavedSearchRepository struct {
+	BaseRepository[models.SavedSearch]
+}
+
+func NewSavedSearchRepository() *SavedSearchRepository {
+	return &SavedSearchRepository{
+		BaseRepository: NewBaseRepository(database.DB, &models.SavedSearch{}),
+	}
+}
+
+// GetByMember возвращает сохраненные поиски участника
+func (r *SavedSearchRepository) GetByMember(memberId int64) ([]models.SavedSearch, error) {
+	var searches []models.SavedSearch
+	err := database.DB.Where("member_id = ?", memberId).Order("created_at DESC").Find(&searches).Error
+	return searches, err
+}
+
+// CountByMember количество сохраненных поисков участника
+func (r *SavedSearchRepository) CountByMember(memberId int64) (int64, error) {
+	var count int64
+	err := database.DB.Model(&models.SavedSearch{}).Where("member_id = ?", memberId).Count(&count).Error
+	return count, err
+}
+
+// Update меняет название, условия и частоту поиска
+func (r *SavedSearchRepository) Update(search *models.SavedSearch) (*models.SavedSearch, error) {
+	err := database.DB.Model(search).Select("name", "filter", "frequency").Updates(search).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(search.Id)
+}
+
+// GetDue возвращает поиски, которые пора проверить: мгновенные всегда, ежедневные не чаще раза в dailyBefore
+func (r *SavedSearchRepository) GetDue(dailyBefore time.Time) ([]models.SavedSearch, error) {
+	var searches []models.SavedSearch
+	err := database.DB.Preload("Member").
+		Where("frequency = ? OR (frequency = ? AND checked_at <= ?)", models.SavedSearchInstant, models.SavedSearchDaily, dailyBefore).
+		Order("id").
+		Find(&searches).Error
+	return searches, err
+}
+
+// MarkChecked сдвигает границу просмотренных результатов. notified отмечает, что участнику отправлено сообщение
+func (r *SavedSearchRepository) MarkChecked(id int64, checkedAt time.Time, notified bool) error {
+	updates := map[string]interface{}{"checked_at": checkedAt}
+	if notified {
+		updates["last_notified_at"] = checkedAt
+	}
+	return database.DB.Model(&models.SavedSearch{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// DeleteForMember удаляет поиск участника
+func (r *SavedSearchRepository) DeleteForMember(id int64, memberId int64) error {
+	result := database.DB.Where("id = ? AND member_id = ?", id, memberId).Delete(&models.SavedSearch{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"errors"
+	"ithozyeva/database"
+	"ithozyeva/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrVacancyChanged статус вакансии изменился, пока ее рассматривали
+var ErrVacancyChanged = errors.New("вакансия уже изменилась, обновите страницу")
+
+type VacancyRepository struct {
+	BaseRepository[models.Vacancy]
+}
+
+func NewVacancyRepository() *VacancyRepository {
+	return &VacancyRepository{
+		BaseRepository: NewBaseRepository(database.DB, &models.Vacancy{}),
+	}
+}
+
+// SearchPublished ищет опубликованные и не истекшие вакансии. Автор подгружается только для участников платформы
+func (r *VacancyRepository) SearchPublished(filter *models.VacancySearchRequest, now time.Time, withAuthor bool) ([]models.Vacancy, int64, error) {
+	var vacancies []models.Vacancy
+	var count int64
+
+	query := database.DB.Model(&models.Vacancy{}).
+		Where("status = ? AND expires_at > ?", models.VacancyPublished, now)
+	if len(filter.TagIds) > 0 {
+		query = query.Where("id IN (SELECT vacancy_id FROM vacancy_tags WHERE prof_tag_id IN ?)", filter.TagIds)
+	}
+	if filter.Grade != "" {
+		query = query.Where("LOWER(grade) = LOWER(?)", filter.Grade)
+	}
+	if filter.WorkFormat != "" {
+		query = query.Where("work_format = ?", filter.WorkFormat)
+	}
+	if filter.CompanyId != nil {
+		query = query.Where("company_id = ?", *filter.CompanyId)
+	}
+	if filter.Company != "" {
+		query = query.Where("company ILIKE ?", "%"+filter.Company+"%")
+	}
+	if filter.SalaryMin != nil {
+		query = query.Where("COALESCE(salary_to, salary_from) >= ?", *filter.SalaryMin)
+	}
+	if filter.PublishedAfter != nil {
+		query = query.Where("reviewed_at > ?", *filter.PublishedAfter)
+	}
+	if filter.PublishedBefore != nil {
+		query = query.Where("reviewed_at <= ?", *filter.PublishedBefore)
+	}
+
+	if err := query.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Limit != nil {
+		query = query.Limit(*filter.Limit)
+	}
+	if filter.Offset != nil {
+		query = query.Offset(*filter.Offset)
+	}
+	if withAuthor {
+		query = query.Preload("Author")
+	}
+
+	err := query.Preload("ProfTags").Preload("CompanyInfo").
+		Order("reviewed_at DESC, id DESC").
+		Find(&vacancies).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return vacancies, count, nil
+}
+
+// Search очередь модерации и список вакансий для администраторов
+func (r *VacancyRepository) Search(limit *int, offset *int, filter *SearchFilter, order *Order) ([]models.Vacancy, int64, error) {
+	var vacancies []models.Vacancy
+	var count int64
+
+	query := database.DB.Model(&models.Vacancy{})
+	if filter != nil {
+		for key, value := range *filter {
+			query = query.Where(key, value)
+		}
+	}
+
+	if err := query.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if limit != nil {
+		query = query.Limit(*limit)
+	}
+	if offset != nil {
+		query = query.Offset(*offset)
+	}
+
+	err := query.Preload("Author").Preload("ProfTags").Preload("CompanyInfo").
+		Order("created_at ASC").
+		Find(&vacancies).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return vacancies, count, nil
+}
+
+func (r *VacancyRepository) GetById(id int64) (*models.Vacancy, error) {
+	var vacancy models.Vacancy
+	err := database.DB.Preload("Author").Preload("ProfTags").Preload("CompanyInfo").First(&vacancy, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &vacancy, nil
+}
+
+// GetByAuthor возвращает все вакансии автора, включая закрытые
+func (r *VacancyRepository) GetByAuthor(authorId int64) ([]models.Vacancy, error) {
+	var vacancies []models.Vacancy
+	err := database.DB.Preload("ProfTags").Preload("CompanyInfo").
+		Where("author_id = ?", authorId).
+		Order("created_at DESC").
+		Find(&vacancies).Error
+	return vacancies, err
+}
+
+// Create сохраняет вакансию, теги берутся из справочника по id
+func (r *VacancyRepository) Create(vacancy *models.Vacancy) (*models.Vacancy, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		tags, err := findProfTags(tx, vacancy.ProfTags)
+		if err != nil {
+			return err
+		}
+		vacancy.ProfTags = nil
+
+		if err := tx.Omit("Author", "CompanyInfo").Create(vacancy).Error; err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+		return tx.Model(vacancy).Association("ProfTags").Append(tags)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(vacancy.Id)
+}
+
+// Update сохраняет изменения автора и заменяет теги. Вакансия снова уходит на модерацию
+func (r *VacancyRepository) Update(vacancy *models.Vacancy) (*models.Vacancy, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		tags, err := findProfTags(tx, vacancy.ProfTags)
+		if err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.Vacancy{}).Where("id = ?", vacancy.Id).Updates(map[string]interface{}{
+			"title":          vacancy.Title,
+			"company_id":     vacancy.CompanyId,
+			"company":        vacancy.Company,
+			"grade":          vacancy.Grade,
+			"work_format":    vacancy.WorkFormat,
+			"salary_from":    vacancy.SalaryFrom,
+			"salary_to":      vacancy.SalaryTo,
+			"description":    vacancy.Description,
+			"expires_at":     vacancy.ExpiresAt,
+			"status":         vacancy.Status,
+			"review_comment": "",
+			"reviewed_at":    nil,
+			"updated_at":     time.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Model(&models.Vacancy{Id: vacancy.Id}).Association("ProfTags").Replace(tags)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(vacancy.Id)
+}
+
+func findProfTags(tx *gorm.DB, requested []models.ProfTag) ([]models.ProfTag, error) {
+	tagIds := make([]int64, 0, len(requested))
+	for _, tag := range requested {
+		tagIds = append(tagIds, tag.Id)
+	}
+	if len(tagIds) == 0 {
+		return []models.ProfTag{}, nil
+	}
+
+	var tags []models.ProfTag
+	err := tx.Where("id IN ?", tagIds).Find(&tags).Error
+	return tags, err
+}
+
+// SetStatus меняет статус, только если вакансия все еще в статусе from
+func (r *VacancyRepository) SetStatus(id int64, from models.VacancyStatus, to models.VacancyStatus, comment string) (*models.Vacancy, error) {
+	updates := map[string]interface{}{
+		"status":     to,
+		"updated_at": time.Now(),
+	}
+	if from == models.VacancyPending {
+		updates["review_comment"] = comment
+		updates["reviewed_at"] = time.Now()
+	}
+
+	result := database.DB.Model(&models.Vacancy{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(updates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrVacancyChanged
+	}
+	return r.GetById(id)
+}
+
+// GetUnannounced возвращает опубликованные вакансии, которые бот еще не разослал подписчикам
+func (r *VacancyRepository) GetUnannounced(now time.Time) ([]models.Vacancy, error) {
+	var vacancies []models.Vacancy
+	err := database.DB.Preload("ProfTags").Preload("CompanyInfo").
+		Where("status = ? AND announced_at IS NULL AND expires_at > ?", models.VacancyPublished, now).
+		Order("reviewed_at ASC").
+		Find(&vacancies).Error
+	return vacancies, err
+}
+
+// MarkAnnounced отмечает, что вакансия разослана
+func (r *VacancyRepository) MarkAnnounced(id int64, announcedAt time.Time) error {
+	return database.DB.Model(&models.Vacancy{}).
+		Where("id = ?", id).
+		Update("announced_at", announcedAt).Error
+}
+
+// GetTagSubscribers возвращает участников с Telegram, подписанных хотя бы на один тег вакансии, кроме ее автора
+func (r *VacancyRepository) GetTagSubscribers(vacancy *models.Vacancy) ([]models.Member, error) {
+	tagIds := make([]int64, 0, len(vacancy.ProfTags))
+	for _, tag := range vacancy.ProfTags {
+		tagIds = append(tagIds, tag.Id)
+	}
+	if len(tagIds) == 0 {
+		return []models.Member{}, nil
+	}
+
+	var members []models.Member
+	err := database.DB.
+		Where("id IN (SELECT member_id FROM vacancy_subscriptions WHERE prof_tag_id IN ?)", tagIds).
+		Where("id <> ? AND telegram_id IS NOT NULL AND telegram_id <> 0", vacancy.AuthorId).
+		Find(&members).Error
+	return members, err
+}
+
+// GetSubscriptionTags возвращает теги, на которые подписан участник
+func (r *VacancyRepository) GetSubscriptionTags(memberId int64) ([]models.ProfTag, error) {
+	var tags []models.ProfTag
+	err := database.DB.
+		Where("id IN (SELECT prof_tag_id FROM vacancy_subscriptions WHERE member_id = ?)", memberId).
+		Order("title").
+		Find(&tags).Error
+	return tags, err
+}
+
+// SetSubscriptionTags заменяет теги подписки участника
+func (r *VacancyRepository) SetSubscriptionTags(memberId int64, tagIds []int64) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("member_id = ?", memberId).Delete(&models.VacancySubscription{}).Error; err != nil {
+			return err
+		}
+		if len(tagIds) == 0 {
+			return nil
+		}
+
+		var existing []int64
+		if err := tx.Model(&models.ProfTag{}).Where("id IN ?", tagIds).Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+		if len(existing) == 0 {
+			return nil
+		}
+
+		subscriptions := make([]models.VacancySubscription, 0, len(existing))
+		for _, tagId := range existing {
+			subscriptions = append(subscriptions, models.VacancySubscription{MemberId: memberId, ProfTagId: tagId})
+		}
+		return tx.Create(&subscriptions).Error
+	})
+}
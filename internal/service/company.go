@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/repository"
+	"ithozyeva/internal/utils"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// companyLogoTTL время жизни временной ссылки на логотип компании
+const companyLogoTTL = 24 * time.Hour
+
+var (
+	ErrCompanyNameRequired = errors.New("укажите название компании")
+	ErrCompanyInvalidLogo  = errors.New("логотип должен быть в формате png, jpg, svg или webp")
+	ErrCompanyNoLogo       = errors.New("у компании нет логотипа")
+	ErrCompanyMergeSelf    = errors.New("нельзя объединить компанию саму с собой")
+)
+
+var companyLogoExtensions = map[string]bool{
+	".png":  true,
+	".jpg":  true,
+	".jpeg": true,
+	".svg":  true,
+	".webp": true,
+}
+
+type CompanyService struct {
+	BaseService[models.Company]
+	repo *repository.CompanyRepository
+}
+
+func NewCompanyService() *CompanyService {
+	repo := repository.NewCompanyRepository()
+	return &CompanyService{
+		BaseService: NewBaseService[models.Company](repo),
+		repo:        repo,
+	}
+}
+
+// SearchByName ищет компании по названию и алиасам
+func (s *CompanyService) SearchByName(name string, limit *int, offset *int) (*models.RegistrySearch[models.Company], error) {
+	items, total, err := s.repo.SearchByName(models.NormalizeCompanyName(name), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RegistrySearch[models.Company]{
+		Items: items,
+		Total: int(total),
+	}, nil
+}
+
+// Resolve находит компанию по id или по названию и алиасам. Если названия нет в справочнике, компания создается
+func (s *CompanyService) Resolve(companyId *int64, name string) (*models.Company, error) {
+	if companyId != nil {
+		return s.repo.GetById(*companyId)
+	}
+
+	name = strings.TrimSpace(name)
+	normalized := models.NormalizeCompanyName(name)
+	if normalized == "" {
+		return nil, ErrCompanyNameRequired
+	}
+
+	company, err := s.repo.FindByName(normalized)
+	if err == nil {
+		return company, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	company, err = s.repo.Create(&models.Company{Name: name, NormalizedName: normalized})
+	if errors.Is(err, repository.ErrCompanyNameTaken) {
+		// Компанию с тем же названием могли создать параллельно
+		return s.repo.FindByName(normalized)
+	}
+	return company, err
+}
+
+// CreateCompany добавляет компанию в справочник
+func (s *CompanyService) CreateCompany(req *models.CompanyRequest) (*models.Company, error) {
+	company := &models.Company{
+		Name:    strings.TrimSpace(req.Name),
+		Website: strings.TrimSpace(req.Website),
+	}
+	company.NormalizedName = models.NormalizeCompanyName(company.Name)
+	if company.NormalizedName == "" {
+		return nil, ErrCompanyNameRequired
+	}
+
+	seen := map[string]bool{company.NormalizedName: true}
+	for _, alias := range req.Aliases {
+		normalized := models.NormalizeCompanyName(alias)
+		if normalized == "" || seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		company.Aliases = append(company.Aliases, models.CompanyAlias{
+			Alias:           strings.TrimSpace(alias),
+			NormalizedAlias: normalized,
+		})
+	}
+
+	return s.repo.Create(company)
+}
+
+// UpdateCompany меняет название и сайт компании
+func (s *CompanyService) UpdateCompany(id int64, req *models.CompanyRequest) (*models.Company, error) {
+	company := &models.Company{
+		Id:      id,
+		Name:    strings.TrimSpace(req.Name),
+		Website: strings.TrimSpace(req.Website),
+	}
+	company.NormalizedName = models.NormalizeCompanyName(company.Name)
+	if company.NormalizedName == "" {
+		return nil, ErrCompanyNameRequired
+	}
+	return s.repo.Update(company)
+}
+
+// AddAlias добавляет другое написание названия компании
+func (s *CompanyService) AddAlias(id int64, alias string) (*models.Company, error) {
+	if _, err := s.repo.GetById(id); err != nil {
+		return nil, err
+	}
+
+	alias = strings.TrimSpace(alias)
+	normalized := models.NormalizeCompanyName(alias)
+	if normalized == "" {
+		return nil, ErrCompanyNameRequired
+	}
+	return s.repo.AddAlias(&models.CompanyAlias{
+		CompanyId:       id,
+		Alias:           alias,
+		NormalizedAlias: normalized,
+	})
+}
+
+// DeleteAlias удаляет алиас компании
+func (s *CompanyService) DeleteAlias(id int64, aliasId int64) (*models.Company, error) {
+	return s.repo.DeleteAlias(id, aliasId)
+}
+
+// Merge объединяет дубль sourceId с компанией targetId
+func (s *CompanyService) Merge(targetId int64, sourceId int64) (*models.Company, error) {
+	if targetId == sourceId {
+		return nil, ErrCompanyMergeSelf
+	}
+
+	source, err := s.repo.GetById(sourceId)
+	if err != nil {
+		return nil, err
+	}
+
+	company, err := s.repo.Merge(sourceId, targetId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Логотип дубля, который не перешел к компании, больше не нужен
+	if source.HasLogo && (company.LogoPath == nil || *company.LogoPath != *source.LogoPath) {
+		s.deleteLogoFile(*source.LogoPath)
+	}
+	return company, nil
+}
+
+// GetOverview возвращает компанию с актуальными реферальными ссылками и участниками
+func (s *CompanyService) GetOverview(id int64) (*models.CompanyOverview, error) {
+	company, err := s.repo.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	links, _, err := repository.NewReferalLinkRepository().SearchLinks(&models.ReferalLinkSearchRequest{CompanyId: &id})
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.GetMembers(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CompanyOverview{
+		Company: company,
+		Links:   links,
+		Members: members,
+	}, nil
+}
+
+// SetMemberCompany меняет место работы участника. Пустой запрос очищает его
+func (s *CompanyService) SetMemberCompany(member *models.Member, req *models.SetMemberCompanyRequest) (*models.Company, error) {
+	if req.CompanyId == nil && strings.TrimSpace(req.Name) == "" {
+		if err := s.repo.SetMemberCompany(member.Id, nil); err != nil {
+			return nil, err
+		}
+		member.CompanyId = nil
+		return nil, nil
+	}
+
+	company, err := s.Resolve(req.CompanyId, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetMemberCompany(member.Id, &company.Id); err != nil {
+		return nil, err
+	}
+	member.CompanyId = &company.Id
+	return company, nil
+}
+
+// GetLogoURL выдает временную ссылку на логотип компании
+func (s *CompanyService) GetLogoURL(id int64) (string, error) {
+	company, err := s.repo.GetById(id)
+	if err != nil {
+		return "", err
+	}
+	if !company.HasLogo {
+		return "", ErrCompanyNoLogo
+	}
+
+	client, err := utils.NewS3Client()
+	if err != nil {
+		return "", err
+	}
+	return client.PresignGet(context.Background(), *company.LogoPath, companyLogoTTL)
+}
+
+// UploadLogo загружает логотип в S3 и заменяет прежний
+func (s *CompanyService) UploadLogo(id int64, fileName string, contentType string, content []byte) (*models.Company, error) {
+	company, err := s.repo.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if !companyLogoExtensions[ext] {
+		return nil, ErrCompanyInvalidLogo
+	}
+
+	client, err := utils.NewS3Client()
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("companies/%d/%s%s", id, uuid.NewString(), ext)
+	if err := client.Upload(context.Background(), key, content, contentType); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetLogo(id, &key); err != nil {
+		_ = client.Delete(context.Background(), key)
+		return nil, err
+	}
+
+	if company.HasLogo {
+		s.deleteLogoFile(*company.LogoPath)
+	}
+
+	return s.repo.GetById(id)
+}
+
+// DeleteLogo удаляет логотип компании
+func (s *CompanyService) DeleteLogo(id int64) (*models.Company, error) {
+	company, err := s.repo.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	if !company.HasLogo {
+		return nil, ErrCompanyNoLogo
+	}
+
+	if err := s.repo.SetLogo(id, nil); err != nil {
+		return nil, err
+	}
+	s.deleteLogoFile(*company.LogoPath)
+
+	return s.repo.GetById(id)
+}
+
+// Delete удаляет компанию, ссылки и участники остаются без привязки к справочнику
+func (s *CompanyService) Delete(company *models.Company) error {
+	existing, err := s.repo.GetById(company.Id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(company); err != nil {
+		return err
+	}
+	if existing.HasLogo {
+		s.deleteLogoFile(*existing.LogoPath)
+	}
+	return nil
+}
+
+func (s *CompanyService) deleteLogoFile(key string) {
+	client, err := utils.NewS3Client()
+	if err != nil {
+		log.Printf("Error deleting company logo %s: %v", key, err)
+		return
+	}
+	if err := client.Delete(context.Background(), key); err != nil {
+		log.Printf("Error deleting company logo %s: %v", key, err)
+	}
+}
@@ -40,6 +40,11 @@ func (s *EventAlertSubscriptionService) GetSubscribedMembersForEvent(eventId int
 	return s.repo.GetSubscribedMembersForEvent(eventId)
 }
 
+// GetSubscribedMembersForOccurrence получает всех пользователей, подписанных на алерты повторения события
+func (s *EventAlertSubscriptionService) GetSubscribedMembersForOccurrence(eventId int64, occurrenceId int64) ([]models.Member, error) {
+	return s.repo.GetSubscribedMembersForOccurrence(eventId, occurrenceId)
+}
+
 // GetPendingSubscriptionsForEvent получает все подписки со статусом PENDING для события
 func (s *EventAlertSubscriptionService) GetPendingSubscriptionsForEvent(eventId int64) ([]models.EventAlertSubscription, error) {
 	return s.repo.GetPendingSubscriptionsForEvent(eventId)
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"errors"
+	"ithozyeva/config"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/repository"
+	"ithozyeva/internal/utils"
+	"time"
+)
+
+var (
+	ErrCheckInUnavailable = errors.New("отметка доступна только для офлайн и гибридных событий")
+	ErrCheckInClosed      = errors.New("отметка на это событие сейчас недоступна")
+	ErrNotEventApplicant  = errors.New("участник не записан на событие")
+	ErrNotEventHost       = errors.New("действие доступно только ведущим события")
+	ErrAlreadyCheckedIn   = errors.New("участник уже отмечен на событии")
+)
+
+const (
+	// checkInOpensBefore за сколько до начала события открывается отметка
+	checkInOpensBefore = 3 * time.Hour
+	// checkInClosesAfter сколько после начала события еще можно отметиться
+	checkInClosesAfter = 12 * time.Hour
+	// checkInPassesAhead на сколько вперед бот выдает коды по команде /checkin
+	checkInPassesAhead = 24 * time.Hour
+)
+
+// CheckInPass код отметки участника на конкретное проведение события
+type CheckInPass struct {
+	Event models.Event
+	Code  string
+}
+
+// checkInTarget проведение события, к которому относятся код и отметка
+type checkInTarget struct {
+	event      *models.Event
+	instance   *utils.EventInstance
+	occurrence *models.EventOccurrence
+}
+
+type EventCheckInService struct {
+	repo       *repository.EventAttendanceRepository
+	eventRepo  *repository.EventRepository
+	memberRepo *repository.MemberRepository
+}
+
+func NewEventCheckInService() *EventCheckInService {
+	return &EventCheckInService{
+		repo:       repository.NewEventAttendanceRepository(),
+		eventRepo:  repository.NewEventRepository(),
+		memberRepo: repository.NewMemberRepository(),
+	}
+}
+
+// GetCode выдает участнику подписанный код отметки на событие. Для повторяющегося события
+// date указывает повторение, по умолчанию берется текущее или ближайшее
+func (s *EventCheckInService) GetCode(eventId int64, date *time.Time, memberId int64) (*CheckInPass, error) {
+	event, err := s.eventRepo.GetById(eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.resolveTarget(event, date, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !target.hasApplicant(memberId) {
+		return nil, ErrNotEventApplicant
+	}
+
+	return target.pass(memberId), nil
+}
+
+// GetMemberPasses выдает коды отметки на офлайн-события участника, которые идут сейчас или начнутся в ближайшие сутки
+func (s *EventCheckInService) GetMemberPasses(memberId int64, now time.Time) ([]CheckInPass, error) {
+	from, to := now.Add(-checkInClosesAfter), now.Add(checkInPassesAhead)
+
+	events, err := s.eventRepo.GetMemberCheckInEvents(memberId, from, to)
+	if err != nil {
+		return nil, err
+	}
+	occurrences, err := s.eventRepo.GetMemberCheckInOccurrences(memberId, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	passes := make([]CheckInPass, 0, len(events)+len(occurrences))
+	for i := range events {
+		target := checkInTarget{
+			event:    &events[i],
+			instance: &utils.EventInstance{RecurrenceId: events[i].Date, Date: events[i].Date},
+		}
+		passes = append(passes, *target.pass(memberId))
+	}
+	for i := range occurrences {
+		occurrence := &occurrences[i]
+		if occurrence.Event == nil {
+			continue
+		}
+		instance := &utils.EventInstance{RecurrenceId: occurrence.OccurrenceDate, Date: occurrence.OccurrenceDate, Override: occurrence}
+		if occurrence.OverrideDate != nil {
+			instance.Date = *occurrence.OverrideDate
+		}
+		target := checkInTarget{event: occurrence.Event, instance: instance, occurrence: occurrence}
+		passes = append(passes, *target.pass(memberId))
+	}
+
+	return passes, nil
+}
+
+// CheckIn отмечает участника по коду. Отмечать может ведущий события или повторения
+// либо участник с правом редактирования событий
+func (s *EventCheckInService) CheckIn(eventId int64, host *models.Member, value string) (*models.EventAttendance, error) {
+	code, err := utils.ParseCheckInCode(config.CFG.JwtSecret, value)
+	if err != nil {
+		return nil, err
+	}
+	if code.EventId != eventId {
+		return nil, utils.ErrInvalidCheckInCode
+	}
+
+	event, err := s.eventRepo.GetById(eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	target, err := s.resolveCodeTarget(event, code)
+	if err != nil {
+		return nil, err
+	}
+	if !s.canManage(target, host) {
+		return nil, ErrNotEventHost
+	}
+	if !checkInOpen(target.instance.Date, now) {
+		return nil, ErrCheckInClosed
+	}
+	if !target.hasApplicant(code.MemberId) {
+		return nil, ErrNotEventApplicant
+	}
+
+	attendance := &models.EventAttendance{
+		EventId:     event.Id,
+		MemberId:    code.MemberId,
+		CheckedInBy: host.Id,
+		CheckedInAt: now,
+	}
+	if target.occurrence != nil {
+		attendance.OccurrenceId = &target.occurrence.Id
+	}
+
+	created, err := s.repo.CheckIn(attendance)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrAlreadyCheckedIn
+	}
+
+	for _, member := range target.applicants() {
+		if member.Id == code.MemberId {
+			attendance.Member = member
+			break
+		}
+	}
+	return attendance, nil
+}
+
+// GetReport возвращает отчет о посещаемости: отмеченных участников и тех, кто записался, но не пришел
+func (s *EventCheckInService) GetReport(eventId int64, date *time.Time, host *models.Member) (*models.EventAttendanceReport, error) {
+	event, err := s.eventRepo.GetById(eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.resolveTarget(event, date, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !s.canManage(target, host) {
+		return nil, ErrNotEventHost
+	}
+
+	report := &models.EventAttendanceReport{
+		EventId:   event.Id,
+		Date:      target.instance.Date,
+		Attendees: []models.EventAttendance{},
+		NoShows:   []models.Member{},
+	}
+
+	// По повторению, на которое еще никто не записывался, отчет пустой
+	if event.IsRecurring() && target.occurrence == nil {
+		return report, nil
+	}
+	if target.occurrence != nil {
+		report.OccurrenceId = &target.occurrence.Id
+	}
+
+	attendances, err := s.repo.GetByEvent(event.Id, report.OccurrenceId)
+	if err != nil {
+		return nil, err
+	}
+	report.Attendees = attendances
+
+	attended := make(map[int64]bool, len(attendances))
+	for _, attendance := range attendances {
+		attended[attendance.MemberId] = true
+	}
+
+	applicants := target.applicants()
+	report.Applied = len(applicants)
+	report.Attended = len(attendances)
+	for _, member := range applicants {
+		if !attended[member.Id] {
+			report.NoShows = append(report.NoShows, member)
+		}
+	}
+
+	return report, nil
+}
+
+// resolveTarget находит проведение события по дате. Без даты берется текущее или ближайшее проведение
+func (s *EventCheckInService) resolveTarget(event *models.Event, date *time.Time, now time.Time) (*checkInTarget, error) {
+	if event.PlaceType != models.EventOffline && event.PlaceType != models.EventHybrid {
+		return nil, ErrCheckInUnavailable
+	}
+
+	if !event.IsRecurring() {
+		return &checkInTarget{event: event, instance: &utils.EventInstance{RecurrenceId: event.Date, Date: event.Date}}, nil
+	}
+
+	var instance *utils.EventInstance
+	if date == nil {
+		instance = utils.NextEventInstance(event, now.Add(-checkInClosesAfter))
+	} else {
+		instance, _ = utils.FindEventInstance(event, *date)
+	}
+	if instance == nil {
+		return nil, ErrInvalidOccurrence
+	}
+
+	return s.withOccurrence(event, instance)
+}
+
+// resolveCodeTarget находит проведение события, на которое выдан код
+func (s *EventCheckInService) resolveCodeTarget(event *models.Event, code *utils.CheckInCode) (*checkInTarget, error) {
+	if code.RecurrenceId == nil || !event.IsRecurring() {
+		if code.RecurrenceId != nil || event.IsRecurring() {
+			return nil, utils.ErrInvalidCheckInCode
+		}
+		return s.resolveTarget(event, nil, time.Now())
+	}
+
+	if event.PlaceType != models.EventOffline && event.PlaceType != models.EventHybrid {
+		return nil, ErrCheckInUnavailable
+	}
+
+	instance, ok := utils.FindEventInstance(event, *code.RecurrenceId)
+	if !ok || !instance.RecurrenceId.Equal(*code.RecurrenceId) {
+		return nil, utils.ErrInvalidCheckInCode
+	}
+
+	return s.withOccurrence(event, instance)
+}
+
+// withOccurrence дополняет проведение сохраненной записью о повторении, если она есть
+func (s *EventCheckInService) withOccurrence(event *models.Event, instance *utils.EventInstance) (*checkInTarget, error) {
+	target := &checkInTarget{event: event, instance: instance}
+
+	occurrences, err := s.eventRepo.GetOccurrencesByDates(event.Id, []time.Time{instance.RecurrenceId})
+	if err != nil {
+		return nil, err
+	}
+	if len(occurrences) > 0 {
+		target.occurrence = &occurrences[0]
+	}
+
+	return target, nil
+}
+
+// canManage проверяет, что участник ведет событие или повторение либо может редактировать события
+func (s *EventCheckInService) canManage(target *checkInTarget, member *models.Member) bool {
+	hosts := target.event.Hosts
+	if target.occurrence != nil {
+		hosts = append(append([]models.Member{}, hosts...), target.occurrence.Hosts...)
+	}
+	for _, host := range hosts {
+		if host.Id == member.Id {
+			return true
+		}
+	}
+	return s.memberRepo.HasPermission(member.Id, models.PermissionCanEditAdminEvents)
+}
+
+// checkInOpen проверяет, что отметка на проведение с датой start открыта в момент now.
+// Сам код не истекает, поэтому старый код принимается только в окне своего проведения
+func checkInOpen(start, now time.Time) bool {
+	return !now.Before(start.Add(-checkInOpensBefore)) && !now.After(start.Add(checkInClosesAfter))
+}
+
+// applicants возвращает участников, записанных на проведение
+func (t *checkInTarget) applicants() []models.Member {
+	if !t.event.IsRecurring() {
+		return t.event.Members
+	}
+	if t.occurrence == nil {
+		return nil
+	}
+	return t.occurrence.Members
+}
+
+func (t *checkInTarget) hasApplicant(memberId int64) bool {
+	for _, member := range t.applicants() {
+		if member.Id == memberId {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *checkInTarget) pass(memberId int64) *CheckInPass {
+	code := utils.CheckInCode{EventId: t.event.Id, MemberId: memberId}
+	if t.event.IsRecurring() {
+		recurrenceId := t.instance.RecurrenceId.UTC()
+		code.RecurrenceId = &recurrenceId
+	}
+
+	return &CheckInPass{
+		Event: utils.EventForInstance(t.event, t.instance),
+		Code:  utils.GenerateCheckInCode(config.CFG.JwtSecret, code),
+	}
+}
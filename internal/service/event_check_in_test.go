@@ -0,0 +1,31 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckInOpen(t *testing.T) {
+	start := time.Date(2026, 10, 19, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want bool
+	}{
+		{name: "слишком рано", now: start.Add(-checkInOpensBefore - time.Second), want: false},
+		{name: "открытие окна", now: start.Add(-checkInOpensBefore), want: true},
+		{name: "начало события", now: start, want: true},
+		{name: "закрытие окна", now: start.Add(checkInClosesAfter), want: true},
+		{name: "код истек", now: start.Add(checkInClosesAfter + time.Second), want: false},
+		{name: "код прошлого повторения", now: start.AddDate(0, 0, 7), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkInOpen(start, tt.now); got != tt.want {
+				t.Errorf("checkInOpen(%s) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"errors"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/repository"
+	"strings"
+	"time"
+)
+
+var (
+	ErrFeedbackNotFound = errors.New("запрос отзыва не найден")
+	ErrInvalidRating    = errors.New("оценка должна быть от 1 до 5")
+	ErrFeedbackAccess   = errors.New("отзывы доступны только ведущим события и администраторам")
+)
+
+// maxFeedbackCommentLength ограничивает длину комментария к отзыву
+const maxFeedbackCommentLength = 2000
+
+// FeedbackRequest событие или повторение, участникам которого нужно отправить запрос отзыва
+type FeedbackRequest struct {
+	Event      *models.Event
+	Occurrence *models.EventOccurrence
+	Members    []models.Member
+}
+
+type EventFeedbackService struct {
+	repo       *repository.EventFeedbackRepository
+	eventRepo  *repository.EventRepository
+	memberRepo *repository.MemberRepository
+}
+
+func NewEventFeedbackService() *EventFeedbackService {
+	return &EventFeedbackService{
+		repo:       repository.NewEventFeedbackRepository(),
+		eventRepo:  repository.NewEventRepository(),
+		memberRepo: repository.NewMemberRepository(),
+	}
+}
+
+// GetDueRequests возвращает события и повторения, прошедшие не меньше delay назад.
+// Совсем старые события (старше delay + lookback) пропускаются, чтобы не спрашивать о них спустя недели
+func (s *EventFeedbackService) GetDueRequests(now time.Time, delay, lookback time.Duration) ([]FeedbackRequest, error) {
+	to := now.Add(-delay)
+	from := to.Add(-lookback)
+
+	events, err := s.eventRepo.GetFeedbackDueEvents(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences, err := s.eventRepo.GetFeedbackDueOccurrences(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := make([]FeedbackRequest, 0, len(events)+len(occurrences))
+	for i := range events {
+		requests = append(requests, FeedbackRequest{Event: &events[i], Members: events[i].Members})
+	}
+	for i := range occurrences {
+		if occurrences[i].Event == nil {
+			continue
+		}
+		requests = append(requests, FeedbackRequest{
+			Event:      occurrences[i].Event,
+			Occurrence: &occurrences[i],
+			Members:    occurrences[i].Members,
+		})
+	}
+
+	return requests, nil
+}
+
+// CreateRequest создает запись о запросе отзыва. Возвращает nil, если участника уже спрашивали
+func (s *EventFeedbackService) CreateRequest(request *FeedbackRequest, memberId int64) (*models.EventFeedback, error) {
+	feedback := &models.EventFeedback{
+		EventId:  request.Event.Id,
+		MemberId: memberId,
+	}
+	if request.Occurrence != nil {
+		feedback.OccurrenceId = &request.Occurrence.Id
+	}
+
+	created, err := s.repo.CreateRequest(feedback)
+	if err != nil || !created {
+		return nil, err
+	}
+	return feedback, nil
+}
+
+// MarkRequested отмечает, что запрос отзыва по событию или повторению разослан
+func (s *EventFeedbackService) MarkRequested(request *FeedbackRequest, requestedAt time.Time) error {
+	if request.Occurrence != nil {
+		return s.eventRepo.MarkOccurrenceFeedbackRequested(request.Occurrence.Id, requestedAt)
+	}
+	return s.eventRepo.MarkEventFeedbackRequested(request.Event.Id, requestedAt)
+}
+
+// Rate сохраняет оценку участника
+func (s *EventFeedbackService) Rate(feedbackId int64, memberId int64, rating int) (*models.EventFeedback, error) {
+	if rating < 1 || rating > 5 {
+		return nil, ErrInvalidRating
+	}
+
+	feedback, err := s.getOwn(feedbackId, memberId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateAnswer(feedback.Id, map[string]interface{}{"rating": rating, "updated_at": time.Now()}); err != nil {
+		return nil, err
+	}
+	feedback.Rating = &rating
+	return feedback, nil
+}
+
+// Comment сохраняет комментарий участника к отзыву
+func (s *EventFeedbackService) Comment(feedbackId int64, memberId int64, comment string) (*models.EventFeedback, error) {
+	feedback, err := s.getOwn(feedbackId, memberId)
+	if err != nil {
+		return nil, err
+	}
+
+	comment = strings.TrimSpace(comment)
+	if runes := []rune(comment); len(runes) > maxFeedbackCommentLength {
+		comment = string(runes[:maxFeedbackCommentLength])
+	}
+
+	if err := s.repo.UpdateAnswer(feedback.Id, map[string]interface{}{"comment": comment, "updated_at": time.Now()}); err != nil {
+		return nil, err
+	}
+	feedback.Comment = &comment
+	return feedback, nil
+}
+
+// GetReport возвращает сводку отзывов о событии. Участник платформы видит ее, только если ведет событие
+// или может редактировать события; member == nil означает администратора с JWT
+func (s *EventFeedbackService) GetReport(eventId int64, member *models.Member) (*models.EventFeedbackReport, error) {
+	event, err := s.eventRepo.GetById(eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	if member != nil && !s.memberRepo.HasPermission(member.Id, models.PermissionCanEditAdminEvents) {
+		isHost := false
+		for _, host := range event.Hosts {
+			if host.Id == member.Id {
+				isHost = true
+				break
+			}
+		}
+		if !isHost {
+			return nil, ErrFeedbackAccess
+		}
+	}
+
+	return s.repo.GetReport(eventId)
+}
+
+// getOwn получает запрос отзыва, адресованный участнику
+func (s *EventFeedbackService) getOwn(feedbackId int64, memberId int64) (*models.EventFeedback, error) {
+	feedback, err := s.repo.GetById(feedbackId)
+	if err != nil || feedback.MemberId != memberId {
+		return nil, ErrFeedbackNotFound
+	}
+	return feedback, nil
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"errors"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEventNotPublished  = errors.New("событие еще не опубликовано")
+	ErrEventPublished     = errors.New("событие уже опубликовано")
+	ErrEventAlreadyHosted = errors.New("участник уже ведет это событие")
+	ErrLastEventHost      = errors.New("у события должен остаться хотя бы один ведущий")
+	ErrInvitationNotFound = errors.New("приглашение не найдено")
+	ErrInvitationAnswered = errors.New("на приглашение уже ответили")
+)
+
+// EventHostingService управление событиями со стороны ведущих: черновики, редактирование и соведущие
+type EventHostingService struct {
+	events         *EventsService
+	eventRepo      *repository.EventRepository
+	invitationRepo *repository.EventHostInvitationRepository
+	memberRepo     *repository.MemberRepository
+}
+
+func NewEventHostingService() *EventHostingService {
+	return &EventHostingService{
+		events:         NewEventsService(),
+		eventRepo:      repository.NewEventRepository(),
+		invitationRepo: repository.NewEventHostInvitationRepository(),
+		memberRepo:     repository.NewMemberRepository(),
+	}
+}
+
+// CreateDraft создает черновик события. Автор становится ведущим, запись откроется после публикации
+func (s *EventHostingService) CreateDraft(event *models.Event, author *models.Member) (*models.Event, error) {
+	event.Id = 0
+	event.Status = models.EventStatusDraft
+	event.Open = false
+	event.CreatedBy = &author.Id
+	host := *author
+	host.MemberRoles = nil
+	event.Hosts = []models.Member{host}
+	event.Members = nil
+	event.Waitlist = nil
+	event.LastRepeatingAlertSentAt = nil
+	event.FeedbackRequestedAt = nil
+
+	return s.events.Create(event)
+}
+
+// UpdateHosted сохраняет изменения события его ведущим. Ведущие, участники и статус публикации
+// меняются отдельными действиями и из запроса не берутся. Возвращает участников, переведенных
+// из листа ожидания, если вместимость увеличилась
+func (s *EventHostingService) UpdateHosted(event *models.Event, member *models.Member) (*models.Event, []models.Member, error) {
+	existing, err := s.getManaged(event.Id, member)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	event.Status = existing.Status
+	event.CreatedBy = existing.CreatedBy
+	event.Hosts = existing.Hosts
+	event.Members = existing.Members
+	event.LastRepeatingAlertSentAt = existing.LastRepeatingAlertSentAt
+	if existing.IsDraft() {
+		event.Open = false
+	}
+
+	updated, err := s.events.Update(event)
+	if err != nil {
+		return nil, nil, err
+	}
+	if updated.IsDraft() {
+		return updated, nil, nil
+	}
+
+	// Вместимость могла увеличиться — переводим участников из листа ожидания
+	return s.events.PromoteFromWaitlist(updated.Id)
+}
+
+// GetHosted возвращает события, которые ведет участник, включая черновики
+func (s *EventHostingService) GetHosted(memberId int64) ([]models.Event, error) {
+	return s.eventRepo.GetHostedEvents(memberId)
+}
+
+// InviteCoHost приглашает участника стать соведущим события
+func (s *EventHostingService) InviteCoHost(eventId int64, inviter *models.Member, memberId int64) (*models.EventHostInvitation, error) {
+	event, err := s.getManaged(eventId, inviter)
+	if err != nil {
+		return nil, err
+	}
+	if isEventHost(event, memberId) {
+		return nil, ErrEventAlreadyHosted
+	}
+	if _, err := s.memberRepo.GetById(memberId); err != nil {
+		return nil, err
+	}
+
+	return s.invitationRepo.Invite(&models.EventHostInvitation{
+		EventId:     event.Id,
+		MemberId:    memberId,
+		InvitedById: inviter.Id,
+	})
+}
+
+// RemoveCoHost убирает соведущего. Последнего ведущего убрать нельзя
+func (s *EventHostingService) RemoveCoHost(eventId int64, member *models.Member, hostId int64) (*models.Event, error) {
+	event, err := s.getManaged(eventId, member)
+	if err != nil {
+		return nil, err
+	}
+	if !isEventHost(event, hostId) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if len(event.Hosts) <= 1 {
+		return nil, ErrLastEventHost
+	}
+
+	if err := s.eventRepo.RemoveHost(event.Id, hostId); err != nil {
+		return nil, err
+	}
+	return s.eventRepo.GetById(event.Id)
+}
+
+// GetInvitations возвращает приглашения, на которые участник еще не ответил
+func (s *EventHostingService) GetInvitations(memberId int64) ([]models.EventHostInvitation, error) {
+	return s.invitationRepo.GetPendingByMember(memberId)
+}
+
+// RespondInvitation принимает или отклоняет приглашение стать соведущим
+func (s *EventHostingService) RespondInvitation(invitationId int64, memberId int64, accept bool) (*models.EventHostInvitation, error) {
+	invitation, err := s.invitationRepo.GetById(invitationId)
+	if err != nil || invitation.MemberId != memberId {
+		return nil, ErrInvitationNotFound
+	}
+	if invitation.Status != models.EventHostInvitationPending {
+		return nil, ErrInvitationAnswered
+	}
+
+	status := models.EventHostInvitationDeclined
+	if accept {
+		status = models.EventHostInvitationAccepted
+	}
+
+	if err := s.invitationRepo.Respond(invitation, status); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvitationAnswered
+		}
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// Publish публикует черновик после проверки администратором. Если черновик — часть серии,
+// на него сразу записываются участники серии
+func (s *EventHostingService) Publish(eventId int64) (*models.Event, error) {
+	event, err := s.eventRepo.GetById(eventId)
+	if err != nil {
+		return nil, err
+	}
+	if !event.IsDraft() {
+		return nil, ErrEventPublished
+	}
+
+	var seriesPart *repository.SeriesPart
+	if event.SeriesId != nil {
+		seriesPart, _ = upcomingPart(event, time.Now())
+	}
+
+	results, err := s.eventRepo.Publish(event.Id, seriesPart)
+	if err != nil {
+		return nil, err
+	}
+	s.events.subscribeEnrolled(results)
+
+	return s.eventRepo.GetById(event.Id)
+}
+
+// getManaged получает событие, которым может управлять участник: ведущий или администратор.
+// Право редактировать события в админке есть и у EVENT_MAKER, поэтому на чужие события оно не распространяется
+func (s *EventHostingService) getManaged(eventId int64, member *models.Member) (*models.Event, error) {
+	event, err := s.eventRepo.GetById(eventId)
+	if err != nil {
+		return nil, err
+	}
+	if !isEventHost(event, member.Id) && !s.memberRepo.HasRole(member.Id, models.MemberRoleAdmin) {
+		return nil, ErrNotEventHost
+	}
+	return event, nil
+}
+
+func isEventHost(event *models.Event, memberId int64) bool {
+	for _, host := range event.Hosts {
+		if host.Id == memberId {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,373 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/repository"
+	"ithozyeva/internal/utils"
+	"log"
+	"mime"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// recordingLinkTTL время жизни временной ссылки на файл записи в S3
+	recordingLinkTTL = 2 * time.Hour
+	// recordingUploadTTL время жизни ссылки на загрузку файла записи в S3
+	recordingUploadTTL = 30 * time.Minute
+	// maxRecordingSize наибольший размер видео записи в S3
+	maxRecordingSize = 500 * 1024 * 1024
+)
+
+var (
+	ErrRecordingMembersOnly     = errors.New("запись доступна только участникам сообщества")
+	ErrRecordingSubscribersOnly = errors.New("запись доступна только подписчикам")
+	ErrRecordingNoFile          = errors.New("у записи нет загруженного файла")
+	ErrRecordingInvalidVideo    = errors.New("поддерживаются только видео в форматах mp4, webm, mov и mkv")
+	ErrRecordingInvalidAccess   = errors.New("неизвестный уровень доступа к записи")
+	ErrRecordingInvalidChapter  = errors.New("неверная отметка времени главы")
+	ErrRecordingInvalidDate     = errors.New("неверный формат даты (ожидается YYYY-MM-DD)")
+	ErrRecordingTooLarge        = errors.New("файл превышает 500MB")
+	ErrRecordingUploadNotFound  = errors.New("файл не найден, загрузите его по выданной ссылке")
+)
+
+var recordingVideoExtensions = map[string]bool{
+	".mp4":  true,
+	".webm": true,
+	".mov":  true,
+	".mkv":  true,
+}
+
+type EventRecordingService struct {
+	BaseService[models.EventRecording]
+	repo *repository.EventRecordingRepository
+}
+
+func NewEventRecordingService() *EventRecordingService {
+	repo := repository.NewEventRecordingRepository()
+	return &EventRecordingService{
+		BaseService: NewBaseService[models.EventRecording](repo),
+		repo:        repo,
+	}
+}
+
+// AllowedAccess уровни доступа, которые видит пользователь. Без авторизации доступны только публичные записи
+func AllowedAccess(member *models.Member) []models.RecordingAccess {
+	if member == nil {
+		return []models.RecordingAccess{models.RecordingAccessPublic}
+	}
+
+	allowed := []models.RecordingAccess{models.RecordingAccessPublic, models.RecordingAccessMembers}
+	roles := member.GetRoleStrings()
+	if utils.HasRole(roles, models.MemberRoleSubscriber) || utils.HasRole(roles, models.MemberRoleAdmin) {
+		allowed = append(allowed, models.RecordingAccessSubscribers)
+	}
+	return allowed
+}
+
+// canAccess проверяет, доступна ли запись пользователю
+func canAccess(recording *models.EventRecording, member *models.Member) bool {
+	for _, access := range AllowedAccess(member) {
+		if recording.Access == access {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchForMember ищет по каталогу записей, доступных пользователю
+func (s *EventRecordingService) SearchForMember(req *models.RecordingSearchRequest, member *models.Member) (*models.RegistrySearch[models.EventRecording], error) {
+	filter, err := recordingFilter(req)
+	if err != nil {
+		return nil, err
+	}
+	filter.Access = AllowedAccess(member)
+
+	return s.search(req, filter)
+}
+
+// SearchAll ищет по всем записям для админки
+func (s *EventRecordingService) SearchAll(req *models.RecordingSearchRequest) (*models.RegistrySearch[models.EventRecording], error) {
+	filter, err := recordingFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.search(req, filter)
+}
+
+func (s *EventRecordingService) search(req *models.RecordingSearchRequest, filter *models.RecordingFilter) (*models.RegistrySearch[models.EventRecording], error) {
+	items, count, err := s.repo.SearchRecordings(req.Limit, req.Offset, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RegistrySearch[models.EventRecording]{Items: items, Total: int(count)}, nil
+}
+
+func recordingFilter(req *models.RecordingSearchRequest) (*models.RecordingFilter, error) {
+	filter := &models.RecordingFilter{
+		TagIds: req.TagIds,
+		HostId: req.HostId,
+		Query:  strings.TrimSpace(req.Query),
+	}
+
+	if req.DateFrom != nil && *req.DateFrom != "" {
+		date, err := time.Parse(models.DateFormat, *req.DateFrom)
+		if err != nil {
+			return nil, ErrRecordingInvalidDate
+		}
+		filter.DateFrom = &date
+	}
+	if req.DateTo != nil && *req.DateTo != "" {
+		date, err := time.Parse(models.DateFormat, *req.DateTo)
+		if err != nil {
+			return nil, ErrRecordingInvalidDate
+		}
+		// Дата окончания включительно
+		date = date.AddDate(0, 0, 1)
+		filter.DateTo = &date
+	}
+
+	return filter, nil
+}
+
+// GetForMember возвращает запись, если она доступна пользователю
+func (s *EventRecordingService) GetForMember(id int64, member *models.Member) (*models.EventRecording, error) {
+	recording, err := s.repo.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	if !canAccess(recording, member) {
+		if member == nil {
+			return nil, ErrRecordingMembersOnly
+		}
+		return nil, ErrRecordingSubscribersOnly
+	}
+	return recording, nil
+}
+
+// GetFileURL выдает временную ссылку на загруженный в S3 файл записи
+func (s *EventRecordingService) GetFileURL(id int64, member *models.Member) (string, error) {
+	recording, err := s.GetForMember(id, member)
+	if err != nil {
+		return "", err
+	}
+	if !recording.HasFile {
+		return "", ErrRecordingNoFile
+	}
+
+	client, err := utils.NewS3Client()
+	if err != nil {
+		return "", err
+	}
+	return client.PresignGet(context.Background(), *recording.FilePath, recordingLinkTTL)
+}
+
+// Create создает запись. Название и дата по умолчанию берутся из события
+func (s *EventRecordingService) Create(recording *models.EventRecording) (*models.EventRecording, error) {
+	if err := s.prepare(recording); err != nil {
+		return nil, err
+	}
+	return s.repo.Create(recording)
+}
+
+// Update сохраняет запись и ее главы
+func (s *EventRecordingService) Update(recording *models.EventRecording) (*models.EventRecording, error) {
+	if _, err := s.repo.GetById(recording.Id); err != nil {
+		return nil, err
+	}
+	if err := s.prepare(recording); err != nil {
+		return nil, err
+	}
+	return s.repo.Update(recording)
+}
+
+// prepare проверяет уровень доступа, дополняет запись данными события и упорядочивает главы
+func (s *EventRecordingService) prepare(recording *models.EventRecording) error {
+	if recording.Access == "" {
+		recording.Access = models.RecordingAccessMembers
+	}
+	if !recording.Access.IsValid() {
+		return ErrRecordingInvalidAccess
+	}
+
+	if recording.Title == "" || recording.RecordedAt.IsZero() {
+		event, err := repository.NewEventRepository().GetById(recording.EventId)
+		if err != nil {
+			return err
+		}
+		if recording.Title == "" {
+			recording.Title = event.Title
+		}
+		if recording.RecordedAt.IsZero() {
+			recording.RecordedAt = event.Date
+		}
+	}
+
+	for i := range recording.Chapters {
+		chapter := &recording.Chapters[i]
+		if chapter.Timestamp != "" {
+			seconds, err := models.ParseTimestamp(chapter.Timestamp)
+			if err != nil {
+				return fmt.Errorf("%w %q: %v", ErrRecordingInvalidChapter, chapter.Title, err)
+			}
+			chapter.StartSeconds = seconds
+		}
+		if recording.Duration != nil && chapter.StartSeconds > *recording.Duration {
+			return fmt.Errorf("%w %q: глава начинается позже конца записи", ErrRecordingInvalidChapter, chapter.Title)
+		}
+	}
+	sort.SliceStable(recording.Chapters, func(i, j int) bool {
+		return recording.Chapters[i].StartSeconds < recording.Chapters[j].StartSeconds
+	})
+
+	recording.Event = nil
+	return nil
+}
+
+// CreateUpload выдает временную ссылку, по которой видео загружается в S3 как запасное хранилище.
+// Файл идет в S3 напрямую, минуя API, и привязывается к записи через AttachFile
+func (s *EventRecordingService) CreateUpload(id int64, req *models.RecordingUploadRequest) (*models.RecordingUpload, error) {
+	recording, err := s.repo.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := strings.ToLower(filepath.Ext(req.FileName))
+	if !recordingVideoExtensions[ext] {
+		return nil, ErrRecordingInvalidVideo
+	}
+
+	client, err := utils.NewS3Client()
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("%s%s%s", recordingKeyPrefix(recording), uuid.NewString(), ext)
+	contentType := recordingContentType(req.FileName, req.ContentType)
+	url, err := client.PresignPut(context.Background(), key, contentType, recordingUploadTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RecordingUpload{
+		UploadURL:   url,
+		Key:         key,
+		ContentType: contentType,
+		ExpiresAt:   time.Now().Add(recordingUploadTTL),
+	}, nil
+}
+
+// AttachFile привязывает к записи видео, загруженное по ссылке из CreateUpload, и заменяет ранее загруженный файл.
+// Слишком большой файл удаляется из S3
+func (s *EventRecordingService) AttachFile(id int64, req *models.RecordingFileRequest) (*models.EventRecording, error) {
+	recording, err := s.repo.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Привязать можно только ключ, выданный для этой записи, а не произвольный объект бакета
+	key := req.Key
+	if !strings.HasPrefix(key, recordingKeyPrefix(recording)) || !recordingVideoExtensions[strings.ToLower(filepath.Ext(key))] {
+		return nil, ErrRecordingUploadNotFound
+	}
+	if recording.HasFile && *recording.FilePath == key {
+		return recording, nil
+	}
+
+	client, err := utils.NewS3Client()
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := client.Size(context.Background(), key)
+	if err != nil {
+		log.Printf("Error checking uploaded recording file %s: %v", key, err)
+		return nil, ErrRecordingUploadNotFound
+	}
+	if size > maxRecordingSize {
+		if err := client.Delete(context.Background(), key); err != nil {
+			log.Printf("Error deleting oversized recording file %s: %v", key, err)
+		}
+		return nil, ErrRecordingTooLarge
+	}
+
+	fileName := req.FileName
+	if fileName == "" {
+		fileName = filepath.Base(key)
+	}
+	contentType := recordingContentType(fileName, req.ContentType)
+	if err := s.repo.SetFile(id, &key, &fileName, &contentType); err != nil {
+		return nil, err
+	}
+
+	if recording.HasFile {
+		if err := client.Delete(context.Background(), *recording.FilePath); err != nil {
+			log.Printf("Error deleting previous recording file %s: %v", *recording.FilePath, err)
+		}
+	}
+
+	return s.repo.GetById(id)
+}
+
+// recordingKeyPrefix папка S3, в которую загружаются файлы записей события
+func recordingKeyPrefix(recording *models.EventRecording) string {
+	return fmt.Sprintf("recordings/%d/", recording.EventId)
+}
+
+// recordingContentType тип содержимого видео: указанный при загрузке или определенный по расширению
+func recordingContentType(fileName string, contentType string) string {
+	if contentType != "" {
+		return contentType
+	}
+	if guessed := mime.TypeByExtension(filepath.Ext(fileName)); guessed != "" {
+		return guessed
+	}
+	return "application/octet-stream"
+}
+
+// DeleteFile удаляет загруженный в S3 файл записи, ссылка на видео остается
+func (s *EventRecordingService) DeleteFile(id int64) (*models.EventRecording, error) {
+	recording, err := s.repo.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	if !recording.HasFile {
+		return nil, ErrRecordingNoFile
+	}
+
+	client, err := utils.NewS3Client()
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Delete(context.Background(), *recording.FilePath); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetFile(id, nil, nil, nil); err != nil {
+		return nil, err
+	}
+	return s.repo.GetById(id)
+}
+
+// Delete удаляет запись вместе с файлом в S3
+func (s *EventRecordingService) Delete(recording *models.EventRecording) error {
+	if recording.HasFile {
+		client, err := utils.NewS3Client()
+		if err != nil {
+			return err
+		}
+		if err := client.Delete(context.Background(), *recording.FilePath); err != nil {
+			return err
+		}
+	}
+	return s.repo.Delete(recording)
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/repository"
+	"ithozyeva/internal/utils"
+	"time"
+)
+
+// SeriesEnrollment части серии, на которые записан участник, с датой ближайшего проведения
+type SeriesEnrollment struct {
+	Series *models.EventSeries
+	Parts  []SeriesEnrollmentPart
+}
+
+// SeriesEnrollmentPart часть серии и итог записи на нее: место или лист ожидания
+type SeriesEnrollmentPart struct {
+	models.Event
+	AttendanceStatus models.EventAttendanceStatus `json:"attendanceStatus"`
+}
+
+// SeriesPromotion участники, переведенные из листа ожидания части серии после отписки от серии
+type SeriesPromotion struct {
+	Event    *models.Event
+	Promoted []models.Member
+}
+
+type EventSeriesService struct {
+	BaseService[models.EventSeries]
+	repo   *repository.EventSeriesRepository
+	events *EventsService
+}
+
+func NewEventSeriesService() *EventSeriesService {
+	repo := repository.NewEventSeriesRepository()
+	return &EventSeriesService{
+		BaseService: NewBaseService[models.EventSeries](repo),
+		repo:        repo,
+		events:      NewEventsService(),
+	}
+}
+
+// Update сохраняет серию. Участников серии записывает на добавленные в нее части
+func (s *EventSeriesService) Update(series *models.EventSeries) (*models.EventSeries, error) {
+	previous, err := s.repo.GetById(series.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.Update(series)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[int64]bool, len(previous.Events))
+	for _, event := range previous.Events {
+		existing[event.Id] = true
+	}
+
+	now := time.Now()
+	for i := range updated.Events {
+		event := &updated.Events[i]
+		if existing[event.Id] || event.IsDraft() {
+			continue
+		}
+		part, _ := upcomingPart(event, now)
+		if part == nil {
+			continue
+		}
+		results, err := s.repo.EnrollMembers(*part)
+		if err != nil {
+			return nil, err
+		}
+		s.events.subscribeEnrolled(results)
+	}
+
+	return updated, nil
+}
+
+// GetPublished возвращает серии с опубликованными частями для публичных страниц
+func (s *EventSeriesService) GetPublished(limit *int, offset *int) (*models.RegistrySearch[models.EventSeries], error) {
+	series, count, err := s.repo.SearchPublished(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RegistrySearch[models.EventSeries]{Items: series, Total: int(count)}, nil
+}
+
+// GetPublishedById возвращает серию с опубликованными частями
+func (s *EventSeriesService) GetPublishedById(id int64) (*models.EventSeries, error) {
+	return s.repo.GetPublishedById(id)
+}
+
+// GetICS формирует календарь со всеми опубликованными частями серии
+func (s *EventSeriesService) GetICS(id int64) (string, error) {
+	series, err := s.repo.GetPublishedById(id)
+	if err != nil {
+		return "", err
+	}
+
+	seriesInfo := &models.EventSeries{Id: series.Id, Title: series.Title, Kind: series.Kind, PartsCount: series.PartsCount}
+	for i := range series.Events {
+		series.Events[i].Series = seriesInfo
+	}
+
+	return utils.GenerateCalendarICS(series.Title, series.Events, nil), nil
+}
+
+// Apply записывает участника на серию и на все ее предстоящие части в одной транзакции.
+// На повторяющуюся часть участник записывается на ближайшее повторение. Для каждой части
+// возвращается, досталось ли место или участник попал в лист ожидания
+func (s *EventSeriesService) Apply(seriesId int64, memberId int64) (*SeriesEnrollment, error) {
+	series, err := s.repo.GetPublishedById(seriesId)
+	if err != nil {
+		return nil, err
+	}
+
+	parts, events := upcomingParts(series, time.Now())
+	results, err := s.repo.AddMember(series.Id, memberId, parts)
+	if err != nil {
+		return nil, err
+	}
+	s.events.subscribeEnrolled(results)
+
+	enrollment := &SeriesEnrollment{Series: series, Parts: make([]SeriesEnrollmentPart, 0, len(results))}
+	for i, result := range results {
+		enrollment.Parts = append(enrollment.Parts, SeriesEnrollmentPart{Event: events[i], AttendanceStatus: result.Status})
+	}
+
+	return enrollment, nil
+}
+
+// Decline отписывает участника от серии и от всех ее предстоящих частей в одной транзакции.
+// Возвращает участников, переведенных из листов ожидания частей
+func (s *EventSeriesService) Decline(seriesId int64, memberId int64) (*models.EventSeries, []SeriesPromotion, error) {
+	series, err := s.repo.GetPublishedById(seriesId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parts, _ := upcomingParts(series, time.Now())
+	results, err := s.repo.RemoveMember(series.Id, memberId, parts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var promotions []SeriesPromotion
+	for _, result := range results {
+		var occurrence *models.EventOccurrence
+		if result.OccurrenceId != nil {
+			occurrence, err = s.events.repo.GetOccurrenceById(*result.OccurrenceId)
+			if err != nil {
+				return nil, nil, err
+			}
+			s.events.setOccurrenceSubscription(occurrence, memberId, models.EventAlertStatusUnsubscribed)
+			for _, member := range result.Promoted {
+				s.events.setOccurrenceSubscription(occurrence, member.Id, models.EventAlertStatusSubscribed)
+			}
+		}
+		if len(result.Promoted) == 0 {
+			continue
+		}
+
+		event, err := s.events.repo.GetById(result.EventId)
+		if err != nil {
+			return nil, nil, err
+		}
+		event.Occurrence = occurrence
+		promotions = append(promotions, SeriesPromotion{Event: event, Promoted: result.Promoted})
+	}
+
+	series, err = s.repo.GetPublishedById(series.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return series, promotions, nil
+}
+
+// upcomingParts собирает предстоящие опубликованные части серии и их ближайшие проведения
+func upcomingParts(series *models.EventSeries, now time.Time) ([]repository.SeriesPart, []models.Event) {
+	var parts []repository.SeriesPart
+	var events []models.Event
+	for i := range series.Events {
+		if series.Events[i].IsDraft() {
+			continue
+		}
+		part, event := upcomingPart(&series.Events[i], now)
+		if part == nil {
+			continue
+		}
+		parts = append(parts, *part)
+		events = append(events, *event)
+	}
+	return parts, events
+}
+
+// upcomingPart возвращает часть серии для записи и ее ближайшее проведение: для повторяющейся
+// части — ближайшее повторение. nil, если часть уже прошла
+func upcomingPart(event *models.Event, now time.Time) (*repository.SeriesPart, *models.Event) {
+	if event.IsRecurring() {
+		instance := utils.NextEventInstance(event, now)
+		if instance == nil {
+			return nil, nil
+		}
+		occurrenceDate := instance.RecurrenceId
+		next := utils.EventForInstance(event, instance)
+		return &repository.SeriesPart{EventId: event.Id, OccurrenceDate: &occurrenceDate}, &next
+	}
+
+	if event.Date.Before(now) {
+		return nil, nil
+	}
+	return &repository.SeriesPart{EventId: event.Id}, event
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"ithozyeva/internal/models"
+	"testing"
+	"time"
+)
+
+func TestUpcomingParts(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	weekly := "FREQ=WEEKLY;BYDAY=MO;COUNT=4"
+	finished := "FREQ=WEEKLY;BYDAY=MO;COUNT=2"
+
+	series := &models.EventSeries{Events: []models.Event{
+		{Id: 1, Date: now.Add(-time.Hour), Status: models.EventStatusPublished},
+		{Id: 2, Date: now.Add(48 * time.Hour), Status: models.EventStatusDraft},
+		{Id: 3, Date: now.Add(24 * time.Hour), Status: models.EventStatusPublished},
+		{Id: 4, Date: time.Date(2026, 10, 5, 18, 0, 0, 0, time.UTC), RRule: &weekly, Status: models.EventStatusPublished},
+		{Id: 5, Date: time.Date(2026, 10, 5, 18, 0, 0, 0, time.UTC), RRule: &finished, Status: models.EventStatusPublished},
+	}}
+
+	parts, events := upcomingParts(series, now)
+
+	tests := []struct {
+		eventId        int64
+		occurrenceDate *time.Time
+		date           time.Time
+	}{
+		{eventId: 3, date: now.Add(24 * time.Hour)},
+		{eventId: 4, occurrenceDate: ptrTime(time.Date(2026, 10, 19, 18, 0, 0, 0, time.UTC)), date: time.Date(2026, 10, 19, 18, 0, 0, 0, time.UTC)},
+	}
+
+	if len(parts) != len(tests) || len(events) != len(tests) {
+		t.Fatalf("upcomingParts() = %+v, want %d parts", parts, len(tests))
+	}
+	for i, tt := range tests {
+		if parts[i].EventId != tt.eventId {
+			t.Errorf("part %d event = %d, want %d", i, parts[i].EventId, tt.eventId)
+		}
+		switch {
+		case tt.occurrenceDate == nil && parts[i].OccurrenceDate != nil:
+			t.Errorf("part %d occurrence = %s, want none", i, parts[i].OccurrenceDate)
+		case tt.occurrenceDate != nil && (parts[i].OccurrenceDate == nil || !parts[i].OccurrenceDate.Equal(*tt.occurrenceDate)):
+			t.Errorf("part %d occurrence = %v, want %s", i, parts[i].OccurrenceDate, tt.occurrenceDate)
+		}
+		if !events[i].Date.Equal(tt.date) {
+			t.Errorf("part %d date = %s, want %s", i, events[i].Date, tt.date)
+		}
+	}
+}
+
+func ptrTime(value time.Time) *time.Time {
+	return &value
+}
@@ -1,21 +1,36 @@
 package service
 
 import (
+	"errors"
 	"ithozyeva/internal/models"
 	"ithozyeva/internal/repository"
+	"ithozyeva/internal/utils"
+	"log"
+	"sort"
 	"time"
+
+	"gorm.io/gorm"
 )
 
+// ErrInvalidOccurrence возвращается, если дата не совпадает ни с одним повторением события
+var ErrInvalidOccurrence = errors.New("дата не совпадает ни с одним повторением события")
+
 type EventsService struct {
 	BaseService[models.Event]
-	repo repository.EventRepository
+	repo          repository.EventRepository
+	alertRepo     *repository.EventAlertSubscriptionRepository
+	tokenRepo     *repository.MemberCalendarTokenRepository
+	recordingRepo *repository.EventRecordingRepository
 }
 
 func NewEventsService() *EventsService {
 	repo := repository.NewEventRepository()
 	return &EventsService{
-		BaseService: NewBaseService(repo),
-		repo:        *repo,
+		BaseService:   NewBaseService(repo),
+		repo:          *repo,
+		alertRepo:     repository.NewEventAlertSubscriptionRepository(),
+		tokenRepo:     repository.NewMemberCalendarTokenRepository(),
+		recordingRepo: repository.NewEventRecordingRepository(),
 	}
 }
 
@@ -23,20 +38,320 @@ func (s *EventsService) AddMember(eventId int, memberId int) (*models.Event, err
 	return s.repo.AddMember(eventId, memberId)
 }
 
-func (s *EventsService) RemoveMember(eventId int, memberId int) (*models.Event, error) {
-	return s.repo.RemoveMember(eventId, memberId)
+// RemoveMember отписывает участника от события и переводит на освободившееся место
+// первого из листа ожидания. Возвращает список переведенных участников
+func (s *EventsService) RemoveMember(eventId int, memberId int) (*models.Event, []models.Member, error) {
+	if _, err := s.repo.RemoveMember(eventId, memberId); err != nil {
+		return nil, nil, err
+	}
+
+	return s.PromoteFromWaitlist(int64(eventId))
+}
+
+// PromoteFromWaitlist заполняет свободные места события участниками из листа ожидания
+func (s *EventsService) PromoteFromWaitlist(eventId int64) (*models.Event, []models.Member, error) {
+	promoted, err := s.repo.PromoteFromWaitlist(eventId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	event, err := s.repo.GetById(eventId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return event, promoted, nil
+}
+
+// ResolveOccurrence возвращает исходную дату повторения события (по правилу повторения).
+// Дата в запросе может быть как исходной, так и датой после переноса. Если дата не указана,
+// берется ближайшее будущее повторение
+func (s *EventsService) ResolveOccurrence(event *models.Event, date *time.Time) (*time.Time, error) {
+	var instance *utils.EventInstance
+	if date == nil {
+		instance = utils.NextEventInstance(event, time.Now())
+	} else {
+		instance, _ = utils.FindEventInstance(event, *date)
+	}
+	if instance == nil {
+		return nil, ErrInvalidOccurrence
+	}
+
+	return &instance.RecurrenceId, nil
+}
+
+// GetOrCreateOccurrence возвращает запись о повторении события, создавая ее при необходимости
+func (s *EventsService) GetOrCreateOccurrence(eventId int64, occurrenceDate time.Time) (*models.EventOccurrence, error) {
+	return s.repo.GetOrCreateOccurrence(eventId, occurrenceDate)
+}
+
+// UpdateOccurrenceAlertSentAt сохраняет время последнего алерта по повторению
+func (s *EventsService) UpdateOccurrenceAlertSentAt(occurrenceId int64, sentAt time.Time) error {
+	return s.repo.UpdateOccurrenceAlertSentAt(occurrenceId, sentAt)
+}
+
+// AddOccurrenceMember записывает участника на конкретное повторение события
+func (s *EventsService) AddOccurrenceMember(event *models.Event, date *time.Time, memberId int64) (*models.Event, error) {
+	occurrenceDate, err := s.ResolveOccurrence(event, date)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrence, err := s.repo.GetOrCreateOccurrence(event.Id, *occurrenceDate)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrence, err = s.repo.AddOccurrenceMember(occurrence, memberId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Алерты о повторении получают только те, кому досталось место
+	for _, member := range occurrence.Members {
+		if member.Id == memberId {
+			s.setOccurrenceSubscription(occurrence, memberId, models.EventAlertStatusSubscribed)
+			break
+		}
+	}
+
+	event.Occurrence = occurrence
+	return event, nil
+}
+
+// RemoveOccurrenceMember отписывает участника от повторения события и переводит
+// на освободившееся место первого из листа ожидания этого повторения
+func (s *EventsService) RemoveOccurrenceMember(event *models.Event, date *time.Time, memberId int64) (*models.Event, []models.Member, error) {
+	occurrenceDate, err := s.ResolveOccurrence(event, date)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	occurrence, err := s.repo.GetOrCreateOccurrence(event.Id, *occurrenceDate)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := s.repo.RemoveOccurrenceMember(occurrence, memberId); err != nil {
+		return nil, nil, err
+	}
+	s.setOccurrenceSubscription(occurrence, memberId, models.EventAlertStatusUnsubscribed)
+
+	promoted, err := s.repo.PromoteOccurrenceWaitlist(occurrence)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, member := range promoted {
+		s.setOccurrenceSubscription(occurrence, member.Id, models.EventAlertStatusSubscribed)
+	}
+
+	occurrence, err = s.repo.GetOccurrenceById(occurrence.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	event.Occurrence = occurrence
+	return event, promoted, nil
+}
+
+// setOccurrenceSubscription обновляет подписку на алерты повторения. Ошибка не прерывает запись на событие
+func (s *EventsService) setOccurrenceSubscription(occurrence *models.EventOccurrence, memberId int64, status models.EventAlertSubscriptionStatus) {
+	occurrenceId := occurrence.Id
+	_, err := s.alertRepo.CreateOrUpdate(&models.EventAlertSubscription{
+		EventId:      occurrence.EventId,
+		MemberId:     memberId,
+		OccurrenceId: &occurrenceId,
+		Status:       status,
+	})
+	if err != nil {
+		log.Printf("Error updating occurrence %d subscription for member %d: %v", occurrence.Id, memberId, err)
+	}
+}
+
+// subscribeEnrolled подписывает на алерты повторений участников, которым досталось место в части серии
+func (s *EventsService) subscribeEnrolled(results []repository.SeriesPartAttendance) {
+	for _, result := range results {
+		if result.OccurrenceId == nil || result.Status != models.EventAttendanceEnrolled {
+			continue
+		}
+		occurrence := &models.EventOccurrence{Id: *result.OccurrenceId, EventId: result.EventId}
+		s.setOccurrenceSubscription(occurrence, result.MemberId, models.EventAlertStatusSubscribed)
+	}
+}
+
+// GetOccurrences возвращает проведения события в интервале [from, to) вместе с участниками.
+// Отмененные повторения не возвращаются, перенесенные попадают в интервал по новой дате.
+// Повторения, на которые еще никто не записался, возвращаются без идентификатора
+func (s *EventsService) GetOccurrences(event *models.Event, from, to time.Time) ([]models.EventOccurrence, error) {
+	instances := utils.EventInstancesBetween(event, from, to)
+
+	dates := make([]time.Time, 0, len(instances))
+	for _, instance := range instances {
+		dates = append(dates, instance.RecurrenceId)
+	}
+
+	stored, err := s.repo.GetOccurrencesByDates(event.Id, dates)
+	if err != nil {
+		return nil, err
+	}
+
+	storedByDate := make(map[int64]models.EventOccurrence, len(stored))
+	for _, occurrence := range stored {
+		storedByDate[occurrence.OccurrenceDate.Unix()] = occurrence
+	}
+
+	result := make([]models.EventOccurrence, 0, len(instances))
+	for _, instance := range instances {
+		if occurrence, ok := storedByDate[instance.RecurrenceId.Unix()]; ok {
+			result = append(result, occurrence)
+			continue
+		}
+		result = append(result, models.EventOccurrence{
+			EventId:        event.Id,
+			OccurrenceDate: instance.RecurrenceId.UTC(),
+			Hosts:          []models.Member{},
+			Members:        []models.Member{},
+			Waitlist:       []models.EventWaitlistEntry{},
+		})
+	}
+
+	return result, nil
+}
+
+// SetOccurrenceOverride отменяет, переносит или меняет ведущих конкретного повторения события.
+// Повторение без изменений снова проводится по правилу
+func (s *EventsService) SetOccurrenceOverride(event *models.Event, override *models.EventOccurrence) (*models.EventOccurrence, error) {
+	if !event.IsRecurring() || !utils.IsEventDate(event, override.OccurrenceDate) {
+		return nil, ErrInvalidOccurrence
+	}
+
+	occurrence, err := s.repo.GetOrCreateOccurrence(event.Id, override.OccurrenceDate)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrence.Cancelled = override.Cancelled
+	occurrence.OverrideDate = override.OverrideDate
+	occurrence.Hosts = override.Hosts
+	if occurrence.Hosts == nil {
+		occurrence.Hosts = []models.Member{}
+	}
+
+	return s.repo.SaveOccurrenceOverride(occurrence)
+}
+
+// Create проверяет правило повторения перед созданием события.
+// Событие, созданное администратором без статуса, сразу публикуется.
+// В серию событие добавляется через редактирование серии
+func (s *EventsService) Create(event *models.Event) (*models.Event, error) {
+	if err := prepareEvent(event); err != nil {
+		return nil, err
+	}
+	if event.Status == "" {
+		event.Status = models.EventStatusPublished
+	}
+	event.SeriesId = nil
+	event.SeriesPosition = 0
+
+	created, err := s.repo.Create(event)
+	if err != nil {
+		return nil, err
+	}
+	s.syncRecording(created)
+	return created, nil
+}
+
+// Update проверяет правило повторения перед сохранением события.
+// Если статус не передан, сохраняется текущий; серия и место в ней меняются только через серию
+func (s *EventsService) Update(event *models.Event) (*models.Event, error) {
+	if err := prepareEvent(event); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetById(event.Id)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status == "" {
+		event.Status = existing.Status
+	}
+	if event.CreatedBy == nil {
+		event.CreatedBy = existing.CreatedBy
+	}
+	event.SeriesId = existing.SeriesId
+	event.SeriesPosition = existing.SeriesPosition
+
+	updated, err := s.repo.Update(event)
+	if err != nil {
+		return nil, err
+	}
+	s.syncRecording(updated)
+	return updated, nil
+}
+
+// syncRecording добавляет ссылку на видео события в каталог записей
+func (s *EventsService) syncRecording(event *models.Event) {
+	if err := s.recordingRepo.SyncEventVideoLink(event); err != nil {
+		log.Printf("Error syncing recording for event %d: %v", event.Id, err)
+	}
+}
+
+// HidePrivateVideoLinks убирает ссылки на видео из публичной выдачи событий,
+// если запись события не открыта всем в каталоге записей
+func (s *EventsService) HidePrivateVideoLinks(events []models.Event) error {
+	eventIds := make([]int64, 0, len(events))
+	for i := range events {
+		if events[i].VideoLink != "" {
+			eventIds = append(eventIds, events[i].Id)
+		}
+	}
+
+	public, err := s.recordingRepo.GetPublicEventIds(eventIds)
+	if err != nil {
+		return err
+	}
+	for i := range events {
+		if !public[events[i].Id] {
+			events[i].VideoLink = ""
+		}
+	}
+	return nil
+}
+
+// prepareEvent проверяет RRULE и приводит его к каноничному виду.
+// Измененные повторения редактируются отдельно и при сохранении события не трогаются
+func prepareEvent(event *models.Event) error {
+	event.Exceptions = nil
+	event.Occurrence = nil
+	event.Series = nil
+
+	if event.RRule == nil || *event.RRule == "" {
+		event.RRule = nil
+		return nil
+	}
+
+	rule, err := utils.ParseRRule(*event.RRule)
+	if err != nil {
+		return err
+	}
+
+	normalized := rule.String()
+	event.RRule = &normalized
+	event.IsRepeating = true
+	return nil
 }
 
+// GetFutureEvents возвращает опубликованные события, у которых еще будут проведения
 func (s *EventsService) GetFutureEvents(now time.Time) ([]models.Event, error) {
-	allEvents, _, err := s.repo.Search(nil, nil, nil, nil)
+	allEvents, _, err := s.repo.Search(nil, nil, &repository.SearchFilter{"status = ?": models.EventStatusPublished}, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	var futureEvents []models.Event
 	for _, event := range allEvents {
-		if event.IsRepeating && event.RepeatPeriod != nil {
-			if event.RepeatEndDate != nil && now.After(*event.RepeatEndDate) {
+		if event.IsRecurring() {
+			if utils.NextEventInstance(&event, now) == nil {
 				continue
 			}
 			futureEvents = append(futureEvents, event)
@@ -49,3 +364,103 @@ func (s *EventsService) GetFutureEvents(now time.Time) ([]models.Event, error) {
 
 	return futureEvents, nil
 }
+
+// GetNext возвращает предстоящие события по возрастанию даты.
+// Повторяющееся событие возвращается с датой и ведущими ближайшего проведения
+func (s *EventsService) GetNext(now time.Time) (*models.RegistrySearch[models.Event], error) {
+	events, err := s.GetFutureEvents(now)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]models.Event, 0, len(events))
+	for i := range events {
+		if !events[i].IsRecurring() {
+			items = append(items, events[i])
+			continue
+		}
+		if instance := utils.NextEventInstance(&events[i], now); instance != nil {
+			items = append(items, utils.EventForInstance(&events[i], instance))
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Date.Before(items[j].Date) })
+
+	return &models.RegistrySearch[models.Event]{
+		Items: items,
+		Total: len(items),
+	}, nil
+}
+
+// calendarTokenSize размер секретного токена персональной ленты в байтах
+const calendarTokenSize = 32
+
+// GetCalendarFeed формирует общую ленту календаря открытых событий, опционально по тегам
+func (s *EventsService) GetCalendarFeed(tagIds []int64) (string, error) {
+	events, err := s.repo.GetCalendarEvents(tagIds)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.GenerateCalendarICS("IT Khoziaeva: события", events, nil), nil
+}
+
+// GetMemberCalendarFeed формирует персональную ленту участника по секретному токену:
+// события, на которые он записан или которые ведет, и отдельные повторения повторяющихся событий
+func (s *EventsService) GetMemberCalendarFeed(token string) (string, error) {
+	calendarToken, err := s.tokenRepo.GetByToken(token)
+	if err != nil {
+		return "", err
+	}
+
+	events, err := s.repo.GetMemberCalendarEvents(calendarToken.MemberId)
+	if err != nil {
+		return "", err
+	}
+
+	occurrences, err := s.repo.GetMemberCalendarOccurrences(calendarToken.MemberId)
+	if err != nil {
+		return "", err
+	}
+
+	// Повторения событий, которые уже есть в ленте целиком, не дублируем
+	included := make(map[int64]bool, len(events))
+	for _, event := range events {
+		included[event.Id] = true
+	}
+	separate := make([]models.EventOccurrence, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		if !included[occurrence.EventId] {
+			separate = append(separate, occurrence)
+		}
+	}
+
+	return utils.GenerateCalendarICS("IT Khoziaeva: мои события", events, separate), nil
+}
+
+// GetMemberCalendarToken возвращает токен персональной ленты участника, создавая его при первом обращении
+func (s *EventsService) GetMemberCalendarToken(memberId int64) (*models.MemberCalendarToken, error) {
+	token, err := s.tokenRepo.GetByMemberId(memberId)
+	if err == nil {
+		return token, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	return s.ResetMemberCalendarToken(memberId)
+}
+
+// ResetMemberCalendarToken выпускает новый токен персональной ленты, старая ссылка перестает работать
+func (s *EventsService) ResetMemberCalendarToken(memberId int64) (*models.MemberCalendarToken, error) {
+	value, err := utils.GenerateRandomToken(calendarTokenSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tokenRepo.Upsert(&models.MemberCalendarToken{
+		MemberId:  memberId,
+		Token:     value,
+		CreatedAt: time.Now(),
+	})
+}
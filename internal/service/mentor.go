@@ -4,6 +4,7 @@ import (
 	"ithozyeva/internal/models"
 	"ithozyeva/internal/repository"
 	"ithozyeva/internal/utils"
+	"math"
 )
 
 // MentorServiceInterface интерфейс для сервиса менторов
@@ -20,8 +21,10 @@ type MentorServiceInterface interface {
 // MentorService реализует интерфейс MentorServiceInterface
 type MentorService struct {
 	BaseService[models.MentorDbShortModel]
-	repo       *repository.MentorRepository
-	memberRepo *repository.MemberRepository
+	repo        *repository.MentorRepository
+	memberRepo  *repository.MemberRepository
+	reviewRepo  *repository.ReviewOnServiceRepository
+	bookingRepo *repository.MentorBookingRepository
 }
 
 // NewMentorService создает новый экземпляр сервиса менторов
@@ -31,6 +34,8 @@ func NewMentorService() *MentorService {
 		BaseService: NewBaseService[models.MentorDbShortModel](repo),
 		repo:        repo,
 		memberRepo:  repository.NewMemberRepository(),
+		reviewRepo:  repository.NewReviewOnServiceRepository(),
+		bookingRepo: repository.NewMentorBookingRepository(),
 	}
 }
 
@@ -51,9 +56,41 @@ func (s *MentorService) GetByIdFull(id int64) (*models.MentorModel, error) {
 		return nil, err
 	}
 
-	mentor := mentorDb.ToModel()
+	mentors := []models.MentorModel{mentorDb.ToModel()}
+	if err := s.fillRatings(mentors); err != nil {
+		return nil, err
+	}
+	if err := s.fillAvailability(mentors); err != nil {
+		return nil, err
+	}
 
-	return &mentor, nil
+	return &mentors[0], nil
+}
+
+// fillRatings проставляет менторам среднюю оценку и число оценок по одобренным отзывам на их услуги
+func (s *MentorService) fillRatings(mentors []models.MentorModel) error {
+	ids := make([]int64, 0, len(mentors))
+	for _, mentor := range mentors {
+		ids = append(ids, mentor.Id)
+	}
+
+	ratings, err := s.reviewRepo.GetMentorRatings(ids)
+	if err != nil {
+		return err
+	}
+
+	byMentor := make(map[int64]models.MentorRating, len(ratings))
+	for _, rating := range ratings {
+		byMentor[rating.MentorId] = rating
+	}
+	for i := range mentors {
+		if rating, ok := byMentor[mentors[i].Id]; ok {
+			value := math.Round(rating.Rating*10) / 10
+			mentors[i].Rating = &value
+			mentors[i].ReviewsCount = rating.ReviewsCount
+		}
+	}
+	return nil
 }
 
 // AddReviewToService добавляет отзыв к услуге ментора
@@ -114,6 +151,12 @@ func (s *MentorService) GetAllWithRelations(limit *int, offset *int) (*models.Re
 		mentor := mentorDb.ToModel()
 		mentors = append(mentors, mentor)
 	}
+	if err := s.fillRatings(mentors); err != nil {
+		return nil, err
+	}
+	if err := s.fillAvailability(mentors); err != nil {
+		return nil, err
+	}
 
 	return &models.RegistrySearch[models.MentorModel]{
 		Items: mentors,
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/repository"
+	"strings"
+)
+
+var (
+	ErrAlreadyMentor            = errors.New("вы уже ментор")
+	ErrMentorApplicationPending = errors.New("ваша заявка уже на рассмотрении")
+	ErrMentorApplicationInvalid = errors.New("укажите специализацию, опыт и хотя бы одну услугу")
+	ErrMentorApplicationClosed  = errors.New("заявка уже рассмотрена")
+	ErrRejectCommentRequired    = errors.New("укажите причину отказа")
+)
+
+// MentorApplicationService заявки участников стать ментором и их рассмотрение
+type MentorApplicationService struct {
+	BaseService[models.MentorApplication]
+	repo    *repository.MentorApplicationRepository
+	mentors *MentorService
+}
+
+func NewMentorApplicationService() *MentorApplicationService {
+	repo := repository.NewMentorApplicationRepository()
+	return &MentorApplicationService{
+		BaseService: NewBaseService[models.MentorApplication](repo),
+		repo:        repo,
+		mentors:     NewMentorService(),
+	}
+}
+
+// Submit сохраняет заявку участника. Одновременно на рассмотрении может быть только одна заявка
+func (s *MentorApplicationService) Submit(member *models.Member, application *models.MentorApplication) (*models.MentorApplication, error) {
+	if _, err := s.mentors.GetByMemberID(member.Id); err == nil {
+		return nil, ErrAlreadyMentor
+	}
+
+	pending, err := s.repo.HasPending(member.Id)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrMentorApplicationPending
+	}
+
+	application.Occupation = strings.TrimSpace(application.Occupation)
+	application.Experience = strings.TrimSpace(application.Experience)
+	if application.Occupation == "" || application.Experience == "" || len(application.Services) == 0 {
+		return nil, ErrMentorApplicationInvalid
+	}
+	for i := range application.Services {
+		application.Services[i].Id = 0
+		application.Services[i].Name = strings.TrimSpace(application.Services[i].Name)
+		if application.Services[i].Name == "" || application.Services[i].Price < 0 {
+			return nil, ErrMentorApplicationInvalid
+		}
+	}
+	for i := range application.Contacts {
+		application.Contacts[i].Id = 0
+	}
+
+	application.Id = 0
+	application.MemberId = member.Id
+	application.Member = nil
+	application.Status = models.MentorApplicationPending
+	application.ReviewComment = ""
+	application.ReviewedAt = nil
+	application.MentorId = nil
+
+	return s.repo.Create(application)
+}
+
+// GetMine возвращает заявки участника
+func (s *MentorApplicationService) GetMine(member *models.Member) ([]models.MentorApplication, error) {
+	return s.repo.GetByMember(member.Id)
+}
+
+// Approve одобряет заявку: создает профиль ментора с тегами, услугами и контактами из заявки.
+// Вместе с профилем участник получает роль MENTOR, а с ней право редактировать свой профиль на платформе
+func (s *MentorApplicationService) Approve(id int64, comment string) (*models.MentorApplication, error) {
+	application, err := s.getPending(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.mentors.GetByMemberID(application.MemberId); err == nil {
+		return nil, ErrAlreadyMentor
+	}
+
+	order, err := s.mentors.repo.NextOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	mentor := &models.MentorDbModel{
+		MemberId:   application.MemberId,
+		Occupation: application.Occupation,
+		Experience: application.Experience,
+		Order:      order,
+		ProfTags:   application.ProfTags,
+	}
+	for _, service := range application.Services {
+		mentor.Services = append(mentor.Services, models.Service{Name: service.Name, Price: service.Price})
+	}
+	for _, contact := range application.Contacts {
+		mentor.Contacts = append(mentor.Contacts, models.Contact{Type: contact.Type, Link: contact.Link})
+	}
+
+	return s.repo.Approve(application.Id, strings.TrimSpace(comment), mentor)
+}
+
+// Reject отклоняет заявку с обязательным комментарием
+func (s *MentorApplicationService) Reject(id int64, comment string) (*models.MentorApplication, error) {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return nil, ErrRejectCommentRequired
+	}
+
+	application, err := s.getPending(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.SetReview(application.Id, models.MentorApplicationRejected, comment, nil)
+}
+
+func (s *MentorApplicationService) getPending(id int64) (*models.MentorApplication, error) {
+	application, err := s.repo.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	if application.Status != models.MentorApplicationPending {
+		return nil, ErrMentorApplicationClosed
+	}
+	return application, nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"errors"
+	"ithozyeva/internal/models"
+	"time"
+)
+
+// mentorVacationExtension на сколько продлевается отпуск кнопкой из напоминания
+const mentorVacationExtension = 7 * 24 * time.Hour
+
+var (
+	ErrInvalidAvailability = errors.New("неизвестный статус доступности")
+	ErrVacationUntil       = errors.New("укажите дату окончания отпуска в будущем")
+	ErrInvalidMaxMentees   = errors.New("максимум менти должен быть больше нуля")
+)
+
+// fillAvailability считает активных менти и проставляет, можно ли записаться к ментору
+func (s *MentorService) fillAvailability(mentors []models.MentorModel) error {
+	ids := make([]int64, 0, len(mentors))
+	for _, mentor := range mentors {
+		ids = append(ids, mentor.Id)
+	}
+
+	mentees, err := s.bookingRepo.CountActiveMentees(ids, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for i := range mentors {
+		mentors[i].ActiveMentees = mentees[mentors[i].Id]
+		mentors[i].AcceptsMentees = acceptsMentees(mentors[i].Availability, mentors[i].MaxMentees, mentors[i].ActiveMentees)
+	}
+	return nil
+}
+
+// acceptsMentees принимает ли ментор новых менти при заданном числе активных
+func acceptsMentees(availability models.MentorAvailability, maxMentees *int, active int) bool {
+	if availability != models.MentorAccepting && availability != "" {
+		return false
+	}
+	return maxMentees == nil || active < *maxMentees
+}
+
+// GetMyAvailability возвращает профиль текущего ментора с доступностью и числом активных менти
+func (s *MentorService) GetMyAvailability(member *models.Member) (*models.MentorModel, error) {
+	mentor, err := s.repo.GetByMemberID(member.Id)
+	if err != nil {
+		return nil, ErrNotMentor
+	}
+	return s.GetByIdFull(mentor.Id)
+}
+
+// SetAvailability меняет доступность текущего ментора
+func (s *MentorService) SetAvailability(member *models.Member, request *models.MentorAvailabilityRequest) (*models.MentorModel, error) {
+	if !request.Availability.IsValid() {
+		return nil, ErrInvalidAvailability
+	}
+	if request.Availability == models.MentorVacation {
+		if request.VacationUntil == nil || !request.VacationUntil.After(time.Now()) {
+			return nil, ErrVacationUntil
+		}
+	} else {
+		request.VacationUntil = nil
+	}
+	if request.MaxMentees != nil && *request.MaxMentees <= 0 {
+		return nil, ErrInvalidMaxMentees
+	}
+
+	mentor, err := s.repo.GetByMemberID(member.Id)
+	if err != nil {
+		return nil, ErrNotMentor
+	}
+	if err := s.repo.SetAvailability(mentor.Id, request); err != nil {
+		return nil, err
+	}
+	return s.GetByIdFull(mentor.Id)
+}
+
+// ResumeAccepting возвращает ментора к приему менти после отпуска
+func (s *MentorService) ResumeAccepting(member *models.Member) (*models.MentorModel, error) {
+	mentor, err := s.repo.GetByMemberID(member.Id)
+	if err != nil {
+		return nil, ErrNotMentor
+	}
+	return s.SetAvailability(member, &models.MentorAvailabilityRequest{
+		Availability: models.MentorAccepting,
+		MaxMentees:   mentor.MaxMentees,
+	})
+}
+
+// ExtendVacation продлевает отпуск ментора на неделю от текущего момента
+func (s *MentorService) ExtendVacation(member *models.Member) (*models.MentorModel, error) {
+	mentor, err := s.repo.GetByMemberID(member.Id)
+	if err != nil {
+		return nil, ErrNotMentor
+	}
+	until := time.Now().Add(mentorVacationExtension)
+	return s.SetAvailability(member, &models.MentorAvailabilityRequest{
+		Availability:  models.MentorVacation,
+		VacationUntil: &until,
+		MaxMentees:    mentor.MaxMentees,
+	})
+}
+
+// GetEndedVacations возвращает менторов, которым пора напомнить о возвращении из отпуска
+func (s *MentorService) GetEndedVacations(now time.Time) ([]models.MentorDbModel, error) {
+	return s.repo.GetEndedVacations(now)
+}
+
+// MarkVacationReminderSent отмечает отправку напоминания об окончании отпуска
+func (s *MentorService) MarkVacationReminderSent(mentorId int64, sentAt time.Time) error {
+	return s.repo.MarkVacationReminderSent(mentorId, sentAt)
+}
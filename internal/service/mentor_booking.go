@@ -0,0 +1,311 @@
+package service
+
+import (
+	"errors"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/repository"
+	"time"
+)
+
+const (
+	// mentorSlotMaxDuration самый длинный слот, который можно опубликовать
+	mentorSlotMaxDuration = 8 * time.Hour
+	// mentorSlotMinDuration самый короткий слот, который можно опубликовать
+	mentorSlotMinDuration = 15 * time.Minute
+)
+
+var (
+	ErrInvalidSlot         = errors.New("слот должен начинаться в будущем и длиться от 15 минут до 8 часов")
+	ErrSlotOverlap         = errors.New("слот пересекается с уже опубликованным")
+	ErrSlotInPast          = errors.New("слот уже прошел")
+	ErrSlotBooked          = errors.New("на слот есть активная запись, сначала отмените ее")
+	ErrNotMentorService    = errors.New("услуга не принадлежит ментору")
+	ErrSelfBooking         = errors.New("нельзя записаться к самому себе")
+	ErrNotBookingSide      = errors.New("запись вам недоступна")
+	ErrBookingNotActive    = errors.New("запись уже отменена")
+	ErrBookingConfirmation = errors.New("подтвердить запись может только вторая сторона")
+	ErrNotMentor           = errors.New("вы не являетесь ментором")
+	ErrMentorOnVacation    = errors.New("ментор в отпуске, выберите время после его возвращения")
+	ErrMentorNotAccepting  = errors.New("ментор сейчас не берет новых менти")
+)
+
+// MentorBookingService расписание менторов и записи участников на их услуги
+type MentorBookingService struct {
+	repo       *repository.MentorBookingRepository
+	mentorRepo *repository.MentorRepository
+}
+
+func NewMentorBookingService() *MentorBookingService {
+	return &MentorBookingService{
+		repo:       repository.NewMentorBookingRepository(),
+		mentorRepo: repository.NewMentorRepository(),
+	}
+}
+
+// getMentor возвращает профиль ментора участника
+func (s *MentorBookingService) getMentor(member *models.Member) (*models.MentorDbModel, error) {
+	mentor, err := s.mentorRepo.GetByMemberID(member.Id)
+	if err != nil {
+		return nil, ErrNotMentor
+	}
+	return mentor, nil
+}
+
+// GetMySlots возвращает предстоящие слоты ментора вместе с занятыми
+func (s *MentorBookingService) GetMySlots(member *models.Member) ([]models.MentorSlot, error) {
+	mentor, err := s.getMentor(member)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetSlots(mentor.Id, time.Now())
+}
+
+// GetFreeSlots возвращает предстоящие свободные слоты ментора для записи
+func (s *MentorBookingService) GetFreeSlots(mentorId int64) ([]models.MentorSlot, error) {
+	now := time.Now()
+	slots, err := s.repo.GetSlots(mentorId, now)
+	if err != nil {
+		return nil, err
+	}
+
+	free := make([]models.MentorSlot, 0, len(slots))
+	for _, slot := range slots {
+		if !slot.Booked && slot.StartsAt.After(now) {
+			free = append(free, slot)
+		}
+	}
+	return free, nil
+}
+
+// AddSlots публикует слоты ментора. Слоты не должны пересекаться ни между собой, ни с уже опубликованными
+func (s *MentorBookingService) AddSlots(member *models.Member, requests []models.MentorSlotRequest) ([]models.MentorSlot, error) {
+	mentor, err := s.getMentor(member)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	slots := make([]models.MentorSlot, 0, len(requests))
+	for _, request := range requests {
+		duration := request.EndsAt.Sub(request.StartsAt)
+		if !request.StartsAt.After(now) || duration < mentorSlotMinDuration || duration > mentorSlotMaxDuration {
+			return nil, ErrInvalidSlot
+		}
+
+		for _, other := range slots {
+			if request.StartsAt.Before(other.EndsAt) && request.EndsAt.After(other.StartsAt) {
+				return nil, ErrSlotOverlap
+			}
+		}
+		overlaps, err := s.repo.HasOverlappingSlot(mentor.Id, request.StartsAt, request.EndsAt)
+		if err != nil {
+			return nil, err
+		}
+		if overlaps {
+			return nil, ErrSlotOverlap
+		}
+
+		slots = append(slots, models.MentorSlot{
+			MentorId: mentor.Id,
+			StartsAt: request.StartsAt.UTC(),
+			EndsAt:   request.EndsAt.UTC(),
+		})
+	}
+
+	return s.repo.CreateSlots(slots)
+}
+
+// DeleteSlot снимает слот с публикации, если на него нет активной записи
+func (s *MentorBookingService) DeleteSlot(member *models.Member, slotId int64) error {
+	mentor, err := s.getMentor(member)
+	if err != nil {
+		return err
+	}
+
+	slot, err := s.repo.GetSlot(slotId)
+	if err != nil {
+		return err
+	}
+	if slot.MentorId != mentor.Id {
+		return ErrNotBookingSide
+	}
+	if slot.Booked {
+		return ErrSlotBooked
+	}
+
+	return s.repo.DeleteSlot(slotId)
+}
+
+// GetMemberBookings возвращает записи участника к менторам
+func (s *MentorBookingService) GetMemberBookings(member *models.Member) ([]models.MentorBooking, error) {
+	return s.repo.GetByMember(member.Id)
+}
+
+// GetMentorBookings возвращает записи к ментору
+func (s *MentorBookingService) GetMentorBookings(member *models.Member) ([]models.MentorBooking, error) {
+	mentor, err := s.getMentor(member)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByMentor(mentor.Id)
+}
+
+// Book записывает участника на слот ментора для конкретной услуги. Запись ждет подтверждения ментора
+func (s *MentorBookingService) Book(member *models.Member, request *models.MentorBookingRequest) (*models.MentorBooking, error) {
+	slot, err := s.repo.GetSlot(request.SlotId)
+	if err != nil {
+		return nil, err
+	}
+	if !slot.StartsAt.After(time.Now()) {
+		return nil, ErrSlotInPast
+	}
+
+	mentor, err := s.mentorRepo.GetByIdFull(slot.MentorId)
+	if err != nil {
+		return nil, err
+	}
+	if mentor.MemberId == member.Id {
+		return nil, ErrSelfBooking
+	}
+
+	hasService := false
+	for _, service := range mentor.Services {
+		if service.Id == request.ServiceId {
+			hasService = true
+			break
+		}
+	}
+	if !hasService {
+		return nil, ErrNotMentorService
+	}
+	if err := s.checkAccepting(mentor, member, slot); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Book(&models.MentorBooking{
+		SlotId:    slot.Id,
+		MentorId:  mentor.Id,
+		ServiceId: request.ServiceId,
+		MemberId:  member.Id,
+		Status:    models.MentorBookingPending,
+		Comment:   request.Comment,
+		ChangedBy: &member.Id,
+	})
+}
+
+// checkAccepting проверяет, что ментор примет участника: на время отпуска запись закрыта,
+// а в листе ожидания и без свободных мест записаться могут только текущие менти
+func (s *MentorBookingService) checkAccepting(mentor *models.MentorDbModel, member *models.Member, slot *models.MentorSlot) error {
+	if onVacation(mentor, slot) {
+		return ErrMentorOnVacation
+	}
+
+	isMentee, err := s.repo.IsMentee(mentor.Id, member.Id)
+	if err != nil || isMentee {
+		return err
+	}
+
+	if mentor.Availability == models.MentorWaitlist {
+		return ErrMentorNotAccepting
+	}
+	if mentor.MaxMentees != nil {
+		mentees, err := s.repo.CountActiveMentees([]int64{mentor.Id}, time.Now())
+		if err != nil {
+			return err
+		}
+		if !acceptsMentees(mentor.Availability, mentor.MaxMentees, mentees[mentor.Id]) {
+			return ErrMentorNotAccepting
+		}
+	}
+	return nil
+}
+
+// onVacation попадает ли слот на отпуск ментора
+func onVacation(mentor *models.MentorDbModel, slot *models.MentorSlot) bool {
+	if mentor.Availability != models.MentorVacation {
+		return false
+	}
+	return mentor.VacationUntil == nil || slot.StartsAt.Before(*mentor.VacationUntil)
+}
+
+// getActive возвращает активную запись, если участник одна из ее сторон
+func (s *MentorBookingService) getActive(bookingId int64, member *models.Member) (*models.MentorBooking, error) {
+	booking, err := s.repo.GetById(bookingId)
+	if err != nil {
+		return nil, err
+	}
+	if !isBookingSide(booking, member) {
+		return nil, ErrNotBookingSide
+	}
+	if !booking.Status.IsActive() {
+		return nil, ErrBookingNotActive
+	}
+	return booking, nil
+}
+
+func isBookingSide(booking *models.MentorBooking, member *models.Member) bool {
+	if booking.MemberId == member.Id {
+		return true
+	}
+	return booking.Mentor != nil && booking.Mentor.MemberId == member.Id
+}
+
+// Confirm подтверждает запись. Новую запись подтверждает ментор, перенос — сторона, которая его не предлагала
+func (s *MentorBookingService) Confirm(bookingId int64, member *models.Member) (*models.MentorBooking, error) {
+	booking, err := s.getActive(bookingId, member)
+	if err != nil {
+		return nil, err
+	}
+	if booking.Status == models.MentorBookingConfirmed {
+		return booking, nil
+	}
+	if booking.ChangedBy != nil && *booking.ChangedBy == member.Id {
+		return nil, ErrBookingConfirmation
+	}
+
+	return s.repo.SetStatus(booking.Id, models.MentorBookingConfirmed, member.Id, "")
+}
+
+// Cancel отменяет запись любой из сторон, слот снова становится свободным
+func (s *MentorBookingService) Cancel(bookingId int64, member *models.Member, reason string) (*models.MentorBooking, error) {
+	booking, err := s.getActive(bookingId, member)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.SetStatus(booking.Id, models.MentorBookingCancelled, member.Id, reason)
+}
+
+// Reschedule переносит запись на другой свободный слот того же ментора
+func (s *MentorBookingService) Reschedule(bookingId int64, member *models.Member, slotId int64) (*models.MentorBooking, error) {
+	booking, err := s.getActive(bookingId, member)
+	if err != nil {
+		return nil, err
+	}
+
+	slot, err := s.repo.GetSlot(slotId)
+	if err != nil {
+		return nil, err
+	}
+	if slot.MentorId != booking.MentorId {
+		return nil, ErrNotBookingSide
+	}
+	if !slot.StartsAt.After(time.Now()) {
+		return nil, ErrSlotInPast
+	}
+	if booking.Mentor != nil && onVacation(booking.Mentor, slot) {
+		return nil, ErrMentorOnVacation
+	}
+
+	return s.repo.Reschedule(booking, slotId, member.Id)
+}
+
+// GetDueReminders возвращает подтвержденные встречи, о которых пора напомнить
+func (s *MentorBookingService) GetDueReminders(now time.Time, window time.Duration) ([]models.MentorBooking, error) {
+	return s.repo.GetDueReminders(now, window)
+}
+
+// MarkReminderSent отмечает отправку напоминания
+func (s *MentorBookingService) MarkReminderSent(bookingId int64, sentAt time.Time) error {
+	return s.repo.MarkReminderSent(bookingId, sentAt)
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"errors"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/repository"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRevisionNoChanges   = errors.New("изменений в профиле нет")
+	ErrRevisionInvalid     = errors.New("у услуги должны быть название и неотрицательная цена, у контакта ссылка")
+	ErrRevisionNotPending  = errors.New("правка уже рассмотрена")
+	ErrRevisionNotApproved = errors.New("откатить профиль можно только к одобренной версии")
+	ErrRevisionOtherMentor = errors.New("версии относятся к разным менторам")
+)
+
+// MentorRevisionService модерация и история публичного профиля ментора
+type MentorRevisionService struct {
+	BaseService[models.MentorRevision]
+	repo    *repository.MentorRevisionRepository
+	mentors *MentorService
+}
+
+func NewMentorRevisionService() *MentorRevisionService {
+	repo := repository.NewMentorRevisionRepository()
+	return &MentorRevisionService{
+		BaseService: NewBaseService[models.MentorRevision](repo),
+		repo:        repo,
+		mentors:     NewMentorService(),
+	}
+}
+
+// ProposeInfo отправляет на модерацию специализацию и опыт
+func (s *MentorRevisionService) ProposeInfo(member *models.Member, occupation, experience string) (*models.MentorRevision, error) {
+	return s.propose(member, func(revision *models.MentorRevision, _ *models.MentorDbModel) {
+		revision.Occupation = occupation
+		revision.Experience = experience
+	})
+}
+
+// ProposeServices отправляет на модерацию список услуг
+func (s *MentorRevisionService) ProposeServices(member *models.Member, services []models.Service) (*models.MentorRevision, error) {
+	return s.propose(member, func(revision *models.MentorRevision, mentor *models.MentorDbModel) {
+		owned := make(map[int]bool, len(mentor.Services))
+		for _, service := range mentor.Services {
+			owned[service.Id] = true
+		}
+
+		revision.Services = make([]models.MentorRevisionService, 0, len(services))
+		for _, service := range services {
+			item := models.MentorRevisionService{Name: service.Name, Price: service.Price}
+			if owned[service.Id] {
+				item.ServiceId = service.Id
+			}
+			revision.Services = append(revision.Services, item)
+		}
+	})
+}
+
+// ProposeContacts отправляет на модерацию контакты
+func (s *MentorRevisionService) ProposeContacts(member *models.Member, contacts []models.Contact) (*models.MentorRevision, error) {
+	return s.propose(member, func(revision *models.MentorRevision, mentor *models.MentorDbModel) {
+		owned := make(map[int]bool, len(mentor.Contacts))
+		for _, contact := range mentor.Contacts {
+			owned[contact.Id] = true
+		}
+
+		revision.Contacts = make([]models.MentorRevisionContact, 0, len(contacts))
+		for _, contact := range contacts {
+			item := models.MentorRevisionContact{Type: contact.Type, Link: contact.Link}
+			if owned[contact.Id] {
+				item.ContactId = contact.Id
+			}
+			revision.Contacts = append(revision.Contacts, item)
+		}
+	})
+}
+
+// propose создает правку поверх ожидающей модерации, а если ее нет, поверх текущего профиля.
+// Так несколько правок подряд объединяются в одну версию
+func (s *MentorRevisionService) propose(member *models.Member, change func(revision *models.MentorRevision, mentor *models.MentorDbModel)) (*models.MentorRevision, error) {
+	mentor, err := s.mentors.GetByMemberID(member.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	live := models.NewMentorRevision(mentor)
+	if err := s.ensureBaseline(live); err != nil {
+		return nil, err
+	}
+
+	base := live
+	pending, err := s.repo.GetPending(mentor.Id)
+	if err == nil {
+		base = pending
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	revision := base.Clone()
+	change(revision, mentor)
+	if err := normalizeRevision(revision); err != nil {
+		return nil, err
+	}
+	if len(models.DiffMentorRevisions(live, revision)) == 0 {
+		return nil, ErrRevisionNoChanges
+	}
+
+	revision.Status = models.MentorRevisionPending
+	return s.repo.Create(revision)
+}
+
+// ensureBaseline сохраняет исходный профиль первой версией, чтобы к нему можно было вернуться
+func (s *MentorRevisionService) ensureBaseline(live *models.MentorRevision) error {
+	exists, err := s.repo.HasAny(live.MentorId)
+	if err != nil || exists {
+		return err
+	}
+
+	baseline := live.Clone()
+	baseline.Status = models.MentorRevisionApproved
+	_, err = s.repo.Create(baseline)
+	return err
+}
+
+func normalizeRevision(revision *models.MentorRevision) error {
+	revision.Occupation = strings.TrimSpace(revision.Occupation)
+	revision.Experience = strings.TrimSpace(revision.Experience)
+	for i := range revision.Services {
+		revision.Services[i].Name = strings.TrimSpace(revision.Services[i].Name)
+		if revision.Services[i].Name == "" || revision.Services[i].Price < 0 {
+			return ErrRevisionInvalid
+		}
+	}
+	for i := range revision.Contacts {
+		revision.Contacts[i].Link = strings.TrimSpace(revision.Contacts[i].Link)
+		if revision.Contacts[i].Link == "" {
+			return ErrRevisionInvalid
+		}
+	}
+	return nil
+}
+
+// GetMine возвращает историю профиля текущего ментора
+func (s *MentorRevisionService) GetMine(member *models.Member) ([]models.MentorRevision, error) {
+	mentor, err := s.mentors.GetByMemberID(member.Id)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByMentor(mentor.Id)
+}
+
+// GetHistory возвращает все версии профиля ментора
+func (s *MentorRevisionService) GetHistory(mentorId int64) ([]models.MentorRevision, error) {
+	return s.repo.GetByMentor(mentorId)
+}
+
+// Diff сравнивает версию с другой версией того же ментора или, если она не указана, с текущим профилем
+func (s *MentorRevisionService) Diff(id int64, againstId *int64) (*models.MentorRevisionDiff, error) {
+	revision, err := s.repo.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &models.MentorRevisionDiff{Revision: revision}
+	var base *models.MentorRevision
+	if againstId != nil {
+		if base, err = s.repo.GetById(*againstId); err != nil {
+			return nil, err
+		}
+		if base.MentorId != revision.MentorId {
+			return nil, ErrRevisionOtherMentor
+		}
+		diff.Base = base
+	} else {
+		mentor, err := s.mentors.repo.GetByIdFull(revision.MentorId)
+		if err != nil {
+			return nil, err
+		}
+		base = models.NewMentorRevision(mentor)
+	}
+
+	diff.Changes = models.DiffMentorRevisions(base, revision)
+	return diff, nil
+}
+
+// Approve публикует правку в профиле ментора
+func (s *MentorRevisionService) Approve(id int64, comment string) (*models.MentorRevision, error) {
+	revision, err := s.repo.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	if revision.Status != models.MentorRevisionPending {
+		return nil, ErrRevisionNotPending
+	}
+
+	mentor, err := s.applied(revision)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Approve(revision.Id, strings.TrimSpace(comment), mentor)
+}
+
+// Reject отклоняет правку с обязательным комментарием, профиль остается прежним
+func (s *MentorRevisionService) Reject(id int64, comment string) (*models.MentorRevision, error) {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return nil, ErrRejectCommentRequired
+	}
+
+	revision, err := s.repo.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	if revision.Status != models.MentorRevisionPending {
+		return nil, ErrRevisionNotPending
+	}
+	return s.repo.SetReview(revision.Id, models.MentorRevisionRejected, comment)
+}
+
+// Rollback возвращает профиль к одобренной версии. Откат сохраняется новой версией, история не переписывается
+func (s *MentorRevisionService) Rollback(id int64, comment string) (*models.MentorRevision, error) {
+	target, err := s.repo.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	if target.Status != models.MentorRevisionApproved {
+		return nil, ErrRevisionNotApproved
+	}
+
+	mentor, err := s.applied(target)
+	if err != nil {
+		return nil, err
+	}
+
+	restored := target.Clone()
+	restored.Status = models.MentorRevisionApproved
+	restored.RestoredFromId = &target.Id
+	restored.ReviewComment = strings.TrimSpace(comment)
+	return s.repo.Restore(restored, mentor)
+}
+
+// UpdateByAdmin изменяет профиль из админки без модерации, но с записью в историю
+func (s *MentorRevisionService) UpdateByAdmin(request *models.MentorDbModel) (*models.MentorModel, error) {
+	mentor, err := s.mentors.GetByMemberID(request.MemberId)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureBaseline(models.NewMentorRevision(mentor)); err != nil {
+		return nil, err
+	}
+
+	result, err := s.mentors.UpdateWithRelations(request)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.mentors.repo.GetByIdFull(mentor.Id)
+	if err != nil {
+		return nil, err
+	}
+	revision := models.NewMentorRevision(updated)
+	if len(models.DiffMentorRevisions(models.NewMentorRevision(mentor), revision)) > 0 {
+		revision.Status = models.MentorRevisionApproved
+		if _, err := s.repo.Create(revision); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// applied возвращает профиль ментора с полями версии, сохраняя теги и порядок ментора
+func (s *MentorRevisionService) applied(revision *models.MentorRevision) (*models.MentorDbModel, error) {
+	mentor, err := s.mentors.repo.GetByIdFull(revision.MentorId)
+	if err != nil {
+		return nil, err
+	}
+	revision.ApplyTo(mentor)
+	return mentor, nil
+}
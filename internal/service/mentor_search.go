@@ -0,0 +1,172 @@
+package service
+
+import (
+	"errors"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/repository"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// mentorMatchDefaultLimit сколько менторов подбирается по умолчанию
+	mentorMatchDefaultLimit = 5
+	// mentorMatchMaxLimit больше стольких менторов за раз не подбираем
+	mentorMatchMaxLimit = 50
+	// mentorStemLength длина основы слова при сравнении: «разработчик» и «разработка» совпадут
+	mentorStemLength = 7
+)
+
+var ErrNothingToMatch = errors.New("укажите желаемую позицию или теги, либо загрузите резюме")
+
+// SearchWithRelations ищет менторов по фильтрам и ранжирует их по релевантности и оценкам в отзывах.
+// Без фильтров возвращает менторов в порядке, заданном в админке
+func (s *MentorService) SearchWithRelations(request *models.MentorSearchRequest) (*models.RegistrySearch[models.MentorModel], error) {
+	request.Query = strings.TrimSpace(request.Query)
+	if !request.HasFilters() {
+		return s.GetAllWithRelations(request.Limit, request.Offset)
+	}
+
+	ranked, total, err := s.repo.SearchWithRelations(&repository.MentorRankQuery{
+		Filter: request,
+		TagIds: request.TagIds,
+		Terms:  searchTerms(request.Query),
+		Limit:  request.Limit,
+		Offset: request.Offset,
+		Now:    time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.rankedModels(ranked)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RegistrySearch[models.MentorModel]{Items: items, Total: int(total)}, nil
+}
+
+// Match подбирает менторов под желаемую позицию и теги участника.
+// Если позиция не указана, берется из последнего загруженного резюме
+func (s *MentorService) Match(member *models.Member, request *models.MentorMatchRequest) ([]models.MentorMatch, error) {
+	position := strings.TrimSpace(request.DesiredPosition)
+	if position == "" && len(request.TagIds) == 0 {
+		resumes, err := repository.NewResumeRepository().ListByTelegramID(member.TelegramID)
+		if err != nil {
+			return nil, err
+		}
+		if len(resumes) > 0 {
+			position = resumes[0].DesiredPosition
+		}
+	}
+
+	terms := searchTerms(position)
+	if len(terms) == 0 && len(request.TagIds) == 0 {
+		return nil, ErrNothingToMatch
+	}
+
+	limit := request.Limit
+	switch {
+	case limit <= 0:
+		limit = mentorMatchDefaultLimit
+	case limit > mentorMatchMaxLimit:
+		limit = mentorMatchMaxLimit
+	}
+
+	// Себя самого и менторов в отпуске не подбираем
+	ranked, _, err := s.repo.SearchWithRelations(&repository.MentorRankQuery{
+		TagIds:          request.TagIds,
+		Terms:           terms,
+		ExcludeMemberId: &member.Id,
+		SkipVacation:    true,
+		OnlyRelevant:    true,
+		Limit:           &limit,
+		Now:             time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mentors, err := s.rankedModels(ranked)
+	if err != nil {
+		return nil, err
+	}
+
+	wantedTags := make(map[int64]bool, len(request.TagIds))
+	for _, id := range request.TagIds {
+		wantedTags[id] = true
+	}
+
+	matches := make([]models.MentorMatch, 0, len(mentors))
+	for i, mentor := range mentors {
+		matchedTags := []models.ProfTag{}
+		for _, tag := range mentor.ProfTags {
+			if wantedTags[tag.Id] {
+				matchedTags = append(matchedTags, tag)
+			}
+		}
+		matches = append(matches, models.MentorMatch{
+			Mentor:      mentor,
+			Score:       roundScore(ranked[i].Score),
+			MatchedTags: matchedTags,
+		})
+	}
+	return matches, nil
+}
+
+// rankedModels преобразует страницу поиска в модели с оценками и доступностью
+func (s *MentorService) rankedModels(ranked []repository.RankedMentor) ([]models.MentorModel, error) {
+	mentors := make([]models.MentorModel, 0, len(ranked))
+	for _, r := range ranked {
+		mentors = append(mentors, r.Mentor.ToModel())
+	}
+	if err := s.fillRatings(mentors); err != nil {
+		return nil, err
+	}
+	if err := s.fillAvailability(mentors); err != nil {
+		return nil, err
+	}
+	return mentors, nil
+}
+
+// searchTerms разбивает запрос на основы слов для поиска в базе
+func searchTerms(text string) []repository.MentorSearchTerm {
+	tokens := tokenize(text)
+	terms := make([]repository.MentorSearchTerm, 0, len(tokens))
+	for _, token := range tokens {
+		terms = append(terms, repository.MentorSearchTerm{
+			Stem:  stemOf(token),
+			Whole: len([]rune(token)) < mentorStemLength,
+		})
+	}
+	return terms
+}
+
+func roundScore(value float64) float64 {
+	return float64(int(value*1000+0.5)) / 1000
+}
+
+// tokenize разбивает текст на слова в нижнем регистре, отбрасывая короткие
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#'
+	})
+
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		if len([]rune(word)) >= 2 {
+			tokens = append(tokens, word)
+		}
+	}
+	return tokens
+}
+
+// stemOf грубая основа слова — его начало, чтобы не зависеть от окончаний
+func stemOf(word string) string {
+	runes := []rune(word)
+	if len(runes) > mentorStemLength {
+		return string(runes[:mentorStemLength])
+	}
+	return word
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"ithozyeva/internal/repository"
+	"testing"
+)
+
+func TestSearchTerms(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []repository.MentorSearchTerm
+	}{
+		{name: "пустой запрос", text: "  ", want: nil},
+		{
+			name: "длинные слова сокращаются до основы",
+			text: "Backend-разработчик",
+			want: []repository.MentorSearchTerm{{Stem: "backend"}, {Stem: "разрабо"}},
+		},
+		{
+			name: "короткие слова совпадают целиком, однобуквенные отбрасываются",
+			text: "C++ и Go, a",
+			want: []repository.MentorSearchTerm{{Stem: "c++", Whole: true}, {Stem: "go", Whole: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := searchTerms(tt.text)
+			if len(got) != len(tt.want) {
+				t.Fatalf("searchTerms(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("searchTerms(%q)[%d] = %+v, want %+v", tt.text, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -1,14 +1,22 @@
 package service
 
 import (
+	"errors"
 	"ithozyeva/internal/models"
 	"ithozyeva/internal/repository"
+	"strings"
 	"time"
 )
 
+var (
+	ErrReferalLinkNotAuthor = errors.New("это не ваша реферальная ссылка")
+	ErrReferalLinkArchived  = errors.New("вакансии по ссылке закончились, укажите новые в профиле")
+)
+
 type ReferalLinkService struct {
 	BaseService[models.ReferalLink]
-	repo repository.ReferalLinkRepository
+	repo      repository.ReferalLinkRepository
+	companies *CompanyService
 }
 
 func NewReferalLinkService() *ReferalLinkService {
@@ -16,13 +24,40 @@ func NewReferalLinkService() *ReferalLinkService {
 	return &ReferalLinkService{
 		BaseService: NewBaseService(repo),
 		repo:        *repo,
+		companies:   NewCompanyService(),
 	}
 }
 
+// SearchLinks ищет ссылки по фильтрам
+func (s *ReferalLinkService) SearchLinks(filter *models.ReferalLinkSearchRequest) (*models.RegistrySearch[models.ReferalLink], error) {
+	filter.Company = strings.TrimSpace(filter.Company)
+	filter.Grade = strings.TrimSpace(filter.Grade)
+
+	items, total, err := s.repo.SearchLinks(filter)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RegistrySearch[models.ReferalLink]{
+		Items: items,
+		Total: int(total),
+	}, nil
+}
+
+// GetCompanySummary сводка по компаниям для тех, кто еще не видит самих ссылок
+func (s *ReferalLinkService) GetCompanySummary() ([]models.ReferalCompanySummary, error) {
+	return s.repo.GetCompanySummary()
+}
+
 func (s *ReferalLinkService) AddLink(req *models.AddLinkRequest, member *models.Member) (*models.ReferalLink, error) {
+	company, err := s.companies.Resolve(req.CompanyId, req.Company)
+	if err != nil {
+		return nil, err
+	}
+
 	newEntity := &models.ReferalLink{
 		Author:         *member,
-		Company:        req.Company,
+		CompanyId:      &company.Id,
+		Company:        company.Name,
 		Grade:          req.Grade,
 		ProfTags:       req.ProfTags,
 		Status:         models.ReferalLinkActive,
@@ -35,10 +70,16 @@ func (s *ReferalLinkService) AddLink(req *models.AddLinkRequest, member *models.
 }
 
 func (s *ReferalLinkService) UpdateLink(req *models.UpdateLinkRequest, member *models.Member) (*models.ReferalLink, error) {
+	company, err := s.companies.Resolve(req.CompanyId, req.Company)
+	if err != nil {
+		return nil, err
+	}
+
 	updatedEntity := &models.ReferalLink{
 		Id:             req.Id,
 		Author:         *member,
-		Company:        req.Company,
+		CompanyId:      &company.Id,
+		Company:        company.Name,
 		Grade:          req.Grade,
 		ProfTags:       req.ProfTags,
 		Status:         req.Status,
@@ -48,3 +89,38 @@ func (s *ReferalLinkService) UpdateLink(req *models.UpdateLinkRequest, member *m
 
 	return s.repo.Update(updatedEntity)
 }
+
+// GetStale возвращает ссылки, актуальность которых пора подтвердить
+func (s *ReferalLinkService) GetStale(before time.Time) ([]models.ReferalLink, error) {
+	return s.repo.GetStale(before)
+}
+
+// MarkConfirmationRequested отмечает, что автора попросили подтвердить ссылку
+func (s *ReferalLinkService) MarkConfirmationRequested(id int64, requestedAt time.Time) error {
+	return s.repo.MarkConfirmationRequested(id, requestedAt)
+}
+
+// FreezeUnconfirmed замораживает ссылки, которые автор не подтвердил до before
+func (s *ReferalLinkService) FreezeUnconfirmed(before time.Time) ([]models.ReferalLink, error) {
+	return s.repo.FreezeUnconfirmed(before)
+}
+
+// ArchiveEmpty переносит в архив ссылки без вакансий
+func (s *ReferalLinkService) ArchiveEmpty() ([]models.ReferalLink, error) {
+	return s.repo.ArchiveEmpty()
+}
+
+// Confirm подтверждает актуальность ссылки, замороженная ссылка снова становится активной
+func (s *ReferalLinkService) Confirm(id int64, member *models.Member) (*models.ReferalLink, error) {
+	link, err := s.repo.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	if link.AuthorId != member.Id {
+		return nil, ErrReferalLinkNotAuthor
+	}
+	if link.Status == models.ReferalLinkArchived || link.VacationsCount <= 0 {
+		return nil, ErrReferalLinkArchived
+	}
+	return s.repo.Confirm(id, time.Now())
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/repository"
+	"ithozyeva/internal/utils"
+	"strings"
+	"time"
+)
+
+// referalResumeLinkTTL сколько действует ссылка на резюме из заявки
+const referalResumeLinkTTL = time.Hour
+
+var (
+	ErrReferalLinkInactive  = errors.New("ссылка заморожена или вакансии по ней закончились")
+	ErrReferalOwnLink       = errors.New("нельзя запросить рекомендацию по своей ссылке")
+	ErrReferalResume        = errors.New("приложите одно из своих резюме")
+	ErrReferalNotSide       = errors.New("заявка вам недоступна")
+	ErrReferalTransition    = errors.New("нельзя перевести заявку в этот статус")
+	ErrReferalAuthorDecides = errors.New("статус заявки меняет автор ссылки, кандидат может только отозвать ее")
+	ErrReferalNoResume      = errors.New("участник удалил резюме из заявки")
+)
+
+// referalTransitions допустимые переходы статусов заявки
+var referalTransitions = map[models.ReferalRequestStatus][]models.ReferalRequestStatus{
+	models.ReferalRequestSubmitted:    {models.ReferalRequestReferred, models.ReferalRequestRejected},
+	models.ReferalRequestReferred:     {models.ReferalRequestInterviewing, models.ReferalRequestHired, models.ReferalRequestRejected},
+	models.ReferalRequestInterviewing: {models.ReferalRequestHired, models.ReferalRequestRejected},
+}
+
+// ReferalRequestService заявки участников на рекомендацию по реферальным ссылкам
+type ReferalRequestService struct {
+	repo       *repository.ReferalRequestRepository
+	linkRepo   *repository.ReferalLinkRepository
+	resumeRepo *repository.ResumeRepository
+}
+
+func NewReferalRequestService() *ReferalRequestService {
+	return &ReferalRequestService{
+		repo:       repository.NewReferalRequestRepository(),
+		linkRepo:   repository.NewReferalLinkRepository(),
+		resumeRepo: repository.NewResumeRepository(),
+	}
+}
+
+// Submit отправляет автору ссылки заявку с резюме участника
+func (s *ReferalRequestService) Submit(member *models.Member, req *models.AddReferalRequest) (*models.ReferalRequest, error) {
+	link, err := s.linkRepo.GetById(req.LinkId)
+	if err != nil {
+		return nil, err
+	}
+	if link.AuthorId == member.Id {
+		return nil, ErrReferalOwnLink
+	}
+	if link.Status != models.ReferalLinkActive || link.VacationsCount <= 0 {
+		return nil, ErrReferalLinkInactive
+	}
+
+	// Без явного выбора к заявке прикладывается основное резюме
+	var resume *models.Resume
+	if req.ResumeId == 0 {
+		resume, err = s.resumeRepo.GetPrimary(member.TelegramID)
+	} else {
+		resume, err = s.resumeRepo.GetByIDAndTelegram(req.ResumeId, member.TelegramID)
+	}
+	if err != nil {
+		return nil, ErrReferalResume
+	}
+
+	return s.repo.Create(&models.ReferalRequest{
+		LinkId:    link.Id,
+		MemberId:  member.Id,
+		ResumeId:  &resume.Id,
+		Message:   strings.TrimSpace(req.Message),
+		Status:    models.ReferalRequestSubmitted,
+		ChangedBy: &member.Id,
+	})
+}
+
+// GetMine возвращает заявки, отправленные участником
+func (s *ReferalRequestService) GetMine(member *models.Member) ([]models.ReferalRequest, error) {
+	return s.repo.GetByMember(member.Id)
+}
+
+// GetIncoming возвращает заявки на ссылки участника
+func (s *ReferalRequestService) GetIncoming(member *models.Member) ([]models.ReferalRequest, error) {
+	return s.repo.GetByAuthor(member.Id)
+}
+
+// ChangeStatus двигает заявку по воронке. Статусы отмечает автор ссылки,
+// кандидат может только отозвать заявку (перевести в отклоненные)
+func (s *ReferalRequestService) ChangeStatus(id int64, member *models.Member, req *models.UpdateReferalRequestStatus) (*models.ReferalRequest, error) {
+	request, err := s.get(id, member)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := false
+	for _, next := range referalTransitions[request.Status] {
+		if next == req.Status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, ErrReferalTransition
+	}
+	if req.Status != models.ReferalRequestRejected && !isReferalAuthor(request, member) {
+		return nil, ErrReferalAuthorDecides
+	}
+
+	return s.repo.SetStatus(request, req.Status, strings.TrimSpace(req.Comment), member.Id)
+}
+
+// GetResumeURL выдает временную ссылку на резюме из заявки автору ссылки или самому участнику
+func (s *ReferalRequestService) GetResumeURL(id int64, member *models.Member) (string, error) {
+	request, err := s.get(id, member)
+	if err != nil {
+		return "", err
+	}
+	if request.Resume == nil {
+		return "", ErrReferalNoResume
+	}
+
+	client, err := utils.NewS3Client()
+	if err != nil {
+		return "", err
+	}
+	return client.PresignGet(context.Background(), request.Resume.FilePath, referalResumeLinkTTL)
+}
+
+// get возвращает заявку, если участник ее автор или автор ссылки
+func (s *ReferalRequestService) get(id int64, member *models.Member) (*models.ReferalRequest, error) {
+	request, err := s.repo.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	if request.MemberId != member.Id && !isReferalAuthor(request, member) {
+		return nil, ErrReferalNotSide
+	}
+	return request, nil
+}
+
+func isReferalAuthor(request *models.ReferalRequest, member *models.Member) bool {
+	return request.Link != nil && request.Link.AuthorId == member.Id
+}
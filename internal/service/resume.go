@@ -4,49 +4,55 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log"
-	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 
 	"ithozyeva/internal/models"
 	"ithozyeva/internal/repository"
 	"ithozyeva/internal/utils"
 )
 
+var ErrResumeUnreadable = errors.New("не удалось прочитать файл резюме")
+
 type ResumeService struct {
-	repo *repository.ResumeRepository
+	repo     *repository.ResumeRepository
+	profTags repository.ProfTagRepository
 }
 
 func NewResumeService() *ResumeService {
 	return &ResumeService{
-		repo: repository.NewResumeRepository(),
+		repo:     repository.NewResumeRepository(),
+		profTags: repository.NewProfTagRepository(),
 	}
 }
 
-func (s *ResumeService) UploadResume(member *models.Member, fileName string, contentType string, content []byte, req *models.CreateResumeRequest) (*models.Resume, *utils.ParsedResumeData, error) {
+func (s *ResumeService) UploadResume(member *models.Member, fileName string, content []byte, req *models.CreateResumeRequest) (*models.Resume, *utils.ParsedResumeData, error) {
 	client, err := utils.NewS3Client()
 	if err != nil {
 		return nil, nil, err
 	}
 
-	ext := strings.ToLower(filepath.Ext(fileName))
-	if ext != ".pdf" && ext != ".docx" && ext != ".doc" {
-		return nil, nil, fmt.Errorf("unsupported file format %s", ext)
+	// Формат определяется по содержимому: расширение и Content-Type от клиента могут не совпадать с файлом
+	format, err := utils.DetectResumeFormat(fileName, content)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	key := fmt.Sprintf("resumes/%d/%s%s", member.TelegramID, uuid.NewString(), ext)
-	if err := client.Upload(context.Background(), key, content, contentType); err != nil {
+	key := fmt.Sprintf("resumes/%d/%s%s", member.TelegramID, uuid.NewString(), format.Extension())
+	if err := client.Upload(context.Background(), key, content, format.ContentType()); err != nil {
 		return nil, nil, err
 	}
 
-	var parsed *utils.ParsedResumeData
-	if data, err := utils.ParseResume(fileName, content); err == nil {
-		parsed = data
-	} else {
-		log.Printf("resume parse skipped: %v", err)
+	parsed, parseErr := utils.ParseResume(fileName, content)
+	if parseErr != nil {
+		log.Printf("resume parse skipped: %v", parseErr)
 		parsed = &utils.ParsedResumeData{}
 	}
 
@@ -65,6 +71,13 @@ func (s *ResumeService) UploadResume(member *models.Member, fileName string, con
 		workFormat = parsed.WorkFormat
 	}
 
+	// Новая версия сразу становится основной, изменения считаются относительно предыдущей основной
+	previous, err := s.getPrimary(member.TelegramID)
+	if err != nil {
+		_ = client.Delete(context.Background(), key)
+		return nil, nil, err
+	}
+
 	resume := &models.Resume{
 		TgID:            member.TelegramID,
 		FilePath:        key,
@@ -72,18 +85,141 @@ func (s *ResumeService) UploadResume(member *models.Member, fileName string, con
 		WorkExperience:  workExperience,
 		DesiredPosition: desiredPosition,
 		WorkFormat:      workFormat,
+		IsPrimary:       true,
+	}
+	if parseErr == nil {
+		s.applyProfile(resume, parsed)
+	}
+
+	change := &models.ResumeChange{
+		Kind:    models.ResumeChangeUploaded,
+		Changes: diffResumes(previous, resume),
+	}
+	if previous != nil {
+		change.PreviousResumeId = &previous.Id
 	}
 
-	created, err := s.repo.Create(resume)
+	created, err := s.repo.CreateVersion(resume, change)
 	if err != nil {
 		_ = client.Delete(context.Background(), key)
 		return nil, nil, err
 	}
 
-	created.ParsedConfidence = parsed.Confidence
 	return created, parsed, nil
 }
 
+// Reparse заново разбирает загруженный файл, например после улучшения парсера.
+// Поля, которые участник заполнил сам, не перезаписываются
+func (s *ResumeService) Reparse(id int64) (*models.Resume, error) {
+	resume, err := s.repo.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := utils.NewS3Client()
+	if err != nil {
+		return nil, err
+	}
+	content, err := client.Download(context.Background(), resume.FilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := utils.ParseResume(resume.FileName, content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrResumeUnreadable, err)
+	}
+
+	before := *resume
+	if resume.WorkExperience == "" {
+		resume.WorkExperience = parsed.WorkExperience
+	}
+	if resume.DesiredPosition == "" {
+		resume.DesiredPosition = parsed.DesiredPosition
+	}
+	if resume.WorkFormat == "" && parsed.WorkFormat.IsValid() {
+		resume.WorkFormat = parsed.WorkFormat
+	}
+	s.applyProfile(resume, parsed)
+
+	var change *models.ResumeChange
+	if changes := diffResumes(&before, resume); len(changes) > 0 {
+		change = &models.ResumeChange{Kind: models.ResumeChangeReparsed, Changes: changes}
+	}
+	return s.repo.SaveProfile(resume, change)
+}
+
+// ReindexMissing извлекает текст для поиска из резюме, загруженных до появления индекса.
+// Обрабатывает одну порцию, возвращает сколько резюме проиндексировано и сколько файлов не прочитано
+func (s *ResumeService) ReindexMissing() (int, int, error) {
+	resumes, err := s.repo.ListUnindexed()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	indexed, failed := 0, 0
+	for _, resume := range resumes {
+		if _, err := s.Reparse(resume.Id); err != nil {
+			if !errors.Is(err, ErrResumeUnreadable) {
+				return indexed, failed, err
+			}
+			log.Printf("resume %d is not indexed: %v", resume.Id, err)
+			if err := s.repo.MarkUnreadable(resume.Id); err != nil {
+				return indexed, failed, err
+			}
+			failed++
+			continue
+		}
+		indexed++
+	}
+	return indexed, failed, nil
+}
+
+// applyProfile переносит разбор в резюме и сопоставляет навыки с тегами платформы
+func (s *ResumeService) applyProfile(resume *models.Resume, parsed *utils.ParsedResumeData) {
+	now := time.Now()
+	profile := parsed.Profile
+	resume.Profile = &profile
+	resume.ParsedAt = &now
+	resume.Text = parsed.Text
+	resume.ExperienceYears = nil
+	if profile.ExperienceYears > 0 {
+		years := profile.ExperienceYears
+		resume.ExperienceYears = &years
+	}
+
+	tags, _, err := s.profTags.Search(nil, nil, nil, nil)
+	if err != nil {
+		log.Printf("resume skills are not matched with tags: %v", err)
+		return
+	}
+	resume.ProfTags = matchSkillTags(profile.Skills, tags)
+}
+
+// matchSkillTags находит теги, совпадающие с навыками без учета регистра, пробелов и точек: «NodeJS» = «Node.js»
+func matchSkillTags(skills []string, tags []models.ProfTag) []models.ProfTag {
+	byName := make(map[string]models.ProfTag, len(tags))
+	for _, tag := range tags {
+		byName[normalizeSkill(tag.Title)] = tag
+	}
+
+	matched := []models.ProfTag{}
+	seen := make(map[int64]bool)
+	for _, skill := range skills {
+		tag, ok := byName[normalizeSkill(skill)]
+		if !ok || seen[tag.Id] {
+			continue
+		}
+		seen[tag.Id] = true
+		matched = append(matched, tag)
+	}
+	return matched
+}
+
+func normalizeSkill(name string) string {
+	return strings.NewReplacer(" ", "", ".", "", "-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
 func (s *ResumeService) ListByTelegramID(tgID int64) ([]models.Resume, error) {
 	return s.repo.ListByTelegramID(tgID)
 }
@@ -93,6 +229,7 @@ func (s *ResumeService) UpdateResume(id, tgID int64, payload *models.UpdateResum
 	if err != nil {
 		return nil, err
 	}
+	before := *resume
 
 	if payload.WorkExperience != nil {
 		resume.WorkExperience = strings.TrimSpace(*payload.WorkExperience)
@@ -104,7 +241,67 @@ func (s *ResumeService) UpdateResume(id, tgID int64, payload *models.UpdateResum
 		resume.WorkFormat = *payload.WorkFormat
 	}
 
-	return s.repo.Update(resume)
+	changes := diffResumes(&before, resume)
+	if len(changes) == 0 {
+		return s.repo.GetByIdWithMember(resume.Id)
+	}
+	return s.repo.UpdateVersion(resume, &models.ResumeChange{Kind: models.ResumeChangeUpdated, Changes: changes})
+}
+
+// SetPrimary делает версию основным резюме участника. В историю попадает разница с прежним основным
+func (s *ResumeService) SetPrimary(id, tgID int64) (*models.Resume, error) {
+	resume, err := s.repo.GetByIDAndTelegram(id, tgID)
+	if err != nil {
+		return nil, err
+	}
+	if resume.IsPrimary {
+		return s.repo.GetByIdWithMember(resume.Id)
+	}
+
+	previous, err := s.getPrimary(tgID)
+	if err != nil {
+		return nil, err
+	}
+
+	change := &models.ResumeChange{
+		Kind:    models.ResumeChangePrimary,
+		Changes: diffResumes(previous, resume),
+	}
+	if previous != nil {
+		change.PreviousResumeId = &previous.Id
+	}
+	return s.repo.SetPrimary(resume, change)
+}
+
+// GetHistory возвращает версии резюме участника и историю их изменений
+func (s *ResumeService) GetHistory(tgID int64) (*models.ResumeHistory, error) {
+	versions, err := s.repo.ListByTelegramID(tgID)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := s.repo.ListChanges(tgID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ResumeHistory{Versions: versions, Changes: changes}, nil
+}
+
+// GetHistoryForAdmin возвращает историю участника, которому принадлежит резюме
+func (s *ResumeService) GetHistoryForAdmin(id int64) (*models.ResumeHistory, error) {
+	resume, err := s.repo.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetHistory(resume.TgID)
+}
+
+// getPrimary возвращает основное резюме или nil, если у участника еще нет резюме
+func (s *ResumeService) getPrimary(tgID int64) (*models.Resume, error) {
+	resume, err := s.repo.GetPrimary(tgID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return resume, err
 }
 
 func (s *ResumeService) DeleteResume(id, tgID int64) error {
@@ -122,7 +319,7 @@ func (s *ResumeService) DeleteResume(id, tgID int64) error {
 		return err
 	}
 
-	return s.repo.Delete(resume)
+	return s.repo.DeleteVersion(resume)
 }
 
 func (s *ResumeService) SearchForAdmin(limit *int, offset *int, filter *models.ResumeFilter) (*models.RegistrySearch[models.Resume], error) {
@@ -154,8 +351,7 @@ func (s *ResumeService) GenerateArchive(filter *models.ResumeFilter) ([]byte, er
 		if err != nil {
 			return nil, err
 		}
-		filename := fmt.Sprintf("%d_%s", resume.TgID, sanitizeFileName(resume.FileName))
-		w, err := zipWriter.Create(filename)
+		w, err := zipWriter.Create(archiveFileName(&resume))
 		if err != nil {
 			return nil, err
 		}
@@ -171,11 +367,136 @@ func (s *ResumeService) GenerateArchive(filter *models.ResumeFilter) ([]byte, er
 	return buf.Bytes(), nil
 }
 
+// archiveFileName имя резюме в архиве. Версия и ID резюме не дают версиям
+// с одинаковым именем файла перезаписать друг друга
+func archiveFileName(resume *models.Resume) string {
+	return fmt.Sprintf("%d_v%d_%d_%s", resume.TgID, resume.Version, resume.Id, sanitizeFileName(resume.FileName))
+}
+
 func sanitizeFileName(name string) string {
-	replacer := strings.NewReplacer(" ", "_")
+	replacer := strings.NewReplacer(" ", "_", "/", "_", "\\", "_")
 	return replacer.Replace(name)
 }
 
 func (s *ResumeService) GetByIdWithMember(id int64) (*models.Resume, error) {
 	return s.repo.GetByIdWithMember(id)
 }
+
+// diffResumes сравнивает поля резюме, которые видит рекрутер. before nil — первая версия
+func diffResumes(before, after *models.Resume) []models.ResumeFieldChange {
+	if before == nil {
+		before = &models.Resume{}
+	}
+	changes := []models.ResumeFieldChange{}
+
+	scalars := []struct {
+		field         string
+		before, after string
+	}{
+		{"fileName", before.FileName, after.FileName},
+		{"desiredPosition", before.DesiredPosition, after.DesiredPosition},
+		{"workExperience", before.WorkExperience, after.WorkExperience},
+		{"workFormat", string(before.WorkFormat), string(after.WorkFormat)},
+		{"experienceYears", formatExperienceYears(before.ExperienceYears), formatExperienceYears(after.ExperienceYears)},
+	}
+	for _, scalar := range scalars {
+		if scalar.before != scalar.after {
+			changes = append(changes, models.ResumeFieldChange{Field: scalar.field, Before: scalar.before, After: scalar.after})
+		}
+	}
+
+	lists := []struct {
+		field         string
+		before, after []string
+	}{
+		{"skills", resumeSkills(before), resumeSkills(after)},
+		{"experience", resumeCompanies(before), resumeCompanies(after)},
+		{"education", resumeEducation(before), resumeEducation(after)},
+		{"contacts", resumeContacts(before), resumeContacts(after)},
+	}
+	for _, list := range lists {
+		added, removed := diffStrings(list.before, list.after)
+		if len(added) > 0 || len(removed) > 0 {
+			changes = append(changes, models.ResumeFieldChange{Field: list.field, Added: added, Removed: removed})
+		}
+	}
+
+	return changes
+}
+
+// diffStrings возвращает значения, которые появились и пропали, без учета регистра
+func diffStrings(before, after []string) ([]string, []string) {
+	inBefore := make(map[string]bool, len(before))
+	for _, value := range before {
+		inBefore[strings.ToLower(value)] = true
+	}
+	inAfter := make(map[string]bool, len(after))
+	for _, value := range after {
+		inAfter[strings.ToLower(value)] = true
+	}
+
+	var added, removed []string
+	for _, value := range after {
+		if !inBefore[strings.ToLower(value)] {
+			added = append(added, value)
+		}
+	}
+	for _, value := range before {
+		if !inAfter[strings.ToLower(value)] {
+			removed = append(removed, value)
+		}
+	}
+	return added, removed
+}
+
+func formatExperienceYears(years *float64) string {
+	if years == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*years, 'f', 1, 64)
+}
+
+func resumeSkills(resume *models.Resume) []string {
+	if resume.Profile == nil {
+		return nil
+	}
+	return resume.Profile.Skills
+}
+
+func resumeCompanies(resume *models.Resume) []string {
+	if resume.Profile == nil {
+		return nil
+	}
+	var companies []string
+	for _, entry := range resume.Profile.Experience {
+		value := strings.TrimSpace(strings.Join([]string{entry.Company, entry.Position}, " — "))
+		companies = append(companies, strings.Trim(value, " —"))
+	}
+	return companies
+}
+
+func resumeEducation(resume *models.Resume) []string {
+	if resume.Profile == nil {
+		return nil
+	}
+	var education []string
+	for _, entry := range resume.Profile.Education {
+		value := entry.Institution
+		if entry.Year != nil {
+			value = fmt.Sprintf("%s (%d)", value, *entry.Year)
+		}
+		education = append(education, value)
+	}
+	return education
+}
+
+func resumeContacts(resume *models.Resume) []string {
+	if resume.Profile == nil {
+		return nil
+	}
+	contacts := resume.Profile.Contacts
+	var values []string
+	values = append(values, contacts.Emails...)
+	values = append(values, contacts.Phones...)
+	return append(values, contacts.Links...)
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"ithozyeva/internal/models"
+	"testing"
+)
+
+func TestArchiveFileName(t *testing.T) {
+	tests := []struct {
+		name   string
+		resume models.Resume
+		want   string
+	}{
+		{
+			name:   "пробелы заменяются",
+			resume: models.Resume{Id: 7, TgID: 100, Version: 1, FileName: "Иван Петров.pdf"},
+			want:   "100_v1_7_Иван_Петров.pdf",
+		},
+		{
+			name:   "разделители путей не создают папок в архиве",
+			resume: models.Resume{Id: 8, TgID: 100, Version: 2, FileName: `../cv\old.docx`},
+			want:   "100_v2_8_.._cv_old.docx",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := archiveFileName(&tt.resume); got != tt.want {
+				t.Errorf("archiveFileName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Версии одного участника с одинаковым именем файла не должны совпадать в архиве
+	first := archiveFileName(&models.Resume{Id: 1, TgID: 100, Version: 1, FileName: "cv.pdf"})
+	second := archiveFileName(&models.Resume{Id: 2, TgID: 100, Version: 2, FileName: "cv.pdf"})
+	if first == second {
+		t.Errorf("archiveFileName() gives the same name %q for different versions", first)
+	}
+}
@@ -1,13 +1,22 @@
 package service
 
 import (
+	"errors"
 	"ithozyeva/internal/models"
 	"ithozyeva/internal/repository"
+	"strings"
+	"time"
+)
+
+var (
+	ErrBookingNotCompleted = errors.New("отзыв можно оставить только после состоявшейся встречи")
+	ErrBookingReviewed     = errors.New("отзыв по этой встрече уже оставлен")
 )
 
 type ReviewOnServiceService struct {
 	BaseService[models.ReviewOnService]
-	repository *repository.ReviewOnServiceRepository
+	repository  *repository.ReviewOnServiceRepository
+	bookingRepo *repository.MentorBookingRepository
 }
 
 func NewReviewOnServiceService() *ReviewOnServiceService {
@@ -15,6 +24,7 @@ func NewReviewOnServiceService() *ReviewOnServiceService {
 	return &ReviewOnServiceService{
 		BaseService: NewBaseService(repo),
 		repository:  repo,
+		bookingRepo: repository.NewMentorBookingRepository(),
 	}
 }
 
@@ -41,3 +51,80 @@ func (s *ReviewOnServiceService) GetReviewsWithMentorInfo(limit *int, offset *in
 		Total: int(total),
 	}, nil
 }
+
+// AddVerifiedReview сохраняет отзыв участника на услугу, которую он получил на подтвержденной
+// и уже прошедшей встрече. Отзыв публикуется после модерации
+func (s *ReviewOnServiceService) AddVerifiedReview(member *models.Member, bookingId int64, request *models.AddReviewOnServiceRequest) (*models.ReviewOnService, error) {
+	if request.Rating < 1 || request.Rating > 5 {
+		return nil, ErrInvalidRating
+	}
+
+	booking, err := s.bookingRepo.GetById(bookingId)
+	if err != nil {
+		return nil, err
+	}
+	if booking.MemberId != member.Id {
+		return nil, ErrNotBookingSide
+	}
+	if booking.Status != models.MentorBookingConfirmed || booking.Slot == nil || booking.Slot.EndsAt.After(time.Now()) {
+		return nil, ErrBookingNotCompleted
+	}
+
+	reviewed, err := s.repository.ExistsForBooking(booking.Id)
+	if err != nil {
+		return nil, err
+	}
+	if reviewed {
+		return nil, ErrBookingReviewed
+	}
+
+	rating := request.Rating
+	review, err := s.repository.Create(&models.ReviewOnService{
+		ServiceId: booking.ServiceId,
+		Author:    strings.TrimSpace(member.FirstName + " " + member.LastName),
+		Text:      strings.TrimSpace(request.Text),
+		Date:      time.Now().Format(models.DateFormat),
+		MemberId:  &member.Id,
+		BookingId: &booking.Id,
+		Rating:    &rating,
+		Status:    models.ReviewOnServiceStatusDraft,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repository.GetById(int64(review.Id))
+}
+
+// Update сохраняет правки модератора. Автор и встреча не меняются, статус меняется только если передан
+func (s *ReviewOnServiceService) Update(review *models.ReviewOnService) (*models.ReviewOnService, error) {
+	existing, err := s.repository.GetById(int64(review.Id))
+	if err != nil {
+		return nil, err
+	}
+
+	review.MemberId = existing.MemberId
+	review.BookingId = existing.BookingId
+	if review.Status == "" {
+		review.Status = existing.Status
+	}
+	if review.Rating != nil && (*review.Rating < 1 || *review.Rating > 5) {
+		return nil, ErrInvalidRating
+	}
+
+	return s.repository.Update(review)
+}
+
+// Approve публикует отзыв после модерации
+func (s *ReviewOnServiceService) Approve(id int64) (*models.ReviewOnService, error) {
+	review, err := s.repository.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if review.Status == models.ReviewOnServiceStatusApproved {
+		return review, nil
+	}
+
+	review.Status = models.ReviewOnServiceStatusApproved
+	return s.repository.Update(review)
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"errors"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/repository"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// savedSearchDigestInterval как часто приходит дайджест ежедневных поисков
+	savedSearchDigestInterval = 24 * time.Hour
+	// savedSearchMaxItems сколько результатов показывается в одном сообщении
+	savedSearchMaxItems = 10
+	// savedSearchMaxPerMember ограничение на количество сохраненных поисков участника
+	savedSearchMaxPerMember = 20
+)
+
+var (
+	ErrSavedSearchInvalidKind      = errors.New("неизвестный вид поиска")
+	ErrSavedSearchInvalidFrequency = errors.New("частота уведомлений должна быть INSTANT или DAILY")
+	ErrSavedSearchEmpty            = errors.New("укажите хотя бы одно условие поиска")
+	ErrSavedSearchForbidden        = errors.New("поиск по резюме доступен только администраторам")
+	ErrSavedSearchLimit            = errors.New("можно сохранить не больше 20 поисков")
+)
+
+var savedSearchNames = map[models.SavedSearchKind]string{
+	models.SavedSearchReferalLinks: "Реферальные ссылки",
+	models.SavedSearchVacancies:    "Вакансии",
+	models.SavedSearchResumes:      "Резюме",
+}
+
+// SavedSearchService сохраненные поиски участников и сбор новых результатов для уведомлений
+type SavedSearchService struct {
+	BaseService[models.SavedSearch]
+	repo      *repository.SavedSearchRepository
+	members   *repository.MemberRepository
+	links     *repository.ReferalLinkRepository
+	vacancies *repository.VacancyRepository
+	resumes   *repository.ResumeRepository
+}
+
+func NewSavedSearchService() *SavedSearchService {
+	repo := repository.NewSavedSearchRepository()
+	return &SavedSearchService{
+		BaseService: NewBaseService[models.SavedSearch](repo),
+		repo:        repo,
+		members:     repository.NewMemberRepository(),
+		links:       repository.NewReferalLinkRepository(),
+		vacancies:   repository.NewVacancyRepository(),
+		resumes:     repository.NewResumeRepository(),
+	}
+}
+
+// GetMine возвращает сохраненные поиски участника
+func (s *SavedSearchService) GetMine(member *models.Member) ([]models.SavedSearch, error) {
+	return s.repo.GetByMember(member.Id)
+}
+
+// Save сохраняет поиск. Уведомления придут только о результатах, появившихся после сохранения
+func (s *SavedSearchService) Save(member *models.Member, req *models.SavedSearchRequest) (*models.SavedSearch, error) {
+	count, err := s.repo.CountByMember(member.Id)
+	if err != nil {
+		return nil, err
+	}
+	if count >= savedSearchMaxPerMember {
+		return nil, ErrSavedSearchLimit
+	}
+
+	search, err := s.fromRequest(member, req)
+	if err != nil {
+		return nil, err
+	}
+	search.MemberId = member.Id
+	search.CheckedAt = time.Now()
+
+	return s.repo.Create(search)
+}
+
+// UpdateMine меняет название, условия и частоту поиска. Вид поиска не меняется
+func (s *SavedSearchService) UpdateMine(id int64, member *models.Member, req *models.SavedSearchRequest) (*models.SavedSearch, error) {
+	existing, err := s.repo.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	if existing.MemberId != member.Id {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	req.Kind = existing.Kind
+	search, err := s.fromRequest(member, req)
+	if err != nil {
+		return nil, err
+	}
+	search.Id = existing.Id
+
+	return s.repo.Update(search)
+}
+
+// DeleteMine удаляет поиск участника
+func (s *SavedSearchService) DeleteMine(id int64, member *models.Member) error {
+	return s.repo.DeleteForMember(id, member.Id)
+}
+
+// GetDue возвращает поиски, которые пора проверить на новые результаты
+func (s *SavedSearchService) GetDue(now time.Time) ([]models.SavedSearch, error) {
+	return s.repo.GetDue(now.Add(-savedSearchDigestInterval))
+}
+
+// Collect находит результаты, появившиеся с прошлой проверки до until. Свои ссылки и вакансии участник не получает
+func (s *SavedSearchService) Collect(search *models.SavedSearch, until time.Time) (*models.SavedSearchMatches, error) {
+	matches := &models.SavedSearchMatches{Search: search, Until: until}
+	filter := search.Filter
+	limit := savedSearchMaxItems
+	after := search.CheckedAt
+
+	switch search.Kind {
+	case models.SavedSearchReferalLinks:
+		links, total, err := s.links.SearchLinks(&models.ReferalLinkSearchRequest{
+			Limit:         &limit,
+			Company:       filter.Company,
+			Grade:         filter.Grade,
+			TagIds:        filter.TagIds,
+			Status:        models.ReferalLinkActive,
+			CreatedAfter:  &after,
+			CreatedBefore: &until,
+		})
+		if err != nil {
+			return nil, err
+		}
+		matches.Total = int(total)
+		for _, link := range links {
+			if link.AuthorId == search.MemberId {
+				matches.Total--
+				continue
+			}
+			matches.Links = append(matches.Links, link)
+		}
+
+	case models.SavedSearchVacancies:
+		vacancies, total, err := s.vacancies.SearchPublished(&models.VacancySearchRequest{
+			Limit:           &limit,
+			TagIds:          filter.TagIds,
+			Grade:           filter.Grade,
+			WorkFormat:      filter.WorkFormat,
+			Company:         filter.Company,
+			SalaryMin:       filter.SalaryMin,
+			PublishedAfter:  &after,
+			PublishedBefore: &until,
+		}, until, false)
+		if err != nil {
+			return nil, err
+		}
+		matches.Total = int(total)
+		for _, vacancy := range vacancies {
+			if vacancy.AuthorId == search.MemberId {
+				matches.Total--
+				continue
+			}
+			matches.Vacancies = append(matches.Vacancies, vacancy)
+		}
+
+	case models.SavedSearchResumes:
+		// Право на резюме могли отозвать после сохранения поиска
+		if !s.members.HasPermission(search.MemberId, models.PermissionCanViewAdminResumes) {
+			return matches, nil
+		}
+		resumeFilter := &models.ResumeFilter{CreatedAfter: &after, CreatedBefore: &until}
+		if filter.WorkFormat != "" {
+			resumeFilter.WorkFormat = &filter.WorkFormat
+		}
+		if filter.DesiredPosition != "" {
+			resumeFilter.DesiredPosition = &filter.DesiredPosition
+		}
+		if filter.WorkExperience != "" {
+			resumeFilter.WorkExperience = &filter.WorkExperience
+		}
+		resumes, total, err := s.resumes.SearchForAdmin(&limit, nil, resumeFilter)
+		if err != nil {
+			return nil, err
+		}
+		matches.Total = int(total)
+		matches.Resumes = resumes
+	}
+
+	return matches, nil
+}
+
+// MarkChecked отмечает, что результаты до checkedAt просмотрены
+func (s *SavedSearchService) MarkChecked(id int64, checkedAt time.Time, notified bool) error {
+	return s.repo.MarkChecked(id, checkedAt, notified)
+}
+
+// fromRequest проверяет условия поиска и право участника на выбранный вид
+func (s *SavedSearchService) fromRequest(member *models.Member, req *models.SavedSearchRequest) (*models.SavedSearch, error) {
+	if !req.Kind.IsValid() {
+		return nil, ErrSavedSearchInvalidKind
+	}
+	if req.Frequency == "" {
+		req.Frequency = models.SavedSearchDaily
+	}
+	if !req.Frequency.IsValid() {
+		return nil, ErrSavedSearchInvalidFrequency
+	}
+	if req.Kind == models.SavedSearchResumes && !s.members.HasPermission(member.Id, models.PermissionCanViewAdminResumes) {
+		return nil, ErrSavedSearchForbidden
+	}
+
+	filter := models.SavedSearchFilter{
+		Grade:           strings.TrimSpace(req.Filter.Grade),
+		Company:         strings.TrimSpace(req.Filter.Company),
+		WorkFormat:      req.Filter.WorkFormat,
+		DesiredPosition: strings.TrimSpace(req.Filter.DesiredPosition),
+		WorkExperience:  strings.TrimSpace(req.Filter.WorkExperience),
+	}
+	if !filter.WorkFormat.IsValid() {
+		return nil, ErrVacancyWorkFormat
+	}
+
+	// Поля, которые этот вид поиска не использует, не сохраняются
+	switch req.Kind {
+	case models.SavedSearchReferalLinks:
+		filter.TagIds = req.Filter.TagIds
+		filter.WorkFormat = ""
+		filter.DesiredPosition = ""
+		filter.WorkExperience = ""
+	case models.SavedSearchVacancies:
+		filter.TagIds = req.Filter.TagIds
+		filter.SalaryMin = req.Filter.SalaryMin
+		filter.DesiredPosition = ""
+		filter.WorkExperience = ""
+	case models.SavedSearchResumes:
+		filter.Grade = ""
+		filter.Company = ""
+	}
+	if len(filter.TagIds) == 0 && filter.Grade == "" && filter.Company == "" && filter.WorkFormat == "" &&
+		filter.SalaryMin == nil && filter.DesiredPosition == "" && filter.WorkExperience == "" {
+		return nil, ErrSavedSearchEmpty
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = savedSearchNames[req.Kind]
+	}
+
+	return &models.SavedSearch{
+		Name:      name,
+		Kind:      req.Kind,
+		Filter:    filter,
+		Frequency: req.Frequency,
+	}, nil
+}
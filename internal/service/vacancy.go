@@ -0,0 +1,228 @@
+package service
+
+import (
+	"errors"
+	"ithozyeva/internal/models"
+	"ithozyeva/internal/repository"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// vacancyDefaultTTL сколько висит вакансия, если автор не указал срок
+	vacancyDefaultTTL = 30 * 24 * time.Hour
+	// vacancyMaxTTL больше этого срока вакансия не публикуется без продления
+	vacancyMaxTTL = 90 * 24 * time.Hour
+)
+
+var (
+	ErrVacancyInvalid    = errors.New("укажите название вакансии и компанию")
+	ErrVacancySalary     = errors.New("неверная вилка зарплаты")
+	ErrVacancyWorkFormat = errors.New("неизвестный формат работы")
+	ErrVacancyExpiry     = errors.New("срок вакансии должен быть в будущем и не дольше 90 дней")
+	ErrVacancyNotAuthor  = errors.New("это не ваша вакансия")
+	ErrVacancyClosed     = errors.New("вакансия закрыта")
+)
+
+// VacancyService вакансии участников, их модерация и рассылка подписчикам тегов
+type VacancyService struct {
+	BaseService[models.Vacancy]
+	repo      *repository.VacancyRepository
+	companies *CompanyService
+}
+
+func NewVacancyService() *VacancyService {
+	repo := repository.NewVacancyRepository()
+	return &VacancyService{
+		BaseService: NewBaseService[models.Vacancy](repo),
+		repo:        repo,
+		companies:   NewCompanyService(),
+	}
+}
+
+// SearchPublished ищет опубликованные вакансии. Без участника авторы не показываются
+func (s *VacancyService) SearchPublished(filter *models.VacancySearchRequest, member *models.Member) (*models.RegistrySearch[models.Vacancy], error) {
+	filter.Grade = strings.TrimSpace(filter.Grade)
+
+	items, total, err := s.repo.SearchPublished(filter, time.Now(), member != nil)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RegistrySearch[models.Vacancy]{
+		Items: items,
+		Total: int(total),
+	}, nil
+}
+
+// GetPublished возвращает опубликованную вакансию. Без участника автор не показывается
+func (s *VacancyService) GetPublished(id int64, member *models.Member) (*models.Vacancy, error) {
+	vacancy, err := s.repo.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	isAuthor := member != nil && member.Id == vacancy.AuthorId
+	if !isAuthor && (vacancy.Status != models.VacancyPublished || !vacancy.ExpiresAt.After(time.Now())) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if member == nil {
+		vacancy.Author = nil
+	}
+	return vacancy, nil
+}
+
+// GetMine возвращает вакансии участника
+func (s *VacancyService) GetMine(member *models.Member) ([]models.Vacancy, error) {
+	return s.repo.GetByAuthor(member.Id)
+}
+
+// Submit отправляет вакансию на модерацию
+func (s *VacancyService) Submit(member *models.Member, req *models.VacancyRequest) (*models.Vacancy, error) {
+	vacancy, err := s.fromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	vacancy.AuthorId = member.Id
+	vacancy.Status = models.VacancyPending
+
+	return s.repo.Create(vacancy)
+}
+
+// UpdateMine сохраняет изменения автора. Измененная вакансия заново проходит модерацию
+func (s *VacancyService) UpdateMine(id int64, member *models.Member, req *models.VacancyRequest) (*models.Vacancy, error) {
+	existing, err := s.getMine(id, member)
+	if err != nil {
+		return nil, err
+	}
+	if existing.Status == models.VacancyClosed {
+		return nil, ErrVacancyClosed
+	}
+
+	vacancy, err := s.fromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	vacancy.Id = existing.Id
+	vacancy.AuthorId = existing.AuthorId
+	vacancy.Status = models.VacancyPending
+
+	return s.repo.Update(vacancy)
+}
+
+// Close снимает вакансию с публикации
+func (s *VacancyService) Close(id int64, member *models.Member) (*models.Vacancy, error) {
+	vacancy, err := s.getMine(id, member)
+	if err != nil {
+		return nil, err
+	}
+	if vacancy.Status == models.VacancyClosed {
+		return nil, ErrVacancyClosed
+	}
+	return s.repo.SetStatus(id, vacancy.Status, models.VacancyClosed, "")
+}
+
+// DeleteMine удаляет вакансию автора
+func (s *VacancyService) DeleteMine(id int64, member *models.Member) error {
+	vacancy, err := s.getMine(id, member)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(vacancy)
+}
+
+// Approve публикует вакансию, после этого бот разошлет ее подписчикам тегов
+func (s *VacancyService) Approve(id int64, comment string) (*models.Vacancy, error) {
+	return s.repo.SetStatus(id, models.VacancyPending, models.VacancyPublished, strings.TrimSpace(comment))
+}
+
+// Reject отклоняет вакансию с обязательным комментарием
+func (s *VacancyService) Reject(id int64, comment string) (*models.Vacancy, error) {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return nil, ErrRejectCommentRequired
+	}
+	return s.repo.SetStatus(id, models.VacancyPending, models.VacancyRejected, comment)
+}
+
+// GetUnannounced возвращает опубликованные вакансии, которые еще не разосланы
+func (s *VacancyService) GetUnannounced() ([]models.Vacancy, error) {
+	return s.repo.GetUnannounced(time.Now())
+}
+
+// MarkAnnounced отмечает, что вакансия разослана
+func (s *VacancyService) MarkAnnounced(id int64, announcedAt time.Time) error {
+	return s.repo.MarkAnnounced(id, announcedAt)
+}
+
+// GetTagSubscribers возвращает участников, подписанных на теги вакансии
+func (s *VacancyService) GetTagSubscribers(vacancy *models.Vacancy) ([]models.Member, error) {
+	return s.repo.GetTagSubscribers(vacancy)
+}
+
+// GetSubscription возвращает теги, новые вакансии по которым участник получает в боте
+func (s *VacancyService) GetSubscription(member *models.Member) ([]models.ProfTag, error) {
+	return s.repo.GetSubscriptionTags(member.Id)
+}
+
+// SetSubscription заменяет теги подписки участника. Пустой список отключает рассылку
+func (s *VacancyService) SetSubscription(member *models.Member, tagIds []int64) ([]models.ProfTag, error) {
+	if err := s.repo.SetSubscriptionTags(member.Id, tagIds); err != nil {
+		return nil, err
+	}
+	return s.repo.GetSubscriptionTags(member.Id)
+}
+
+func (s *VacancyService) getMine(id int64, member *models.Member) (*models.Vacancy, error) {
+	vacancy, err := s.repo.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	if vacancy.AuthorId != member.Id {
+		return nil, ErrVacancyNotAuthor
+	}
+	return vacancy, nil
+}
+
+// fromRequest проверяет вакансию от автора и привязывает ее к компании из справочника
+func (s *VacancyService) fromRequest(req *models.VacancyRequest) (*models.Vacancy, error) {
+	title := strings.TrimSpace(req.Title)
+	if title == "" || (req.CompanyId == nil && strings.TrimSpace(req.Company) == "") {
+		return nil, ErrVacancyInvalid
+	}
+	if !req.WorkFormat.IsValid() {
+		return nil, ErrVacancyWorkFormat
+	}
+	if (req.SalaryFrom != nil && *req.SalaryFrom < 0) || (req.SalaryTo != nil && *req.SalaryTo < 0) ||
+		(req.SalaryFrom != nil && req.SalaryTo != nil && *req.SalaryFrom > *req.SalaryTo) {
+		return nil, ErrVacancySalary
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(vacancyDefaultTTL)
+	if req.ExpiresAt != nil {
+		expiresAt = *req.ExpiresAt
+	}
+	if !expiresAt.After(now) || expiresAt.After(now.Add(vacancyMaxTTL)) {
+		return nil, ErrVacancyExpiry
+	}
+
+	company, err := s.companies.Resolve(req.CompanyId, req.Company)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Vacancy{
+		Title:       title,
+		CompanyId:   &company.Id,
+		Company:     company.Name,
+		Grade:       strings.TrimSpace(req.Grade),
+		ProfTags:    req.ProfTags,
+		WorkFormat:  req.WorkFormat,
+		SalaryFrom:  req.SalaryFrom,
+		SalaryTo:    req.SalaryTo,
+		Description: strings.TrimSpace(req.Description),
+		ExpiresAt:   expiresAt,
+	}, nil
+}
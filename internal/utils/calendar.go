@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"fmt"
+	"ithozyeva/internal/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+// vtimezoneYearsAhead на сколько лет вперед описываются переходы таймзоны в VTIMEZONE
+const vtimezoneYearsAhead = 5
+
+// GenerateCalendarICS формирует подписываемый календарь из событий целиком (с RRULE для повторяющихся)
+// и отдельных повторений, на которые записан участник
+func GenerateCalendarICS(name string, events []models.Event, occurrences []models.EventOccurrence) string {
+	now := time.Now()
+
+	builder := strings.Builder{}
+	builder.WriteString("BEGIN:VCALENDAR\n")
+	builder.WriteString("VERSION:2.0\n")
+	builder.WriteString("PRODID:-//IT Khoziaeva//Event Calendar//EN\n")
+	builder.WriteString("CALSCALE:GREGORIAN\n")
+	builder.WriteString("METHOD:PUBLISH\n")
+	builder.WriteString(fmt.Sprintf("X-WR-CALNAME:%s\n", escapeICS(name)))
+	// Подсказка календарям, как часто обновлять подписку
+	builder.WriteString("REFRESH-INTERVAL;VALUE=DURATION:PT1H\n")
+	builder.WriteString("X-PUBLISHED-TTL:PT1H\n")
+
+	writeVTimezones(&builder, events, occurrences, now)
+
+	for i := range events {
+		writeICSEvent(&builder, &events[i], now)
+	}
+	for i := range occurrences {
+		writeICSOccurrence(&builder, &occurrences[i], now)
+	}
+
+	builder.WriteString("END:VCALENDAR\n")
+	return builder.String()
+}
+
+// writeICSOccurrence записывает отдельное повторение события как самостоятельный VEVENT
+func writeICSOccurrence(builder *strings.Builder, occurrence *models.EventOccurrence, now time.Time) {
+	if occurrence.Event == nil || occurrence.Cancelled {
+		return
+	}
+
+	date := occurrence.OccurrenceDate
+	if occurrence.OverrideDate != nil {
+		date = *occurrence.OverrideDate
+	}
+	hosts := occurrence.Event.Hosts
+	if len(occurrence.Hosts) > 0 {
+		hosts = occurrence.Hosts
+	}
+
+	// У повторения свой UID, чтобы календарь не ждал основного события серии
+	uid := fmt.Sprintf("event-%d-occurrence-%d@ithozyeva.com", occurrence.Event.Id, occurrence.Id)
+
+	builder.WriteString("BEGIN:VEVENT\n")
+	writeICSEventBody(builder, occurrence.Event, uid, date, hosts, now)
+	builder.WriteString("END:VEVENT\n")
+}
+
+// writeVTimezones записывает VTIMEZONE для каждой таймзоны, используемой событиями
+func writeVTimezones(builder *strings.Builder, events []models.Event, occurrences []models.EventOccurrence, now time.Time) {
+	earliest := map[string]time.Time{}
+	locations := map[string]*time.Location{}
+
+	collect := func(event *models.Event) {
+		location := EventLocation(event)
+		if location == time.UTC {
+			return
+		}
+		name := location.String()
+		locations[name] = location
+		if current, ok := earliest[name]; !ok || event.Date.Before(current) {
+			earliest[name] = event.Date
+		}
+	}
+	for i := range events {
+		collect(&events[i])
+	}
+	for i := range occurrences {
+		if occurrences[i].Event != nil {
+			collect(occurrences[i].Event)
+		}
+	}
+
+	names := make([]string, 0, len(locations))
+	for name := range locations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		from := time.Date(earliest[name].Year()-1, time.January, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(now.Year()+vtimezoneYearsAhead, time.January, 1, 0, 0, 0, 0, time.UTC)
+		writeVTimezone(builder, locations[name], from, to)
+	}
+}
+
+// timezoneTransition момент смены смещения таймзоны
+type timezoneTransition struct {
+	at         time.Time
+	offsetFrom int
+	offsetTo   int
+	name       string
+	isDST      bool
+}
+
+// writeVTimezone описывает таймзону по фактическим переходам из базы tzdata в интервале [from, to)
+func writeVTimezone(builder *strings.Builder, location *time.Location, from, to time.Time) {
+	transitions := timezoneTransitions(location, from, to)
+
+	builder.WriteString("BEGIN:VTIMEZONE\n")
+	builder.WriteString(fmt.Sprintf("TZID:%s\n", location.String()))
+
+	// Начальный компонент задает смещение, действующее до первого перехода: без него
+	// клиенты не знают смещения для времени раньше первого перехода в интервале
+	initial := from.In(location)
+	name, offset := initial.Zone()
+	writeVTimezoneComponent(builder, timezoneTransition{
+		at:         from,
+		offsetFrom: offset,
+		offsetTo:   offset,
+		name:       name,
+		isDST:      initial.IsDST(),
+	})
+
+	for _, transition := range transitions {
+		writeVTimezoneComponent(builder, transition)
+	}
+
+	builder.WriteString("END:VTIMEZONE\n")
+}
+
+// writeVTimezoneComponent записывает STANDARD или DAYLIGHT, начинающийся с перехода
+func writeVTimezoneComponent(builder *strings.Builder, transition timezoneTransition) {
+	component := "STANDARD"
+	if transition.isDST {
+		component = "DAYLIGHT"
+	}
+
+	// DTSTART перехода указывается в местном времени до перехода
+	localStart := transition.at.Add(time.Duration(transition.offsetFrom) * time.Second).UTC()
+
+	builder.WriteString(fmt.Sprintf("BEGIN:%s\n", component))
+	builder.WriteString(fmt.Sprintf("DTSTART:%s\n", localStart.Format("20060102T150405")))
+	builder.WriteString(fmt.Sprintf("TZOFFSETFROM:%s\n", formatUTCOffset(transition.offsetFrom)))
+	builder.WriteString(fmt.Sprintf("TZOFFSETTO:%s\n", formatUTCOffset(transition.offsetTo)))
+	builder.WriteString(fmt.Sprintf("TZNAME:%s\n", transition.name))
+	builder.WriteString(fmt.Sprintf("END:%s\n", component))
+}
+
+// timezoneTransitions находит переходы смещения таймзоны: сначала с шагом в сутки, затем бинарным поиском
+func timezoneTransitions(location *time.Location, from, to time.Time) []timezoneTransition {
+	var result []timezoneTransition
+
+	_, previousOffset := from.In(location).Zone()
+	for day := from; day.Before(to); day = day.Add(24 * time.Hour) {
+		next := day.Add(24 * time.Hour)
+		_, nextOffset := next.In(location).Zone()
+		if nextOffset == previousOffset {
+			continue
+		}
+
+		low, high := day, next
+		for high.Sub(low) > time.Second {
+			middle := low.Add(high.Sub(low) / 2)
+			if _, offset := middle.In(location).Zone(); offset == previousOffset {
+				low = middle
+			} else {
+				high = middle
+			}
+		}
+
+		at := high.Truncate(time.Second)
+		name, _ := at.In(location).Zone()
+		result = append(result, timezoneTransition{
+			at:         at,
+			offsetFrom: previousOffset,
+			offsetTo:   nextOffset,
+			name:       name,
+			isDST:      at.In(location).IsDST(),
+		})
+		previousOffset = nextOffset
+	}
+
+	return result
+}
+
+// formatUTCOffset форматирует смещение в секундах в вид +0300
+func formatUTCOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, offset/3600, (offset%3600)/60)
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteVTimezone(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		from, to string
+		want     []string
+	}{
+		{
+			name:     "начальное смещение и переходы на летнее и зимнее время",
+			location: "Europe/Berlin",
+			from:     "2025-01-01T00:00:00Z",
+			to:       "2026-01-01T00:00:00Z",
+			want: []string{
+				"BEGIN:VTIMEZONE", "TZID:Europe/Berlin",
+				"BEGIN:STANDARD", "DTSTART:20250101T010000", "TZOFFSETFROM:+0100", "TZOFFSETTO:+0100", "TZNAME:CET", "END:STANDARD",
+				"BEGIN:DAYLIGHT", "DTSTART:20250330T020000", "TZOFFSETFROM:+0100", "TZOFFSETTO:+0200", "TZNAME:CEST", "END:DAYLIGHT",
+				"BEGIN:STANDARD", "DTSTART:20251026T030000", "TZOFFSETFROM:+0200", "TZOFFSETTO:+0100", "TZNAME:CET", "END:STANDARD",
+				"END:VTIMEZONE",
+			},
+		},
+		{
+			name:     "интервал начинается летом",
+			location: "Europe/Berlin",
+			from:     "2025-07-01T00:00:00Z",
+			to:       "2025-12-01T00:00:00Z",
+			want: []string{
+				"BEGIN:VTIMEZONE", "TZID:Europe/Berlin",
+				"BEGIN:DAYLIGHT", "DTSTART:20250701T020000", "TZOFFSETFROM:+0200", "TZOFFSETTO:+0200", "TZNAME:CEST", "END:DAYLIGHT",
+				"BEGIN:STANDARD", "DTSTART:20251026T030000", "TZOFFSETFROM:+0200", "TZOFFSETTO:+0100", "TZNAME:CET", "END:STANDARD",
+				"END:VTIMEZONE",
+			},
+		},
+		{
+			name:     "таймзона без переходов",
+			location: "Europe/Moscow",
+			from:     "2025-01-01T00:00:00Z",
+			to:       "2026-01-01T00:00:00Z",
+			want: []string{
+				"BEGIN:VTIMEZONE", "TZID:Europe/Moscow",
+				"BEGIN:STANDARD", "DTSTART:20250101T030000", "TZOFFSETFROM:+0300", "TZOFFSETTO:+0300", "TZNAME:MSK", "END:STANDARD",
+				"END:VTIMEZONE",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location, err := time.LoadLocation(tt.location)
+			if err != nil {
+				t.Skipf("нет данных таймзон: %v", err)
+			}
+
+			var builder strings.Builder
+			writeVTimezone(&builder, location, mustTime(t, tt.from), mustTime(t, tt.to))
+
+			got := strings.Split(strings.TrimSuffix(builder.String(), "\n"), "\n")
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("writeVTimezone() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCheckInCode возвращается для поддельного, поврежденного или чужого кода отметки
+var ErrInvalidCheckInCode = errors.New("неверный код отметки")
+
+// checkInSignatureSize длина подписи кода в байтах, укороченная, чтобы QR-код оставался читаемым
+const checkInSignatureSize = 16
+
+// CheckInCode данные кода отметки участника на событии.
+// RecurrenceId указывается для повторяющихся событий — исходная дата повторения.
+// Код детерминирован: для одного участника и проведения события он всегда один и тот же и не одноразовый.
+// Повторная отметка отклоняется по уже сохраненной записи о посещении, а не по самому коду
+type CheckInCode struct {
+	EventId      int64
+	MemberId     int64
+	RecurrenceId *time.Time
+}
+
+// GenerateCheckInCode подписывает код отметки HMAC-SHA256. Код имеет вид <данные>.<подпись> в base64url
+func GenerateCheckInCode(secret []byte, code CheckInCode) string {
+	payload := code.payload()
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(signCheckInPayload(secret, payload))
+}
+
+// ParseCheckInCode проверяет подпись кода отметки и возвращает его данные
+func ParseCheckInCode(secret []byte, value string) (*CheckInCode, error) {
+	encodedPayload, encodedSignature, ok := strings.Cut(strings.TrimSpace(value), ".")
+	if !ok {
+		return nil, ErrInvalidCheckInCode
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, ErrInvalidCheckInCode
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return nil, ErrInvalidCheckInCode
+	}
+	if !hmac.Equal(signature, signCheckInPayload(secret, string(payload))) {
+		return nil, ErrInvalidCheckInCode
+	}
+
+	parts := strings.Split(string(payload), ":")
+	if len(parts) != 3 {
+		return nil, ErrInvalidCheckInCode
+	}
+
+	eventId, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCheckInCode
+	}
+	memberId, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCheckInCode
+	}
+	recurrence, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCheckInCode
+	}
+
+	code := &CheckInCode{EventId: eventId, MemberId: memberId}
+	if recurrence != 0 {
+		recurrenceId := time.Unix(recurrence, 0).UTC()
+		code.RecurrenceId = &recurrenceId
+	}
+	return code, nil
+}
+
+func (c CheckInCode) payload() string {
+	var recurrence int64
+	if c.RecurrenceId != nil {
+		recurrence = c.RecurrenceId.Unix()
+	}
+	return fmt.Sprintf("%d:%d:%d", c.EventId, c.MemberId, recurrence)
+}
+
+func signCheckInPayload(secret []byte, payload string) []byte {
+	// Префикс отделяет подписи кодов отметки от других подписей тем же секретом
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("check-in:" + payload))
+	return mac.Sum(nil)[:checkInSignatureSize]
+}
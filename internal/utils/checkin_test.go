@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCheckInCode(t *testing.T) {
+	secret := []byte("secret")
+	recurrenceId := time.Date(2026, 10, 19, 18, 0, 0, 0, time.UTC)
+	valid := GenerateCheckInCode(secret, CheckInCode{EventId: 7, MemberId: 42})
+	recurring := GenerateCheckInCode(secret, CheckInCode{EventId: 7, MemberId: 42, RecurrenceId: &recurrenceId})
+
+	payload, signature, _ := strings.Cut(valid, ".")
+	forgedPayload := base64.RawURLEncoding.EncodeToString([]byte("7:43:0"))
+	signatureBytes, _ := base64.RawURLEncoding.DecodeString(signature)
+	signatureBytes[0] ^= 0xFF
+	tamperedSignature := base64.RawURLEncoding.EncodeToString(signatureBytes)
+
+	tests := []struct {
+		name    string
+		secret  []byte
+		value   string
+		want    *CheckInCode
+		wantErr bool
+	}{
+		{name: "разовое событие", secret: secret, value: valid, want: &CheckInCode{EventId: 7, MemberId: 42}},
+		{name: "повторение события", secret: secret, value: recurring, want: &CheckInCode{EventId: 7, MemberId: 42, RecurrenceId: &recurrenceId}},
+		{name: "пробелы по краям", secret: secret, value: "  " + valid + "\n", want: &CheckInCode{EventId: 7, MemberId: 42}},
+		{name: "подмена участника", secret: secret, value: forgedPayload + "." + signature, wantErr: true},
+		{name: "измененная подпись", secret: secret, value: payload + "." + tamperedSignature, wantErr: true},
+		{name: "обрезанная подпись", secret: secret, value: valid[:len(valid)-2], wantErr: true},
+		{name: "чужой секрет", secret: []byte("other"), value: valid, wantErr: true},
+		{name: "без подписи", secret: secret, value: payload, wantErr: true},
+		{name: "не base64", secret: secret, value: "!!!.???", wantErr: true},
+		{name: "пустая строка", secret: secret, value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCheckInCode(tt.secret, tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidCheckInCode) {
+					t.Fatalf("ParseCheckInCode() error = %v, want %v", err, ErrInvalidCheckInCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCheckInCode() error = %v", err)
+			}
+			if got.EventId != tt.want.EventId || got.MemberId != tt.want.MemberId {
+				t.Errorf("ParseCheckInCode() = %+v, want %+v", got, tt.want)
+			}
+			switch {
+			case tt.want.RecurrenceId == nil && got.RecurrenceId != nil:
+				t.Errorf("RecurrenceId = %v, want nil", got.RecurrenceId)
+			case tt.want.RecurrenceId != nil && (got.RecurrenceId == nil || !got.RecurrenceId.Equal(*tt.want.RecurrenceId)):
+				t.Errorf("RecurrenceId = %v, want %v", got.RecurrenceId, tt.want.RecurrenceId)
+			}
+		})
+	}
+}
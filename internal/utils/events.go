@@ -3,36 +3,107 @@ package utils
 import (
 	"fmt"
 	"ithozyeva/internal/models"
+	"sort"
 	"strings"
 	"time"
 )
 
 func GenerateICS(event *models.Event) string {
-	formatTime := func(t time.Time) string {
-		return t.UTC().Format("20060102T150405Z") // iCalendar формат UTC
+	builder := strings.Builder{}
+	builder.WriteString("BEGIN:VCALENDAR\n")
+	builder.WriteString("VERSION:2.0\n")
+	builder.WriteString("PRODID:-//IT Khoziaeva//Event Calendar//EN\n")
+	builder.WriteString("CALSCALE:GREGORIAN\n")
+
+	now := time.Now()
+	writeVTimezones(&builder, []models.Event{*event}, nil, now)
+	writeICSEvent(&builder, event, now)
+
+	builder.WriteString("END:VCALENDAR\n")
+	return builder.String()
+}
+
+// writeICSEvent записывает VEVENT события, а для повторяющегося события — RRULE, EXDATE
+// и отдельные VEVENT с RECURRENCE-ID для перенесенных повторений и повторений с другими ведущими
+func writeICSEvent(builder *strings.Builder, event *models.Event, now time.Time) {
+	rule, err := EventRule(event)
+	if err != nil {
+		rule = nil
+	}
+	uid := fmt.Sprintf("event-%d@ithozyeva.com", event.Id)
+
+	builder.WriteString("BEGIN:VEVENT\n")
+	writeICSEventBody(builder, event, uid, event.Date, event.Hosts, now)
+	if rule != nil {
+		builder.WriteString(fmt.Sprintf("RRULE:%s\n", rule.String()))
+
+		for _, exception := range event.Exceptions {
+			if exception.Cancelled {
+				builder.WriteString(fmt.Sprintf("EXDATE%s\n", formatICSDate(event, exception.OccurrenceDate)))
+			}
+		}
+	}
+	builder.WriteString("END:VEVENT\n")
+
+	if rule == nil {
+		return
+	}
+
+	for _, exception := range event.Exceptions {
+		if exception.Cancelled || !exception.IsOverridden() {
+			continue
+		}
+
+		date := exception.OccurrenceDate
+		if exception.OverrideDate != nil {
+			date = *exception.OverrideDate
+		}
+		hosts := event.Hosts
+		if len(exception.Hosts) > 0 {
+			hosts = exception.Hosts
+		}
+
+		builder.WriteString("BEGIN:VEVENT\n")
+		writeICSEventBody(builder, event, uid, date, hosts, now)
+		builder.WriteString(fmt.Sprintf("RECURRENCE-ID%s\n", formatICSDate(event, exception.OccurrenceDate)))
+		builder.WriteString("END:VEVENT\n")
+	}
+}
+
+// formatICSDate форматирует дату для DTSTART, EXDATE и RECURRENCE-ID вместе с разделителем:
+// в местном времени с TZID, чтобы повторения не смещались при переходе на летнее время, либо в UTC
+func formatICSDate(event *models.Event, date time.Time) string {
+	location := EventLocation(event)
+	if location == time.UTC {
+		return ":" + date.UTC().Format(icsUTCFormat)
 	}
+	return fmt.Sprintf(";TZID=%s:%s", location.String(), date.In(location).Format("20060102T150405"))
+}
 
+func writeICSEventBody(builder *strings.Builder, event *models.Event, uid string, start time.Time, hosts []models.Member, now time.Time) {
 	// Получаем таймзону события для информации
 	timezone := event.Timezone
 	if timezone == "" {
 		timezone = "UTC"
 	}
 
-	builder := strings.Builder{}
-	builder.WriteString("BEGIN:VCALENDAR\n")
-	builder.WriteString("VERSION:2.0\n")
-	builder.WriteString("PRODID:-//IT Khoziaeva//Event Calendar//EN\n")
-	builder.WriteString("CALSCALE:GREGORIAN\n")
-
-	builder.WriteString("BEGIN:VEVENT\n")
-	builder.WriteString(fmt.Sprintf("UID:event-%d@ithozyeva.com\n", event.Id))
-	builder.WriteString(fmt.Sprintf("DTSTAMP:%s\n", formatTime(time.Now())))
-	// Дата события уже в UTC в базе, просто используем её
-	builder.WriteString(fmt.Sprintf("DTSTART:%s\n", formatTime(event.Date)))
+	builder.WriteString(fmt.Sprintf("UID:%s\n", uid))
+	builder.WriteString(fmt.Sprintf("DTSTAMP:%s\n", now.UTC().Format(icsUTCFormat)))
+	builder.WriteString(fmt.Sprintf("DTSTART%s\n", formatICSDate(event, start)))
 	builder.WriteString(fmt.Sprintf("SUMMARY:%s\n", escapeICS(event.Title)))
 
 	// Добавляем информацию о таймзоне в описание для справки
 	description := event.Description
+	if len(hosts) > 0 {
+		names := make([]string, 0, len(hosts))
+		for _, host := range hosts {
+			names = append(names, strings.TrimSpace(host.FirstName+" "+host.LastName))
+		}
+		description = fmt.Sprintf("%s\n\nВедущие: %s", description, strings.Join(names, ", "))
+	}
+	if label := EventSeriesLabel(event); label != "" {
+		description = fmt.Sprintf("%s\n\n%s", description, label)
+	}
 	if timezone != "UTC" {
 		description = fmt.Sprintf("%s\n\n⏰ Время указано для таймзоны: %s", description, timezone)
 	}
@@ -50,11 +121,247 @@ func GenerateICS(event *models.Event) string {
 	} else {
 		builder.WriteString(fmt.Sprintf("LOCATION:%s\n", escapeICS(place)))
 	}
+}
 
-	builder.WriteString("END:VEVENT\n")
-	builder.WriteString("END:VCALENDAR\n")
-	return builder.String()
+// maxOccurrenceIterations ограничивает перебор повторений, чтобы битые данные не зациклили расчет
+const maxOccurrenceIterations = 100000
+
+// EventInstance конкретное проведение события с учетом переносов.
+// RecurrenceId — дата по правилу повторения, по ней хранятся участники и алерты повторения
+type EventInstance struct {
+	RecurrenceId time.Time
+	Date         time.Time
+	Override     *models.EventOccurrence
+}
+
+// EventLocation возвращает таймзону события, по умолчанию UTC
+func EventLocation(event *models.Event) *time.Location {
+	if event.Timezone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(event.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// EventRule возвращает правило повторения события. Для событий без RRULE правило строится
+// из устаревших полей RepeatPeriod, RepeatInterval и RepeatEndDate. Для разовых событий возвращает nil
+func EventRule(event *models.Event) (*RRule, error) {
+	if event.RRule != nil && *event.RRule != "" {
+		return ParseRRule(*event.RRule)
+	}
+	if !event.IsRepeating || event.RepeatPeriod == nil {
+		return nil, nil
+	}
+
+	rule := &RRule{Freq: models.RepeatPeriod(*event.RepeatPeriod), Interval: 1}
+	switch rule.Freq {
+	case models.RepeatDaily, models.RepeatWeekly, models.RepeatMonthly, models.RepeatYearly:
+	default:
+		return nil, fmt.Errorf("%w: неподдерживаемая частота %s", ErrInvalidRRule, *event.RepeatPeriod)
+	}
+	if event.RepeatInterval != nil && *event.RepeatInterval > 0 {
+		rule.Interval = *event.RepeatInterval
+	}
+	if event.RepeatEndDate != nil {
+		until := *event.RepeatEndDate
+		rule.Until = &until
+	}
+	return rule, nil
+}
+
+// iterateEventDates перебирает даты события по правилу повторения без учета исключений.
+// Разовое событие (или событие с битым правилом) дает единственную дату
+func iterateEventDates(event *models.Event, fn func(time.Time) bool) {
+	rule, err := EventRule(event)
+	if err != nil || rule == nil {
+		fn(event.Date)
+		return
+	}
+	rule.Iterate(event.Date, EventLocation(event), fn)
+}
+
+// eventExceptions индексирует измененные повторения по исходной дате
+func eventExceptions(event *models.Event) map[int64]*models.EventOccurrence {
+	result := make(map[int64]*models.EventOccurrence, len(event.Exceptions))
+	for i := range event.Exceptions {
+		result[event.Exceptions[i].OccurrenceDate.Unix()] = &event.Exceptions[i]
+	}
+	return result
+}
+
+// newEventInstance применяет к дате правила перенос, если он есть. Для отмененного повторения возвращает false
+func newEventInstance(date time.Time, exceptions map[int64]*models.EventOccurrence) (EventInstance, bool) {
+	instance := EventInstance{RecurrenceId: date, Date: date}
+	if override, ok := exceptions[date.Unix()]; ok {
+		if override.Cancelled {
+			return instance, false
+		}
+		instance.Override = override
+		if override.OverrideDate != nil {
+			instance.Date = *override.OverrideDate
+		}
+	}
+	return instance, true
+}
+
+// IsEventDate проверяет, что дата совпадает с одной из дат правила повторения
+func IsEventDate(event *models.Event, date time.Time) bool {
+	found := false
+	iterateEventDates(event, func(occurrence time.Time) bool {
+		if occurrence.Equal(date) {
+			found = true
+		}
+		return occurrence.Before(date)
+	})
+	return found
+}
+
+// movedInstances возвращает перенесенные повторения, новая дата которых попадает в [from, to),
+// а исходная — нет
+func movedInstances(event *models.Event, from, to time.Time) []EventInstance {
+	var result []EventInstance
+	for i := range event.Exceptions {
+		override := &event.Exceptions[i]
+		if override.Cancelled || override.OverrideDate == nil {
+			continue
+		}
+		original := override.OccurrenceDate
+		if !original.Before(from) && original.Before(to) {
+			continue
+		}
+		if override.OverrideDate.Before(from) || !override.OverrideDate.Before(to) {
+			continue
+		}
+		if IsEventDate(event, original) {
+			result = append(result, EventInstance{RecurrenceId: original, Date: *override.OverrideDate, Override: override})
+		}
+	}
+	return result
+}
+
+// EventInstancesBetween возвращает проведения события, фактическая дата которых попадает в [from, to).
+// Отмененные повторения (EXDATE) пропускаются, перенесенные возвращаются с новой датой
+func EventInstancesBetween(event *models.Event, from, to time.Time) []EventInstance {
+	exceptions := eventExceptions(event)
+
+	var result []EventInstance
+	iterateEventDates(event, func(date time.Time) bool {
+		if !date.Before(to) {
+			return false
+		}
+		if date.Before(from) {
+			return true
+		}
+		if instance, ok := newEventInstance(date, exceptions); ok {
+			if !instance.Date.Before(from) && instance.Date.Before(to) {
+				result = append(result, instance)
+			}
+		}
+		return true
+	})
+
+	result = append(result, movedInstances(event, from, to)...)
+	sort.Slice(result, func(i, j int) bool { return result[i].Date.Before(result[j].Date) })
+	return result
+}
+
+// NextEventInstance возвращает первое проведение события, которое начинается строго после указанной даты
+func NextEventInstance(event *models.Event, after time.Time) *EventInstance {
+	exceptions := eventExceptions(event)
+
+	var next *EventInstance
+	iterateEventDates(event, func(date time.Time) bool {
+		if !date.After(after) {
+			return true
+		}
+		instance, ok := newEventInstance(date, exceptions)
+		if !ok || !instance.Date.After(after) {
+			return true
+		}
+		next = &instance
+		return false
+	})
+
+	// Повторение могли перенести раньше найденного
+	for i := range event.Exceptions {
+		override := &event.Exceptions[i]
+		if override.Cancelled || override.OverrideDate == nil || !override.OverrideDate.After(after) {
+			continue
+		}
+		if next != nil && !override.OverrideDate.Before(next.Date) {
+			continue
+		}
+		if IsEventDate(event, override.OccurrenceDate) {
+			next = &EventInstance{RecurrenceId: override.OccurrenceDate, Date: *override.OverrideDate, Override: override}
+		}
+	}
+
+	return next
 }
+
+// FindEventInstance ищет проведение события по дате (с точностью до минуты).
+// Дата может быть как исходной датой повторения, так и датой после переноса
+func FindEventInstance(event *models.Event, date time.Time) (*EventInstance, bool) {
+	from, to := date.Add(-time.Minute), date.Add(time.Minute)
+
+	if instances := EventInstancesBetween(event, from, to); len(instances) > 0 {
+		return &instances[0], true
+	}
+
+	exceptions := eventExceptions(event)
+	var found *EventInstance
+	iterateEventDates(event, func(occurrence time.Time) bool {
+		if !occurrence.Before(to) {
+			return false
+		}
+		if !occurrence.Before(from) {
+			if instance, ok := newEventInstance(occurrence, exceptions); ok {
+				found = &instance
+			}
+			return false
+		}
+		return true
+	})
+
+	return found, found != nil
+}
+
+// EventForInstance возвращает копию события с датой и ведущими конкретного проведения
+func EventForInstance(event *models.Event, instance *EventInstance) models.Event {
+	result := *event
+	result.Date = instance.Date
+	if instance.Override != nil {
+		result.Occurrence = instance.Override
+		if len(instance.Override.Hosts) > 0 {
+			result.Hosts = instance.Override.Hosts
+		}
+	}
+	return result
+}
+
+// EventSeriesLabel описывает место события в серии, например «Серия «Go с нуля», часть 2 из 5».
+// Для события вне серии возвращает пустую строку
+func EventSeriesLabel(event *models.Event) string {
+	if event.Series == nil {
+		return ""
+	}
+
+	label := fmt.Sprintf("Серия «%s»", event.Series.Title)
+	if event.SeriesPosition > 0 {
+		label += fmt.Sprintf(", часть %d", event.SeriesPosition)
+		if event.Series.PartsCount >= event.SeriesPosition {
+			label += fmt.Sprintf(" из %d", event.Series.PartsCount)
+		}
+	}
+	if event.Track != "" {
+		label += fmt.Sprintf(", трек «%s»", event.Track)
+	}
+	return label
+}
+
 func escapeICS(s string) string {
 	replacer := strings.NewReplacer(
 		"\\", "\\\\",
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"crypto/rand"
 	"crypto/sha512"
 	"encoding/hex"
 )
@@ -13,3 +14,12 @@ func HashPassword(password string) string {
 func CheckPasswordHash(password, hash string) bool {
 	return HashPassword(password) == hash
 }
+
+// GenerateRandomToken возвращает криптостойкий случайный токен из size байт в hex-представлении
+func GenerateRandomToken(size int) (string, error) {
+	buffer := make([]byte, size)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buffer), nil
+}
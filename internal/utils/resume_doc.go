@@ -0,0 +1,377 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Документ Word 97-2003 хранится в составном файле OLE (CFB): текст лежит в потоке WordDocument,
+// а таблица фрагментов (piece table), по которой он собирается, — в потоке 0Table или 1Table
+
+const (
+	cfbEndOfChain = 0xFFFFFFFE
+	cfbFreeSector = 0xFFFFFFFF
+)
+
+var errInvalidDoc = errors.New("invalid doc file")
+
+type cfbFile struct {
+	data       []byte
+	sectorSize int
+	// sectorCount сколько секторов помещается в файле: ни одна цепочка не может быть длиннее
+	sectorCount    int
+	miniSectorSize int
+	miniCutoff     uint32
+	fat            []uint32
+	miniFat        []uint32
+	miniStream     []byte
+	entries        []cfbEntry
+}
+
+type cfbEntry struct {
+	name  string
+	start uint32
+	size  uint64
+	kind  byte
+}
+
+func openCFB(data []byte) (*cfbFile, error) {
+	if len(data) < 512 || !bytes.HasPrefix(data, cfbSignature) {
+		return nil, errInvalidDoc
+	}
+	le := binary.LittleEndian
+
+	sectorShift := le.Uint16(data[0x1E:])
+	miniShift := le.Uint16(data[0x20:])
+	if sectorShift != 9 && sectorShift != 12 || miniShift != 6 {
+		return nil, errInvalidDoc
+	}
+	f := &cfbFile{
+		data:           data,
+		sectorSize:     1 << sectorShift,
+		miniSectorSize: 1 << miniShift,
+		miniCutoff:     le.Uint32(data[0x38:]),
+	}
+	f.sectorCount = len(data)/f.sectorSize - 1
+
+	// Сектора таблицы размещения: первые 109 в заголовке, остальные в цепочке DIFAT.
+	// Каждый сектор читается один раз, иначе зацикленный файл раздувает таблицу до нехватки памяти
+	var fatSectors []uint32
+	usedFat := make(map[uint32]bool)
+	addFatSector := func(sector uint32) error {
+		if usedFat[sector] || len(fatSectors) >= f.sectorCount {
+			return errInvalidDoc
+		}
+		usedFat[sector] = true
+		fatSectors = append(fatSectors, sector)
+		return nil
+	}
+	for i := 0; i < 109; i++ {
+		sector := le.Uint32(data[0x4C+i*4:])
+		if sector == cfbFreeSector || sector == cfbEndOfChain {
+			break
+		}
+		if err := addFatSector(sector); err != nil {
+			return nil, err
+		}
+	}
+	visitedDifat := make(map[uint32]bool)
+	for difat := le.Uint32(data[0x44:]); difat != cfbEndOfChain && difat != cfbFreeSector; {
+		if visitedDifat[difat] || len(visitedDifat) >= f.sectorCount {
+			return nil, errInvalidDoc
+		}
+		visitedDifat[difat] = true
+
+		sector, ok := f.sector(difat)
+		if !ok {
+			return nil, errInvalidDoc
+		}
+		perSector := f.sectorSize/4 - 1
+		for i := 0; i < perSector; i++ {
+			value := le.Uint32(sector[i*4:])
+			if value == cfbFreeSector || value == cfbEndOfChain {
+				continue
+			}
+			if err := addFatSector(value); err != nil {
+				return nil, err
+			}
+		}
+		difat = le.Uint32(sector[perSector*4:])
+	}
+	for _, index := range fatSectors {
+		sector, ok := f.sector(index)
+		if !ok {
+			return nil, errInvalidDoc
+		}
+		for i := 0; i < f.sectorSize; i += 4 {
+			f.fat = append(f.fat, le.Uint32(sector[i:]))
+		}
+	}
+
+	directory, err := f.chain(le.Uint32(data[0x30:]), 0)
+	if err != nil {
+		return nil, err
+	}
+	for offset := 0; offset+128 <= len(directory); offset += 128 {
+		raw := directory[offset : offset+128]
+		nameLen := int(le.Uint16(raw[64:]))
+		if nameLen > 64 {
+			nameLen = 64
+		}
+		units := make([]uint16, 0, nameLen/2)
+		for i := 0; i+1 < nameLen; i += 2 {
+			if unit := le.Uint16(raw[i:]); unit != 0 {
+				units = append(units, unit)
+			}
+		}
+		f.entries = append(f.entries, cfbEntry{
+			name:  string(utf16.Decode(units)),
+			kind:  raw[66],
+			start: le.Uint32(raw[116:]),
+			size:  le.Uint64(raw[120:]) & 0xFFFFFFFF,
+		})
+	}
+	if len(f.entries) == 0 {
+		return nil, errInvalidDoc
+	}
+
+	// Маленькие потоки хранятся в mini stream корневой записи
+	if miniFatStart := le.Uint32(data[0x3C:]); miniFatStart != cfbEndOfChain && miniFatStart != cfbFreeSector {
+		miniFat, err := f.chain(miniFatStart, 0)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i+4 <= len(miniFat); i += 4 {
+			f.miniFat = append(f.miniFat, le.Uint32(miniFat[i:]))
+		}
+		root := f.entries[0]
+		if f.miniStream, err = f.chain(root.start, root.size); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+func (f *cfbFile) sector(index uint32) ([]byte, bool) {
+	if int64(index) >= int64(f.sectorCount) {
+		return nil, false
+	}
+	offset := (int(index) + 1) * f.sectorSize
+	if offset+f.sectorSize > len(f.data) {
+		return nil, false
+	}
+	return f.data[offset : offset+f.sectorSize], true
+}
+
+// chain собирает поток по цепочке секторов. size 0 — читать до конца цепочки.
+// Повторный сектор означает петлю в таблице размещения
+func (f *cfbFile) chain(start uint32, size uint64) ([]byte, error) {
+	if size > uint64(len(f.data)) {
+		return nil, errInvalidDoc
+	}
+	var buf bytes.Buffer
+	visited := make(map[uint32]bool)
+	for start != cfbEndOfChain {
+		if visited[start] || len(visited) >= f.sectorCount {
+			return nil, errInvalidDoc
+		}
+		visited[start] = true
+		sector, ok := f.sector(start)
+		if !ok {
+			return nil, errInvalidDoc
+		}
+		buf.Write(sector)
+		if size > 0 && uint64(buf.Len()) >= size {
+			break
+		}
+		if int(start) >= len(f.fat) {
+			return nil, errInvalidDoc
+		}
+		start = f.fat[start]
+	}
+	if size > 0 && uint64(buf.Len()) > size {
+		return buf.Bytes()[:size], nil
+	}
+	return buf.Bytes(), nil
+}
+
+func (f *cfbFile) miniChain(start uint32, size uint64) ([]byte, error) {
+	if size > uint64(len(f.miniStream)) {
+		return nil, errInvalidDoc
+	}
+	var buf bytes.Buffer
+	visited := make(map[uint32]bool)
+	for start != cfbEndOfChain && uint64(buf.Len()) < size {
+		offset := int(start) * f.miniSectorSize
+		if visited[start] || int(start) >= len(f.miniFat) || offset+f.miniSectorSize > len(f.miniStream) {
+			return nil, errInvalidDoc
+		}
+		visited[start] = true
+		buf.Write(f.miniStream[offset : offset+f.miniSectorSize])
+		start = f.miniFat[start]
+	}
+	if uint64(buf.Len()) < size {
+		return nil, errInvalidDoc
+	}
+	return buf.Bytes()[:size], nil
+}
+
+// stream возвращает поток по имени
+func (f *cfbFile) stream(name string) ([]byte, error) {
+	for _, entry := range f.entries {
+		// 2 — тип записи «поток»
+		if entry.kind != 2 || entry.name != name {
+			continue
+		}
+		if entry.size < uint64(f.miniCutoff) {
+			return f.miniChain(entry.start, entry.size)
+		}
+		return f.chain(entry.start, entry.size)
+	}
+	return nil, errInvalidDoc
+}
+
+// extractTextFromDoc собирает текст документа Word 97-2003 по таблице фрагментов
+func extractTextFromDoc(content []byte) (string, error) {
+	file, err := openCFB(content)
+	if err != nil {
+		return "", err
+	}
+
+	wordDocument, err := file.stream("WordDocument")
+	if err != nil {
+		return "", err
+	}
+	if len(wordDocument) < 0x1AA {
+		return "", errInvalidDoc
+	}
+	le := binary.LittleEndian
+
+	// Бит fWhichTblStm в FIB выбирает поток таблиц, fcClx и lcbClx — положение таблицы фрагментов в нем
+	tableName := "0Table"
+	if le.Uint16(wordDocument[0x0A:])&0x0200 != 0 {
+		tableName = "1Table"
+	}
+	table, err := file.stream(tableName)
+	if err != nil {
+		return "", err
+	}
+	fcClx := int(le.Uint32(wordDocument[0x1A2:]))
+	lcbClx := int(le.Uint32(wordDocument[0x1A6:]))
+	if fcClx < 0 || lcbClx <= 0 || fcClx+lcbClx > len(table) {
+		return "", errInvalidDoc
+	}
+	clx := table[fcClx : fcClx+lcbClx]
+
+	// Пропускаем Prc с форматированием до Pcdt
+	pos := 0
+	for pos < len(clx) && clx[pos] == 0x01 {
+		if pos+3 > len(clx) {
+			return "", errInvalidDoc
+		}
+		size := int(int16(le.Uint16(clx[pos+1:])))
+		if size < 0 {
+			return "", errInvalidDoc
+		}
+		pos += 3 + size
+	}
+	if pos+5 > len(clx) || clx[pos] != 0x02 {
+		return "", errInvalidDoc
+	}
+	lcb := int(le.Uint32(clx[pos+1:]))
+	plcPcd := clx[pos+5:]
+	if lcb > len(plcPcd) || lcb < 4 {
+		return "", errInvalidDoc
+	}
+	plcPcd = plcPcd[:lcb]
+
+	// PlcPcd: n+1 позиций символов по 4 байта и n описателей фрагментов по 8 байт
+	pieces := (lcb - 4) / 12
+	var builder strings.Builder
+	for i := 0; i < pieces; i++ {
+		cpStart := le.Uint32(plcPcd[i*4:])
+		cpEnd := le.Uint32(plcPcd[(i+1)*4:])
+		if cpEnd <= cpStart {
+			continue
+		}
+		chars := int(cpEnd - cpStart)
+		fc := le.Uint32(plcPcd[(pieces+1)*4+i*8+2:])
+
+		if fc&0x40000000 != 0 {
+			// Сжатый фрагмент: по байту на символ в Windows-1252
+			offset := int(fc&^0x40000000) / 2
+			if offset+chars > len(wordDocument) {
+				return "", errInvalidDoc
+			}
+			decoded, err := charmap.Windows1252.NewDecoder().Bytes(wordDocument[offset : offset+chars])
+			if err != nil {
+				return "", err
+			}
+			builder.Write(decoded)
+			continue
+		}
+
+		offset := int(fc)
+		if offset+chars*2 > len(wordDocument) {
+			return "", errInvalidDoc
+		}
+		units := make([]uint16, chars)
+		for j := range units {
+			units[j] = le.Uint16(wordDocument[offset+j*2:])
+		}
+		builder.WriteString(string(utf16.Decode(units)))
+	}
+
+	return cleanDocText(builder.String()), nil
+}
+
+// cleanDocText заменяет служебные символы Word: конец абзаца, ячейки таблиц и поля.
+// От полей (гиперссылок, оглавления) остается только отображаемый результат
+func cleanDocText(text string) string {
+	var builder strings.Builder
+	fieldDepth := 0
+	inInstruction := make([]bool, 0, 4)
+	for _, r := range text {
+		switch r {
+		case 0x13:
+			fieldDepth++
+			inInstruction = append(inInstruction, true)
+			continue
+		case 0x14:
+			if fieldDepth > 0 {
+				inInstruction[fieldDepth-1] = false
+			}
+			continue
+		case 0x15:
+			if fieldDepth > 0 {
+				fieldDepth--
+				inInstruction = inInstruction[:fieldDepth]
+			}
+			continue
+		}
+		if fieldDepth > 0 && inInstruction[fieldDepth-1] {
+			continue
+		}
+
+		switch {
+		case r == '\r' || r == 0x0B || r == 0x0C:
+			builder.WriteRune('\n')
+		case r == 0x07:
+			builder.WriteRune('\t')
+		case r == '\t':
+			builder.WriteRune(r)
+		case r == 0x1E:
+			builder.WriteRune('-')
+		case r < 0x20:
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+// difatSector заполняет сектор DIFAT свободными записями и ссылкой на следующий сектор
+func difatSector(next uint32) []uint32 {
+	sector := make([]uint32, 128)
+	for i := range sector {
+		sector[i] = cfbFreeSector
+	}
+	sector[127] = next
+	return sector
+}
+
+func TestOpenCFBRejectsCycles(t *testing.T) {
+	const fatSect = 0xFFFFFFFD
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "difat ссылается сам на себя",
+			data: cfbTestFile(0, cfbEndOfChain, nil, difatSector(0)),
+		},
+		{
+			name: "петля из двух секторов difat",
+			data: cfbTestFile(0, cfbEndOfChain, nil, difatSector(1), difatSector(0)),
+		},
+		{
+			name: "сектор fat указан дважды",
+			data: cfbTestFile(cfbEndOfChain, 1, []uint32{0, 0}, []uint32{fatSect, cfbEndOfChain}, nil),
+		},
+		{
+			name: "цепочка каталога замкнута на себя",
+			data: cfbTestFile(cfbEndOfChain, 1, []uint32{0}, []uint32{fatSect, 1}, nil),
+		},
+		{
+			name: "цепочка каталога возвращается к началу",
+			data: cfbTestFile(cfbEndOfChain, 1, []uint32{0}, []uint32{fatSect, 2, 1}, nil, nil),
+		},
+		{
+			name: "сектор за пределами файла",
+			data: cfbTestFile(cfbEndOfChain, 1_000_000, []uint32{0}, []uint32{fatSect}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := openCFB(tt.data); !errors.Is(err, errInvalidDoc) {
+				t.Fatalf("openCFB() error = %v, want %v", err, errInvalidDoc)
+			}
+		})
+	}
+}
+
+func TestCFBMiniChainRejectsCycles(t *testing.T) {
+	f := &cfbFile{
+		miniSectorSize: 64,
+		miniFat:        []uint32{0, cfbEndOfChain},
+		miniStream:     make([]byte, 128),
+	}
+	if _, err := f.miniChain(0, 128); !errors.Is(err, errInvalidDoc) {
+		t.Fatalf("miniChain() error = %v, want %v", err, errInvalidDoc)
+	}
+}
@@ -0,0 +1,429 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	xunicode "golang.org/x/text/encoding/unicode"
+)
+
+// ResumeFormat формат файла резюме, определенный по содержимому
+type ResumeFormat string
+
+const (
+	ResumeFormatPDF  ResumeFormat = "pdf"
+	ResumeFormatDOCX ResumeFormat = "docx"
+	ResumeFormatDOC  ResumeFormat = "doc"
+	ResumeFormatODT  ResumeFormat = "odt"
+	ResumeFormatRTF  ResumeFormat = "rtf"
+	ResumeFormatTXT  ResumeFormat = "txt"
+	ResumeFormatMD   ResumeFormat = "md"
+)
+
+var ErrUnsupportedResumeFormat = errors.New("формат резюме не поддерживается: загрузите PDF, DOC, DOCX, ODT, RTF, TXT или MD")
+
+var resumeContentTypes = map[ResumeFormat]string{
+	ResumeFormatPDF:  "application/pdf",
+	ResumeFormatDOCX: "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	ResumeFormatDOC:  "application/msword",
+	ResumeFormatODT:  "application/vnd.oasis.opendocument.text",
+	ResumeFormatRTF:  "application/rtf",
+	ResumeFormatTXT:  "text/plain; charset=utf-8",
+	ResumeFormatMD:   "text/markdown; charset=utf-8",
+}
+
+// Extension расширение файла для хранения резюме этого формата
+func (f ResumeFormat) Extension() string {
+	return "." + string(f)
+}
+
+func (f ResumeFormat) ContentType() string {
+	return resumeContentTypes[f]
+}
+
+var (
+	pdfSignature = []byte("%PDF-")
+	rtfSignature = []byte(`{\rtf`)
+	cfbSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+	zipSignature = []byte("PK\x03\x04")
+	utf8BOM      = []byte{0xEF, 0xBB, 0xBF}
+)
+
+// DetectResumeFormat определяет формат по сигнатуре файла. Zip-архивы различаются по содержимому
+// (Word или OpenDocument), а расширение учитывается только для Markdown
+func DetectResumeFormat(filename string, content []byte) (ResumeFormat, error) {
+	head := bytes.TrimPrefix(content, utf8BOM)
+	switch {
+	case bytes.HasPrefix(head, pdfSignature):
+		return ResumeFormatPDF, nil
+	case bytes.HasPrefix(head, rtfSignature):
+		return ResumeFormatRTF, nil
+	case bytes.HasPrefix(content, cfbSignature):
+		return ResumeFormatDOC, nil
+	case bytes.HasPrefix(content, zipSignature):
+		return detectZipFormat(content)
+	case looksLikeText(content):
+		switch strings.ToLower(filepath.Ext(filename)) {
+		case ".md", ".markdown":
+			return ResumeFormatMD, nil
+		}
+		return ResumeFormatTXT, nil
+	}
+	return "", ErrUnsupportedResumeFormat
+}
+
+func detectZipFormat(content []byte) (ResumeFormat, error) {
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return "", ErrUnsupportedResumeFormat
+	}
+
+	hasContent := false
+	for _, file := range zr.File {
+		switch file.Name {
+		case "word/document.xml":
+			return ResumeFormatDOCX, nil
+		case "content.xml":
+			hasContent = true
+		case "mimetype":
+			rc, err := file.Open()
+			if err != nil {
+				continue
+			}
+			mimetype, _ := io.ReadAll(io.LimitReader(rc, 128))
+			rc.Close()
+			if strings.TrimSpace(string(mimetype)) == resumeContentTypes[ResumeFormatODT] {
+				return ResumeFormatODT, nil
+			}
+		}
+	}
+	// Некоторые редакторы не пишут mimetype, но content.xml есть только у OpenDocument
+	if hasContent {
+		return ResumeFormatODT, nil
+	}
+	return "", ErrUnsupportedResumeFormat
+}
+
+// looksLikeText проверяет, что в начале файла нет двоичных данных
+func looksLikeText(content []byte) bool {
+	if len(content) == 0 {
+		return false
+	}
+	if bytes.HasPrefix(content, []byte{0xFF, 0xFE}) || bytes.HasPrefix(content, []byte{0xFE, 0xFF}) {
+		return true
+	}
+
+	sample := content
+	if len(sample) > 4096 {
+		sample = sample[:4096]
+	}
+	control := 0
+	for _, b := range sample {
+		if b == 0 {
+			return false
+		}
+		if b < 0x20 && b != '\n' && b != '\r' && b != '\t' && b != '\f' {
+			control++
+		}
+	}
+	return control*100 < len(sample)
+}
+
+// decodePlainText переводит текст в UTF-8. Файлы без BOM и не в UTF-8 считаются Windows-1251
+func decodePlainText(content []byte) string {
+	var decoder *encoding.Decoder
+	switch {
+	case bytes.HasPrefix(content, utf8BOM):
+		return string(content[len(utf8BOM):])
+	case bytes.HasPrefix(content, []byte{0xFF, 0xFE}):
+		decoder = xunicode.UTF16(xunicode.LittleEndian, xunicode.UseBOM).NewDecoder()
+	case bytes.HasPrefix(content, []byte{0xFE, 0xFF}):
+		decoder = xunicode.UTF16(xunicode.BigEndian, xunicode.UseBOM).NewDecoder()
+	case utf8.Valid(content):
+		return string(content)
+	default:
+		decoder = charmap.Windows1251.NewDecoder()
+	}
+
+	decoded, err := decoder.Bytes(content)
+	if err != nil {
+		return string(content)
+	}
+	return string(decoded)
+}
+
+var (
+	markdownLinkRe     = regexp.MustCompile(`!?\[([^\]]*)\]\(([^)\s]+)[^)]*\)`)
+	markdownHeaderRe   = regexp.MustCompile(`(?m)^\s{0,3}#{1,6}\s+`)
+	markdownQuoteRe    = regexp.MustCompile(`(?m)^\s*>\s?`)
+	markdownRuleRe     = regexp.MustCompile(`(?m)^\s*([-*_]\s*){3,}$`)
+	markdownEmphasisRe = regexp.MustCompile("\\*\\*|__|`+|~~")
+)
+
+// stripMarkdown убирает разметку, оставляя текст. Ссылки превращаются в «текст url», чтобы попасть в контакты
+func stripMarkdown(text string) string {
+	text = markdownLinkRe.ReplaceAllString(text, "$1 $2")
+	text = markdownRuleRe.ReplaceAllString(text, "")
+	text = markdownHeaderRe.ReplaceAllString(text, "")
+	text = markdownQuoteRe.ReplaceAllString(text, "")
+	return markdownEmphasisRe.ReplaceAllString(text, "")
+}
+
+// extractTextFromODT читает content.xml документа OpenDocument
+func extractTextFromODT(content []byte) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return "", err
+	}
+
+	for _, file := range zr.File {
+		if file.Name != "content.xml" {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return "", err
+		}
+		defer rc.Close()
+
+		var builder strings.Builder
+		decoder := xml.NewDecoder(rc)
+		for {
+			tok, err := decoder.Token()
+			if err != nil {
+				if err == io.EOF {
+					break
+				}
+				return "", err
+			}
+
+			switch t := tok.(type) {
+			case xml.CharData:
+				builder.Write(t)
+			case xml.StartElement:
+				switch t.Name.Local {
+				case "tab":
+					builder.WriteString("\t")
+				case "line-break":
+					builder.WriteString("\n")
+				case "s":
+					// text:s — несколько пробелов подряд, количество в атрибуте c
+					count := 1
+					for _, attr := range t.Attr {
+						if attr.Name.Local == "c" {
+							if c, err := strconv.Atoi(attr.Value); err == nil && c > 0 && c < 100 {
+								count = c
+							}
+						}
+					}
+					builder.WriteString(strings.Repeat(" ", count))
+				}
+			case xml.EndElement:
+				switch t.Name.Local {
+				case "p", "h", "list-item", "table-row":
+					builder.WriteString("\n")
+				case "table-cell":
+					builder.WriteString("\t")
+				}
+			}
+		}
+		return builder.String(), nil
+	}
+
+	return "", fmt.Errorf("content.xml not found in odt")
+}
+
+// rtfSkipDestinations группы RTF, которые не относятся к тексту документа
+var rtfSkipDestinations = map[string]bool{
+	"fonttbl": true, "colortbl": true, "stylesheet": true, "info": true, "pict": true,
+	"object": true, "header": true, "headerl": true, "headerr": true, "headerf": true,
+	"footer": true, "footerl": true, "footerr": true, "footerf": true, "themedata": true,
+	"colorschememapping": true, "latentstyles": true, "datastore": true, "xmlnstbl": true,
+	"listtable": true, "listoverridetable": true, "rsidtbl": true, "generator": true,
+	"filetbl": true, "revtbl": true, "fldinst": true, "bkmkstart": true, "bkmkend": true,
+}
+
+type rtfGroup struct {
+	skip    bool
+	ucSkip  int
+	charset *charmap.Charmap
+}
+
+// extractTextFromRTF разбирает управляющие слова RTF: абзацы, \uN и \'hh в кодировке из \ansicpg
+func extractTextFromRTF(content []byte) (string, error) {
+	var builder strings.Builder
+	state := rtfGroup{ucSkip: 1, charset: charmap.Windows1252}
+	var stack []rtfGroup
+	pendingSkip := 0
+	var hexBytes []byte
+
+	flushHex := func() {
+		if len(hexBytes) == 0 {
+			return
+		}
+		if !state.skip {
+			decoded, err := state.charset.NewDecoder().Bytes(hexBytes)
+			if err == nil {
+				builder.Write(decoded)
+			}
+		}
+		hexBytes = hexBytes[:0]
+	}
+	write := func(text string) {
+		flushHex()
+		if pendingSkip > 0 {
+			pendingSkip--
+			return
+		}
+		if !state.skip {
+			builder.WriteString(text)
+		}
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch c {
+		case '{':
+			flushHex()
+			stack = append(stack, state)
+		case '}':
+			flushHex()
+			if len(stack) == 0 {
+				return builder.String(), nil
+			}
+			state = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+		case '\r', '\n':
+		case '\\':
+			i++
+			if i >= len(content) {
+				break
+			}
+			c = content[i]
+			switch {
+			case c == '\'':
+				if i+2 < len(content) {
+					if b, err := strconv.ParseUint(string(content[i+1:i+3]), 16, 8); err == nil {
+						if pendingSkip > 0 {
+							pendingSkip--
+						} else {
+							hexBytes = append(hexBytes, byte(b))
+						}
+					}
+					i += 2
+				}
+			case c == '*':
+				state.skip = true
+			case c == '~':
+				write(" ")
+			case c == '_':
+				write("-")
+			case c == '-':
+			case c == '\\' || c == '{' || c == '}':
+				write(string(c))
+			case c == '\r' || c == '\n':
+				write("\n")
+			case unicode.IsLetter(rune(c)) && c < utf8.RuneSelf:
+				start := i
+				for i < len(content) && content[i] < utf8.RuneSelf && unicode.IsLetter(rune(content[i])) {
+					i++
+				}
+				word := string(content[start:i])
+				numStart := i
+				if i < len(content) && content[i] == '-' {
+					i++
+				}
+				for i < len(content) && content[i] >= '0' && content[i] <= '9' {
+					i++
+				}
+				param, hasParam := 0, false
+				if i > numStart {
+					if value, err := strconv.Atoi(string(content[numStart:i])); err == nil {
+						param, hasParam = value, true
+					}
+				}
+				// Пробел после управляющего слова — часть слова
+				if i >= len(content) || content[i] != ' ' {
+					i--
+				}
+
+				switch word {
+				case "par", "line", "row", "sect", "page":
+					write("\n")
+				case "tab", "cell":
+					write("\t")
+				case "emdash":
+					write("—")
+				case "endash":
+					write("–")
+				case "bullet":
+					write("•")
+				case "lquote", "rquote":
+					write("'")
+				case "ldblquote", "rdblquote":
+					write("\"")
+				case "u":
+					if hasParam {
+						if param < 0 {
+							param += 65536
+						}
+						write(string(rune(param)))
+						pendingSkip = state.ucSkip
+					}
+				case "uc":
+					if hasParam {
+						state.ucSkip = param
+					}
+				case "ansicpg":
+					if charset := windowsCharmap(param); charset != nil {
+						state.charset = charset
+					}
+				case "bin":
+					if hasParam && param > 0 {
+						i += param
+					}
+				default:
+					if rtfSkipDestinations[word] {
+						flushHex()
+						state.skip = true
+					}
+				}
+			}
+		default:
+			write(string(c))
+		}
+	}
+
+	flushHex()
+	return builder.String(), nil
+}
+
+// windowsCharmap кодировки, которые встречаются в \ansicpg резюме
+func windowsCharmap(codepage int) *charmap.Charmap {
+	switch codepage {
+	case 1250:
+		return charmap.Windows1250
+	case 1251:
+		return charmap.Windows1251
+	case 1252:
+		return charmap.Windows1252
+	case 866:
+		return charmap.CodePage866
+	case 10007:
+		return charmap.MacintoshCyrillic
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// cfbTestFile собирает заголовок составного файла с секторами по 512 байт.
+// fatSectors попадают в DIFAT заголовка, sectors — содержимое секторов по порядку
+func cfbTestFile(difatStart, directoryStart uint32, fatSectors []uint32, sectors ...[]uint32) []byte {
+	le := binary.LittleEndian
+	data := make([]byte, 512*(len(sectors)+1))
+	copy(data, cfbSignature)
+	le.PutUint16(data[0x1E:], 9)
+	le.PutUint16(data[0x20:], 6)
+	le.PutUint32(data[0x30:], directoryStart)
+	le.PutUint32(data[0x38:], 4096)
+	le.PutUint32(data[0x3C:], cfbEndOfChain)
+	le.PutUint32(data[0x44:], difatStart)
+	for i := 0; i < 109; i++ {
+		value := uint32(cfbFreeSector)
+		if i < len(fatSectors) {
+			value = fatSectors[i]
+		}
+		le.PutUint32(data[0x4C+i*4:], value)
+	}
+	for i, sector := range sectors {
+		offset := 512 * (i + 1)
+		for j := 0; j < 128; j++ {
+			value := uint32(cfbFreeSector)
+			if j < len(sector) {
+				value = sector[j]
+			}
+			le.PutUint32(data[offset+j*4:], value)
+		}
+	}
+	return data
+}
+
+// testZip собирает zip-архив из файлов по порядку
+func testZip(t *testing.T, files ...[2]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := zw.Create(file[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(file[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// testDoc собирает документ Word 97-2003 из двух фрагментов: в UTF-16 и сжатого в Windows-1252.
+// Сектора: 0 — таблица размещения, 1 — каталог, 2-9 — WordDocument, 10-17 — 1Table
+func testDoc(unicodeText, compressedText string) []byte {
+	le := binary.LittleEndian
+	const (
+		unicodeOffset    = 0x800
+		compressedOffset = 0xC00
+	)
+
+	wordDocument := make([]byte, 4096)
+	le.PutUint16(wordDocument[0:], 0xA5EC)
+	le.PutUint16(wordDocument[0x0A:], 0x0200)
+	units := utf16.Encode([]rune(unicodeText))
+	for i, unit := range units {
+		le.PutUint16(wordDocument[unicodeOffset+i*2:], unit)
+	}
+	copy(wordDocument[compressedOffset:], compressedText)
+
+	plc := make([]byte, 3*4+2*8)
+	le.PutUint32(plc[0:], 0)
+	le.PutUint32(plc[4:], uint32(len(units)))
+	le.PutUint32(plc[8:], uint32(len(units)+len(compressedText)))
+	le.PutUint32(plc[12+2:], unicodeOffset)
+	le.PutUint32(plc[20+2:], compressedOffset*2|0x40000000)
+	clx := []byte{0x01, 0x02, 0x00, 0x00, 0x00, 0x02}
+	clx = le.AppendUint32(clx, uint32(len(plc)))
+	clx = append(clx, plc...)
+	le.PutUint32(wordDocument[0x1A2:], 0)
+	le.PutUint32(wordDocument[0x1A6:], uint32(len(clx)))
+
+	table := make([]byte, 4096)
+	copy(table, clx)
+
+	fat := make([]uint32, 128)
+	for i := range fat {
+		fat[i] = cfbFreeSector
+	}
+	fat[0] = 0xFFFFFFFD
+	fat[1] = cfbEndOfChain
+	for i := 2; i < 17; i++ {
+		fat[i] = uint32(i + 1)
+	}
+	fat[9] = cfbEndOfChain
+	fat[17] = cfbEndOfChain
+
+	directory := make([]byte, 512)
+	entry := func(index int, name string, kind byte, start uint32, size uint64) {
+		raw := directory[index*128 : (index+1)*128]
+		nameUnits := utf16.Encode([]rune(name + "\x00"))
+		for i, unit := range nameUnits {
+			le.PutUint16(raw[i*2:], unit)
+		}
+		le.PutUint16(raw[64:], uint16(len(nameUnits)*2))
+		raw[66] = kind
+		le.PutUint32(raw[116:], start)
+		le.PutUint64(raw[120:], size)
+	}
+	entry(0, "Root Entry", 5, cfbEndOfChain, 0)
+	entry(1, "WordDocument", 2, 2, uint64(len(wordDocument)))
+	entry(2, "1Table", 2, 10, uint64(len(table)))
+
+	data := cfbTestFile(cfbEndOfChain, 1, []uint32{0}, fat)
+	data = append(data, directory...)
+	data = append(data, wordDocument...)
+	return append(data, table...)
+}
+
+const testODTContent = `<?xml version="1.0"?><office:document-content xmlns:office="o" xmlns:text="t"><office:body><office:text>` +
+	`<text:h>Навыки</text:h><text:p>Go,<text:s text:c="3"/>SQL<text:tab/>Docker</text:p><text:p>a<text:line-break/>b</text:p>` +
+	`</office:text></office:body></office:document-content>`
+
+const testDocxContent = `<?xml version="1.0"?><w:document xmlns:w="w"><w:body>` +
+	`<w:p><w:r><w:t>Иван Петров</w:t></w:r></w:p><w:p><w:r><w:t>Go developer</w:t></w:r></w:p>` +
+	`</w:body></w:document>`
+
+func TestDetectResumeFormat(t *testing.T) {
+	cp1251, _ := charmap.Windows1251.NewEncoder().String("Опыт работы 3 года")
+
+	tests := []struct {
+		name     string
+		filename string
+		content  []byte
+		want     ResumeFormat
+		wantErr  bool
+	}{
+		{name: "pdf", filename: "cv.docx", content: []byte("%PDF-1.7\n..."), want: ResumeFormatPDF},
+		{name: "rtf", filename: "cv.doc", content: []byte(`{\rtf1\ansi hello}`), want: ResumeFormatRTF},
+		{name: "rtf с BOM", filename: "cv.rtf", content: []byte("\xEF\xBB\xBF{\\rtf1 hello}"), want: ResumeFormatRTF},
+		{name: "doc", filename: "cv.pdf", content: testDoc("Иван", ""), want: ResumeFormatDOC},
+		{name: "docx", filename: "cv", content: testZip(t, [2]string{"word/document.xml", testDocxContent}), want: ResumeFormatDOCX},
+		{
+			name:     "odt по mimetype",
+			filename: "cv.zip",
+			content:  testZip(t, [2]string{"mimetype", "application/vnd.oasis.opendocument.text"}, [2]string{"content.xml", testODTContent}),
+			want:     ResumeFormatODT,
+		},
+		{name: "odt без mimetype", filename: "cv.odt", content: testZip(t, [2]string{"content.xml", testODTContent}), want: ResumeFormatODT},
+		{name: "произвольный zip", filename: "cv.docx", content: testZip(t, [2]string{"readme.txt", "hello"}), wantErr: true},
+		{name: "поврежденный zip", filename: "cv.docx", content: []byte("PK\x03\x04garbage"), wantErr: true},
+		{name: "текст в UTF-8", filename: "cv.txt", content: []byte("Иван Петров\nGo developer"), want: ResumeFormatTXT},
+		{name: "текст в Windows-1251", filename: "cv", content: []byte(cp1251), want: ResumeFormatTXT},
+		{name: "текст в UTF-16", filename: "cv.txt", content: []byte{0xFF, 0xFE, 'h', 0, 'i', 0}, want: ResumeFormatTXT},
+		{name: "markdown по расширению", filename: "CV.MD", content: []byte("# Иван"), want: ResumeFormatMD},
+		{name: "markdown с расширением markdown", filename: "cv.markdown", content: []byte("# Иван"), want: ResumeFormatMD},
+		{name: "двоичные данные", filename: "cv.txt", content: []byte{0x01, 0x02, 0x00, 0x03}, wantErr: true},
+		{name: "пустой файл", filename: "cv.txt", content: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectResumeFormat(tt.filename, tt.content)
+			if tt.wantErr {
+				if err != ErrUnsupportedResumeFormat {
+					t.Fatalf("DetectResumeFormat() = %q, %v, want %v", got, err, ErrUnsupportedResumeFormat)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DetectResumeFormat() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DetectResumeFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractResumeText(t *testing.T) {
+	cp1251, _ := charmap.Windows1251.NewEncoder().String("Опыт работы 3 года")
+	rtf := `{\rtf1\ansi\ansicpg1251\deff0{\fonttbl{\f0 Times;}}{\*\generator X;}\pard \'cf\'f0\'e8\'e2\'e5\'f2\par ` +
+		"\\u1055?\\u1088? " + `test\tab x\par{\field{\*\fldinst HYPERLINK}{\fldrslt site.ru}}\par}`
+
+	tests := []struct {
+		name    string
+		format  ResumeFormat
+		content []byte
+		want    []string
+		exclude []string
+	}{
+		{
+			name:    "docx",
+			format:  ResumeFormatDOCX,
+			content: testZip(t, [2]string{"word/document.xml", testDocxContent}),
+			want:    []string{"Иван Петров\n", "Go developer\n"},
+		},
+		{
+			name:    "odt с пробелами, табуляцией и переносом",
+			format:  ResumeFormatODT,
+			content: testZip(t, [2]string{"content.xml", testODTContent}),
+			want:    []string{"Навыки\n", "Go,   SQL\tDocker\n", "a\nb\n"},
+		},
+		{
+			name:    "doc из фрагментов с полем",
+			format:  ResumeFormatDOC,
+			content: testDoc("Иван Петров\rОпыт работы\r\x13 HYPERLINK \"x\" \x14github.com/ivan\x15\r", "Skills: Go, SQL\r"),
+			want:    []string{"Иван Петров\n", "Опыт работы\n", "github.com/ivan\n", "Skills: Go, SQL\n"},
+			exclude: []string{"HYPERLINK"},
+		},
+		{
+			name:    "rtf с кодировкой и юникодом",
+			format:  ResumeFormatRTF,
+			content: []byte(rtf),
+			want:    []string{"Привет\n", "Пр test\tx\n", "site.ru"},
+			exclude: []string{"Times", "HYPERLINK", "X;"},
+		},
+		{
+			name:    "txt в Windows-1251",
+			format:  ResumeFormatTXT,
+			content: []byte(cp1251),
+			want:    []string{"Опыт работы 3 года"},
+		},
+		{
+			name:    "txt в UTF-16",
+			format:  ResumeFormatTXT,
+			content: []byte{0xFF, 0xFE, 0x1F, 0x04, 0x40, 0x04, 'i', 0},
+			want:    []string{"Прi"},
+		},
+		{
+			name:    "markdown",
+			format:  ResumeFormatMD,
+			content: []byte("# Иван\n**Навыки**: `Go`, SQL\n[GitHub](https://github.com/ivan)\n---\n"),
+			want:    []string{"Иван\n", "Навыки: Go, SQL\n", "GitHub https://github.com/ivan"},
+			exclude: []string{"#", "**", "`", "---"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractResumeText(tt.format, tt.content)
+			if err != nil {
+				t.Fatalf("ExtractResumeText() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("ExtractResumeText() = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, exclude := range tt.exclude {
+				if strings.Contains(got, exclude) {
+					t.Errorf("ExtractResumeText() = %q, want no %q", got, exclude)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractResumeTextMalformed(t *testing.T) {
+	doc := testDoc("Иван", "")
+	brokenClx := append([]byte(nil), doc...)
+	// lcbClx в FIB указывает за пределы потока таблиц
+	binary.LittleEndian.PutUint32(brokenClx[512*3+0x1A6:], 1<<20)
+
+	tests := []struct {
+		name    string
+		format  ResumeFormat
+		content []byte
+		wantErr bool
+	}{
+		{name: "pdf без структуры", format: ResumeFormatPDF, content: []byte("%PDF-1.7 garbage"), wantErr: true},
+		{name: "docx без document.xml", format: ResumeFormatDOCX, content: testZip(t, [2]string{"readme.txt", "x"}), wantErr: true},
+		{name: "docx с битым xml", format: ResumeFormatDOCX, content: testZip(t, [2]string{"word/document.xml", "<w:document><w:p>"}), wantErr: true},
+		{name: "odt без content.xml", format: ResumeFormatODT, content: testZip(t, [2]string{"mimetype", "x"}), wantErr: true},
+		{name: "odt не zip", format: ResumeFormatODT, content: []byte("PK\x03\x04garbage"), wantErr: true},
+		{name: "обрезанный doc", format: ResumeFormatDOC, content: doc[:1024], wantErr: true},
+		{name: "doc с таблицей фрагментов за пределами потока", format: ResumeFormatDOC, content: brokenClx, wantErr: true},
+		{name: "doc только с заголовком", format: ResumeFormatDOC, content: cfbSignature, wantErr: true},
+		{name: "rtf с незакрытыми группами", format: ResumeFormatRTF, content: []byte(`{\rtf1{{{\b text`)},
+		{name: "rtf с \bin за концом файла", format: ResumeFormatRTF, content: []byte(`{\rtf1 a\bin999999 b}`)},
+		{name: "rtf с обрезанным \\'", format: ResumeFormatRTF, content: []byte(`{\rtf1 a\'c`)},
+		{name: "неизвестный формат", format: ResumeFormat("xls"), content: []byte("x"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ExtractResumeText(tt.format, tt.content)
+			if tt.wantErr && err == nil {
+				t.Fatal("ExtractResumeText() error = nil, want error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("ExtractResumeText() error = %v", err)
+			}
+		})
+	}
+}
@@ -6,41 +6,59 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
-	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/ledongthuc/pdf"
 
 	"ithozyeva/internal/models"
 )
 
+// ParsedResumeData поля резюме, найденные в файле, и полный разбор по разделам
 type ParsedResumeData struct {
-	WorkExperience  string
-	DesiredPosition string
-	WorkFormat      models.WorkFormat
-	Confidence      float64
+	WorkExperience  string               `json:"workExperience"`
+	DesiredPosition string               `json:"desiredPosition"`
+	WorkFormat      models.WorkFormat    `json:"workFormat"`
+	Profile         models.ResumeProfile `json:"profile"`
+	// Text очищенный текст файла для полнотекстового поиска
+	Text string `json:"-"`
 }
 
+// ParseResume определяет формат по содержимому файла, а не по расширению, и разбирает текст.
+// Имя файла нужно только чтобы отличить Markdown от обычного текста
 func ParseResume(filename string, content []byte) (*ParsedResumeData, error) {
-	ext := strings.ToLower(filepath.Ext(filename))
-	var text string
-	var err error
-
-	switch ext {
-	case ".pdf":
-		text, err = extractTextFromPDF(content)
-	case ".docx":
-		text, err = extractTextFromDocx(content)
-	default:
-		return &ParsedResumeData{}, fmt.Errorf("format %s is not supported for parsing", ext)
+	format, err := DetectResumeFormat(filename, content)
+	if err != nil {
+		return &ParsedResumeData{}, err
 	}
 
+	text, err := ExtractResumeText(format, content)
 	if err != nil {
 		return nil, err
 	}
 
-	data := analyzeText(text)
-	return data, nil
+	return analyzeResume(text, time.Now()), nil
+}
+
+// ExtractResumeText достает текст из файла резюме известного формата
+func ExtractResumeText(format ResumeFormat, content []byte) (string, error) {
+	switch format {
+	case ResumeFormatPDF:
+		return extractTextFromPDF(content)
+	case ResumeFormatDOCX:
+		return extractTextFromDocx(content)
+	case ResumeFormatODT:
+		return extractTextFromODT(content)
+	case ResumeFormatDOC:
+		return extractTextFromDoc(content)
+	case ResumeFormatRTF:
+		return extractTextFromRTF(content)
+	case ResumeFormatMD:
+		return stripMarkdown(decodePlainText(content)), nil
+	case ResumeFormatTXT:
+		return decodePlainText(content), nil
+	}
+	return "", fmt.Errorf("format %s is not supported for parsing", format)
 }
 
 func extractTextFromPDF(content []byte) (string, error) {
@@ -109,57 +127,3 @@ func extractTextFromDocx(content []byte) (string, error) {
 
 	return "", fmt.Errorf("document.xml not found in docx")
 }
-
-func analyzeText(text string) *ParsedResumeData {
-	lines := strings.Split(text, "\n")
-	var (
-		workExp string
-		role    string
-		format  models.WorkFormat
-		matches int
-	)
-
-	for _, line := range lines {
-		clean := strings.TrimSpace(line)
-		if clean == "" {
-			continue
-		}
-		lower := strings.ToLower(clean)
-
-		if workExp == "" && (strings.Contains(lower, "опыт") || strings.Contains(lower, "experience")) {
-			workExp = clean
-			matches++
-		}
-
-		if role == "" && (strings.Contains(lower, "должн") || strings.Contains(lower, "position") || strings.Contains(lower, "role")) {
-			role = clean
-			matches++
-		}
-
-		if format == "" {
-			switch {
-			case strings.Contains(lower, "удален"):
-				format = models.WorkFormatRemote
-				matches++
-			case strings.Contains(lower, "гибрид"):
-				format = models.WorkFormatHybrid
-				matches++
-			case strings.Contains(lower, "офис"):
-				format = models.WorkFormatOffice
-				matches++
-			}
-		}
-
-		if matches == 3 {
-			break
-		}
-	}
-
-	confidence := float64(matches) / 3
-	return &ParsedResumeData{
-		WorkExperience:  workExp,
-		DesiredPosition: role,
-		WorkFormat:      format,
-		Confidence:      confidence,
-	}
-}
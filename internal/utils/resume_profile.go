@@ -0,0 +1,573 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"ithozyeva/internal/models"
+)
+
+type resumeSection int
+
+const (
+	sectionNone resumeSection = iota
+	sectionPosition
+	sectionExperience
+	sectionEducation
+	sectionSkills
+	sectionOther
+)
+
+// resumeHeaders заголовки разделов резюме hh.ru, LinkedIn и свободной формы
+var resumeHeaders = []struct {
+	title   string
+	section resumeSection
+}{
+	{"желаемая должность", sectionPosition},
+	{"desired position", sectionPosition},
+	{"position", sectionPosition},
+	{"должность", sectionPosition},
+	{"опыт работы", sectionExperience},
+	{"опыт", sectionExperience},
+	{"work experience", sectionExperience},
+	{"professional experience", sectionExperience},
+	{"employment history", sectionExperience},
+	{"experience", sectionExperience},
+	{"образование", sectionEducation},
+	{"education", sectionEducation},
+	{"ключевые навыки", sectionSkills},
+	{"навыки", sectionSkills},
+	{"технологии", sectionSkills},
+	{"стек", sectionSkills},
+	{"technical skills", sectionSkills},
+	{"skills", sectionSkills},
+	{"tech stack", sectionSkills},
+	{"stack", sectionSkills},
+	{"о себе", sectionOther},
+	{"обо мне", sectionOther},
+	{"about", sectionOther},
+	{"summary", sectionOther},
+	{"знание языков", sectionOther},
+	{"languages", sectionOther},
+	{"повышение квалификации", sectionOther},
+	{"курсы", sectionOther},
+	{"courses", sectionOther},
+	{"сертификаты", sectionOther},
+	{"certificates", sectionOther},
+	{"проекты", sectionOther},
+	{"projects", sectionOther},
+	{"дополнительная информация", sectionOther},
+	{"additional information", sectionOther},
+	{"гражданство", sectionOther},
+	{"контакты", sectionOther},
+	{"contacts", sectionOther},
+}
+
+const (
+	resumeMonthPattern   = `(январ[ья]|феврал[ья]|марта?|апрел[ья]|ма[йя]|июн[ья]|июл[ья]|августа?|сентябр[ья]|октябр[ья]|ноябр[ья]|декабр[ья]|jan(?:uary)?|feb(?:ruary)?|mar(?:ch)?|apr(?:il)?|may|june?|july?|aug(?:ust)?|sep(?:t(?:ember)?)?|oct(?:ober)?|nov(?:ember)?|dec(?:ember)?)`
+	resumeDatePattern    = `(?:` + resumeMonthPattern + `\.?\s+|(\d{1,2})[./])?((?:19|20)\d{2})`
+	resumePresentPattern = `(настоящее время|настоящий момент|н\.\s?в\.?|сейчас|present|now|current|today)`
+)
+
+var (
+	resumeRangeRe = regexp.MustCompile(`(?i)` + resumeDatePattern + `\s*(?:-|–|—|по|to|till|until)\s*(?:по\s+)?(?:` + resumeDatePattern + `|` + resumePresentPattern + `)`)
+	// resumeOpenRangeRe дата начала, у которой окончание перенесено на следующую строку
+	resumeOpenRangeRe = regexp.MustCompile(`(?i)` + resumeDatePattern + `\s*(?:-|–|—)$`)
+	resumeDurationRe  = regexp.MustCompile(`(?i)(\d+)\s*(?:года|год|лет|years?)(?:\s*(?:и|and)?\s*(\d+)\s*(?:месяцев|месяца|месяц|months?))?|^(\d+)\s*(?:месяцев|месяца|месяц|months?)`)
+	resumeYearRe      = regexp.MustCompile(`\b(?:19|20)\d{2}\b`)
+	resumeEmailRe     = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	resumePhoneRe     = regexp.MustCompile(`\+?\d[\d\s()\-]{8,18}\d`)
+	resumeLinkRe      = regexp.MustCompile(`(?:https?://|www\.)[^\s,;)]+|(?:github\.com|gitlab\.com|linkedin\.com|habr\.com|hh\.ru|t\.me)/[^\s,;)]+`)
+	resumeTelegramRe  = regexp.MustCompile(`(?:^|[\s(])@([A-Za-z][A-Za-z0-9_]{4,31})\b`)
+	resumeSkillSplit  = regexp.MustCompile(`[,;•|·\t]|\s/\s`)
+)
+
+var resumeMonths = map[string]time.Month{
+	"янв": time.January, "фев": time.February, "мар": time.March, "апр": time.April,
+	"май": time.May, "мая": time.May, "июн": time.June, "июл": time.July,
+	"авг": time.August, "сен": time.September, "окт": time.October, "ноя": time.November, "дек": time.December,
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April, "may": time.May, "jun": time.June, "jul": time.July,
+	"aug": time.August, "sep": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+const (
+	maxResumeSkills    = 100
+	maxResumeEducation = 20
+	maxResumeSkillLen  = 40
+)
+
+// analyzeResume разбирает текст резюме по разделам: опыт, образование, навыки и контакты.
+// now нужен для работы «по настоящее время»
+func analyzeResume(text string, now time.Time) *ParsedResumeData {
+	lines := resumeLines(text)
+	sections := splitResumeSections(lines)
+
+	profile := models.ResumeProfile{
+		Contacts: extractResumeContacts(text),
+	}
+
+	experienceLines := sections[sectionExperience]
+	if len(experienceLines) == 0 {
+		// Без заголовков ищем даты во всем тексте, кроме образования
+		experienceLines = excludeLines(lines, sections[sectionEducation])
+	}
+	profile.Experience = parseResumeExperience(experienceLines, now)
+	profile.Education = parseResumeEducation(sections[sectionEducation])
+	profile.Skills = parseResumeSkills(sections[sectionSkills])
+
+	months := totalResumeMonths(profile.Experience)
+	if months == 0 {
+		months = statedResumeMonths(lines)
+	}
+	profile.ExperienceYears = math.Round(float64(months)/12*10) / 10
+
+	data := &ParsedResumeData{
+		DesiredPosition: resumeDesiredPosition(lines, sections[sectionPosition]),
+		WorkFormat:      resumeWorkFormat(text),
+		Text:            strings.Join(lines, "\n"),
+	}
+	if months > 0 {
+		data.WorkExperience = FormatExperience(months)
+	}
+
+	found := 0
+	for _, ok := range []bool{
+		data.DesiredPosition != "",
+		months > 0,
+		len(profile.Education) > 0,
+		len(profile.Skills) > 0,
+		len(profile.Contacts.Emails)+len(profile.Contacts.Phones)+len(profile.Contacts.Links) > 0,
+		data.WorkFormat != "",
+	} {
+		if ok {
+			found++
+		}
+	}
+	profile.Confidence = math.Round(float64(found)/6*100) / 100
+
+	data.Profile = profile
+	return data
+}
+
+// FormatExperience описывает стаж по-русски: «3 года 2 месяца»
+func FormatExperience(months int) string {
+	years, rest := months/12, months%12
+	var parts []string
+	if years > 0 {
+		parts = append(parts, fmt.Sprintf("%d %s", years, pluralRu(years, "год", "года", "лет")))
+	}
+	if rest > 0 {
+		parts = append(parts, fmt.Sprintf("%d %s", rest, pluralRu(rest, "месяц", "месяца", "месяцев")))
+	}
+	return strings.Join(parts, " ")
+}
+
+func pluralRu(n int, one, few, many string) string {
+	n %= 100
+	if n >= 11 && n <= 14 {
+		return many
+	}
+	switch n % 10 {
+	case 1:
+		return one
+	case 2, 3, 4:
+		return few
+	}
+	return many
+}
+
+func resumeLines(text string) []string {
+	// Postgres не хранит нулевые байты и невалидный UTF-8, которые встречаются в PDF
+	text = strings.ReplaceAll(strings.ToValidUTF8(text, ""), "\x00", "")
+	raw := strings.Split(strings.ReplaceAll(text, "\r", "\n"), "\n")
+	lines := make([]string, 0, len(raw))
+	for _, line := range raw {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// resumeHeader определяет заголовок раздела. rest — текст после заголовка на той же строке,
+// например «— 5 лет 2 месяца» у hh.ru
+func resumeHeader(line string) (resumeSection, string, bool) {
+	lower := strings.ToLower(line)
+	if len([]rune(lower)) > 60 {
+		return sectionNone, "", false
+	}
+	for _, header := range resumeHeaders {
+		if !strings.HasPrefix(lower, header.title) {
+			continue
+		}
+		rest := strings.TrimSpace(lower[len(header.title):])
+		if rest == "" {
+			return header.section, "", true
+		}
+		first := []rune(rest)[0]
+		if unicode.IsLetter(first) {
+			if header.section == sectionPosition && strings.HasPrefix(rest, "и зарплата") {
+				return header.section, "", true
+			}
+			continue
+		}
+		if len(line) == len(lower) {
+			rest = line[len(header.title):]
+		}
+		return header.section, strings.TrimSpace(strings.TrimLeft(rest, " :—–-")), true
+	}
+	return sectionNone, "", false
+}
+
+// splitResumeSections раскладывает строки по разделам. Текст с заголовка переносится в начало раздела
+func splitResumeSections(lines []string) map[resumeSection][]string {
+	sections := make(map[resumeSection][]string)
+	current := sectionNone
+	for _, line := range lines {
+		if section, rest, ok := resumeHeader(line); ok {
+			current = section
+			if rest != "" {
+				sections[current] = append(sections[current], rest)
+			}
+			continue
+		}
+		sections[current] = append(sections[current], line)
+	}
+	return sections
+}
+
+func excludeLines(lines []string, excluded []string) []string {
+	skip := make(map[string]bool, len(excluded))
+	for _, line := range excluded {
+		skip[line] = true
+	}
+	result := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if !skip[line] {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// parseResumeExperience находит места работы по периодам дат. Компания и должность берутся
+// из той же строки, а если ее нет — из следующих строк
+func parseResumeExperience(lines []string, now time.Time) []models.ResumeExperience {
+	var entries []models.ResumeExperience
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if resumeOpenRangeRe.MatchString(line) && i+1 < len(lines) {
+			line += " " + lines[i+1]
+			i++
+		}
+
+		loc := resumeRangeRe.FindStringSubmatchIndex(line)
+		if loc == nil {
+			continue
+		}
+		entry, ok := resumeExperienceDates(line, loc, now)
+		if !ok {
+			continue
+		}
+
+		// Текст строки без дат: «Яндекс, Backend developer»
+		if rest := strings.Trim(line[:loc[0]]+" "+line[loc[1]:], " ,|:—–-"); rest != "" {
+			entry.Company, entry.Position = splitCompanyPosition(rest)
+		} else {
+			var details []string
+			for j := i + 1; j < len(lines) && len(details) < 2; j++ {
+				next := lines[j]
+				if resumeRangeRe.MatchString(next) || resumeOpenRangeRe.MatchString(next) {
+					break
+				}
+				if resumeDurationRe.MatchString(next) && len([]rune(next)) < 30 {
+					continue
+				}
+				details = append(details, next)
+			}
+			if len(details) > 0 {
+				entry.Company = details[0]
+			}
+			if len(details) > 1 {
+				entry.Position = details[1]
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func splitCompanyPosition(text string) (string, string) {
+	for _, separator := range []string{" — ", " – ", " - ", " | ", ", ", " at ", " в "} {
+		if parts := strings.SplitN(text, separator, 2); len(parts) == 2 {
+			if separator == " at " || separator == " в " {
+				return strings.TrimSpace(parts[1]), strings.TrimSpace(parts[0])
+			}
+			return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		}
+	}
+	return text, ""
+}
+
+func resumeExperienceDates(line string, loc []int, now time.Time) (models.ResumeExperience, bool) {
+	group := func(n int) string {
+		if loc[2*n] < 0 {
+			return ""
+		}
+		return line[loc[2*n]:loc[2*n+1]]
+	}
+
+	start, _, ok := resumeDate(group(1), group(2), group(3))
+	if !ok {
+		return models.ResumeExperience{}, false
+	}
+	entry := models.ResumeExperience{Start: &start}
+
+	var end time.Time
+	inclusive := true
+	if group(7) != "" {
+		end = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		entry.Current = true
+	} else {
+		var hasMonth bool
+		end, hasMonth, ok = resumeDate(group(4), group(5), group(6))
+		if !ok {
+			return models.ResumeExperience{}, false
+		}
+		entry.End = &end
+		// «2018 — 2020» считается как два года, а «январь — март» как три месяца
+		inclusive = hasMonth
+	}
+	if end.Before(start) {
+		return models.ResumeExperience{}, false
+	}
+
+	entry.Months = monthIndex(end) - monthIndex(start)
+	if inclusive {
+		entry.Months++
+	}
+	return entry, true
+}
+
+// resumeDate собирает дату из названия месяца или его номера и года. Без месяца берется январь
+func resumeDate(monthName, monthNumber, year string) (time.Time, bool, bool) {
+	y, err := strconv.Atoi(year)
+	if err != nil {
+		return time.Time{}, false, false
+	}
+	month, hasMonth := time.January, false
+	if monthName != "" {
+		if m, ok := resumeMonths[strings.ToLower(string([]rune(monthName)[:3]))]; ok {
+			month, hasMonth = m, true
+		}
+	} else if monthNumber != "" {
+		if m, err := strconv.Atoi(monthNumber); err == nil && m >= 1 && m <= 12 {
+			month, hasMonth = time.Month(m), true
+		}
+	}
+	return time.Date(y, month, 1, 0, 0, 0, 0, time.UTC), hasMonth, true
+}
+
+func monthIndex(t time.Time) int {
+	return t.Year()*12 + int(t.Month()) - 1
+}
+
+// totalResumeMonths суммирует стаж без двойного учета пересекающихся мест работы
+func totalResumeMonths(entries []models.ResumeExperience) int {
+	type interval struct{ from, to int }
+	intervals := make([]interval, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Start == nil || entry.Months <= 0 {
+			continue
+		}
+		from := monthIndex(*entry.Start)
+		intervals = append(intervals, interval{from, from + entry.Months})
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].from < intervals[j].from })
+
+	total, end := 0, math.MinInt
+	for _, current := range intervals {
+		if current.from > end {
+			total += current.to - current.from
+			end = current.to
+		} else if current.to > end {
+			total += current.to - end
+			end = current.to
+		}
+	}
+	return total
+}
+
+// statedResumeMonths стаж, указанный текстом: «Опыт работы — 5 лет 2 месяца»
+func statedResumeMonths(lines []string) int {
+	for _, line := range lines {
+		lower := strings.ToLower(line)
+		if !strings.Contains(lower, "опыт") && !strings.Contains(lower, "experience") && !strings.Contains(lower, "стаж") {
+			continue
+		}
+		match := resumeDurationRe.FindStringSubmatch(lower)
+		if match == nil {
+			continue
+		}
+		years, _ := strconv.Atoi(match[1])
+		months, _ := strconv.Atoi(match[2])
+		if match[3] != "" {
+			months, _ = strconv.Atoi(match[3])
+		}
+		if total := years*12 + months; total > 0 && total < 70*12 {
+			return total
+		}
+	}
+	return 0
+}
+
+// parseResumeEducation делит раздел образования на записи по годам окончания
+func parseResumeEducation(lines []string) []models.ResumeEducation {
+	var entries []models.ResumeEducation
+	hasYears := false
+	for _, line := range lines {
+		if resumeYearRe.MatchString(line) {
+			hasYears = true
+			break
+		}
+	}
+
+	for _, line := range lines {
+		if !hasYears {
+			entries = append(entries, models.ResumeEducation{Institution: line})
+		} else if years := resumeYearRe.FindAllString(line, -1); len(years) > 0 {
+			year, _ := strconv.Atoi(years[len(years)-1])
+			entry := models.ResumeEducation{Year: &year}
+			entry.Institution = strings.Trim(resumeYearRe.ReplaceAllString(line, ""), " ,|:—–-")
+			entries = append(entries, entry)
+		} else if len(entries) > 0 {
+			last := &entries[len(entries)-1]
+			if last.Institution == "" {
+				last.Institution = line
+			} else if last.Details == "" {
+				last.Details = line
+			} else {
+				last.Details += "; " + line
+			}
+		}
+		if len(entries) >= maxResumeEducation {
+			break
+		}
+	}
+	return entries
+}
+
+// parseResumeSkills разбивает раздел навыков на отдельные навыки без повторов
+func parseResumeSkills(lines []string) []string {
+	var skills []string
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		for _, part := range resumeSkillSplit.Split(line, -1) {
+			skill := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(part), "-*•·–—"))
+			skill = strings.TrimRight(skill, ".")
+			if skill == "" || len([]rune(skill)) > maxResumeSkillLen || len(strings.Fields(skill)) > 4 {
+				continue
+			}
+			key := strings.ToLower(skill)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			skills = append(skills, skill)
+			if len(skills) >= maxResumeSkills {
+				return skills
+			}
+		}
+	}
+	return skills
+}
+
+// extractResumeContacts достает почту, телефоны и ссылки на профили
+func extractResumeContacts(text string) models.ResumeContacts {
+	var contacts models.ResumeContacts
+
+	contacts.Emails = uniqueStrings(resumeEmailRe.FindAllString(text, -1))
+
+	var phones []string
+	for _, match := range resumePhoneRe.FindAllString(text, -1) {
+		// Периоды работы «2018 - 2020» похожи на номер
+		if resumeYearRe.MatchString(match) && strings.ContainsAny(match, "-–") && !strings.HasPrefix(match, "+") {
+			continue
+		}
+		var digits strings.Builder
+		if strings.HasPrefix(match, "+") {
+			digits.WriteByte('+')
+		}
+		for _, r := range match {
+			if r >= '0' && r <= '9' {
+				digits.WriteRune(r)
+			}
+		}
+		if count := len(strings.TrimPrefix(digits.String(), "+")); count >= 10 && count <= 15 {
+			phones = append(phones, digits.String())
+		}
+	}
+	contacts.Phones = uniqueStrings(phones)
+
+	links := resumeLinkRe.FindAllString(text, -1)
+	for _, match := range resumeTelegramRe.FindAllStringSubmatch(text, -1) {
+		links = append(links, "https://t.me/"+match[1])
+	}
+	for i, link := range links {
+		links[i] = strings.TrimRight(link, ".")
+	}
+	contacts.Links = uniqueStrings(links)
+
+	return contacts
+}
+
+func uniqueStrings(values []string) []string {
+	var result []string
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		key := strings.ToLower(value)
+		if value == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, value)
+	}
+	return result
+}
+
+func resumeDesiredPosition(lines []string, section []string) string {
+	if len(section) > 0 {
+		return section[0]
+	}
+	for _, line := range lines {
+		lower := strings.ToLower(line)
+		for _, label := range []string{"должность:", "position:"} {
+			if index := strings.Index(lower, label); index >= 0 {
+				if value := strings.TrimSpace(line[index+len(label):]); value != "" {
+					return value
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func resumeWorkFormat(text string) models.WorkFormat {
+	lower := strings.ToLower(text)
+	switch {
+	case strings.Contains(lower, "удален") || strings.Contains(lower, "remote"):
+		return models.WorkFormatRemote
+	case strings.Contains(lower, "гибрид") || strings.Contains(lower, "hybrid"):
+		return models.WorkFormatHybrid
+	case strings.Contains(lower, "офис") || strings.Contains(lower, "office"):
+		return models.WorkFormatOffice
+	}
+	return ""
+}
@@ -0,0 +1,182 @@
+package utils
+
+import (
+	"ithozyeva/internal/models"
+	"testing"
+	"time"
+)
+
+func TestFormatExperience(t *testing.T) {
+	tests := []struct {
+		months int
+		want   string
+	}{
+		{months: 0, want: ""},
+		{months: 1, want: "1 месяц"},
+		{months: 5, want: "5 месяцев"},
+		{months: 12, want: "1 год"},
+		{months: 26, want: "2 года 2 месяца"},
+		{months: 11*12 + 11, want: "11 лет 11 месяцев"},
+		{months: 21 * 12, want: "21 год"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatExperience(tt.months); got != tt.want {
+			t.Errorf("FormatExperience(%d) = %q, want %q", tt.months, got, tt.want)
+		}
+	}
+}
+
+func TestTotalResumeMonths(t *testing.T) {
+	month := func(year int, m time.Month) *time.Time {
+		date := time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+		return &date
+	}
+
+	tests := []struct {
+		name    string
+		entries []models.ResumeExperience
+		want    int
+	}{
+		{name: "пусто", want: 0},
+		{
+			name: "последовательные места",
+			entries: []models.ResumeExperience{
+				{Start: month(2020, time.January), Months: 12},
+				{Start: month(2021, time.January), Months: 6},
+			},
+			want: 18,
+		},
+		{
+			name: "пересечение не считается дважды",
+			entries: []models.ResumeExperience{
+				{Start: month(2020, time.January), Months: 12},
+				{Start: month(2020, time.July), Months: 12},
+			},
+			want: 18,
+		},
+		{
+			name: "вложенный период",
+			entries: []models.ResumeExperience{
+				{Start: month(2020, time.January), Months: 24},
+				{Start: month(2020, time.June), Months: 3},
+			},
+			want: 24,
+		},
+		{
+			name: "записи без даты начала пропускаются",
+			entries: []models.ResumeExperience{
+				{Months: 100},
+				{Start: month(2020, time.January), Months: 5},
+			},
+			want: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := totalResumeMonths(tt.entries); got != tt.want {
+				t.Errorf("totalResumeMonths() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeResume(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		text           string
+		wantPosition   string
+		wantExperience string
+		wantYears      float64
+		wantCompanies  []string
+		wantSkills     []string
+		wantEducation  int
+		wantEmails     []string
+		wantWorkFormat models.WorkFormat
+		wantEmpty      bool
+	}{
+		{
+			name: "резюме hh.ru",
+			text: "Иван Петров\n" +
+				"ivan@example.com, +7 (999) 123-45-67\n" +
+				"Желаемая должность\nGo-разработчик\nГотов к удаленной работе\n" +
+				"Опыт работы — 3 года 9 месяцев\n" +
+				"Январь 2023 — настоящее время\nЯндекс\nBackend-разработчик\n" +
+				"Март 2021 — Декабрь 2022\nОзон\nGo-разработчик\n" +
+				"Образование\n2020 МГУ, факультет ВМК\n" +
+				"Навыки\nGo, PostgreSQL, Docker",
+			wantPosition:   "Go-разработчик",
+			wantExperience: "5 лет 8 месяцев",
+			wantYears:      5.7,
+			wantCompanies:  []string{"Яндекс", "Озон"},
+			wantSkills:     []string{"Go", "PostgreSQL", "Docker"},
+			wantEducation:  1,
+			wantEmails:     []string{"ivan@example.com"},
+			wantWorkFormat: models.WorkFormatRemote,
+		},
+		{
+			name:           "стаж только текстом",
+			text:           "Опыт работы: 4 года 2 месяца\nSkills\nPython; SQL",
+			wantExperience: "4 года 2 месяца",
+			wantYears:      4.2,
+			wantSkills:     []string{"Python", "SQL"},
+		},
+		{
+			name:      "текст без разделов",
+			text:      "просто текст без дат и контактов",
+			wantEmpty: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := analyzeResume(tt.text, now)
+			profile := data.Profile
+
+			if data.DesiredPosition != tt.wantPosition {
+				t.Errorf("DesiredPosition = %q, want %q", data.DesiredPosition, tt.wantPosition)
+			}
+			if data.WorkExperience != tt.wantExperience {
+				t.Errorf("WorkExperience = %q, want %q", data.WorkExperience, tt.wantExperience)
+			}
+			if profile.ExperienceYears != tt.wantYears {
+				t.Errorf("ExperienceYears = %v, want %v", profile.ExperienceYears, tt.wantYears)
+			}
+			if data.WorkFormat != tt.wantWorkFormat {
+				t.Errorf("WorkFormat = %q, want %q", data.WorkFormat, tt.wantWorkFormat)
+			}
+			if len(profile.Education) != tt.wantEducation {
+				t.Errorf("Education = %+v, want %d entries", profile.Education, tt.wantEducation)
+			}
+
+			companies := make([]string, 0, len(profile.Experience))
+			for _, entry := range profile.Experience {
+				companies = append(companies, entry.Company)
+			}
+			assertStrings(t, "companies", companies, tt.wantCompanies)
+			assertStrings(t, "skills", profile.Skills, tt.wantSkills)
+			assertStrings(t, "emails", profile.Contacts.Emails, tt.wantEmails)
+
+			if tt.wantEmpty && profile.Confidence != 0 {
+				t.Errorf("Confidence = %v, want 0", profile.Confidence)
+			}
+		})
+	}
+}
+
+func assertStrings(t *testing.T, field string, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s = %q, want %q", field, got, want)
+		return
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%s = %q, want %q", field, got, want)
+			return
+		}
+	}
+}
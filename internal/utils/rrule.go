@@ -0,0 +1,451 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"ithozyeva/internal/models"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRRule = errors.New("неверное правило повторения")
+
+// RRuleWeekday день недели из BYDAY. N — порядковый номер в периоде (1MO, -1FR), 0 — каждый такой день
+type RRuleWeekday struct {
+	N   int
+	Day time.Weekday
+}
+
+// RRule правило повторения события по RFC 5545.
+// Поддерживаются FREQ, INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY и BYMONTH; неделя начинается с понедельника
+type RRule struct {
+	Freq       models.RepeatPeriod
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []RRuleWeekday
+	ByMonthDay []int
+	ByMonth    []time.Month
+}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+var rruleWeekdayNames = map[time.Weekday]string{
+	time.Monday:    "MO",
+	time.Tuesday:   "TU",
+	time.Wednesday: "WE",
+	time.Thursday:  "TH",
+	time.Friday:    "FR",
+	time.Saturday:  "SA",
+	time.Sunday:    "SU",
+}
+
+const icsUTCFormat = "20060102T150405Z"
+
+// ParseRRule разбирает строку правила вида "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH" (префикс "RRULE:" допускается)
+func ParseRRule(value string) (*RRule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" {
+		return nil, fmt.Errorf("%w: пустое правило", ErrInvalidRRule)
+	}
+
+	rule := &RRule{Interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidRRule, part)
+		}
+
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.Freq = models.RepeatPeriod(strings.ToUpper(val))
+			switch rule.Freq {
+			case models.RepeatDaily, models.RepeatWeekly, models.RepeatMonthly, models.RepeatYearly:
+			default:
+				err = fmt.Errorf("неподдерживаемая частота %s", val)
+			}
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(val)
+			if err == nil && rule.Interval < 1 {
+				err = fmt.Errorf("INTERVAL должен быть положительным")
+			}
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(val)
+			if err == nil && rule.Count < 1 {
+				err = fmt.Errorf("COUNT должен быть положительным")
+			}
+		case "UNTIL":
+			rule.Until, err = parseRRuleUntil(val)
+		case "BYDAY":
+			rule.ByDay, err = parseRRuleByDay(val)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseRRuleInts(val, -31, 31)
+		case "BYMONTH":
+			var months []int
+			months, err = parseRRuleInts(val, 1, 12)
+			for _, month := range months {
+				rule.ByMonth = append(rule.ByMonth, time.Month(month))
+			}
+		case "WKST":
+			if strings.ToUpper(val) != "MO" {
+				err = fmt.Errorf("поддерживается только WKST=MO")
+			}
+		default:
+			err = fmt.Errorf("неподдерживаемый параметр %s", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRRule, err)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("%w: не указан FREQ", ErrInvalidRRule)
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, fmt.Errorf("%w: COUNT и UNTIL нельзя указывать одновременно", ErrInvalidRRule)
+	}
+	for _, day := range rule.ByDay {
+		if day.N != 0 && rule.Freq != models.RepeatMonthly && rule.Freq != models.RepeatYearly {
+			return nil, fmt.Errorf("%w: порядковый BYDAY допустим только для MONTHLY и YEARLY", ErrInvalidRRule)
+		}
+	}
+	if len(rule.ByMonthDay) > 0 && rule.Freq == models.RepeatWeekly {
+		return nil, fmt.Errorf("%w: BYMONTHDAY не используется с WEEKLY", ErrInvalidRRule)
+	}
+
+	return rule, nil
+}
+
+func parseRRuleUntil(value string) (*time.Time, error) {
+	layouts := []string{icsUTCFormat, "20060102T150405", "20060102"}
+	for _, layout := range layouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// Дата без времени включает весь день
+				parsed = parsed.Add(24*time.Hour - time.Second)
+			}
+			return &parsed, nil
+		}
+	}
+	return nil, fmt.Errorf("неверный формат UNTIL %s", value)
+}
+
+func parseRRuleByDay(value string) ([]RRuleWeekday, error) {
+	var result []RRuleWeekday
+	for _, item := range strings.Split(value, ",") {
+		item = strings.ToUpper(strings.TrimSpace(item))
+		if len(item) < 2 {
+			return nil, fmt.Errorf("неверный BYDAY %s", item)
+		}
+		day, ok := rruleWeekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("неверный BYDAY %s", item)
+		}
+		n := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			n, err = strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("неверный BYDAY %s", item)
+			}
+		}
+		result = append(result, RRuleWeekday{N: n, Day: day})
+	}
+	return result, nil
+}
+
+func parseRRuleInts(value string, min, max int) ([]int, error) {
+	var result []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil || n == 0 || n < min || n > max {
+			return nil, fmt.Errorf("неверное значение %s", item)
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+// String возвращает правило в формате RFC 5545 (без префикса "RRULE:")
+func (r *RRule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(icsUTCFormat))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			if day.N != 0 {
+				days = append(days, fmt.Sprintf("%d%s", day.N, rruleWeekdayNames[day.Day]))
+			} else {
+				days = append(days, rruleWeekdayNames[day.Day])
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, day := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(day))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]string, 0, len(r.ByMonth))
+		for _, month := range r.ByMonth {
+			months = append(months, strconv.Itoa(int(month)))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(months, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Iterate перебирает повторения по возрастанию, начиная с dtstart, пока fn возвращает true.
+// Дни вычисляются в таймзоне loc, время суток берется из dtstart, поэтому переход на летнее время
+// не сдвигает событие относительно местного времени
+func (r *RRule) Iterate(dtstart time.Time, loc *time.Location, fn func(time.Time) bool) {
+	start := dtstart.In(loc)
+	hour, minute, second := start.Clock()
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	emitted := 0
+	for period := 0; period < maxOccurrenceIterations; period++ {
+		days, periodStart := r.periodDays(start, period*interval)
+		// Запас в двое суток покрывает разницу между календарной датой и UTC
+		if r.Until != nil && periodStart.AddDate(0, 0, -2).After(*r.Until) {
+			return
+		}
+
+		for _, day := range days {
+			occurrence := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, second, 0, loc)
+			if occurrence.Before(dtstart) {
+				continue
+			}
+			if r.Until != nil && occurrence.After(*r.Until) {
+				return
+			}
+			if r.Count > 0 && emitted >= r.Count {
+				return
+			}
+			emitted++
+			if !fn(occurrence) {
+				return
+			}
+		}
+	}
+}
+
+// periodDays возвращает отсортированные дни периода со смещением offset от периода dtstart
+// и начало этого периода. Дни представлены полуночью UTC и используются только как календарные даты
+func (r *RRule) periodDays(start time.Time, offset int) ([]time.Time, time.Time) {
+	startDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+
+	var days []time.Time
+	var periodStart time.Time
+
+	switch r.Freq {
+	case models.RepeatDaily:
+		day := startDay.AddDate(0, 0, offset)
+		periodStart = day
+		if r.matchesMonth(day.Month()) && r.matchesMonthDay(day) && r.matchesWeekday(day.Weekday()) {
+			days = append(days, day)
+		}
+	case models.RepeatWeekly:
+		weekStart := startDay.AddDate(0, 0, -mondayOffset(startDay.Weekday())+offset*7)
+		periodStart = weekStart
+		if len(r.ByDay) == 0 {
+			days = append(days, weekStart.AddDate(0, 0, mondayOffset(start.Weekday())))
+		} else {
+			for _, weekday := range r.ByDay {
+				days = append(days, weekStart.AddDate(0, 0, mondayOffset(weekday.Day)))
+			}
+		}
+		days = filterDays(days, func(day time.Time) bool { return r.matchesMonth(day.Month()) })
+	case models.RepeatMonthly:
+		month := time.Date(start.Year(), start.Month()+time.Month(offset), 1, 0, 0, 0, 0, time.UTC)
+		periodStart = month
+		if r.matchesMonth(month.Month()) {
+			days = r.monthDays(month.Year(), month.Month(), start.Day())
+		}
+	case models.RepeatYearly:
+		year := start.Year() + offset
+		periodStart = time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		switch {
+		case len(r.ByMonth) > 0:
+			for _, month := range r.ByMonth {
+				days = append(days, r.monthDays(year, month, start.Day())...)
+			}
+		case len(r.ByDay) > 0 && len(r.ByMonthDay) == 0:
+			days = weekdaysInRange(periodStart, periodStart.AddDate(1, 0, 0), r.ByDay)
+		case len(r.ByMonthDay) > 0:
+			for month := time.January; month <= time.December; month++ {
+				days = append(days, r.monthDays(year, month, start.Day())...)
+			}
+		default:
+			day := time.Date(year, start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+			// 29 февраля повторяется только в високосные годы
+			if day.Month() == start.Month() {
+				days = append(days, day)
+			}
+		}
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return uniqueDays(days), periodStart
+}
+
+// monthDays раскрывает BYMONTHDAY и BYDAY внутри месяца. Без них используется день месяца из dtstart
+func (r *RRule) monthDays(year int, month time.Month, defaultDay int) []time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	next := first.AddDate(0, 1, 0)
+	lastDay := next.AddDate(0, 0, -1).Day()
+
+	var byMonthDay []time.Time
+	for _, n := range r.ByMonthDay {
+		day := n
+		if n < 0 {
+			day = lastDay + 1 + n
+		}
+		if day >= 1 && day <= lastDay {
+			byMonthDay = append(byMonthDay, time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+		}
+	}
+
+	var byDay []time.Time
+	if len(r.ByDay) > 0 {
+		byDay = weekdaysInRange(first, next, r.ByDay)
+	}
+
+	switch {
+	case len(r.ByMonthDay) > 0 && len(r.ByDay) > 0:
+		return filterDays(byMonthDay, func(day time.Time) bool { return containsDay(byDay, day) })
+	case len(r.ByMonthDay) > 0:
+		return byMonthDay
+	case len(r.ByDay) > 0:
+		return byDay
+	case defaultDay <= lastDay:
+		return []time.Time{time.Date(year, month, defaultDay, 0, 0, 0, 0, time.UTC)}
+	default:
+		return nil
+	}
+}
+
+func (r *RRule) matchesMonth(month time.Month) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, m := range r.ByMonth {
+		if m == month {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RRule) matchesMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	lastDay := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, n := range r.ByMonthDay {
+		if n == day.Day() || (n < 0 && lastDay+1+n == day.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RRule) matchesWeekday(weekday time.Weekday) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, day := range r.ByDay {
+		if day.Day == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// weekdaysInRange возвращает дни [from, to), подходящие под BYDAY, с учетом порядковых номеров
+func weekdaysInRange(from, to time.Time, byDay []RRuleWeekday) []time.Time {
+	var result []time.Time
+	for _, weekday := range byDay {
+		var matches []time.Time
+		for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+			if day.Weekday() == weekday.Day {
+				matches = append(matches, day)
+			}
+		}
+
+		switch {
+		case weekday.N == 0:
+			result = append(result, matches...)
+		case weekday.N > 0 && weekday.N <= len(matches):
+			result = append(result, matches[weekday.N-1])
+		case weekday.N < 0 && -weekday.N <= len(matches):
+			result = append(result, matches[len(matches)+weekday.N])
+		}
+	}
+	return result
+}
+
+// mondayOffset возвращает номер дня недели, начиная с понедельника (0) до воскресенья (6)
+func mondayOffset(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
+
+func filterDays(days []time.Time, keep func(time.Time) bool) []time.Time {
+	result := days[:0]
+	for _, day := range days {
+		if keep(day) {
+			result = append(result, day)
+		}
+	}
+	return result
+}
+
+func containsDay(days []time.Time, day time.Time) bool {
+	for _, d := range days {
+		if d.Equal(day) {
+			return true
+		}
+	}
+	return false
+}
+
+func uniqueDays(days []time.Time) []time.Time {
+	if len(days) < 2 {
+		return days
+	}
+	result := days[:1]
+	for _, day := range days[1:] {
+		if !day.Equal(result[len(result)-1]) {
+			result = append(result, day)
+		}
+	}
+	return result
+}
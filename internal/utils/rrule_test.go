@@ -0,0 +1,229 @@
+package utils
+
+import (
+	"errors"
+	"ithozyeva/internal/models"
+	"testing"
+	"time"
+)
+
+func mustTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatalf("time.Parse(%q): %v", value, err)
+	}
+	return parsed
+}
+
+func TestParseRRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "еженедельно по вторникам и четвергам", value: "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH", want: "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH"},
+		{name: "регистр не важен", value: "freq=monthly;byday=-1fr;count=3", want: "FREQ=MONTHLY;COUNT=3;BYDAY=-1FR"},
+		{name: "дата UNTIL включает весь день", value: "FREQ=DAILY;UNTIL=20261020", want: "FREQ=DAILY;UNTIL=20261020T235959Z"},
+		{name: "INTERVAL=1 не выводится", value: "FREQ=YEARLY;INTERVAL=1;BYMONTH=2;BYMONTHDAY=-1", want: "FREQ=YEARLY;BYMONTHDAY=-1;BYMONTH=2"},
+		{name: "пустое правило", value: "", wantErr: true},
+		{name: "без FREQ", value: "COUNT=3", wantErr: true},
+		{name: "неизвестная частота", value: "FREQ=HOURLY", wantErr: true},
+		{name: "COUNT и UNTIL вместе", value: "FREQ=DAILY;COUNT=3;UNTIL=20261020", wantErr: true},
+		{name: "нулевой INTERVAL", value: "FREQ=DAILY;INTERVAL=0", wantErr: true},
+		{name: "порядковый BYDAY в WEEKLY", value: "FREQ=WEEKLY;BYDAY=2MO", wantErr: true},
+		{name: "BYMONTHDAY в WEEKLY", value: "FREQ=WEEKLY;BYMONTHDAY=1", wantErr: true},
+		{name: "неверный день недели", value: "FREQ=WEEKLY;BYDAY=XX", wantErr: true},
+		{name: "неподдерживаемый параметр", value: "FREQ=DAILY;BYHOUR=10", wantErr: true},
+		{name: "WKST кроме понедельника", value: "FREQ=WEEKLY;WKST=SU", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRRule(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRRule) {
+					t.Fatalf("ParseRRule(%q) error = %v, want %v", tt.value, err, ErrInvalidRRule)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRRule(%q) error = %v", tt.value, err)
+			}
+			if got := rule.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRRuleIterate(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("нет данных таймзон: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart string
+		loc     *time.Location
+		limit   int
+		want    []string
+	}{
+		{
+			name:    "BYDAY с COUNT",
+			rule:    "FREQ=WEEKLY;BYDAY=TU,TH;COUNT=4",
+			dtstart: "2026-10-06T18:00:00Z",
+			want:    []string{"2026-10-06T18:00:00Z", "2026-10-08T18:00:00Z", "2026-10-13T18:00:00Z", "2026-10-15T18:00:00Z"},
+		},
+		{
+			name:    "дни раньше DTSTART в первой неделе пропускаются",
+			rule:    "FREQ=WEEKLY;BYDAY=MO,FR;COUNT=3",
+			dtstart: "2026-10-07T10:00:00Z",
+			want:    []string{"2026-10-09T10:00:00Z", "2026-10-12T10:00:00Z", "2026-10-16T10:00:00Z"},
+		},
+		{
+			name:    "INTERVAL с UNTIL",
+			rule:    "FREQ=DAILY;INTERVAL=2;UNTIL=20261007T100000Z",
+			dtstart: "2026-10-01T10:00:00Z",
+			want:    []string{"2026-10-01T10:00:00Z", "2026-10-03T10:00:00Z", "2026-10-05T10:00:00Z", "2026-10-07T10:00:00Z"},
+		},
+		{
+			name:    "последняя пятница месяца",
+			rule:    "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+			dtstart: "2026-10-01T16:00:00Z",
+			want:    []string{"2026-10-30T16:00:00Z", "2026-11-27T16:00:00Z", "2026-12-25T16:00:00Z"},
+		},
+		{
+			name:    "31 число пропускает короткие месяцы",
+			rule:    "FREQ=MONTHLY;COUNT=3",
+			dtstart: "2026-10-31T12:00:00Z",
+			want:    []string{"2026-10-31T12:00:00Z", "2026-12-31T12:00:00Z", "2027-01-31T12:00:00Z"},
+		},
+		{
+			name:    "29 февраля только в високосные годы",
+			rule:    "FREQ=YEARLY;COUNT=2",
+			dtstart: "2028-02-29T12:00:00Z",
+			want:    []string{"2028-02-29T12:00:00Z", "2032-02-29T12:00:00Z"},
+		},
+		{
+			name:    "переход на зимнее время не сдвигает местное время",
+			rule:    "FREQ=WEEKLY;COUNT=2",
+			dtstart: "2026-10-19T17:00:00Z",
+			loc:     berlin,
+			want:    []string{"2026-10-19T17:00:00Z", "2026-10-26T18:00:00Z"},
+		},
+		{
+			name:    "без COUNT и UNTIL перебор останавливает fn",
+			rule:    "FREQ=DAILY",
+			dtstart: "2026-10-01T10:00:00Z",
+			limit:   2,
+			want:    []string{"2026-10-01T10:00:00Z", "2026-10-02T10:00:00Z"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRRule(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRRule(%q) error = %v", tt.rule, err)
+			}
+			loc := tt.loc
+			if loc == nil {
+				loc = time.UTC
+			}
+
+			var got []time.Time
+			rule.Iterate(mustTime(t, tt.dtstart), loc, func(occurrence time.Time) bool {
+				got = append(got, occurrence)
+				return tt.limit == 0 || len(got) < tt.limit
+			})
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Iterate() = %v, want %v", got, tt.want)
+			}
+			for i, want := range tt.want {
+				if !got[i].Equal(mustTime(t, want)) {
+					t.Errorf("occurrence %d = %s, want %s", i, got[i].UTC().Format(time.RFC3339), want)
+				}
+			}
+		})
+	}
+}
+
+func TestEventInstancesBetween(t *testing.T) {
+	rrule := "FREQ=WEEKLY;BYDAY=MO;COUNT=4"
+	moved := mustTime(t, "2026-10-21T18:00:00Z")
+
+	tests := []struct {
+		name       string
+		exceptions []models.EventOccurrence
+		from, to   string
+		want       []string
+	}{
+		{
+			name: "без исключений",
+			from: "2026-10-01T00:00:00Z",
+			to:   "2026-11-30T00:00:00Z",
+			want: []string{"2026-10-05T18:00:00Z", "2026-10-12T18:00:00Z", "2026-10-19T18:00:00Z", "2026-10-26T18:00:00Z"},
+		},
+		{
+			name: "отмененное повторение пропускается",
+			exceptions: []models.EventOccurrence{
+				{OccurrenceDate: mustTime(t, "2026-10-12T18:00:00Z"), Cancelled: true},
+			},
+			from: "2026-10-01T00:00:00Z",
+			to:   "2026-11-30T00:00:00Z",
+			want: []string{"2026-10-05T18:00:00Z", "2026-10-19T18:00:00Z", "2026-10-26T18:00:00Z"},
+		},
+		{
+			name: "перенесенное повторение возвращается с новой датой",
+			exceptions: []models.EventOccurrence{
+				{OccurrenceDate: mustTime(t, "2026-10-19T18:00:00Z"), OverrideDate: &moved},
+			},
+			from: "2026-10-01T00:00:00Z",
+			to:   "2026-11-30T00:00:00Z",
+			want: []string{"2026-10-05T18:00:00Z", "2026-10-12T18:00:00Z", "2026-10-21T18:00:00Z", "2026-10-26T18:00:00Z"},
+		},
+		{
+			name: "перенос в окно из-за его пределов",
+			exceptions: []models.EventOccurrence{
+				{OccurrenceDate: mustTime(t, "2026-10-19T18:00:00Z"), OverrideDate: &moved},
+			},
+			from: "2026-10-20T00:00:00Z",
+			to:   "2026-10-25T00:00:00Z",
+			want: []string{"2026-10-21T18:00:00Z"},
+		},
+		{
+			name: "перенос за пределы окна",
+			exceptions: []models.EventOccurrence{
+				{OccurrenceDate: mustTime(t, "2026-10-19T18:00:00Z"), OverrideDate: &moved},
+			},
+			from: "2026-10-18T00:00:00Z",
+			to:   "2026-10-20T00:00:00Z",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &models.Event{
+				Date:       mustTime(t, "2026-10-05T18:00:00Z"),
+				RRule:      &rrule,
+				Exceptions: tt.exceptions,
+			}
+
+			got := EventInstancesBetween(event, mustTime(t, tt.from), mustTime(t, tt.to))
+			if len(got) != len(tt.want) {
+				t.Fatalf("EventInstancesBetween() returned %d instances, want %d", len(got), len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !got[i].Date.Equal(mustTime(t, want)) {
+					t.Errorf("instance %d = %s, want %s", i, got[i].Date.Format(time.RFC3339), want)
+				}
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"log"
 	"net/url"
 	"strings"
+	"time"
 
 	"ithozyeva/config"
 
@@ -235,3 +236,43 @@ func (c *S3Client) Download(ctx context.Context, key string) ([]byte, error) {
 	}
 	return buf.Bytes(), nil
 }
+
+// PresignGet возвращает временную ссылку на скачивание объекта
+func (c *S3Client) PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	presigner := s3.NewPresignClient(c.client)
+	req, err := presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", err
+	}
+	return req.URL, nil
+}
+
+// PresignPut возвращает временную ссылку, по которой объект загружается в S3 напрямую.
+// Загрузка должна передать тот же Content-Type, что подписан в ссылке
+func (c *S3Client) PresignPut(ctx context.Context, key string, contentType string, ttl time.Duration) (string, error) {
+	presigner := s3.NewPresignClient(c.client)
+	req, err := presigner.PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(c.bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", err
+	}
+	return req.URL, nil
+}
+
+// Size возвращает размер объекта в байтах
+func (c *S3Client) Size(ctx context.Context, key string) (int64, error) {
+	obj, err := c.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return aws.ToInt64(obj.ContentLength), nil
+}
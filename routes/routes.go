@@ -51,10 +51,33 @@ func SetupPublicRoutes(app *fiber.App, db *gorm.DB) {
 	reviewHandler := handler.NewReviewOnCommunityHandler()
 	api.Get("/review-on-community", reviewHandler.GetApproved)
 
+	// Публичная сводка реферальных ссылок по компаниям
+	referalLinkHandler := handler.NewReferalLinkHandler()
+	api.Get("/referals/summary", referalLinkHandler.GetCompanySummary)
+
+	companyHandler := handler.NewCompanyHandler()
+	api.Get("/companies/:id/logo", companyHandler.GetLogo)
+
+	vacancyHandler := handler.NewVacancyHandler()
+	api.Get("/vacancies", vacancyHandler.SearchPublished)
+	api.Get("/vacancies/:id", vacancyHandler.GetPublished)
+
 	eventsHandler := handler.NewEventsHandler()
 	api.Get("/events/old", eventsHandler.GetOld)
 	api.Get("/events/next", eventsHandler.GetNext)
 	api.Get("/events/ics", eventsHandler.GetICSFile)
+	api.Get("/events/calendar.ics", eventsHandler.GetCalendarFeed)
+	api.Get("/events/calendar/:token.ics", eventsHandler.GetMemberCalendarFeed)
+
+	eventSeriesHandler := handler.NewEventSeriesHandler()
+	api.Get("/events/series", eventSeriesHandler.GetPublished)
+	api.Get("/events/series/:id", eventSeriesHandler.GetPublishedById)
+	api.Get("/events/series/:id/ics", eventSeriesHandler.GetICSFile)
+
+	// Каталог записей: без авторизации доступны только публичные
+	recordingHandler := handler.NewEventRecordingHandler()
+	api.Get("/recordings", recordingHandler.SearchAvailable)
+	api.Get("/recordings/:id", recordingHandler.GetAvailable)
 
 	// Маршруты для словарей
 	dictionaryHandler := handler.NewDictionaryHandler()
@@ -75,9 +98,26 @@ func SetupAdminRoutes(app *fiber.App, db *gorm.DB) {
 	mentors.Post("/", authMiddleware.RequirePermission(models.PermissionCanEditAdminMentors), mentorHandler.Create)
 	mentors.Put("/:id", authMiddleware.RequirePermission(models.PermissionCanEditAdminMentors), mentorHandler.Update)
 	mentors.Delete("/:id", authMiddleware.RequirePermission(models.PermissionCanEditAdminMentors), mentorHandler.Delete)
-	mentors.Post("/review", mentorHandler.AddReviewToService)
+	mentors.Post("/review", authMiddleware.RequirePermission(models.PermissionCanEditAdminMentorsReview), mentorHandler.AddReviewToService)
 	mentors.Get("/:id/services", mentorHandler.GetServices)
 
+	mentorRevisionHandler := handler.NewMentorRevisionHandler()
+	mentors.Get("/:id/revisions", mentorRevisionHandler.GetHistory)
+	mentorRevisions := protected.Group("/mentor-revisions", authMiddleware.RequirePermission(models.PermissionCanViewAdminMentors))
+	mentorRevisions.Get("/", mentorRevisionHandler.Search)
+	mentorRevisions.Get("/:id", mentorRevisionHandler.GetById)
+	mentorRevisions.Get("/:id/diff", mentorRevisionHandler.Diff)
+	mentorRevisions.Post("/:id/approve", authMiddleware.RequirePermission(models.PermissionCanEditAdminMentors), mentorRevisionHandler.Approve)
+	mentorRevisions.Post("/:id/reject", authMiddleware.RequirePermission(models.PermissionCanEditAdminMentors), mentorRevisionHandler.Reject)
+	mentorRevisions.Post("/:id/rollback", authMiddleware.RequirePermission(models.PermissionCanEditAdminMentors), mentorRevisionHandler.Rollback)
+
+	mentorApplicationHandler := handler.NewMentorApplicationHandler()
+	mentorApplications := protected.Group("/mentor-applications", authMiddleware.RequirePermission(models.PermissionCanViewAdminMentors))
+	mentorApplications.Get("/", mentorApplicationHandler.Search)
+	mentorApplications.Get("/:id", mentorApplicationHandler.GetById)
+	mentorApplications.Post("/:id/approve", authMiddleware.RequirePermission(models.PermissionCanEditAdminMentors), mentorApplicationHandler.Approve)
+	mentorApplications.Post("/:id/reject", authMiddleware.RequirePermission(models.PermissionCanEditAdminMentors), mentorApplicationHandler.Reject)
+
 	// Здесь будут защищенные маршруты
 
 	// Маршруты для профессиональных тегов
@@ -113,6 +153,7 @@ func SetupAdminRoutes(app *fiber.App, db *gorm.DB) {
 	reviewOnServiceHandler := handler.NewReviewOnServiceHandler()
 	reviewsOnService := protected.Group("/reviews-on-service", authMiddleware.RequirePermission(models.PermissionCanViewAdminMentorsReview))
 	reviewsOnService.Get("/", reviewOnServiceHandler.Search)
+	reviewsOnService.Post("/:id/approve", authMiddleware.RequirePermission(models.PermissionCanApproveAdminMentorsReview), reviewOnServiceHandler.Approve)
 	reviewsOnService.Get("/:id", reviewOnServiceHandler.GetById)
 	reviewsOnService.Post("/", authMiddleware.RequirePermission(models.PermissionCanEditAdminMentorsReview), reviewOnServiceHandler.CreateReview)
 	reviewsOnService.Patch("/:id", authMiddleware.RequirePermission(models.PermissionCanEditAdminMentorsReview), reviewOnServiceHandler.Update)
@@ -120,17 +161,69 @@ func SetupAdminRoutes(app *fiber.App, db *gorm.DB) {
 
 	// Маршруты для ивентов
 	eventHandler := handler.NewEventsHandler()
+	eventHostingHandler := handler.NewEventHostingHandler()
 	events := protected.Group("/events", authMiddleware.RequirePermission(models.PermissionCanViewAdminEvents))
 	events.Get("/", eventHandler.Search)
 	events.Get("/:id", eventHandler.GetById)
 	events.Post("/", authMiddleware.RequirePermission(models.PermissionCanEditAdminEvents), eventHandler.Create)
 	events.Put("/:id", authMiddleware.RequirePermission(models.PermissionCanEditAdminEvents), eventHandler.Update)
 	events.Delete("/:id", authMiddleware.RequirePermission(models.PermissionCanEditAdminEvents), eventHandler.Delete)
+	events.Put("/:id/occurrences", authMiddleware.RequirePermission(models.PermissionCanEditAdminEvents), eventHandler.SetOccurrenceOverride)
+	events.Post("/:id/publish", authMiddleware.RequirePermission(models.PermissionCanPublishAdminEvents), eventHostingHandler.Publish)
+	events.Get("/:id/feedback", eventHandler.GetFeedbackReport)
+
+	// Маршруты для серий событий
+	eventSeriesHandler := handler.NewEventSeriesHandler()
+	eventSeries := protected.Group("/event-series", authMiddleware.RequirePermission(models.PermissionCanViewAdminEvents))
+	eventSeries.Get("/", eventSeriesHandler.Search)
+	eventSeries.Get("/:id", eventSeriesHandler.GetById)
+	eventSeries.Post("/", authMiddleware.RequirePermission(models.PermissionCanEditAdminEvents), eventSeriesHandler.Create)
+	eventSeries.Put("/:id", authMiddleware.RequirePermission(models.PermissionCanEditAdminEvents), eventSeriesHandler.Update)
+	eventSeries.Delete("/:id", authMiddleware.RequirePermission(models.PermissionCanEditAdminEvents), eventSeriesHandler.Delete)
+
+	// Маршруты для записей событий
+	recordingHandler := handler.NewEventRecordingHandler()
+	recordings := protected.Group("/recordings", authMiddleware.RequirePermission(models.PermissionCanViewAdminEvents))
+	recordings.Get("/", recordingHandler.Search)
+	recordings.Get("/:id", recordingHandler.GetById)
+	recordings.Post("/", authMiddleware.RequirePermission(models.PermissionCanEditAdminEvents), recordingHandler.Create)
+	recordings.Put("/:id", authMiddleware.RequirePermission(models.PermissionCanEditAdminEvents), recordingHandler.Update)
+	recordings.Delete("/:id", authMiddleware.RequirePermission(models.PermissionCanEditAdminEvents), recordingHandler.Delete)
+	recordings.Post("/:id/file/upload", authMiddleware.RequirePermission(models.PermissionCanEditAdminEvents), recordingHandler.CreateUpload)
+	recordings.Post("/:id/file", authMiddleware.RequirePermission(models.PermissionCanEditAdminEvents), recordingHandler.AttachFile)
+	recordings.Delete("/:id/file", authMiddleware.RequirePermission(models.PermissionCanEditAdminEvents), recordingHandler.DeleteFile)
+
+	// Справочник компаний
+	companyHandler := handler.NewCompanyHandler()
+	companies := protected.Group("/companies", authMiddleware.RequirePermission(models.PermissionCanViewAdminCompanies))
+	companies.Get("/", companyHandler.Search)
+	companies.Get("/:id", companyHandler.GetOverview)
+	companies.Post("/", authMiddleware.RequirePermission(models.PermissionCanEditAdminCompanies), companyHandler.Create)
+	companies.Put("/:id", authMiddleware.RequirePermission(models.PermissionCanEditAdminCompanies), companyHandler.Update)
+	companies.Delete("/:id", authMiddleware.RequirePermission(models.PermissionCanEditAdminCompanies), companyHandler.Delete)
+	companies.Post("/:id/aliases", authMiddleware.RequirePermission(models.PermissionCanEditAdminCompanies), companyHandler.AddAlias)
+	companies.Delete("/:id/aliases/:aliasId", authMiddleware.RequirePermission(models.PermissionCanEditAdminCompanies), companyHandler.DeleteAlias)
+	companies.Post("/:id/merge", authMiddleware.RequirePermission(models.PermissionCanEditAdminCompanies), companyHandler.Merge)
+	companies.Post("/:id/logo", authMiddleware.RequirePermission(models.PermissionCanEditAdminCompanies), companyHandler.UploadLogo)
+	companies.Delete("/:id/logo", authMiddleware.RequirePermission(models.PermissionCanEditAdminCompanies), companyHandler.DeleteLogo)
+
+	// Модерация вакансий
+	vacancyHandler := handler.NewVacancyHandler()
+	vacancies := protected.Group("/vacancies", authMiddleware.RequirePermission(models.PermissionCanViewAdminVacancies))
+	vacancies.Get("/", vacancyHandler.Search)
+	vacancies.Get("/:id", vacancyHandler.GetById)
+	vacancies.Post("/:id/approve", authMiddleware.RequirePermission(models.PermissionCanEditAdminVacancies), vacancyHandler.Approve)
+	vacancies.Post("/:id/reject", authMiddleware.RequirePermission(models.PermissionCanEditAdminVacancies), vacancyHandler.Reject)
+	vacancies.Delete("/:id", authMiddleware.RequirePermission(models.PermissionCanEditAdminVacancies), vacancyHandler.Delete)
+
 	resumeHandler := handler.NewResumeHandler()
 	resumes := protected.Group("/resumes", authMiddleware.RequirePermission(models.PermissionCanViewAdminResumes))
 	resumes.Get("/", resumeHandler.AdminList)
 	resumes.Get("/download", resumeHandler.AdminDownload)
+	resumes.Post("/reindex", authMiddleware.RequirePermission(models.PermissionCanEditAdminResumes), resumeHandler.AdminReindex)
 	resumes.Get("/:id", resumeHandler.AdminGet)
+	resumes.Get("/:id/history", resumeHandler.AdminHistory)
+	resumes.Post("/:id/reparse", authMiddleware.RequirePermission(models.PermissionCanEditAdminResumes), resumeHandler.AdminReparse)
 
 	// Маршруты для тегов ивентов
 	eventTagHandler := handler.NewEventTagHandler()
@@ -159,6 +252,13 @@ func SetupPlatformRoutes(app *fiber.App, db *gorm.DB) {
 	members.Get("/me", memberHandler.Me)
 	members.Patch("/me", memberHandler.UpdateProfile)
 
+	// Справочник компаний: страница компании и место работы участника
+	companyHandler := handler.NewCompanyHandler()
+	members.Put("/me/company", companyHandler.SetMyCompany)
+	companies := protected.Group("/companies")
+	companies.Get("/", companyHandler.Search)
+	companies.Get("/:id", companyHandler.GetOverview)
+
 	// Маршруты для ментора
 	mentorsHandler := handler.NewMentorHandler()
 	mentorsMe := protected.Group("/mentors/me")
@@ -166,13 +266,69 @@ func SetupPlatformRoutes(app *fiber.App, db *gorm.DB) {
 	mentorsMe.Post("/update-prof-tags", authMiddleware.RequirePermission(models.PermissionCanEditPlatformMentors), mentorsHandler.UpdateProfTags)
 	mentorsMe.Post("/update-services", authMiddleware.RequirePermission(models.PermissionCanEditPlatformMentors), mentorsHandler.UpdateServices)
 	mentorsMe.Post("/update-contacts", authMiddleware.RequirePermission(models.PermissionCanEditPlatformMentors), mentorsHandler.UpdateContacts)
+	mentorsMe.Get("/availability", authMiddleware.RequirePermission(models.PermissionCanEditPlatformMentors), mentorsHandler.GetAvailability)
+	mentorsMe.Put("/availability", authMiddleware.RequirePermission(models.PermissionCanEditPlatformMentors), mentorsHandler.UpdateAvailability)
+	platformMentorRevisionHandler := handler.NewMentorRevisionHandler()
+	mentorsMe.Get("/revisions", authMiddleware.RequirePermission(models.PermissionCanEditPlatformMentors), platformMentorRevisionHandler.GetMine)
+
+	// Расписание ментора и записи к нему
+	mentorBookingHandler := handler.NewMentorBookingHandler()
+	mentorsMe.Get("/slots", authMiddleware.RequirePermission(models.PermissionCanEditPlatformMentors), mentorBookingHandler.GetMySlots)
+	mentorsMe.Post("/slots", authMiddleware.RequirePermission(models.PermissionCanEditPlatformMentors), mentorBookingHandler.AddSlots)
+	mentorsMe.Delete("/slots/:id", authMiddleware.RequirePermission(models.PermissionCanEditPlatformMentors), mentorBookingHandler.DeleteSlot)
+	mentorsMe.Get("/bookings", authMiddleware.RequirePermission(models.PermissionCanEditPlatformMentors), mentorBookingHandler.GetMentorBookings)
+	protected.Get("/mentors/:id/slots", mentorBookingHandler.GetFreeSlots)
+	protected.Post("/mentors/match", mentorsHandler.Match)
+
+	// Записи участника к менторам
+	bookings := protected.Group("/mentor-bookings")
+	bookings.Get("/", mentorBookingHandler.GetMyBookings)
+	bookings.Post("/", mentorBookingHandler.Book)
+	bookings.Post("/:id/confirm", mentorBookingHandler.Confirm)
+	bookings.Post("/:id/cancel", mentorBookingHandler.Cancel)
+	bookings.Post("/:id/reschedule", mentorBookingHandler.Reschedule)
+	reviewOnServiceHandler := handler.NewReviewOnServiceHandler()
+	bookings.Post("/:id/review", reviewOnServiceHandler.AddVerifiedReview)
+
+	// Заявки на менторство
+	platformMentorApplicationHandler := handler.NewMentorApplicationHandler()
+	mentorApplications := protected.Group("/mentor-applications")
+	mentorApplications.Get("/me", platformMentorApplicationHandler.GetMine)
+	mentorApplications.Post("/", platformMentorApplicationHandler.Submit)
 
 	// Маршруты для ивентов
 	eventHandler := handler.NewEventsHandler()
+	eventHostingHandler := handler.NewEventHostingHandler()
 	events := protected.Group("/events")
-	events.Get("/", eventHandler.Search)
+	events.Get("/", eventHandler.SearchPublished)
+	events.Get("/hosted", authMiddleware.RequirePermission(models.PermissionCanEditPlatformEvents), eventHostingHandler.GetHosted)
+	events.Post("/", authMiddleware.RequirePermission(models.PermissionCanEditPlatformEvents), eventHostingHandler.CreateDraft)
+	events.Put("/:id", authMiddleware.RequirePermission(models.PermissionCanEditPlatformEvents), eventHostingHandler.UpdateHosted)
+	events.Post("/:id/co-hosts", authMiddleware.RequirePermission(models.PermissionCanEditPlatformEvents), eventHostingHandler.InviteCoHost)
+	events.Delete("/:id/co-hosts/:memberId", authMiddleware.RequirePermission(models.PermissionCanEditPlatformEvents), eventHostingHandler.RemoveCoHost)
+	events.Get("/invitations", eventHostingHandler.GetInvitations)
+	events.Post("/invitations/:id/accept", eventHostingHandler.AcceptInvitation)
+	events.Post("/invitations/:id/decline", eventHostingHandler.DeclineInvitation)
+
+	eventSeriesHandler := handler.NewEventSeriesHandler()
+	events.Post("/series/:id/apply", eventSeriesHandler.Apply)
+	events.Post("/series/:id/decline", eventSeriesHandler.Decline)
 	events.Post("/apply", eventHandler.AddMember)
 	events.Post("/decline", eventHandler.RemoveMember)
+	events.Get("/:id/occurrences", eventHandler.GetOccurrences)
+	events.Get("/calendar", eventHandler.GetMyCalendar)
+	events.Post("/calendar/reset", eventHandler.ResetMyCalendar)
+	events.Get("/:id/check-in-code", eventHandler.GetCheckInCode)
+	events.Post("/:id/check-in", eventHandler.CheckIn)
+	events.Get("/:id/attendance", eventHandler.GetAttendanceReport)
+	events.Get("/:id/feedback", eventHandler.GetFeedbackReport)
+
+	// Каталог записей с учетом доступа участника
+	recordingHandler := handler.NewEventRecordingHandler()
+	recordings := protected.Group("/recordings")
+	recordings.Get("/", recordingHandler.SearchAvailable)
+	recordings.Get("/:id", recordingHandler.GetAvailable)
+	recordings.Get("/:id/file", recordingHandler.GetFile)
 
 	// Маршурты для таблицы рефералов
 	referalsHandler := handler.NewReferalLinkHandler()
@@ -181,11 +337,42 @@ func SetupPlatformRoutes(app *fiber.App, db *gorm.DB) {
 	referals.Post("/add-link", referalsHandler.AddLink)
 	referals.Put("/update-link", referalsHandler.UpdateLink)
 	referals.Delete("/delete-link", referalsHandler.DeleteLink)
+	referals.Post("/:id/confirm", referalsHandler.ConfirmLink)
+
+	referalRequestHandler := handler.NewReferalRequestHandler()
+	referals.Post("/requests", referalRequestHandler.Submit)
+	referals.Get("/requests/my", referalRequestHandler.GetMine)
+	referals.Get("/requests/incoming", referalRequestHandler.GetIncoming)
+	referals.Post("/requests/:id/status", referalRequestHandler.ChangeStatus)
+	referals.Get("/requests/:id/resume", referalRequestHandler.GetResume)
+
+	// Доска вакансий от участников
+	vacancyHandler := handler.NewVacancyHandler()
+	vacancies := protected.Group("/vacancies")
+	vacancies.Get("/", vacancyHandler.SearchPublished)
+	vacancies.Get("/my", vacancyHandler.GetMine)
+	vacancies.Get("/subscription", vacancyHandler.GetSubscription)
+	vacancies.Put("/subscription", vacancyHandler.UpdateSubscription)
+	vacancies.Get("/:id", vacancyHandler.GetPublished)
+	vacancies.Post("/", vacancyHandler.Submit)
+	vacancies.Put("/:id", vacancyHandler.UpdateMine)
+	vacancies.Post("/:id/close", vacancyHandler.Close)
+	vacancies.Delete("/:id", vacancyHandler.DeleteMine)
+
+	// Сохраненные поиски с уведомлениями в боте
+	savedSearchHandler := handler.NewSavedSearchHandler()
+	savedSearches := protected.Group("/saved-searches")
+	savedSearches.Get("/", savedSearchHandler.GetMine)
+	savedSearches.Post("/", savedSearchHandler.Save)
+	savedSearches.Put("/:id", savedSearchHandler.Update)
+	savedSearches.Delete("/:id", savedSearchHandler.Delete)
 
 	resumeHandler := handler.NewResumeHandler()
 	resumes := protected.Group("/resumes")
 	resumes.Post("/", resumeHandler.Upload)
 	resumes.Get("/me", resumeHandler.ListMy)
+	resumes.Get("/me/history", resumeHandler.HistoryMy)
+	resumes.Put("/:id/primary", resumeHandler.SetPrimaryMy)
 	resumes.Patch("/:id", resumeHandler.UpdateMy)
 	resumes.Delete("/:id", resumeHandler.DeleteMy)
 }